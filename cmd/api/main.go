@@ -2,12 +2,21 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"go-pismo-challenge/pkg/clock"
 	"go-pismo-challenge/pkg/config"
 	"go-pismo-challenge/pkg/database"
 	"go-pismo-challenge/pkg/handler"
+	"go-pismo-challenge/pkg/job"
+	"go-pismo-challenge/pkg/migrator"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/publisher"
 	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/memory"
+	"go-pismo-challenge/pkg/repository/sqlite"
 	"go-pismo-challenge/pkg/server"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/telemetry"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,8 +27,24 @@ import (
 )
 
 type Service struct {
-	accountHandler *handler.Account
-	trxHandler     *handler.Transaction
+	accountHandler      *handler.Account
+	accountEventHandler *handler.AccountEvent
+	trxHandler          *handler.Transaction
+	transferHandler     *handler.Transfer
+	webhookHandler      *handler.Webhook
+	statementHandler    *handler.Statement
+	accrualHandler      *handler.Accrual
+	ledgerHandler       *handler.Ledger
+	healthHandler       *handler.Health
+	statementCloser     *job.Job
+	accrualPoster       *job.Job
+	outboxRelay         *job.Job
+	webhookDeliverer    *job.Job
+	accountEvents       service.AccountEventService
+	shutdownTracing     telemetry.ShutdownFunc
+	serverOptions       server.Options
+	shutdownDrainDelay  time.Duration
+	shutdownGrace       time.Duration
 }
 
 // @title Pismo API
@@ -38,44 +63,339 @@ func main() {
 func NewService(ctx context.Context) *Service {
 	cfg := config.LoadConfig()
 
-	db, err := database.NewConnection(cfg.DSN(), cfg.DatabaseMaxOpenConns)
+	log.Logger = log.Hook(telemetry.LogHook{})
+
+	shutdownTracing, err := telemetry.Setup(ctx, telemetry.Options{
+		ServiceName:  cfg.ServiceName,
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		OTLPInsecure: cfg.TracingOTLPInsecure,
+		SampleRatio:  cfg.TracingSampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(fmt.Errorf("failed to establish database connection: %w", err))
+		log.Fatal().Err(err).Msg("failed to set up tracing")
+	}
+
+	var repos repositories
+	switch cfg.RepositoryBackend {
+	case config.RepositoryBackendPostgres:
+		repos = newPostgresRepositories(ctx, cfg)
+	case config.RepositoryBackendSQLite:
+		repos = newSQLiteRepositories(ctx, cfg)
+	case config.RepositoryBackendMemory:
+		log.Warn().Msg("using in-memory repositories, data is lost on restart")
+		repos = newMemoryRepositories(cfg)
+	default:
+		log.Fatal().Str("backend", cfg.RepositoryBackend).Msg("unknown repository backend")
+	}
+
+	accountService := service.NewAccountService(repos.accounts, repos.ratePlans, repos.outbox, repos.unitOfWork)
+	trxService := service.NewTransactionService(repos.transactions, repos.accounts, repos.operationTypes, repos.ledger, repos.outbox,
+		repos.unitOfWork)
+	statementService := service.NewStatementService(repos.statements, repos.accounts, repos.transactions, repos.operationTypes,
+		repos.ledger, repos.unitOfWork, service.StatementOptions{
+			MinPaymentPercent: cfg.StatementMinPaymentPercent,
+			MinPaymentFloor:   cfg.StatementMinPaymentFloor,
+			Currency:          cfg.StatementCurrency,
+		})
+	accrualService := service.NewAccrualService(repos.statements, repos.accounts, repos.ratePlans, repos.transactions, trxService,
+		clock.System())
+	ledgerService := service.NewLedgerService(repos.ledger)
+	transferService := service.NewTransferService(repos.transfers, repos.accounts, repos.ledger, trxService,
+		repos.unitOfWork)
+	webhookSender := publisher.NewWebhookSender(publisher.WebhookSenderOptions{
+		Timeout:    cfg.WebhookTimeout,
+		AllowLocal: cfg.WebhookAllowLocal,
+	}, clock.System())
+	webhookService := service.NewWebhookService(repos.webhooks, webhookSender,
+		repos.unitOfWork, clock.System(), service.WebhookOptions{
+			BatchSize:              cfg.WebhookBatchSize,
+			InitialBackoff:         cfg.WebhookRetryInitialBackoff,
+			MaxBackoff:             cfg.WebhookRetryMaxBackoff,
+			MaxConsecutiveFailures: cfg.WebhookMaxConsecutiveFailures,
+		})
+	accountEventService := service.NewAccountEventService(repos.transactions, repos.accounts, repos.ledger, repos.listener,
+		service.AccountEventOptions{BatchSize: cfg.AccountEventsBatchSize})
+
+	accountHandler := handler.NewAccountHandler(accountService)
+	accountEventHandler := handler.NewAccountEventHandler(accountEventService, cfg.AccountEventsHeartbeatInterval,
+		cfg.AccountEventsWriteTimeout)
+	if cfg.TransactionBatchMode != model.BatchModeAllOrNothing && cfg.TransactionBatchMode != model.BatchModeBestEffort {
+		log.Fatal().Str("mode", cfg.TransactionBatchMode).Msg("unknown transaction batch mode")
 	}
+	trxHandler := handler.NewTransactionHandler(trxService, handler.TransactionBatchOptions{
+		DefaultMode: cfg.TransactionBatchMode,
+		MaxItems:    cfg.TransactionBatchMaxItems,
+	})
+	transferHandler := handler.NewTransferHandler(transferService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	statementHandler := handler.NewStatementHandler(statementService)
+	accrualHandler := handler.NewAccrualHandler(accrualService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+	healthHandler := handler.NewHealthHandler(cfg.HealthCheckTimeout, repos.healthChecks...)
 
-	if err := database.MigrationVersionCheck(ctx, db, cfg.DatabaseMigrationTable, cfg.DatabaseMinVersion); err != nil {
-		log.Fatal().Err(fmt.Errorf("failed while checking database migration version: %w", err))
+	var statementCloser *job.Job
+	if cfg.StatementJobInterval > 0 {
+		statementCloser = job.NewStatementCloser(statementService, cfg.StatementJobInterval, clock.System())
 	}
 
-	accountRepo := repository.NewAccountRepo(db)
-	accountHandler := handler.NewAccountHandler(accountRepo)
+	var accrualPoster *job.Job
+	if cfg.AccrualJobInterval > 0 {
+		accrualPoster = job.NewAccrualPoster(accrualService, cfg.AccrualJobInterval)
+	}
 
-	trxRepo := repository.NewTransactionRepo(db)
-	operationTypeRepo := repository.NewOperationTypeRepo(db)
-	trxHandler := handler.NewTransactionHandler(trxRepo, accountRepo, operationTypeRepo)
+	var outboxRelay *job.Job
+	if cfg.OutboxRelayInterval > 0 {
+		// the relay queues the deliveries of the events to the webhooks subscribed to them
+		relayPublisher := publisher.NewFanout(newPublisher(cfg), webhookService)
+		outboxService := service.NewOutboxService(repos.outbox, relayPublisher, repos.unitOfWork, clock.System(), service.OutboxOptions{
+			BatchSize:      cfg.OutboxBatchSize,
+			InitialBackoff: cfg.OutboxRetryInitialBackoff,
+			MaxBackoff:     cfg.OutboxRetryMaxBackoff,
+		})
+		outboxRelay = job.NewOutboxRelay(outboxService, cfg.OutboxRelayInterval)
+	}
+
+	var webhookDeliverer *job.Job
+	if cfg.WebhookDeliveryInterval > 0 {
+		webhookDeliverer = job.NewWebhookDeliverer(webhookService, cfg.WebhookDeliveryInterval)
+	}
 
 	return &Service{
-		accountHandler: accountHandler,
-		trxHandler:     trxHandler,
+		accountHandler:      accountHandler,
+		accountEventHandler: accountEventHandler,
+		trxHandler:          trxHandler,
+		transferHandler:     transferHandler,
+		webhookHandler:      webhookHandler,
+		statementHandler:    statementHandler,
+		accrualHandler:      accrualHandler,
+		ledgerHandler:       ledgerHandler,
+		healthHandler:       healthHandler,
+		statementCloser:     statementCloser,
+		accrualPoster:       accrualPoster,
+		outboxRelay:         outboxRelay,
+		webhookDeliverer:    webhookDeliverer,
+		accountEvents:       accountEventService,
+		shutdownTracing:     shutdownTracing,
+		serverOptions: server.Options{
+			Addr:                cfg.HTTPAddr,
+			ReadTimeout:         cfg.HTTPReadTimeout,
+			ReadHeaderTimeout:   cfg.HTTPReadHeaderTimeout,
+			WriteTimeout:        cfg.HTTPWriteTimeout,
+			IdleTimeout:         cfg.HTTPIdleTimeout,
+			MaxHeaderBytes:      cfg.HTTPMaxHeaderBytes,
+			MaxRequestBodyBytes: cfg.HTTPMaxRequestBodyBytes,
+			RequestTimeouts: server.RouteTimeouts{
+				Default: cfg.RequestTimeout,
+				Routes:  cfg.RequestTimeoutOverrides,
+			},
+			TLSCertFile:     cfg.TLSCertFile,
+			TLSKeyFile:      cfg.TLSKeyFile,
+			TLSClientCAFile: cfg.TLSClientCAFile,
+		},
+		shutdownDrainDelay: cfg.ShutdownDrainDelay,
+		shutdownGrace:      cfg.ShutdownGracePeriod,
+	}
+}
+
+type repositories struct {
+	accounts       repository.AccountConnector
+	transactions   repository.TransactionConnector
+	operationTypes repository.OperationTypeConnector
+	statements     repository.StatementConnector
+	ratePlans      repository.RatePlanConnector
+	ledger         repository.LedgerConnector
+	transfers      repository.TransferConnector
+	outbox         repository.OutboxConnector
+	webhooks       repository.WebhookConnector
+	listener       repository.TransactionListener
+	unitOfWork     repository.UnitOfWork
+	healthChecks   []handler.HealthCheck
+}
+
+func newPostgresRepositories(ctx context.Context, cfg config.Config) repositories {
+	db, err := database.NewConnection(ctx, cfg.DatabaseOptions(0))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to establish database connection")
+	}
+
+	return repositories{
+		accounts:       repository.NewAccountRepo(db),
+		transactions:   repository.NewTransactionRepo(db),
+		operationTypes: repository.NewOperationTypeRepo(db),
+		statements:     repository.NewStatementRepo(db),
+		ratePlans:      repository.NewRatePlanRepo(db),
+		ledger:         repository.NewLedgerRepo(db),
+		transfers:      repository.NewTransferRepo(db),
+		outbox:         repository.NewOutboxRepo(db),
+		webhooks:       repository.NewWebhookRepo(db),
+		listener:       repository.NewTransactionListener(cfg.DSN()),
+		unitOfWork:     repository.NewUnitOfWork(db, cfg.DatabaseTxMaxRetries),
+		healthChecks:   databaseHealthChecks(ctx, cfg, db),
+	}
+}
+
+func newSQLiteRepositories(ctx context.Context, cfg config.Config) repositories {
+	db, err := database.NewSQLiteConnection(ctx, cfg.SQLiteOptions(0))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to open sqlite database")
+	}
+
+	return repositories{
+		accounts:       sqlite.NewAccountRepo(db),
+		transactions:   sqlite.NewTransactionRepo(db),
+		operationTypes: sqlite.NewOperationTypeRepo(db),
+		statements:     sqlite.NewStatementRepo(db),
+		ratePlans:      sqlite.NewRatePlanRepo(db),
+		ledger:         sqlite.NewLedgerRepo(db),
+		transfers:      sqlite.NewTransferRepo(db),
+		outbox:         sqlite.NewOutboxRepo(db),
+		webhooks:       sqlite.NewWebhookRepo(db),
+		listener:       repository.NewPollingListener(cfg.AccountEventsPollInterval),
+		unitOfWork:     repository.NewUnitOfWork(db, cfg.DatabaseTxMaxRetries),
+		healthChecks:   databaseHealthChecks(ctx, cfg, db),
+	}
+}
+
+// databaseHealthChecks verifies that the schema version is supported by this binary and
+// returns the readiness checks of the database
+func databaseHealthChecks(ctx context.Context, cfg config.Config, db *sql.DB) []handler.HealthCheck {
+	maxVersion, err := migrator.LatestVersion(migrator.FS, cfg.MigrationDialect())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read known migration versions")
+	}
+	minVersion := int64(cfg.DatabaseMinVersion)
+	m, err := migrator.New(db, cfg.MigrationOptions(), migrator.FS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialise migrator")
+	}
+
+	versionInfo, err := database.MigrationVersionCheck(ctx, m, minVersion, maxVersion)
+	if err != nil {
+		log.Fatal().Err(err).Int64("current", versionInfo.Current).Int64("min", minVersion).Int64("max", maxVersion).
+			Msg("failed while checking database migration version")
+	}
+	log.Info().Int64("current", versionInfo.Current).Int64("min", minVersion).Int64("max", maxVersion).
+		Msg("database migration version check successful")
+
+	return []handler.HealthCheck{
+		{Name: "database", Check: func(ctx context.Context) (any, error) {
+			return nil, db.PingContext(ctx)
+		}},
+		{Name: "migration", Check: func(ctx context.Context) (any, error) {
+			return database.MigrationVersionCheck(ctx, m, minVersion, maxVersion)
+		}},
+	}
+}
+
+// newPublisher returns the publisher of the outbox events, the file is kept open while the
+// service runs
+func newPublisher(cfg config.Config) publisher.Publisher {
+	switch cfg.OutboxPublisher {
+	case config.OutboxPublisherStdout:
+		return publisher.NewWriterPublisher(os.Stdout)
+	case config.OutboxPublisherFile:
+		f, err := os.OpenFile(cfg.OutboxFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", cfg.OutboxFilePath).Msg("failed to open outbox file")
+		}
+
+		return publisher.NewWriterPublisher(f)
+	case config.OutboxPublisherWebhook:
+		if cfg.OutboxWebhookURL == "" {
+			log.Fatal().Msg("OUTBOX_WEBHOOK_URL is required by the webhook outbox publisher")
+		}
+
+		return publisher.NewWebhookPublisher(cfg.OutboxWebhookURL, cfg.OutboxWebhookTimeout)
+	default:
+		log.Fatal().Str("publisher", cfg.OutboxPublisher).Msg("unknown outbox publisher")
+	}
+
+	return nil
+}
+
+func newMemoryRepositories(cfg config.Config) repositories {
+	return repositories{
+		accounts:       memory.NewAccountRepo(),
+		transactions:   memory.NewTransactionRepo(),
+		operationTypes: memory.NewOperationTypeRepo(),
+		statements:     memory.NewStatementRepo(),
+		ratePlans:      memory.NewRatePlanRepo(),
+		ledger:         memory.NewLedgerRepo(),
+		transfers:      memory.NewTransferRepo(),
+		outbox:         memory.NewOutboxRepo(),
+		webhooks:       memory.NewWebhookRepo(),
+		listener:       repository.NewPollingListener(cfg.AccountEventsPollInterval),
+		unitOfWork:     memory.NewUnitOfWork(),
 	}
 }
 
 func (s *Service) Run(ctx context.Context) {
-	webServer := server.NewServer(s.accountHandler, s.trxHandler)
+	webServer, err := server.NewServer(s.serverOptions, server.Handlers{
+		Account:      s.accountHandler,
+		AccountEvent: s.accountEventHandler,
+		Transaction:  s.trxHandler,
+		Transfer:     s.transferHandler,
+		Webhook:      s.webhookHandler,
+		Statement:    s.statementHandler,
+		Accrual:      s.accrualHandler,
+		Ledger:       s.ledgerHandler,
+		Health:       s.healthHandler,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to configure server")
+	}
+
 	go func() {
-		if err := webServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal().Err(err)
+		log.Info().Str("addr", webServer.Addr).Bool("tls", webServer.TLSConfig != nil).Msg("starting server")
+		if err := server.ListenAndServe(webServer); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("server failed")
+		}
+	}()
+
+	// the job stops with ctx, a cycle being closed is rolled back and closed on the next start
+	if s.statementCloser != nil {
+		go s.statementCloser.Run(ctx)
+	}
+	// accruals are keyed by day, posting them again after a restart is a no-op
+	if s.accrualPoster != nil {
+		go s.accrualPoster.Run(ctx)
+	}
+	// events are delivered at least once, an event published but not marked before the relay
+	// stops is published again on the next start
+	if s.outboxRelay != nil {
+		go s.outboxRelay.Run(ctx)
+	}
+	// a delivery sent but not recorded before the job stops is sent again on the next start
+	if s.webhookDeliverer != nil {
+		go s.webhookDeliverer.Run(ctx)
+	}
+	// streams read the transactions they have not sent when woken, a notification missed while the
+	// listener reconnects only delays them until the reconnection wakes every stream
+	go func() {
+		if err := s.accountEvents.Listen(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to listen for transactions, account event streams are not woken")
 		}
 	}()
 
 	defer func() {
+		// fail readiness first and give the load balancer time to stop sending traffic
+		s.healthHandler.SetShuttingDown()
+		log.Info().Dur("drain_delay", s.shutdownDrainDelay).Msg("shutdown signal received, draining")
+		time.Sleep(s.shutdownDrainDelay)
+
 		// create a new context for shutdown timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownGrace)
 		defer cancel()
 
 		if err := webServer.Shutdown(shutdownCtx); err != nil {
 			log.Fatal().Err(err).Msg("server shutdown failed")
 		}
+
+		// flush spans of the requests drained above
+		if err := s.shutdownTracing(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
 	}()
 
 	<-ctx.Done()
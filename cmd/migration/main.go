@@ -2,20 +2,281 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"go-pismo-challenge/pkg/config"
+	"go-pismo-challenge/pkg/database"
 	"go-pismo-challenge/pkg/migrator"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pressly/goose/v3"
 	"github.com/rs/zerolog/log"
 )
 
+// exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage: migration [flags] [command] [args]
+
+Commands:
+  up                 apply all pending migrations (default)
+  up-to <version>    apply pending migrations up to and including version
+  down               roll back the current migration
+  down-to <version>  roll back migrations until version is the current one
+  redo               roll back the current migration and apply it again
+  status             list migrations and whether they are applied
+  version            print the current migration version
+  create <name>      create an empty SQL migration for every dialect, or in --dir
+
+Flags:
+`
+
 func main() {
-	log.Info().Msg("initiating migration")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("migration", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dryRun := flags.Bool("dry-run", false, "print the SQL that would run without applying it")
+	dir := flags.String("dir", "", "directory new migrations are created in (default: the directory of every dialect)")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	command := "up"
+	if flags.NArg() > 0 {
+		command = flags.Arg(0)
+	}
+	cmdArgs := flags.Args()
+	if len(cmdArgs) > 0 {
+		cmdArgs = cmdArgs[1:]
+	}
+
+	var (
+		version int64
+		err     error
+	)
+	switch command {
+	case "up-to", "down-to":
+		if len(cmdArgs) != 1 {
+			fmt.Fprintf(stderr, "%s requires exactly one version argument\n", command)
+
+			return exitUsage
+		}
+		if version, err = strconv.ParseInt(cmdArgs[0], 10, 64); err != nil || version < 0 {
+			fmt.Fprintf(stderr, "invalid version %q\n", cmdArgs[0])
+
+			return exitUsage
+		}
+	case "create":
+		if len(cmdArgs) != 1 {
+			fmt.Fprintln(stderr, "create requires exactly one name argument")
+
+			return exitUsage
+		}
+
+		dirs := []string{*dir}
+		if *dir == "" {
+			if dirs, err = dialectDirs(); err != nil {
+				log.Error().Err(err).Msg("failed to create migration")
+
+				return exitFailure
+			}
+		}
+
+		for _, d := range dirs {
+			path, err := migrator.Create(d, cmdArgs[0])
+			if err != nil {
+				log.Error().Err(err).Msg("failed to create migration")
+
+				return exitFailure
+			}
+			fmt.Fprintln(stdout, path)
+		}
+
+		return exitOK
+	case "up", "down", "redo", "status", "version":
+		if len(cmdArgs) != 0 {
+			fmt.Fprintf(stderr, "%s takes no arguments\n", command)
+
+			return exitUsage
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", command)
+		flags.Usage()
+
+		return exitUsage
+	}
+
 	cfg := config.LoadConfig()
 
-	// run migration
-	if err := migrator.Run(context.Background(), cfg.DSN(), cfg.DatabaseMigrationTable, migrator.FS); err != nil {
-		log.Fatal().Err(err).Msg("failed to apply migration")
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to establish db connection")
+
+		return exitFailure
+	}
+	defer db.Close()
+
+	m, err := migrator.New(db, cfg.MigrationOptions(), migrator.FS)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to initialise migrator")
+
+		return exitFailure
+	}
+
+	if err := execute(ctx, m, command, version, *dryRun, stdout); err != nil {
+		log.Error().Err(err).Str("command", command).Msg("migration failed")
+
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func openDatabase(ctx context.Context, cfg config.Config) (*sql.DB, error) {
+	switch cfg.RepositoryBackend {
+	case config.RepositoryBackendPostgres:
+		// migrations may legitimately run longer than any API query. Two connections are needed,
+		// one holding the migration lock and one running goose
+		dbOpts := cfg.DatabaseOptions(2)
+		dbOpts.StatementTimeout = 0
+
+		return database.NewConnection(ctx, dbOpts)
+	case config.RepositoryBackendSQLite:
+		return database.NewSQLiteConnection(ctx, cfg.SQLiteOptions(1))
+	default:
+		return nil, fmt.Errorf("repository backend %q has no migrations", cfg.RepositoryBackend)
+	}
+}
+
+// dialectDirs returns the source directories of the migrations of every dialect
+func dialectDirs() ([]string, error) {
+	dirs := make([]string, 0, len(migrator.Dialects))
+	for _, d := range migrator.Dialects {
+		dir, err := migrator.Dir(d)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, filepath.Join("pkg/migrator", dir))
+	}
+
+	return dirs, nil
+}
+
+func execute(ctx context.Context, m *migrator.Migrator, command string, version int64, dryRun bool, stdout io.Writer) error {
+	switch command {
+	case "status":
+		return printStatus(ctx, m, stdout)
+	case "version":
+		v, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, v)
+
+		return nil
+	}
+
+	if dryRun {
+		return printPlan(ctx, m, command, version, stdout)
+	}
+
+	log.Info().Str("command", command).Msg("initiating migration")
+
+	var err error
+	switch command {
+	case "up":
+		err = m.Up(ctx)
+	case "up-to":
+		err = m.UpTo(ctx, version)
+	case "down":
+		err = m.Down(ctx)
+	case "down-to":
+		err = m.DownTo(ctx, version)
+	case "redo":
+		err = m.Redo(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("command", command).Msg("migration successfully")
+
+	return nil
+}
+
+func printPlan(ctx context.Context, m *migrator.Migrator, command string, version int64, stdout io.Writer) error {
+	var (
+		plan []migrator.PlannedMigration
+		err  error
+	)
+	switch command {
+	case "up":
+		plan, err = m.PlanUp(ctx, goose.MaxVersion)
+	case "up-to":
+		plan, err = m.PlanUp(ctx, version)
+	case "down":
+		plan, err = m.PlanDown(ctx)
+	case "down-to":
+		plan, err = m.PlanDownTo(ctx, version)
+	case "redo":
+		plan, err = m.PlanRedo(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(plan) == 0 {
+		fmt.Fprintln(stdout, "-- nothing to do")
+
+		return nil
+	}
+
+	for _, p := range plan {
+		fmt.Fprintf(stdout, "-- %s %s (version %d)\n%s\n\n", p.Direction, p.Source, p.Version, p.SQL)
+	}
+
+	return nil
+}
+
+func printStatus(ctx context.Context, m *migrator.Migrator, stdout io.Writer) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLIED AT\tMIGRATION")
+	for _, st := range statuses {
+		appliedAt := "Pending"
+		if st.Applied {
+			appliedAt = st.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", st.Version, appliedAt, st.Source)
 	}
 
-	log.Info().Msg("migration successfully")
+	return w.Flush()
 }
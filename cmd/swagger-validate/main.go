@@ -0,0 +1,167 @@
+// Command swagger-validate completes the swagger documentation generated by swag with the
+// constraints of validate tags that swag does not read, see validate.Schemas. It rewrites the
+// docs in place the way swag writes them, and runs after swag through go generate:
+//
+//	go run ./cmd/swagger-validate [-dir docs]
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/validate"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// models are the validated request bodies, documented as the definition named after their type
+var models = []any{ //nolint:gochecknoglobals // list of documented models
+	model.AccountRequest{},
+	model.TransactionRequest{},
+	model.TransferRequest{},
+	model.WebhookRequest{},
+}
+
+const (
+	// docTemplateStart and docTemplateEnd enclose the swagger template of docs.go
+	docTemplateStart = "const docTemplate = `"
+	docTemplateEnd   = "`\n"
+	// schemesLine is the template line swag adds to the swagger of docs.go
+	schemesLine = "\n    \"schemes\": {{ marshal .Schemes }},"
+	// backtick is how swag escapes backticks in the template
+	backtick = "`+\"`\"+`"
+)
+
+var errNoDocTemplate = errors.New("docTemplate not found")
+
+func main() {
+	dir := flag.String("dir", "docs", "directory of the documentation generated by swag")
+	flag.Parse()
+
+	if err := run(*dir); err != nil {
+		log.Fatal().Err(err).Msg("failed to add validate constraints to the swagger documentation")
+	}
+}
+
+func run(dir string) error {
+	doc, err := os.ReadFile(filepath.Join(dir, "swagger.json"))
+	if err != nil {
+		return err
+	}
+
+	var swagger spec.Swagger
+	if err := json.Unmarshal(doc, &swagger); err != nil {
+		return fmt.Errorf("failed to parse swagger.json: %w", err)
+	}
+	if err := addConstraints(&swagger); err != nil {
+		return err
+	}
+
+	if doc, err = json.MarshalIndent(&swagger, "", "    "); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "swagger.json"), doc, 0o644); err != nil { //nolint:gosec // generated docs are public
+		return err
+	}
+
+	if err := writeYAML(filepath.Join(dir, "swagger.yaml"), &swagger); err != nil {
+		return err
+	}
+
+	return writeDocTemplate(filepath.Join(dir, "docs.go"))
+}
+
+// addConstraints sets the constraints of the properties of every model
+func addConstraints(swagger *spec.Swagger) error {
+	for _, m := range models {
+		name := reflect.TypeOf(m).String()
+		definition, ok := swagger.Definitions[name]
+		if !ok {
+			return fmt.Errorf("definition %s not found", name)
+		}
+
+		for field, schema := range validate.Schemas(m) {
+			prop, ok := definition.Properties[field]
+			if !ok {
+				return fmt.Errorf("property %s of %s not found", field, name)
+			}
+
+			if schema.MultipleOf != 0 {
+				prop.MultipleOf = &schema.MultipleOf
+			}
+			if schema.Pattern != "" {
+				prop.Pattern = schema.Pattern
+			}
+			definition.Properties[field] = prop
+		}
+
+		swagger.Definitions[name] = definition
+	}
+
+	return nil
+}
+
+// writeYAML writes the swagger as yaml, converted from json like swag does
+func writeYAML(path string, swagger *spec.Swagger) error {
+	doc, err := json.Marshal(swagger)
+	if err != nil {
+		return err
+	}
+
+	var obj any
+	if err := yaml.Unmarshal(doc, &obj); err != nil {
+		return err
+	}
+	if doc, err = yaml.Marshal(obj); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, doc, 0o644) //nolint:gosec // generated docs are public
+}
+
+// writeDocTemplate adds the constraints to the swagger template embedded in docs.go
+func writeDocTemplate(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	start := strings.Index(string(src), docTemplateStart)
+	if start < 0 {
+		return errNoDocTemplate
+	}
+	start += len(docTemplateStart)
+	end := strings.Index(string(src[start:]), docTemplateEnd)
+	if end < 0 {
+		return errNoDocTemplate
+	}
+	end += start
+
+	doc := strings.ReplaceAll(string(src[start:end]), backtick, "`")
+	doc = strings.Replace(doc, schemesLine, "", 1)
+
+	var swagger spec.Swagger
+	if err := json.Unmarshal([]byte(doc), &swagger); err != nil {
+		return fmt.Errorf("failed to parse the template of docs.go: %w", err)
+	}
+	if err := addConstraints(&swagger); err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(&swagger, "", "    ")
+	if err != nil {
+		return err
+	}
+	doc = "{" + schemesLine + string(out[1:])
+	doc = strings.ReplaceAll(doc, "`", backtick)
+
+	return os.WriteFile(path, []byte(string(src[:start])+doc+string(src[end:])), 0o644) //nolint:gosec // generated docs are public
+}
@@ -1,2 +1,3 @@
 //go:generate go run -mod=mod github.com/swaggo/swag/cmd/swag init -g ./cmd/api/main.go  -p snakecase
+//go:generate go run ./cmd/swagger-validate
 package gopismo
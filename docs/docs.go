@@ -23,7 +23,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
                     "accounts"
@@ -53,11 +54,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/util.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -69,7 +94,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
                     "accounts"
@@ -102,6 +128,310 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/util.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{uuid}/accruals/preview": {
+            "get": {
+                "description": "Dry run of the accrual job: lists the interest and late fees accrued by now on the unpaid balance of the latest statement\nof an Account, and whether each was posted as a transaction already. Nothing is posted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "accruals"
+                ],
+                "summary": "Previews the Accruals of an Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AccrualPreview"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{uuid}/events": {
+            "get": {
+                "description": "Server-sent events of the transactions created for an Account, each batch followed by the\nbalance of the Account. The id of an event is the id of the last transaction sent, a client\nreconnecting with the Last-Event-ID header receives the transactions created after it.\nWithout it, the stream starts with the current balance.",
+                "produces": [
+                    "text/event-stream",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Streams the activity of an Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AccountBalance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{uuid}/statements": {
+            "get": {
+                "description": "List the statements of the closed billing cycles of an Account, latest first. Transactions are only returned by the statement endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "statements"
+                ],
+                "summary": "Returns the Statements of an Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Statement"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{uuid}/statements/{id}": {
+            "get": {
+                "description": "Get a Statement of an Account by UUID, with the transactions of its billing cycle.\nThe Accept header selects JSON (the default), CSV, OFX 2.2 or PDF, the documents are sent as attachments.\nAmounts of CSV and PDF documents are formatted for the locale best matching the Accept-Language header,\none of en-US (the default), pt-BR, de, fr or es-ES.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ofx",
+                    "application/pdf",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "statements"
+                ],
+                "summary": "Returns a Statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Statement UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Statement"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/trial-balance": {
+            "get": {
+                "description": "Totals the debits and credits of the journal entries of every ledger account. The balance of asset accounts\nis their debits minus credits, the balance of revenue accounts the opposite.\nBalanced tells whether all debits equal all credits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Returns the Trial Balance of the Ledger",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TrialBalance"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -113,7 +443,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json"
                 ],
                 "tags": [
                     "transactions"
@@ -150,29 +481,797 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/util.ErrorResponse"
                         }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/batch": {
+            "post": {
+                "description": "Create the transactions of a batch, sent as a JSON array or as one JSON object per line with\nContent-Type application/x-ndjson. Each transaction is validated and created like by\nPOST /transactions, those failing validation or referencing an account or operation type that\ndoes not exist are rejected with their field errors, and those with an idempotency key used\nbefore are duplicates. A best_effort batch creates the transactions not rejected, an\nall_or_nothing batch with a rejected transaction creates none and responds with 422.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Returns the Result of every Transaction of a Batch",
+                "parameters": [
+                    {
+                        "description": "Add transactions request",
+                        "name": "transactions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TransactionRequest"
+                            }
+                        }
+                    },
+                    {
+                        "enum": [
+                            "all_or_nothing",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Whether rejected transactions leave the others to be created",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TransactionBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.TransactionBatchResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers": {
+            "post": {
+                "description": "Moves an amount between two accounts, creating a TRANSFER_OUT transaction debiting the from account\nand a TRANSFER_IN transaction crediting the to account atomically.\nTransfers from or to a blocked account, or leaving the from account owing more than its credit limit, are rejected with 422.\nRetrying with the same idempotency_key is rejected as a duplicate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Returns the Created Transfer",
+                "parameters": [
+                    {
+                        "description": "Add transfer request",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "post": {
+                "description": "Registers an endpoint receiving the events of the given types as JSON POST requests. The response holds\nthe secret signing the deliveries, which is not returned again. Each delivery has an X-Webhook-Timestamp\nheader with its unix time and an X-Webhook-Signature header \"v1=\" followed by the hex encoded\nHMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret. Failed deliveries are retried\nwith exponential backoff, and the webhook is disabled after repeated failures until it is enabled again.\nRetrying with the same idempotency_key is rejected as a duplicate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns the Created Webhook",
+                "parameters": [
+                    {
+                        "description": "Add webhook request",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{uuid}": {
+            "get": {
+                "description": "Get a Webhook by UUID, without its secret. Disabled webhooks receive no deliveries.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns a Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{uuid}/deliveries": {
+            "get": {
+                "description": "List the latest 100 attempts to deliver events to a Webhook, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns the Delivery Log of a Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDeliveryAttempt"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{uuid}/enable": {
+            "post": {
+                "description": "Re-enable a Webhook disabled after repeated failed deliveries, resetting its consecutive failures. The\ndeliveries queued before it was disabled are attempted again, events published while it was disabled\nare not delivered. The url is checked again and must still resolve to a public address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Returns the Enabled Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/util.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.Account": {
+            "type": "object",
+            "properties": {
+                "closing_day": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T06:22:46.931755Z"
+                },
+                "credit_limit": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1000
+                },
+                "document_number": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "some-string"
+                },
+                "due_day": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 10
+                },
+                "rate_plan_id": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "active"
+                },
+                "uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "model.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "balance": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 60.5
+                }
+            }
+        },
+        "model.AccountRequest": {
+            "type": "object",
+            "required": [
+                "document_number",
+                "idempotency_key"
+            ],
+            "properties": {
+                "closing_day": {
+                    "description": "ClosingDay and DueDay are the days of the month the billing cycle closes and its\nstatement is due, capped at 28 so that every month has them",
+                    "type": "integer",
+                    "format": "int64",
+                    "maximum": 28,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "credit_limit": {
+                    "description": "CreditLimit is the most the account may owe after a transfer out of it, 0 means no limit",
+                    "type": "number",
+                    "format": "float64",
+                    "maximum": 9999999999.99,
+                    "minimum": 0,
+                    "multipleOf": 0.01,
+                    "example": 1000
+                },
+                "document_number": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "some-string"
+                },
+                "due_day": {
+                    "type": "integer",
+                    "format": "int64",
+                    "maximum": 28,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "idempotency_key": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "some-string"
+                },
+                "rate_plan_id": {
+                    "description": "RatePlanID is the rate plan interest and late fees accrue by",
+                    "type": "integer",
+                    "format": "int64",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "model.AccountResponse": {
+            "type": "object",
+            "properties": {
+                "uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "model.Accrual": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0.58
+                },
+                "balance": {
+                    "description": "Balance is the unpaid balance the interest of the day accrued on, zero for late fees",
+                    "type": "number",
+                    "format": "float64",
+                    "example": 706.5
+                },
+                "date": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-16T00:00:00Z"
+                },
+                "idempotency_key": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "interest:550e8400-e29b-41d4-a716-446655440000:2025-10-16"
+                },
+                "operation_type_id": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 6
+                },
+                "posted": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "model.AccrualPreview": {
+            "type": "object",
+            "properties": {
+                "account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "accruals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Accrual"
+                    }
+                },
+                "as_of": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-20T06:22:46.931755Z"
+                },
+                "rate_plan": {
+                    "$ref": "#/definitions/model.RatePlan"
+                },
+                "statement_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "model.RatePlan": {
+            "type": "object",
+            "properties": {
+                "apr": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 29.99
+                },
+                "compound_daily": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "STANDARD"
+                },
+                "grace_period_days": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 3
+                },
+                "id": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "late_fee": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 10
+                }
+            }
+        },
+        "model.Statement": {
+            "type": "object",
+            "properties": {
+                "account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "closing_balance": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 60.5
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T00:05:00Z"
+                },
+                "due_date": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-10T00:00:00Z"
+                },
+                "minimum_payment": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 20
+                },
+                "opening_balance": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 100
+                },
+                "payments": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 100
+                },
+                "period_end": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T00:00:00Z"
+                },
+                "period_start": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-09-01T00:00:00Z"
+                },
+                "purchases": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 60.5
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Transaction"
                     }
+                },
+                "uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.Account": {
+        },
+        "model.Transaction": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "amount": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.1
+                },
+                "event_date": {
                     "type": "string",
                     "format": "time",
                     "example": "2025-10-01T06:22:46.931755Z"
                 },
-                "document_number": {
+                "operation_type_id": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "uuid": {
                     "type": "string",
-                    "format": "string",
-                    "example": "some-string"
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "model.TransactionBatchItem": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.FieldError"
+                    }
+                },
+                "index": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "duplicate",
+                        "rejected",
+                        "skipped"
+                    ],
+                    "example": "created"
                 },
                 "uuid": {
                     "type": "string",
@@ -181,22 +1280,80 @@ const docTemplate = `{
                 }
             }
         },
-        "model.AccountRequest": {
+        "model.TransactionBatchResult": {
             "type": "object",
             "properties": {
-                "document_number": {
+                "created": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2
+                },
+                "duplicates": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 0
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TransactionBatchItem"
+                    }
+                },
+                "mode": {
                     "type": "string",
-                    "format": "string",
-                    "example": "some-string"
+                    "enum": [
+                        "all_or_nothing",
+                        "best_effort"
+                    ],
+                    "example": "best_effort"
+                },
+                "rejected": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "skipped": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 0
+                }
+            }
+        },
+        "model.TransactionRequest": {
+            "type": "object",
+            "required": [
+                "account_uuid",
+                "idempotency_key",
+                "operation_type_id"
+            ],
+            "properties": {
+                "account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "amount": {
+                    "type": "number",
+                    "format": "float64",
+                    "maximum": 99999999.99,
+                    "minimum": 0,
+                    "multipleOf": 0.01,
+                    "example": 1.1
                 },
                 "idempotency_key": {
                     "type": "string",
                     "format": "string",
                     "example": "some-string"
+                },
+                "operation_type_id": {
+                    "type": "integer",
+                    "format": "int64",
+                    "minimum": 1,
+                    "example": 1
                 }
             }
         },
-        "model.AccountResponse": {
+        "model.TransactionResponse": {
             "type": "object",
             "properties": {
                 "uuid": {
@@ -206,34 +1363,182 @@ const docTemplate = `{
                 }
             }
         },
-        "model.TransactionRequest": {
+        "model.Transfer": {
             "type": "object",
             "properties": {
-                "account_uuid": {
+                "amount": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 25.5
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T06:22:46.931755Z"
+                },
+                "credit_transaction_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "debit_transaction_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "from_account_uuid": {
                     "type": "string",
                     "format": "uuid",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
+                "to_account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+                },
+                "uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "model.TransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from_account_uuid",
+                "idempotency_key",
+                "to_account_uuid"
+            ],
+            "properties": {
                 "amount": {
                     "type": "number",
                     "format": "float64",
-                    "example": 1.1
+                    "maximum": 99999999.99,
+                    "minimum": 0,
+                    "multipleOf": 0.01,
+                    "example": 25.5
+                },
+                "from_account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
                 "idempotency_key": {
                     "type": "string",
                     "format": "string",
                     "example": "some-string"
                 },
-                "operation_type_id": {
+                "to_account_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+                }
+            }
+        },
+        "model.TrialBalance": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TrialBalanceAccount"
+                    }
+                },
+                "balanced": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "total_credits": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 260.5
+                },
+                "total_debits": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 260.5
+                }
+            }
+        },
+        "model.TrialBalanceAccount": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 60.5
+                },
+                "code": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "CUSTOMER_RECEIVABLE"
+                },
+                "credits": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 100
+                },
+                "debits": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 160.5
+                },
+                "ledger_account_id": {
                     "type": "integer",
                     "format": "int64",
                     "example": 1
+                },
+                "type": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "asset"
                 }
             }
         },
-        "model.TransactionResponse": {
+        "model.Webhook": {
             "type": "object",
             "properties": {
+                "consecutive_failures": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 0
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T06:22:46.931755Z"
+                },
+                "disabled_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-02T06:22:46.931755Z"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "account.created",
+                        "transaction.created"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "whsec_5f2b..."
+                },
+                "url": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "https://example.com/webhooks"
+                },
                 "uuid": {
                     "type": "string",
                     "format": "uuid",
@@ -241,6 +1546,83 @@ const docTemplate = `{
                 }
             }
         },
+        "model.WebhookDeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "attempted_at": {
+                    "type": "string",
+                    "format": "time",
+                    "example": "2025-10-01T06:22:46.931755Z"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 42
+                },
+                "error": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "webhook responded with status 503"
+                },
+                "event_type": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "transaction.created"
+                },
+                "event_uuid": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 200
+                },
+                "succeeded": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "idempotency_key",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "maxItems": 8,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "account.created",
+                        "transaction.created"
+                    ]
+                },
+                "idempotency_key": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "some-string"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "pattern": "^https?://[^/\\s]+",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
         "util.ErrorDescription": {
             "type": "object",
             "properties": {
@@ -261,6 +1643,9 @@ const docTemplate = `{
                 },
                 "title": {
                     "type": "string"
+                },
+                "trace": {
+                    "type": "string"
                 }
             }
         },
@@ -278,6 +1663,9 @@ const docTemplate = `{
         "util.FieldError": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string"
+                },
                 "field": {
                     "type": "string"
                 },
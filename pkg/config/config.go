@@ -2,34 +2,212 @@ package config
 
 import (
 	"fmt"
+	"go-pismo-challenge/pkg/database"
+	"go-pismo-challenge/pkg/migrator"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	RepositoryBackendPostgres = "postgres"
+	RepositoryBackendSQLite   = "sqlite"
+	RepositoryBackendMemory   = "memory"
+
+	OutboxPublisherStdout  = "stdout"
+	OutboxPublisherFile    = "file"
+	OutboxPublisherWebhook = "webhook"
+)
+
 type Config struct {
 	ServiceName string `env:"SERVICE_NAME,required"`
 
-	DatabaseHost           string `env:"DATABASE_HOST,required"`
-	DatabaseName           string `env:"DATABASE_NAME,required"`
-	DatabaseUserName       string `env:"DATABASE_USERNAME,required"`
-	DatabasePassword       string `env:"DATABASE_PASSWORD,required"`
-	DatabaseSSLMode        string `env:"DATABASE_SSL_MODE,required"`
-	DatabaseSSLRootCert    string `env:"DATABASE_SSL_ROOT_CERT,required"`
-	DatabaseMaxOpenConns   int    `env:"DATABASE_MAX_OPEN_CONNS,required"`
-	DatabaseMigrationTable string `env:"DATABASE_MIGRATION_TABLE,required"`
-	DatabaseMinVersion     int    `env:"DATABASE_MIN_VERSION,required"`
+	// RepositoryBackend selects where data is stored: "postgres", "sqlite" or "memory". The
+	// memory backend loses all data on restart and is meant for local development only
+	RepositoryBackend string `env:"REPOSITORY_BACKEND" envDefault:"postgres"`
+
+	SQLitePath           string        `env:"SQLITE_PATH" envDefault:"pismo.db"`
+	SQLiteBusyTimeout    time.Duration `env:"SQLITE_BUSY_TIMEOUT" envDefault:"5s"`
+	SQLiteMigrationTable string        `env:"SQLITE_MIGRATION_TABLE" envDefault:"goose_db_version"`
+
+	// Postgres connection settings, required when the postgres backend is used
+	DatabaseHost           string `env:"DATABASE_HOST"`
+	DatabaseName           string `env:"DATABASE_NAME"`
+	DatabaseUserName       string `env:"DATABASE_USERNAME"`
+	DatabasePassword       string `env:"DATABASE_PASSWORD"`
+	DatabaseSSLMode        string `env:"DATABASE_SSL_MODE"`
+	DatabaseSSLRootCert    string `env:"DATABASE_SSL_ROOT_CERT"`
+	DatabaseMigrationTable string `env:"DATABASE_MIGRATION_TABLE"`
+
+	// DatabaseMaxOpenConns and DatabaseMinVersion are required by the postgres backend, the
+	// connections of SQLite are unlimited and any schema version is accepted when not set
+	DatabaseMaxOpenConns int `env:"DATABASE_MAX_OPEN_CONNS"`
+	DatabaseMinVersion   int `env:"DATABASE_MIN_VERSION"`
+
+	DatabaseMaxIdleConns          int           `env:"DATABASE_MAX_IDLE_CONNS" envDefault:"2"`
+	DatabaseConnMaxLifetime       time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" envDefault:"30m"`
+	DatabaseConnMaxIdleTime       time.Duration `env:"DATABASE_CONN_MAX_IDLE_TIME" envDefault:"5m"`
+	DatabaseStatementTimeout      time.Duration `env:"DATABASE_STATEMENT_TIMEOUT" envDefault:"30s"`
+	DatabaseConnectMaxWait        time.Duration `env:"DATABASE_CONNECT_MAX_WAIT" envDefault:"60s"`
+	DatabaseConnectInitialBackoff time.Duration `env:"DATABASE_CONNECT_INITIAL_BACKOFF" envDefault:"500ms"`
+	DatabaseConnectMaxBackoff     time.Duration `env:"DATABASE_CONNECT_MAX_BACKOFF" envDefault:"10s"`
+	DatabaseMigrationLockTimeout  time.Duration `env:"DATABASE_MIGRATION_LOCK_TIMEOUT" envDefault:"5m"`
+	DatabaseTxMaxRetries          int           `env:"DATABASE_TX_MAX_RETRIES" envDefault:"3"`
+
+	HTTPAddr                string        `env:"HTTP_ADDR" envDefault:":3000"`
+	HTTPReadTimeout         time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"10s"`
+	HTTPReadHeaderTimeout   time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" envDefault:"5s"`
+	HTTPWriteTimeout        time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"15s"`
+	HTTPIdleTimeout         time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"60s"`
+	HTTPMaxHeaderBytes      int           `env:"HTTP_MAX_HEADER_BYTES" envDefault:"1048576"`
+	HTTPMaxRequestBodyBytes int64         `env:"HTTP_MAX_REQUEST_BODY_BYTES" envDefault:"1048576"`
+	ShutdownGracePeriod     time.Duration `env:"SHUTDOWN_GRACE_PERIOD" envDefault:"5s"`
+
+	// RequestTimeout is the deadline of API requests, RequestTimeoutOverrides sets it per route
+	// group, e.g. "/api/v1/transactions:3s,/api/v1/accounts:1s"
+	RequestTimeout          time.Duration            `env:"REQUEST_TIMEOUT" envDefault:"5s"`
+	RequestTimeoutOverrides map[string]time.Duration `env:"REQUEST_TIMEOUT_OVERRIDES"`
+
+	// TLS is enabled when both cert and key are set, client certificates are required and
+	// verified against TLSClientCAFile when it is set
+	TLSCertFile     string `env:"TLS_CERT_FILE"`
+	TLSKeyFile      string `env:"TLS_KEY_FILE"`
+	TLSClientCAFile string `env:"TLS_CLIENT_CA_FILE"`
+
+	// HealthCheckTimeout bounds each readiness check, ShutdownDrainDelay is how long /readyz
+	// reports failure after SIGTERM before the server stops accepting connections
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"`
+
+	// StatementJobInterval is how often billing cycles that ended are closed into statements, 0
+	// disables the job. The minimum payment of a statement is StatementMinPaymentPercent of its
+	// closing balance, but at least StatementMinPaymentFloor. StatementCurrency is the ISO 4217
+	// code of the amounts in exported statements
+	StatementJobInterval       time.Duration `env:"STATEMENT_JOB_INTERVAL" envDefault:"1h"`
+	StatementMinPaymentPercent float64       `env:"STATEMENT_MIN_PAYMENT_PERCENT" envDefault:"15"`
+	StatementMinPaymentFloor   float64       `env:"STATEMENT_MIN_PAYMENT_FLOOR" envDefault:"20"`
+	StatementCurrency          string        `env:"STATEMENT_CURRENCY" envDefault:"USD"`
+
+	// AccrualJobInterval is how often interest and late fees accrued on unpaid statements are
+	// posted, 0 disables the job. Accruals are keyed by day, so running more often is harmless
+	AccrualJobInterval time.Duration `env:"ACCRUAL_JOB_INTERVAL" envDefault:"24h"`
+
+	// OutboxPublisher selects where the events of created accounts and transactions are published:
+	// "stdout", "file" (OutboxFilePath) or "webhook" (OutboxWebhookURL). OutboxRelayInterval is how
+	// often pending events are published, 0 disables the relay and events are kept in the outbox.
+	// An event that fails to publish is retried after OutboxRetryInitialBackoff, doubled on every
+	// attempt up to OutboxRetryMaxBackoff
+	OutboxPublisher           string        `env:"OUTBOX_PUBLISHER" envDefault:"stdout"`
+	OutboxFilePath            string        `env:"OUTBOX_FILE_PATH" envDefault:"events.jsonl"`
+	OutboxWebhookURL          string        `env:"OUTBOX_WEBHOOK_URL"`
+	OutboxWebhookTimeout      time.Duration `env:"OUTBOX_WEBHOOK_TIMEOUT" envDefault:"5s"`
+	OutboxRelayInterval       time.Duration `env:"OUTBOX_RELAY_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize           int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	OutboxRetryInitialBackoff time.Duration `env:"OUTBOX_RETRY_INITIAL_BACKOFF" envDefault:"1s"`
+	OutboxRetryMaxBackoff     time.Duration `env:"OUTBOX_RETRY_MAX_BACKOFF" envDefault:"5m"`
+
+	// WebhookDeliveryInterval is how often the deliveries the outbox relay queued for webhooks are
+	// attempted, 0 disables the job and deliveries are kept queued. A delivery waits up to
+	// WebhookTimeout for the response, and is retried after WebhookRetryInitialBackoff, doubled on
+	// every attempt up to WebhookRetryMaxBackoff. A webhook failing WebhookMaxConsecutiveFailures
+	// deliveries in a row is disabled. Webhooks must be https urls of public addresses, unless
+	// WebhookAllowLocal is set for local development
+	WebhookDeliveryInterval       time.Duration `env:"WEBHOOK_DELIVERY_INTERVAL" envDefault:"1s"`
+	WebhookTimeout                time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"5s"`
+	WebhookBatchSize              int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"100"`
+	WebhookRetryInitialBackoff    time.Duration `env:"WEBHOOK_RETRY_INITIAL_BACKOFF" envDefault:"1s"`
+	WebhookRetryMaxBackoff        time.Duration `env:"WEBHOOK_RETRY_MAX_BACKOFF" envDefault:"1h"`
+	WebhookMaxConsecutiveFailures int           `env:"WEBHOOK_MAX_CONSECUTIVE_FAILURES" envDefault:"10"`
+	WebhookAllowLocal             bool          `env:"WEBHOOK_ALLOW_LOCAL" envDefault:"false"`
+
+	// AccountEventsBatchSize transactions are read at a time by the event streams of accounts. A
+	// comment is sent on every stream each AccountEventsHeartbeatInterval, and a stream whose client
+	// does not take a write within AccountEventsWriteTimeout is ended. The sqlite and memory
+	// backends have no notifications, their streams check for transactions every
+	// AccountEventsPollInterval
+	AccountEventsBatchSize         int           `env:"ACCOUNT_EVENTS_BATCH_SIZE" envDefault:"100"`
+	AccountEventsHeartbeatInterval time.Duration `env:"ACCOUNT_EVENTS_HEARTBEAT_INTERVAL" envDefault:"15s"`
+	AccountEventsWriteTimeout      time.Duration `env:"ACCOUNT_EVENTS_WRITE_TIMEOUT" envDefault:"10s"`
+	AccountEventsPollInterval      time.Duration `env:"ACCOUNT_EVENTS_POLL_INTERVAL" envDefault:"1s"`
+
+	// TransactionBatchMode is the mode of the transaction batches sent without one:
+	// "all_or_nothing" or "best_effort". A batch holds at most TransactionBatchMaxItems
+	// transactions, and is limited by HTTPMaxRequestBodyBytes too
+	TransactionBatchMode     string `env:"TRANSACTION_BATCH_MODE" envDefault:"best_effort"`
+	TransactionBatchMaxItems int    `env:"TRANSACTION_BATCH_MAX_ITEMS" envDefault:"1000"`
+
+	// TracingExporter selects where spans are sent: "none", "stdout" or "otlp"
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"localhost:4318"`
+	TracingOTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" envDefault:"false"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
 }
 
 func LoadConfig() Config {
 	var cfg Config
 	if err := env.Parse(&cfg); err != nil {
-		log.Fatal().Err(fmt.Errorf("failed to load config: %w", err))
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+
+	if err := cfg.validate(); err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
 	}
 
 	return cfg
 }
 
+// validate checks the settings that are only required by some backends
+func (c Config) validate() error {
+	switch c.RepositoryBackend {
+	case RepositoryBackendPostgres:
+		if err := c.validatePostgres(); err != nil {
+			return err
+		}
+	case RepositoryBackendMemory:
+		return nil
+	}
+
+	// checked at startup rather than when the migrator first runs
+	if err := migrator.ValidateTable(c.MigrationTable()); err != nil {
+		return fmt.Errorf("invalid migration table: %w", err)
+	}
+
+	return nil
+}
+
+// validatePostgres checks that the settings required by the postgres backend are set
+func (c Config) validatePostgres() error {
+	var missing []string
+	for _, v := range []struct{ name, value string }{
+		{"DATABASE_HOST", c.DatabaseHost},
+		{"DATABASE_NAME", c.DatabaseName},
+		{"DATABASE_USERNAME", c.DatabaseUserName},
+		{"DATABASE_PASSWORD", c.DatabasePassword},
+		{"DATABASE_SSL_MODE", c.DatabaseSSLMode},
+		{"DATABASE_SSL_ROOT_CERT", c.DatabaseSSLRootCert},
+		{"DATABASE_MIGRATION_TABLE", c.DatabaseMigrationTable},
+	} {
+		if v.value == "" {
+			missing = append(missing, v.name)
+		}
+	}
+	for _, name := range []string{"DATABASE_MAX_OPEN_CONNS", "DATABASE_MIN_VERSION"} {
+		if _, ok := os.LookupEnv(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required environment variables for the %s backend are not set: %s",
+			c.RepositoryBackend, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
 func (c Config) DSN() string {
 	return fmt.Sprintf(
 		"postgres://%s:%s@%s/%s?sslmode=%s&sslrootcert=%s&application_name=%s",
@@ -42,3 +220,63 @@ func (c Config) DSN() string {
 		c.ServiceName,
 	)
 }
+
+// DatabaseOptions returns the connection pool options, maxOpenConns overrides
+// DatabaseMaxOpenConns when > 0
+func (c Config) DatabaseOptions(maxOpenConns int) database.Options {
+	if maxOpenConns <= 0 {
+		maxOpenConns = c.DatabaseMaxOpenConns
+	}
+
+	return database.Options{
+		DSN:                   c.DSN(),
+		MaxOpenConns:          maxOpenConns,
+		MaxIdleConns:          min(c.DatabaseMaxIdleConns, maxOpenConns),
+		ConnMaxLifetime:       c.DatabaseConnMaxLifetime,
+		ConnMaxIdleTime:       c.DatabaseConnMaxIdleTime,
+		StatementTimeout:      c.DatabaseStatementTimeout,
+		ConnectMaxWait:        c.DatabaseConnectMaxWait,
+		ConnectInitialBackoff: c.DatabaseConnectInitialBackoff,
+		ConnectMaxBackoff:     c.DatabaseConnectMaxBackoff,
+	}
+}
+
+// SQLiteOptions returns the options of the SQLite database, maxOpenConns overrides
+// DatabaseMaxOpenConns when > 0
+func (c Config) SQLiteOptions(maxOpenConns int) database.SQLiteOptions {
+	if maxOpenConns <= 0 {
+		maxOpenConns = c.DatabaseMaxOpenConns
+	}
+
+	return database.SQLiteOptions{
+		Path:         c.SQLitePath,
+		MaxOpenConns: maxOpenConns,
+		BusyTimeout:  c.SQLiteBusyTimeout,
+	}
+}
+
+// MigrationDialect returns the migration dialect of the configured backend
+func (c Config) MigrationDialect() string {
+	if c.RepositoryBackend == RepositoryBackendSQLite {
+		return migrator.DialectSQLite
+	}
+
+	return migrator.DialectPostgres
+}
+
+// MigrationTable returns the version table of the configured backend
+func (c Config) MigrationTable() string {
+	if c.RepositoryBackend == RepositoryBackendSQLite {
+		return c.SQLiteMigrationTable
+	}
+
+	return c.DatabaseMigrationTable
+}
+
+func (c Config) MigrationOptions() migrator.Options {
+	return migrator.Options{
+		Dialect:     c.MigrationDialect(),
+		Table:       c.MigrationTable(),
+		LockTimeout: c.DatabaseMigrationLockTimeout,
+	}
+}
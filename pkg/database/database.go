@@ -5,42 +5,112 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
-func NewConnection(dsn string, maxConnections int) (*sql.DB, error) {
+type Options struct {
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is set as the statement_timeout of every connection, 0 disables it
+	StatementTimeout time.Duration
+
+	// ConnectMaxWait is how long to keep retrying the initial connection, waiting between
+	// ConnectInitialBackoff and ConnectMaxBackoff (doubling, with jitter) between attempts
+	ConnectMaxWait        time.Duration
+	ConnectInitialBackoff time.Duration
+	ConnectMaxBackoff     time.Duration
+}
+
+// NewConnection opens a connection pool and waits until the database is reachable,
+// retrying with exponential backoff so that the service survives a database that starts late
+func NewConnection(ctx context.Context, opts Options) (*sql.DB, error) {
+	dsn, err := withStatementTimeout(opts.DSN, opts.StatementTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create database connection")
+		return nil, fmt.Errorf("failed to create database connection: %w", err)
 	}
 
-	db.SetMaxOpenConns(maxConnections)
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	err = retry(ctx, opts.ConnectMaxWait, opts.ConnectInitialBackoff, opts.ConnectMaxBackoff, func(attempt int) error {
+		if err := db.PingContext(ctx); err != nil {
+			log.Warn().Err(err).Int("attempt", attempt).Msg("database not reachable yet")
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		db.Close()
 
-	if err := db.PingContext(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	return db, nil
 }
 
-// Check database migration version against the minimum required version
-func MigrationVersionCheck(ctx context.Context, db *sql.DB, tableName string, minVersion int) error {
-	schemaVersionQuery := fmt.Sprintf(
-		`SELECT version_id FROM %s WHERE is_applied = true AND version_id >= %d;`,
-		tableName,
-		minVersion)
-
-	var res any
-	if err := db.QueryRowContext(ctx, schemaVersionQuery).Scan(&res); err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("%w: expected %s version to be >= %d", errors.New("version incompatible"), tableName, minVersion)
+// retry calls fn until it succeeds, ctx is done or maxWait has elapsed. The wait between
+// attempts doubles from initial up to maxBackoff and is randomised by +/-50% to avoid
+// synchronised retries of several replicas. The last error of fn is returned on failure.
+func retry(ctx context.Context, maxWait, initial, maxBackoff time.Duration, fn func(attempt int) error) error {
+	deadline := time.Now().Add(maxWait)
+	backoff := initial
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
 		}
 
-		return fmt.Errorf("error while checking version: %w", err)
+		remaining := time.Until(deadline)
+		if remaining <= 0 || backoff <= 0 {
+			return err
+		}
+
+		wait := min(rand.N(backoff)+backoff/2, remaining)
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// adds the statement_timeout runtime parameter, which lib/pq passes on to the server
+// for every new connection
+func withStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse database DSN: %w", err)
 	}
-	log.Info().Msg("database migration version check successful")
 
-	return nil
+	q := u.Query()
+	q.Set("statement_timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
 }
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type databaseSuite struct {
+	suite.Suite
+}
+
+func TestDatabase(t *testing.T) {
+	suite.Run(t, new(databaseSuite))
+}
+
+func (s *databaseSuite) TestRetrySucceedsAfterFailures() {
+	calls := 0
+	err := retry(context.Background(), time.Second, time.Millisecond, 4*time.Millisecond, func(attempt int) error {
+		calls++
+		s.Equal(calls, attempt)
+		if attempt < 3 {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal(3, calls)
+}
+
+func (s *databaseSuite) TestRetryGivesUpAfterMaxWait() {
+	mockErr := errors.New("connection refused")
+	start := time.Now()
+
+	err := retry(context.Background(), 20*time.Millisecond, time.Millisecond, 5*time.Millisecond, func(int) error {
+		return mockErr
+	})
+
+	s.ErrorIs(err, mockErr)
+	s.Less(time.Since(start), time.Second)
+}
+
+func (s *databaseSuite) TestRetryStopsOnContextCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockErr := errors.New("connection refused")
+
+	err := retry(ctx, time.Minute, 10*time.Millisecond, time.Second, func(int) error {
+		cancel()
+
+		return mockErr
+	})
+
+	s.ErrorIs(err, mockErr)
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *databaseSuite) TestWithStatementTimeout() {
+	dsn, err := withStatementTimeout("postgres://u:p@localhost/db?sslmode=disable", 1500*time.Millisecond)
+	s.Require().NoError(err)
+
+	u, err := url.Parse(dsn)
+	s.Require().NoError(err)
+	s.Equal("1500", u.Query().Get("statement_timeout"))
+	s.Equal("disable", u.Query().Get("sslmode"))
+
+	dsn, err = withStatementTimeout("postgres://u:p@localhost/db", 0)
+	s.Require().NoError(err)
+	s.Equal("postgres://u:p@localhost/db", dsn)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+type SQLiteOptions struct {
+	// Path of the database file, created when missing
+	Path         string
+	MaxOpenConns int
+	// BusyTimeout is how long a statement waits for a lock held by another connection
+	BusyTimeout time.Duration
+}
+
+// NewSQLiteConnection opens the SQLite database file at opts.Path. Foreign keys are enforced
+// and WAL journaling lets readers proceed while a write is in progress
+func NewSQLiteConnection(ctx context.Context, opts SQLiteOptions) (*sql.DB, error) {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", opts.BusyTimeout.Milliseconds()))
+	query.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+opts.Path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", opts.Path, err)
+	}
+
+	return db, nil
+}
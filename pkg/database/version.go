@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrVersionTooOld = errors.New("database schema is older than the minimum supported version")
+	ErrVersionTooNew = errors.New("database schema is newer than the latest version known to this binary")
+)
+
+// VersionInfo is the effective schema version and the range this binary supports
+type VersionInfo struct {
+	Current int64 `json:"current"`
+	Min     int64 `json:"min"`
+	Max     int64 `json:"max"`
+}
+
+// VersionReader reads the migration version the schema is at, implemented by migrator.Migrator
+type VersionReader interface {
+	Version(ctx context.Context) (int64, error)
+}
+
+// MigrationVersionCheck verifies that the current schema version, as read by v, lies within
+// [minVersion, maxVersion]. The returned VersionInfo is filled whenever the current version could be read
+func MigrationVersionCheck(ctx context.Context, v VersionReader, minVersion, maxVersion int64) (VersionInfo, error) {
+	info := VersionInfo{Min: minVersion, Max: maxVersion}
+
+	current, err := v.Version(ctx)
+	if err != nil {
+		return info, fmt.Errorf("error while checking version: %w", err)
+	}
+	info.Current = current
+
+	switch {
+	case current < minVersion:
+		return info, fmt.Errorf("%w: schema is at %d, expected >= %d", ErrVersionTooOld, current, minVersion)
+	case current > maxVersion:
+		return info, fmt.Errorf("%w: schema is at %d, expected <= %d", ErrVersionTooNew, current, maxVersion)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// versionFunc reads the schema version from a function
+type versionFunc func(ctx context.Context) (int64, error)
+
+func (f versionFunc) Version(ctx context.Context) (int64, error) {
+	return f(ctx)
+}
+
+// atVersion returns a VersionReader of a schema at version v
+func atVersion(v int64) VersionReader {
+	return versionFunc(func(context.Context) (int64, error) { return v, nil })
+}
+
+type versionSuite struct {
+	suite.Suite
+}
+
+func TestVersion(t *testing.T) {
+	suite.Run(t, new(versionSuite))
+}
+
+func (s *versionSuite) TestWithinRange() {
+	info, err := MigrationVersionCheck(context.Background(), atVersion(3), 2, 3)
+	s.NoError(err)
+	s.Equal(VersionInfo{Current: 3, Min: 2, Max: 3}, info)
+}
+
+func (s *versionSuite) TestOlderThanSupported() {
+	info, err := MigrationVersionCheck(context.Background(), atVersion(2), 3, 3)
+	s.ErrorIs(err, ErrVersionTooOld)
+	s.Equal(int64(2), info.Current)
+}
+
+func (s *versionSuite) TestNewerThanKnown() {
+	info, err := MigrationVersionCheck(context.Background(), atVersion(4), 1, 3)
+	s.ErrorIs(err, ErrVersionTooNew)
+	s.Equal(int64(4), info.Current)
+}
+
+func (s *versionSuite) TestReadFailed() {
+	failure := versionFunc(func(context.Context) (int64, error) { return 0, errors.New("connection refused") })
+
+	info, err := MigrationVersionCheck(context.Background(), failure, 1, 3)
+	s.ErrorContains(err, "connection refused")
+	s.Equal(VersionInfo{Min: 1, Max: 3}, info)
+}
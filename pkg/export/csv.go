@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"golang.org/x/text/language"
+)
+
+// WriteCSV writes a line per transaction of the statement. Locales with a decimal comma get
+// semicolon separated values, as spreadsheets of those locales expect
+func WriteCSV(w io.Writer, s Statement, locale language.Tag) error {
+	cw := csv.NewWriter(w)
+	if decimalComma(locale) {
+		cw.Comma = ';'
+	}
+
+	if err := cw.Write([]string{"date", "description", "amount", "currency", "transaction_uuid"}); err != nil {
+		return err
+	}
+	for _, trx := range s.Transactions {
+		err := cw.Write([]string{
+			trx.EventDate.UTC().Format(dateLayout),
+			s.description(trx),
+			formatAmount(locale, trx.Amount),
+			s.Currency,
+			trx.UUID.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
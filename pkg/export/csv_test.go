@@ -0,0 +1,40 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestWriteCSV(t *testing.T) {
+	s := mockStatement()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, s, language.AmericanEnglish))
+	assert.Equal(t, "date,description,amount,currency,transaction_uuid\n"+
+		"2025-09-15,CASH_PURCHASE,\"-1,234.50\",USD,"+s.Transactions[0].UUID.String()+"\n"+
+		"2025-09-24,PAYMENT,100.00,USD,"+s.Transactions[1].UUID.String()+"\n", buf.String())
+}
+
+// locales with a decimal comma separate values with a semicolon
+func TestWriteCSVDecimalComma(t *testing.T) {
+	s := mockStatement()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, s, language.BrazilianPortuguese))
+	assert.Equal(t, "date;description;amount;currency;transaction_uuid\n"+
+		"2025-09-15;CASH_PURCHASE;-1.234,50;USD;"+s.Transactions[0].UUID.String()+"\n"+
+		"2025-09-24;PAYMENT;100,00;USD;"+s.Transactions[1].UUID.String()+"\n", buf.String())
+}
+
+func TestWriteCSVWithoutTransactions(t *testing.T) {
+	s := mockStatement()
+	s.Transactions = nil
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, s, language.AmericanEnglish))
+	assert.Equal(t, "date,description,amount,currency,transaction_uuid\n", buf.String())
+}
@@ -0,0 +1,103 @@
+// Package export renders statements as documents to download. Amounts of CSV and PDF documents
+// are formatted for the locale of the reader, OFX documents use the notation of the specification
+package export
+
+import (
+	"go-pismo-challenge/pkg/model"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+const (
+	ContentTypeCSV = "text/csv"
+	ContentTypeOFX = "application/x-ofx"
+	ContentTypePDF = "application/pdf"
+)
+
+const dateLayout = "2006-01-02"
+
+// Statement is a statement to export, with the descriptions of the operation types of its
+// transactions by id and the currency of its amounts
+type Statement struct {
+	model.Statement
+	OperationTypes map[int]string
+	Currency       string
+}
+
+// Format renders statements as documents of a media type
+type Format struct {
+	ContentType string
+	Extension   string
+	Write       func(w io.Writer, s Statement, locale language.Tag) error
+}
+
+// Formats returns the supported formats
+func Formats() []Format {
+	return []Format{
+		{ContentType: ContentTypeCSV, Extension: "csv", Write: WriteCSV},
+		{ContentType: ContentTypeOFX, Extension: "ofx", Write: WriteOFX},
+		{ContentType: ContentTypePDF, Extension: "pdf", Write: WritePDF},
+	}
+}
+
+// ByContentType returns the format rendering documents of the media type
+func ByContentType(contentType string) (Format, bool) {
+	for _, f := range Formats() {
+		if f.ContentType == contentType {
+			return f, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// Locales returns the locales amounts are formatted for, the first one being the default
+func Locales() []language.Tag {
+	return []language.Tag{
+		language.AmericanEnglish,
+		language.BrazilianPortuguese,
+		language.German,
+		language.French,
+		language.EuropeanSpanish,
+	}
+}
+
+// Locale returns the supported locale best matching an Accept-Language header, the default
+// one when the header is empty, malformed or matches none
+func Locale(acceptLanguage string) language.Tag {
+	locales := Locales()
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return locales[0]
+	}
+
+	_, index, _ := language.NewMatcher(locales).Match(tags...)
+
+	return locales[index]
+}
+
+// formatAmount formats v with two decimals and the separators of the locale
+func formatAmount(locale language.Tag, v float64) string {
+	return message.NewPrinter(locale).Sprint(number.Decimal(v, number.Scale(2)))
+}
+
+// decimalComma reports whether the locale separates decimals with a comma
+func decimalComma(locale language.Tag) bool {
+	return strings.Contains(formatAmount(locale, 0.5), ",")
+}
+
+// description returns the description of the operation type of a transaction, its id when
+// the type is unknown
+func (s Statement) description(trx model.Transaction) string {
+	if d, ok := s.OperationTypes[trx.OperationTypeID]; ok {
+		return d
+	}
+
+	return strconv.Itoa(trx.OperationTypeID)
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"go-pismo-challenge/pkg/model"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func mockStatement() Statement {
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	periodEnd := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	return Statement{
+		Statement: model.Statement{
+			UUID:           uuid.NewV5(accountUUID, periodEnd.Format(time.RFC3339)),
+			AccountUUID:    accountUUID,
+			PeriodStart:    periodEnd.AddDate(0, -1, 0),
+			PeriodEnd:      periodEnd,
+			DueDate:        periodEnd.AddDate(0, 0, 10),
+			OpeningBalance: 100,
+			Purchases:      1234.5,
+			Payments:       100,
+			ClosingBalance: 1234.5,
+			MinimumPayment: 185.18,
+			CreatedAt:      periodEnd.Add(time.Hour),
+			Transactions: []model.Transaction{
+				{
+					UUID:            uuid.NewV5(uuid.Nil, "1"),
+					AccountUUID:     accountUUID,
+					OperationTypeID: 1,
+					Amount:          -1234.5,
+					EventDate:       periodEnd.AddDate(0, 0, -20),
+				},
+				{
+					UUID:            uuid.NewV5(uuid.Nil, "2"),
+					AccountUUID:     accountUUID,
+					OperationTypeID: 4,
+					Amount:          100,
+					EventDate:       periodEnd.AddDate(0, 0, -10).Add(-3 * time.Hour),
+				},
+			},
+		},
+		OperationTypes: map[int]string{1: "CASH_PURCHASE", 4: "PAYMENT"},
+		Currency:       "USD",
+	}
+}
+
+func TestLocale(t *testing.T) {
+	for _, tc := range []struct {
+		acceptLanguage string
+		want           language.Tag
+	}{
+		{"", language.AmericanEnglish},
+		{"invalid;;q=x", language.AmericanEnglish},
+		{"ja", language.AmericanEnglish},
+		{"en-GB", language.AmericanEnglish},
+		{"pt-BR", language.BrazilianPortuguese},
+		{"pt", language.BrazilianPortuguese},
+		{"de-CH, en;q=0.5", language.German},
+		{"fr;q=0.8, es-ES", language.EuropeanSpanish},
+	} {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tc.want, Locale(tc.acceptLanguage))
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for _, tc := range []struct {
+		locale language.Tag
+		want   string
+	}{
+		{language.AmericanEnglish, "-1,234,567.50"},
+		{language.BrazilianPortuguese, "-1.234.567,50"},
+		{language.German, "-1.234.567,50"},
+		{language.French, "-1 234 567,50"},
+	} {
+		t.Run(tc.locale.String(), func(t *testing.T) {
+			assert.Equal(t, tc.want, formatAmount(tc.locale, -1234567.5))
+			assert.Equal(t, tc.want != "-1,234,567.50", decimalComma(tc.locale))
+		})
+	}
+}
+
+func TestByContentType(t *testing.T) {
+	for _, f := range Formats() {
+		got, ok := ByContentType(f.ContentType)
+		assert.True(t, ok)
+		assert.Equal(t, f.Extension, got.Extension)
+	}
+
+	_, ok := ByContentType("application/json")
+	assert.False(t, ok)
+}
+
+// a transaction of an operation type without description shows the id of the type
+func TestDescription(t *testing.T) {
+	s := mockStatement()
+
+	assert.Equal(t, "PAYMENT", s.description(model.Transaction{OperationTypeID: 4}))
+	assert.Equal(t, "3", s.description(model.Transaction{OperationTypeID: 3}))
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// ofxHeader opens OFX 2.2 documents, an XML declaration followed by the OFX processing instruction
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+const (
+	ofxDateLayout = "20060102150405.000[0:GMT]"
+	// ofxNameLength is the maximum length of the payee name of a transaction
+	ofxNameLength = 32
+)
+
+type ofxDocument struct {
+	XMLName    xml.Name      `xml:"OFX"`
+	SignOn     ofxSignOn     `xml:"SIGNONMSGSRSV1>SONRS"`
+	CreditCard ofxCreditCard `xml:"CREDITCARDMSGSRSV1>CCSTMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxCreditCard struct {
+	TrnUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"CCSTMTRS"`
+}
+
+type ofxStatement struct {
+	Currency     string           `xml:"CURDEF"`
+	AccountID    string           `xml:"CCACCTFROM>ACCTID"`
+	DTStart      string           `xml:"BANKTRANLIST>DTSTART"`
+	DTEnd        string           `xml:"BANKTRANLIST>DTEND"`
+	Transactions []ofxTrn         `xml:"BANKTRANLIST>STMTTRN"`
+	LedgerBal    ofxLedgerBalance `xml:"LEDGERBAL"`
+}
+
+type ofxTrn struct {
+	Type     string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	Amount   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	Name     string `xml:"NAME"`
+}
+
+type ofxLedgerBalance struct {
+	Amount string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+// WriteOFX writes the statement as an OFX 2.2 credit card statement. OFX amounts are signed
+// from the point of view of the cardholder, so purchases and the balance owed are negative.
+// The locale is ignored, OFX amounts always use a decimal point
+func WriteOFX(w io.Writer, s Statement, _ language.Tag) error {
+	st := ofxStatement{
+		Currency:     s.Currency,
+		AccountID:    s.AccountUUID.String(),
+		DTStart:      ofxDate(s.PeriodStart),
+		DTEnd:        ofxDate(s.PeriodEnd),
+		Transactions: make([]ofxTrn, 0, len(s.Transactions)),
+		LedgerBal: ofxLedgerBalance{
+			Amount: ofxAmount(-s.ClosingBalance),
+			DTAsOf: ofxDate(s.PeriodEnd),
+		},
+	}
+	for _, trx := range s.Transactions {
+		trnType := "CREDIT"
+		if trx.Amount < 0 {
+			trnType = "DEBIT"
+		}
+
+		name := []rune(s.description(trx))
+		st.Transactions = append(st.Transactions, ofxTrn{
+			Type:     trnType,
+			DTPosted: ofxDate(trx.EventDate),
+			Amount:   ofxAmount(trx.Amount),
+			FITID:    trx.UUID.String(),
+			Name:     string(name[:min(len(name), ofxNameLength)]),
+		})
+	}
+
+	doc := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   ofxStatus{Code: 0, Severity: "INFO"},
+			DTServer: ofxDate(s.CreatedAt),
+			Language: "ENG",
+		},
+		CreditCard: ofxCreditCard{
+			TrnUID:    s.UUID.String(),
+			Status:    ofxStatus{Code: 0, Severity: "INFO"},
+			Statement: st,
+		},
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout)
+}
+
+func ofxAmount(v float64) string {
+	if v == 0 {
+		// negating a zero balance must not print "-0.00"
+		v = 0
+	}
+
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
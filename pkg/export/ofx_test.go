@@ -0,0 +1,64 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestWriteOFX(t *testing.T) {
+	s := mockStatement()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOFX(&buf, s, language.BrazilianPortuguese))
+	assert.True(t, strings.HasPrefix(buf.String(), ofxHeader))
+
+	var doc ofxDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, "20251005010000.000[0:GMT]", doc.SignOn.DTServer)
+	assert.Equal(t, s.UUID.String(), doc.CreditCard.TrnUID)
+	assert.Equal(t, ofxStatement{
+		Currency:  "USD",
+		AccountID: s.AccountUUID.String(),
+		DTStart:   "20250905000000.000[0:GMT]",
+		DTEnd:     "20251005000000.000[0:GMT]",
+		Transactions: []ofxTrn{
+			{
+				Type:     "DEBIT",
+				DTPosted: "20250915000000.000[0:GMT]",
+				Amount:   "-1234.50",
+				FITID:    s.Transactions[0].UUID.String(),
+				Name:     "CASH_PURCHASE",
+			},
+			{
+				Type:     "CREDIT",
+				DTPosted: "20250924210000.000[0:GMT]",
+				Amount:   "100.00",
+				FITID:    s.Transactions[1].UUID.String(),
+				Name:     "PAYMENT",
+			},
+		},
+		LedgerBal: ofxLedgerBalance{Amount: "-1234.50", DTAsOf: "20251005000000.000[0:GMT]"},
+	}, doc.CreditCard.Statement)
+}
+
+// the payee name is limited to 32 characters and a zero balance is not negative
+func TestWriteOFXLimits(t *testing.T) {
+	s := mockStatement()
+	s.ClosingBalance = 0
+	s.OperationTypes[1] = strings.Repeat("ã", 40)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOFX(&buf, s, language.AmericanEnglish))
+
+	var doc ofxDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, strings.Repeat("ã", 32), doc.CreditCard.Statement.Transactions[0].Name)
+	assert.Equal(t, "0.00", doc.CreditCard.Statement.LedgerBal.Amount)
+}
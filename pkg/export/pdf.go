@@ -0,0 +1,217 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// the page is A4, sizes are in points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfLineHeight = 14
+	pdfFontSize   = 10
+	// pdfCourierWidth is the advance of every Courier glyph per point of font size, amounts are
+	// set in Courier to right align them without font metrics
+	pdfCourierWidth = 0.6
+)
+
+// fonts of the standard 14 every PDF reader provides, referenced by resource name
+const (
+	pdfRegular = "F1"
+	pdfBold    = "F2"
+	pdfMono    = "F3"
+)
+
+// pdfFonts are the base fonts of the resource names, in object order
+func pdfFonts() [][2]string {
+	return [][2]string{{pdfRegular, "Helvetica"}, {pdfBold, "Helvetica-Bold"}, {pdfMono, "Courier"}}
+}
+
+// pdfDocument lays text out on pages, top to bottom
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+	// header is repeated on top of the pages a table continues on
+	header func()
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = pdfPageHeight - pdfMargin
+	if d.header != nil {
+		d.header()
+	}
+}
+
+// line moves down to the next line of the given height, breaking the page when it does not fit
+func (d *pdfDocument) line(height float64) {
+	if d.y-height < pdfMargin+pdfLineHeight {
+		d.newPage()
+	}
+	d.y -= height
+}
+
+func (d *pdfDocument) text(font string, size, x float64, s string) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %.2f %.2f Td %s Tj ET\n", font, size, x, d.y, pdfString(s))
+}
+
+// amount writes s in Courier, right aligned to the right margin
+func (d *pdfDocument) amount(size float64, s string) {
+	d.text(pdfMono, size, pdfPageWidth-pdfMargin-float64(len(pdfEncode(s)))*size*pdfCourierWidth, s)
+}
+
+// WritePDF writes the statement as a PDF document with a summary of the billing cycle and a
+// line per transaction
+func WritePDF(w io.Writer, s Statement, locale language.Tag) error {
+	d := &pdfDocument{}
+	d.newPage()
+
+	d.line(18)
+	d.text(pdfBold, 18, pdfMargin, "Statement")
+	d.line(pdfLineHeight * 1.5)
+	for _, row := range [][2]string{
+		{"Account", s.AccountUUID.String()},
+		{"Period", s.PeriodStart.UTC().Format(dateLayout) + " to " + s.PeriodEnd.UTC().Format(dateLayout)},
+		{"Due date", s.DueDate.UTC().Format(dateLayout)},
+		{"Currency", s.Currency},
+	} {
+		d.line(pdfLineHeight)
+		d.text(pdfBold, pdfFontSize, pdfMargin, row[0])
+		d.text(pdfRegular, pdfFontSize, pdfMargin+80, row[1])
+	}
+
+	d.line(pdfLineHeight)
+	for _, row := range []struct {
+		label  string
+		amount float64
+	}{
+		{"Opening balance", s.OpeningBalance},
+		{"Purchases", s.Purchases},
+		{"Payments", s.Payments},
+		{"Closing balance", s.ClosingBalance},
+		{"Minimum payment", s.MinimumPayment},
+	} {
+		d.line(pdfLineHeight)
+		d.text(pdfRegular, pdfFontSize, pdfMargin, row.label)
+		d.amount(pdfFontSize, formatAmount(locale, row.amount))
+	}
+
+	d.line(pdfLineHeight)
+	d.line(pdfLineHeight * 1.5)
+	d.text(pdfBold, 12, pdfMargin, "Transactions")
+	if len(s.Transactions) == 0 {
+		d.line(pdfLineHeight * 1.5)
+		d.text(pdfRegular, pdfFontSize, pdfMargin, "No transactions in this period.")
+	}
+
+	tableHeader := func() {
+		d.y -= pdfLineHeight * 1.5
+		d.text(pdfBold, pdfFontSize, pdfMargin, "Date")
+		d.text(pdfBold, pdfFontSize, pdfMargin+80, "Description")
+		d.text(pdfBold, pdfFontSize, pdfPageWidth-pdfMargin-6*pdfFontSize*pdfCourierWidth, "Amount")
+	}
+	if len(s.Transactions) > 0 {
+		d.line(pdfLineHeight)
+		tableHeader()
+		d.header = tableHeader
+	}
+	for _, trx := range s.Transactions {
+		d.line(pdfLineHeight)
+		d.text(pdfRegular, pdfFontSize, pdfMargin, trx.EventDate.UTC().Format(dateLayout))
+		d.text(pdfRegular, pdfFontSize, pdfMargin+80, s.description(trx))
+		d.amount(pdfFontSize, formatAmount(locale, trx.Amount))
+	}
+
+	_, err := w.Write(d.render())
+
+	return err
+}
+
+// render serializes the pages with a page number footer: the catalog, the page tree, the
+// fonts, then a page and its content stream per page, followed by the cross-reference table
+func (d *pdfDocument) render() []byte {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	fonts := pdfFonts()
+	firstPage := 3 + len(fonts)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	fontRefs := make([]string, len(fonts))
+	for i, f := range fonts {
+		fontRefs[i] = fmt.Sprintf("/%s %d 0 R", f[0], 3+i)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, f := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f[1]))
+	}
+	for i, page := range d.pages {
+		fmt.Fprintf(page, "BT /%s 8 Tf %d %d Td %s Tj ET\n", pdfRegular, pdfMargin, pdfMargin/2,
+			pdfString(fmt.Sprintf("Page %d of %d", i+1, len(d.pages))))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, strings.Join(fontRefs, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfString returns s as a literal string of the WinAnsi encoded fonts
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range pdfEncode(s) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+
+	return b.String()
+}
+
+// pdfEncode encodes s to WinAnsi, which matches Latin-1 for the printable characters the
+// documents use. Narrow no-break spaces, grouping digits in some locales, become no-break
+// spaces, other characters outside of Latin-1 are replaced with a question mark
+func pdfEncode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= ' ' && r <= '~', r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		case r == '\u202f':
+			b = append(b, 0xa0)
+		default:
+			b = append(b, '?')
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+// assertPDF checks the structure of a PDF document, every entry of the cross-reference table
+// pointing at its object, and returns its content streams
+func assertPDF(t *testing.T, doc []byte) string {
+	t.Helper()
+
+	require.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+
+	lines := strings.Split(string(doc[xref:]), "\n")
+	require.Equal(t, "xref", lines[0])
+	count, err := strconv.Atoi(strings.Fields(lines[1])[1])
+	require.NoError(t, err)
+	for i := 1; i < count; i++ {
+		offset, err := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(doc[offset:], []byte(strconv.Itoa(i)+" 0 obj\n")), "object %d", i)
+	}
+
+	var streams strings.Builder
+	for _, s := range regexp.MustCompile(`(?s)stream\n(.*?)endstream`).FindAllSubmatch(doc, -1) {
+		streams.Write(s[1])
+	}
+
+	return streams.String()
+}
+
+func TestWritePDF(t *testing.T) {
+	s := mockStatement()
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, s, language.German))
+
+	content := assertPDF(t, buf.Bytes())
+	assert.Contains(t, buf.String(), "/Count 1 ")
+	for _, text := range []string{
+		"(Statement)",
+		"(" + s.AccountUUID.String() + ")",
+		"(2025-09-05 to 2025-10-05)",
+		"(2025-10-15)",
+		"(1.234,50)",
+		"(185,18)",
+		"(CASH_PURCHASE)",
+		"(-1.234,50)",
+		"(PAYMENT)",
+		"(Page 1 of 1)",
+	} {
+		assert.Contains(t, content, text)
+	}
+}
+
+// transactions not fitting on a page continue on the next one, below the table header
+func TestWritePDFPages(t *testing.T) {
+	s := mockStatement()
+	for len(s.Transactions) < 100 {
+		s.Transactions = append(s.Transactions, s.Transactions...)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, s, language.AmericanEnglish))
+
+	content := assertPDF(t, buf.Bytes())
+	assert.Contains(t, buf.String(), "/Count 3 ")
+	assert.Contains(t, content, "(Page 3 of 3)")
+	assert.Equal(t, 3, strings.Count(content, "(Description)"))
+}
+
+func TestPDFString(t *testing.T) {
+	assert.Equal(t, `(a \(b\) \\ c)`, pdfString(`a (b) \ c`))
+	assert.Equal(t, "(1\xa0234,50 \xe3 ? ?)", pdfString("1 234,50 ã € 日"))
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+type AccountEvent struct {
+	accountEventService service.AccountEventService
+	heartbeatInterval   time.Duration
+	writeTimeout        time.Duration
+}
+
+// NewAccountEventHandler returns the handler of the event streams of accounts. A comment is sent
+// on streams every heartbeatInterval, and a stream whose client does not take a write within
+// writeTimeout is ended. Either is disabled by 0
+func NewAccountEventHandler(a service.AccountEventService, heartbeatInterval, writeTimeout time.Duration) *AccountEvent {
+	return &AccountEvent{
+		accountEventService: a,
+		heartbeatInterval:   heartbeatInterval,
+		writeTimeout:        writeTimeout,
+	}
+}
+
+// @Summary Streams the activity of an Account
+// @Description Server-sent events of the transactions created for an Account, each batch followed by the
+// @Description balance of the Account. The id of an event is the id of the last transaction sent, a client
+// @Description reconnecting with the Last-Event-ID header receives the transactions created after it.
+// @Description Without it, the stream starts with the current balance.
+// @Tags accounts
+// @Produce text/event-stream
+// @Produce application/problem+json
+// @Param   uuid path string true "Account UUID"
+// @Param   Last-Event-ID header string false "Id of the last event received"
+// @Success 200 {object} model.AccountBalance
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Router /accounts/{uuid}/events [get]
+func (h *AccountEvent) Stream(w http.ResponseWriter, r *http.Request) error {
+	uuid := chi.URLParam(r, "uuid")
+	if uuid == "" {
+		return notFoundError(accountNotFound, errors.New("path param 'uuid' cannot be empty"))
+	}
+
+	var lastEventID *int64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			return badRequestError("invalid Last-Event-ID", fmt.Errorf("header 'Last-Event-ID' must be a transaction id: '%s'", header))
+		}
+		lastEventID = &id
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream := &eventStream{w: w, rc: http.NewResponseController(w), writeTimeout: h.writeTimeout}
+	var heartbeats sync.WaitGroup
+	if h.heartbeatInterval > 0 {
+		heartbeats.Go(func() {
+			stream.heartbeat(ctx, cancel, h.heartbeatInterval)
+		})
+	}
+
+	err := h.accountEventService.Stream(ctx, uuid, lastEventID, stream.send)
+	// nothing is written once the handler returned
+	cancel()
+	heartbeats.Wait()
+
+	if err == nil {
+		return nil
+	}
+	if !stream.isStarted() {
+		if errors.Is(err, service.ErrAccountNotFound) {
+			return notFoundError(accountNotFound, err)
+		}
+
+		return withTitle("failed to stream account events", err)
+	}
+
+	// the status was sent, the client reconnects and resumes from the last event it received
+	log.Warn().Ctx(r.Context()).Err(err).Str("account_uuid", uuid).Msg("account event stream failed")
+
+	return nil
+}
+
+// eventStream writes server-sent events, the status is sent with the first event
+type eventStream struct {
+	mu           sync.Mutex
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+	started      bool
+}
+
+func (s *eventStream) send(e model.AccountEvent) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data))
+}
+
+// heartbeat sends a comment every interval until ctx is done, so that proxies keep idle streams
+// open and clients gone are noticed. A failed write ends the stream through cancel
+func (s *eventStream) heartbeat(ctx context.Context, cancel context.CancelFunc, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		// a stream not started yet may still respond with an error
+		var err error
+		if s.started {
+			err = s.write(": heartbeat\n\n")
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			cancel()
+
+			return
+		}
+	}
+}
+
+// write writes and flushes msg within the write timeout, so that a client not reading its stream
+// ends it rather than holding the connection. s.mu must be held
+func (s *eventStream) write(msg string) error {
+	if s.writeTimeout > 0 {
+		err := s.rc.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return fmt.Errorf("failed to set write deadline: %w", err)
+		}
+	}
+
+	if !s.started {
+		s.w.Header().Set("Content-Type", eventStreamContentType)
+		s.w.Header().Set("Cache-Control", "no-cache")
+		// proxies buffering responses would hold the events back
+		s.w.Header().Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err := io.WriteString(s.w, msg); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return s.rc.Flush()
+}
+
+func (s *eventStream) isStarted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+const mockEventAccountUUID = "550e8400-e29b-41d4-a716-446655440000"
+
+type accountEventTestSuite struct {
+	suite.Suite
+	ctrl             *gomock.Controller
+	connector        *AccountEvent
+	mockAccountEvent *mocks.MockAccountEventService
+	router           *chi.Mux
+	recoder          *httptest.ResponseRecorder
+}
+
+func TestAccountEventHandler(t *testing.T) {
+	suite.Run(t, new(accountEventTestSuite))
+}
+
+// Setup test suite
+func (s *accountEventTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockAccountEvent = mocks.NewMockAccountEventService(s.ctrl)
+
+	s.connector = NewAccountEventHandler(s.mockAccountEvent, 0, time.Second)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Get("/accounts/{uuid}/events", Handle(s.connector.Stream))
+}
+
+// Assert expectations
+func (s *accountEventTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *accountEventTestSuite) serve(lastEventID string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/"+mockEventAccountUUID+"/events", nil)
+	s.Require().NoError(err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	return s.recoder.Body.String()
+}
+
+// sendEvents returns a Stream sending the events and then returning err
+func sendEvents(err error, events ...model.AccountEvent) func(context.Context, string, *int64, func(model.AccountEvent) error) error {
+	return func(_ context.Context, _ string, _ *int64, send func(model.AccountEvent) error) error {
+		for _, e := range events {
+			if sendErr := send(e); sendErr != nil {
+				return sendErr
+			}
+		}
+
+		return err
+	}
+}
+
+// Success: The events are written as server-sent events
+//
+// Return: 200
+func (s *accountEventTestSuite) TestStreamSuccess() {
+	account := uuid.FromStringOrNil(mockEventAccountUUID)
+	trx := model.Transaction{
+		UUID:            uuid.NewV5(uuid.Nil, "transaction"),
+		AccountUUID:     account,
+		OperationTypeID: 1,
+		Amount:          -60.5,
+		EventDate:       time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, nil, gomock.Any()).DoAndReturn(sendEvents(nil,
+		model.AccountEvent{ID: 7, Type: model.AccountEventTransaction, Data: trx},
+		model.AccountEvent{ID: 7, Type: model.AccountEventBalance, Data: model.AccountBalance{AccountUUID: account, Balance: 60.5}},
+	))
+
+	resBody := s.serve("")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Equal("text/event-stream", s.recoder.Header().Get("Content-Type"))
+	s.Equal("no-cache", s.recoder.Header().Get("Cache-Control"))
+	s.Equal(fmt.Sprintf("id: 7\nevent: transaction\ndata: {\"uuid\":\"%s\",\"account_uuid\":\"%s\",\"operation_type_id\":1,"+
+		"\"amount\":-60.5,\"event_date\":\"2025-10-01T06:22:46Z\"}\n\n"+
+		"id: 7\nevent: balance\ndata: {\"account_uuid\":\"%s\",\"balance\":60.5}\n\n",
+		trx.UUID, account, account), resBody)
+}
+
+// Success: The stream resumes after the Last-Event-ID
+//
+// Return: 200
+func (s *accountEventTestSuite) TestStreamResume() {
+	lastEventID := int64(42)
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, &lastEventID, gomock.Any()).
+		DoAndReturn(sendEvents(nil, model.AccountEvent{ID: 42, Type: model.AccountEventBalance, Data: model.AccountBalance{}}))
+
+	resBody := s.serve("42")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Regexp("^id: 42\nevent: balance\n", resBody)
+}
+
+// BadRequest: The Last-Event-ID is not a transaction id
+//
+// Returns: 400
+func (s *accountEventTestSuite) TestStreamInvalidLastEventID() {
+	resBody := s.serve("abc")
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("invalid Last-Event-ID", resBody)
+}
+
+// NotFound: The account does not exist
+//
+// Returns: 404
+func (s *accountEventTestSuite) TestStreamAccountNotFound() {
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, nil, gomock.Any()).
+		Return(fmt.Errorf("%w for uuid: '%s'", service.ErrAccountNotFound, mockEventAccountUUID))
+
+	resBody := s.serve("")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("account not found", resBody)
+}
+
+// InternalServerError: The stream failed before its first event
+//
+// Returns: 500
+func (s *accountEventTestSuite) TestStreamFailed() {
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, nil, gomock.Any()).Return(errors.New("some-db-error"))
+
+	resBody := s.serve("")
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("failed to stream account events", resBody)
+}
+
+// Success: A stream failing after its first event ends, the client resumes it
+//
+// Returns: 200
+func (s *accountEventTestSuite) TestStreamFailedAfterStart() {
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, nil, gomock.Any()).
+		DoAndReturn(sendEvents(errors.New("some-db-error"), model.AccountEvent{ID: 1, Type: model.AccountEventBalance, Data: 0}))
+
+	resBody := s.serve("")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Equal("id: 1\nevent: balance\ndata: 0\n\n", resBody)
+}
+
+// Success: Heartbeats are sent once the stream started
+//
+// Returns: 200
+func (s *accountEventTestSuite) TestStreamHeartbeat() {
+	s.connector = NewAccountEventHandler(s.mockAccountEvent, time.Millisecond, time.Second)
+	s.router = chi.NewRouter()
+	s.router.Get("/accounts/{uuid}/events", Handle(s.connector.Stream))
+
+	s.mockAccountEvent.EXPECT().Stream(gomock.Any(), mockEventAccountUUID, nil, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ *int64, send func(model.AccountEvent) error) error {
+			if err := send(model.AccountEvent{ID: 1, Type: model.AccountEventBalance, Data: 0}); err != nil {
+				return err
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			return nil
+		})
+
+	resBody := s.serve("")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Regexp("^id: 1\nevent: balance\ndata: 0\n\n(: heartbeat\n\n)+$", resBody)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"go-pismo-challenge/pkg/model"
 	"go-pismo-challenge/pkg/repository"
-	"go-pismo-challenge/pkg/repository/mocks"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"go-pismo-challenge/pkg/util"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -24,7 +27,7 @@ type accountTestSuite struct {
 	suite.Suite
 	ctrl         *gomock.Controller
 	connector    *Account
-	mockAccounts *mocks.MockAccountConnector
+	mockAccounts *mocks.MockAccountService
 	router       *chi.Mux
 	recoder      *httptest.ResponseRecorder
 }
@@ -36,14 +39,14 @@ func TestAccountHnadler(t *testing.T) {
 // Setup test suite
 func (s *accountTestSuite) SetupTest() {
 	s.ctrl = gomock.NewController(s.T())
-	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockAccounts = mocks.NewMockAccountService(s.ctrl)
 
 	s.connector = NewAccountHandler(s.mockAccounts)
 	s.recoder = httptest.NewRecorder()
 	s.router = chi.NewRouter()
 
-	s.router.Post("/accounts", s.connector.Create)
-	s.router.Get("/accounts/{uuid}", s.connector.Get)
+	s.router.Post("/accounts", Handle(s.connector.Create))
+	s.router.Get("/accounts/{uuid}", Handle(s.connector.Get))
 }
 
 // Assert expectations
@@ -62,18 +65,18 @@ func (s *accountTestSuite) TestCreateAccountSuccess() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 	accountUUID := getMockUUID()
 
-	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), accountUUID.String()).Return(nil)
 	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, a model.Account) error {
+		DoAndReturn(func(ctx context.Context, req model.AccountRequest) (model.Account, error) {
 			// validate fields
-			if a.UUID != accountUUID || a.DocumentNumber != "abc" {
-				return errors.New("incorrect params")
+			if req.IdempotencyKey != "bc1f3956-e92e-4666-a5cd-4cbbd937b17f" || req.DocumentNumber != "abc" {
+				return model.Account{}, errors.New("incorrect params")
 			}
 
-			return nil
+			return model.Account{UUID: accountUUID, DocumentNumber: req.DocumentNumber}, nil
 		})
 
 	s.router.ServeHTTP(s.recoder, req)
@@ -81,35 +84,23 @@ func (s *accountTestSuite) TestCreateAccountSuccess() {
 	s.Equal(http.StatusCreated, s.recoder.Code)
 	resBody, err := io.ReadAll(s.recoder.Body)
 	s.NoError(err)
-	s.Regexp("uuid", string(resBody))
+	s.Regexp(accountUUID.String(), string(resBody))
 }
 
-// BadRequest: `document_number` field was not passed in the request body
+// BadRequest: The service rejected the request body
 //
 // Returns: 400
-func (s *accountTestSuite) TestAccountBadRequestDocumentNumber() {
-	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/accounts",
-		strings.NewReader(`{ "idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f" }`))
-	s.Require().NoError(err)
-	defer req.Body.Close()
-
-	s.router.ServeHTTP(s.recoder, req)
-
-	s.Equal(http.StatusBadRequest, s.recoder.Code)
-	resBody, err := io.ReadAll(s.recoder.Body)
-	s.NoError(err)
-	s.Regexp("document_number", string(resBody))
-}
-
-// BadRequest: `idempotency_key` field was not passed in the request body
-//
-// Returns: 400
-func (s *accountTestSuite) TestAccountBadRequestIdempotency() {
+func (s *accountTestSuite) TestAccountBadRequestValidation() {
 	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/accounts",
 		strings.NewReader(`{ "document_number" : "abc" }`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Account{}, &service.ValidationError{
+		Fields: []util.FieldError{{Field: "idempotency_key", Message: "field is required"}},
+	})
+
 	s.router.ServeHTTP(s.recoder, req)
 
 	s.Equal(http.StatusBadRequest, s.recoder.Code)
@@ -129,10 +120,10 @@ func (s *accountTestSuite) TestAccountIdempotencyCheckFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
-	accountUUID := getMockUUID()
 
-	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), accountUUID.String()).Return(repository.ErrDuplicate)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Account{}, service.ErrDuplicateRequest)
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -154,19 +145,10 @@ func (s *accountTestSuite) TestAccountCreateFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
-	accountUUID := getMockUUID()
 
-	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), accountUUID.String()).Return(nil)
-	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, a model.Account) error {
-			// validate fields
-			if a.UUID != accountUUID || a.DocumentNumber != "abc" {
-				return errors.New("incorrect params")
-			}
-
-			return mockDBError
-		})
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Account{}, mockDBError)
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -234,7 +216,7 @@ func (s *accountTestSuite) TestGetAccountFailureNotFound() {
 	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/"+accountUUID.String(), nil)
 	s.Require().NoError(err)
 
-	s.mockAccounts.EXPECT().Get(gomock.Any(), accountUUID.String()).Return(model.Account{}, repository.ErrNoRows)
+	s.mockAccounts.EXPECT().Get(gomock.Any(), accountUUID.String()).Return(model.Account{}, fmt.Errorf("%w for uuid: '%s'", service.ErrAccountNotFound, accountUUID))
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -261,3 +243,72 @@ func (s *accountTestSuite) TestGetAccountFailureIncorrectURLNotFound() {
 func getMockUUID() uuid.UUID {
 	return uuid.NewV5(uuid.Nil, "bc1f3956-e92e-4666-a5cd-4cbbd937b17f")
 }
+
+// GatewayTimeout: Request deadline expired while querying the account
+//
+// Return: 504
+func (s *accountTestSuite) TestGetAccountTimeout() {
+	accountUUID := getMockUUID()
+
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/"+accountUUID.String(), nil)
+	s.Require().NoError(err)
+
+	s.mockAccounts.EXPECT().Get(gomock.Any(), accountUUID.String()).
+		Return(model.Account{}, fmt.Errorf("%w: %w", repository.ErrQueryTimeout, context.DeadlineExceeded))
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	s.Equal(http.StatusGatewayTimeout, s.recoder.Code)
+	s.Regexp("request_timeout", s.recoder.Body.String())
+}
+
+// ServiceUnavailable: Database cancelled the insert on statement timeout
+//
+// Return: 503
+func (s *accountTestSuite) TestAccountCreateQueryCanceled() {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/accounts",
+		strings.NewReader(
+			`{
+				"document_number" : "abc",
+				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
+			}`))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	defer req.Body.Close()
+
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Account{}, repository.ErrQueryCanceled)
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	s.Equal(http.StatusServiceUnavailable, s.recoder.Code)
+	s.Regexp("request_timeout", s.recoder.Body.String())
+}
+
+// BadRequest: Request body is not valid JSON
+//
+// Return: 400
+func (s *accountTestSuite) TestAccountCreateMalformedBody() {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/accounts",
+		strings.NewReader(`{ "document_number" : `))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	defer req.Body.Close()
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("failed to decode request body", s.recoder.Body.String())
+}
+
+// NotFound: Get called without the uuid path param, the service is not called
+//
+// Return: 404
+func (s *accountTestSuite) TestGetAccountEmptyUUID() {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/", nil)
+	s.Require().NoError(err)
+
+	Handle(s.connector.Get)(s.recoder, req)
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("path param 'uuid' cannot be empty", s.recoder.Body.String())
+}
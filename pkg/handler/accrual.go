@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"go-pismo-challenge/pkg/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Accrual struct {
+	accrualService service.AccrualService
+}
+
+func NewAccrualHandler(s service.AccrualService) *Accrual {
+	return &Accrual{
+		accrualService: s,
+	}
+}
+
+// @Summary Previews the Accruals of an Account
+// @Description Dry run of the accrual job: lists the interest and late fees accrued by now on the unpaid balance of the latest statement
+// @Description of an Account, and whether each was posted as a transaction already. Nothing is posted.
+// @Tags accruals
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param   uuid path string true "Account UUID"
+// @Success 200 {object} model.AccrualPreview
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /accounts/{uuid}/accruals/preview [get]
+func (a *Accrual) Preview(w http.ResponseWriter, r *http.Request) error {
+	preview, err := a.accrualService.Preview(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		if errors.Is(err, service.ErrAccountNotFound) {
+			return notFoundError(accountNotFound, err)
+		}
+
+		return withTitle("failed to preview accruals", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, preview)
+
+	return nil
+}
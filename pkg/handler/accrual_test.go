@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type accrualTestSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	connector    *Accrual
+	mockAccruals *mocks.MockAccrualService
+	router       *chi.Mux
+	recoder      *httptest.ResponseRecorder
+}
+
+func TestAccrualHandler(t *testing.T) {
+	suite.Run(t, new(accrualTestSuite))
+}
+
+// Setup test suite
+func (s *accrualTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockAccruals = mocks.NewMockAccrualService(s.ctrl)
+
+	s.connector = NewAccrualHandler(s.mockAccruals)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Get("/accounts/{uuid}/accruals/preview", Handle(s.connector.Preview))
+}
+
+// Assert expectations
+func (s *accrualTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *accrualTestSuite) serve(path string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, path, nil)
+	s.Require().NoError(err)
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.Require().NoError(err)
+
+	return string(resBody)
+}
+
+// Success: Preview the accruals of an account
+//
+// Return: 200
+func (s *accrualTestSuite) TestPreviewSuccess() {
+	st := mockStatement()
+	expected := model.AccrualPreview{
+		AccountUUID:   st.AccountUUID,
+		StatementUUID: &st.UUID,
+		RatePlan:      model.RatePlan{ID: 1, Description: "STANDARD", APR: 29.99, CompoundDaily: true, GracePeriodDays: 3, LateFee: 10},
+		AsOf:          st.DueDate.AddDate(0, 0, 5),
+		Accruals: []model.Accrual{{
+			Date:            st.DueDate.AddDate(0, 0, 1),
+			OperationTypeID: model.OperationTypeInterest,
+			Amount:          0.05,
+			Balance:         60.5,
+			IdempotencyKey:  fmt.Sprintf("interest:%s:%s", st.AccountUUID, st.DueDate.AddDate(0, 0, 1).Format(time.DateOnly)),
+			Posted:          true,
+		}},
+	}
+
+	s.mockAccruals.EXPECT().Preview(gomock.Any(), st.AccountUUID.String()).Return(expected, nil)
+
+	resBody := s.serve("/accounts/" + st.AccountUUID.String() + "/accruals/preview")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// NotFound: the account does not exist
+//
+// Return: 404
+func (s *accrualTestSuite) TestPreviewAccountNotFound() {
+	accountUUID := getMockUUID().String()
+
+	s.mockAccruals.EXPECT().Preview(gomock.Any(), accountUUID).
+		Return(model.AccrualPreview{}, fmt.Errorf("%w for uuid: '%s'", service.ErrAccountNotFound, accountUUID))
+
+	resBody := s.serve("/accounts/" + accountUUID + "/accruals/preview")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("account not found", resBody)
+}
+
+// InternalServerError: failed to preview the accruals
+//
+// Return: 500
+func (s *accrualTestSuite) TestPreviewFailure() {
+	accountUUID := getMockUUID().String()
+
+	s.mockAccruals.EXPECT().Preview(gomock.Any(), accountUUID).Return(model.AccrualPreview{}, errors.New("some-db-error"))
+
+	resBody := s.serve("/accounts/" + accountUUID + "/accruals/preview")
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("failed to preview accruals", resBody)
+}
@@ -1,12 +1,234 @@
 package handler
 
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/util"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+)
+
 const (
 	internalError   = "internal_error"
 	validationError = "validation_error"
 	badRequest      = "bad_request"
 	notFound        = "not_found"
+	requestTimeout  = "request_timeout"
+
+	accountBlocked      = "account_blocked"
+	creditLimitExceeded = "credit_limit_exceeded"
+
+	unsupportedMediaType = "unsupported_media_type"
+	payloadTooLarge      = "payload_too_large"
+	notAcceptable        = "not_acceptable"
 
 	failedToCreateAccount = "failed to create account"
 	failedToCreateTrx     = "failed to create transaction"
 	accountNotFound       = "account not found"
+	failedToGetStatement  = "failed to get statement"
+
+	jsonContentType = "application/json"
 )
+
+// HandlerFunc is an HTTP handler that returns the error to respond with instead of writing it
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// statusClientClosedRequest is the non-standard status logged for requests whose client went away
+// before the response was written
+const statusClientClosedRequest = 499
+
+// Handle adapts h to an http.HandlerFunc, any error returned by h is written by writeError. The
+// errors of requests cancelled by their client are not a failure of the server, no response is
+// written for them but the 499 status for the access log
+func Handle(h HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h(w, r)
+		if err == nil {
+			return
+		}
+
+		if errors.Is(r.Context().Err(), context.Canceled) {
+			log.Debug().Ctx(r.Context()).Err(err).Msg("client closed request")
+			w.WriteHeader(statusClientClosedRequest)
+
+			return
+		}
+
+		writeError(w, r, err)
+	}
+}
+
+// Error is an application error carrying the status, code and title of its error response.
+// A zero Status or empty Code is derived from Err by errorStatus
+type Error struct {
+	Status int
+	Code   string
+	Title  string
+	Fields []util.FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Title
+	}
+
+	return e.Title + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// withTitle sets the title of the error response, its status and code are derived from err
+func withTitle(title string, err error) *Error {
+	return &Error{Title: title, Err: err}
+}
+
+func badRequestError(title string, err error) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: badRequest, Title: title, Err: err}
+}
+
+func notFoundError(title string, err error) *Error {
+	return &Error{Status: http.StatusNotFound, Code: notFound, Title: title, Err: err}
+}
+
+// errorStatus maps an error to a response status and error code. Transfers the accounts do not
+// allow are a 422 with the code of the failed check. Queries stopped by the request
+// deadline return 504 and queries cancelled by the database return 503, both with the
+// request_timeout code, unknown errors are a 500.
+func errorStatus(err error) (int, string) {
+	var vErr *service.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		return http.StatusBadRequest, validationError
+	case errors.Is(err, service.ErrDuplicateRequest),
+		errors.Is(err, service.ErrOperationTypeNotFound),
+		errors.Is(err, service.ErrRatePlanNotFound),
+		errors.Is(err, repository.ErrDuplicate):
+		return http.StatusBadRequest, badRequest
+	case errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrStatementNotFound),
+		errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, repository.ErrNoRows):
+		return http.StatusNotFound, notFound
+	case errors.Is(err, service.ErrAccountBlocked):
+		return http.StatusUnprocessableEntity, accountBlocked
+	case errors.Is(err, service.ErrCreditLimitExceeded):
+		return http.StatusUnprocessableEntity, creditLimitExceeded
+	case errors.Is(err, repository.ErrQueryTimeout):
+		return http.StatusGatewayTimeout, requestTimeout
+	case errors.Is(err, repository.ErrQueryCanceled):
+		return http.StatusServiceUnavailable, requestTimeout
+	default:
+		return http.StatusInternalServerError, internalError
+	}
+}
+
+// writeError writes the error response of err, as a problem document if the client accepts
+// application/problem+json. Validation errors are written with one entry per invalid field
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e := Error{Err: err}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		e = *appErr
+	}
+
+	status, code := errorStatus(e.Err)
+	if e.Status == 0 {
+		e.Status = status
+	}
+	if e.Code == "" {
+		e.Code = code
+	}
+	if e.Title == "" {
+		e.Title = strings.ToLower(http.StatusText(e.Status))
+	}
+
+	var details string
+	if e.Err != nil {
+		details = e.Err.Error()
+	}
+
+	var vErr *service.ValidationError
+	if errors.As(e.Err, &vErr) {
+		details = "failed to validate request body"
+		if e.Fields == nil {
+			e.Fields = vErr.Fields
+		}
+	}
+
+	if e.Status >= http.StatusInternalServerError {
+		log.Error().Ctx(r.Context()).Err(err).Int("status", e.Status).Msg(e.Title)
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	var writeErr error
+	if util.AcceptsProblemJSON(r) {
+		writeErr = util.WriteProblemJSON(r.Context(), w, util.ProblemDetails{
+			Title:    e.Title,
+			Status:   e.Status,
+			Detail:   details,
+			Instance: middleware.GetReqID(r.Context()),
+			Code:     e.Code,
+			Errors:   e.Fields,
+		})
+	} else {
+		writeErr = util.WriteJSONError(r.Context(), w, e.Status, util.ErrorDescription{
+			Status:  e.Status,
+			Code:    e.Code,
+			Title:   e.Title,
+			Details: details,
+		}, e.Fields...)
+	}
+	if writeErr != nil {
+		log.Error().Ctx(r.Context()).Err(writeErr).Msg("failed to write error response")
+	}
+}
+
+// writeJSON writes a successful response. The status is sent before the body is encoded, so an
+// encoding failure can only be logged
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	if err := util.WriteJSON(w, status, data); err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("failed to write response")
+	}
+}
+
+// decodeJSON decodes the request body into v. Bodies that cannot be decoded are a bad request,
+// with the offending field listed if known. Their size is limited by the server, see
+// server.Options.MaxRequestBodyBytes
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	if err := util.DecodeJSON(w, r, v, 0); err != nil {
+		return decodeFailure(err)
+	}
+
+	return nil
+}
+
+// decodeFailure returns the error response of a body that could not be decoded, a bad request
+// unless err says otherwise
+func decodeFailure(err error) *Error {
+	e := badRequestError("failed to decode request body", err)
+
+	var dErr *util.DecodeError
+	if errors.As(err, &dErr) {
+		e.Status = dErr.Status
+		switch {
+		case dErr.Field != nil:
+			e.Code = validationError
+			e.Fields = []util.FieldError{*dErr.Field}
+		case dErr.Status == http.StatusUnsupportedMediaType:
+			e.Code = unsupportedMediaType
+		case dErr.Status == http.StatusRequestEntityTooLarge:
+			e.Code = payloadTooLarge
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/util"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/suite"
+)
+
+type handleTestSuite struct {
+	suite.Suite
+}
+
+func TestHandle(t *testing.T) {
+	suite.Run(t, new(handleTestSuite))
+}
+
+// serve runs a handler returning err and decodes the error response
+func (s *handleTestSuite) serve(err error) (int, util.ErrorResponse) {
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	Handle(func(w http.ResponseWriter, r *http.Request) error { return err })(rec, req)
+
+	var resp util.ErrorResponse
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&resp))
+	s.Require().NotEmpty(resp.Errors)
+
+	return rec.Code, resp
+}
+
+func (s *handleTestSuite) TestNoError() {
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	Handle(func(w http.ResponseWriter, r *http.Request) error {
+		writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+
+		return nil
+	})(rec, req)
+
+	s.Equal(http.StatusOK, rec.Code)
+	s.JSONEq(`{"status":"ok"}`, rec.Body.String())
+}
+
+// A request cancelled by its client is answered with 499 and no body, whatever the error
+func (s *handleTestSuite) TestClientClosedRequest() {
+	ctx, cancel := context.WithCancel(s.T().Context())
+	cancel()
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("%w: %w", repository.ErrQueryCanceled, context.Canceled)
+	})(rec, req)
+
+	s.Equal(499, rec.Code)
+	s.Empty(rec.Body.String())
+}
+
+func (s *handleTestSuite) TestMapping() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, internalError},
+		{"no rows", repository.ErrNoRows, http.StatusNotFound, notFound},
+		{"duplicate", repository.ErrDuplicate, http.StatusBadRequest, badRequest},
+		{"duplicate request", fmt.Errorf("wrapped: %w", service.ErrDuplicateRequest), http.StatusBadRequest, badRequest},
+		{"account not found", service.ErrAccountNotFound, http.StatusNotFound, notFound},
+		{"statement not found", service.ErrStatementNotFound, http.StatusNotFound, notFound},
+		{"webhook not found", service.ErrWebhookNotFound, http.StatusNotFound, notFound},
+		{"operation type not found", service.ErrOperationTypeNotFound, http.StatusBadRequest, badRequest},
+		{"rate plan not found", service.ErrRatePlanNotFound, http.StatusBadRequest, badRequest},
+		{"timeout", repository.ErrQueryTimeout, http.StatusGatewayTimeout, requestTimeout},
+		{"canceled", repository.ErrQueryCanceled, http.StatusServiceUnavailable, requestTimeout},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			status, resp := s.serve(tt.err)
+
+			s.Equal(tt.status, status)
+			s.Equal(tt.code, resp.Errors[0].Code)
+			s.Equal(tt.status, resp.Errors[0].Status)
+			s.Equal(tt.err.Error(), resp.Errors[0].Details)
+		})
+	}
+}
+
+// the status and code of an Error take precedence over its wrapped error
+func (s *handleTestSuite) TestAppError() {
+	status, resp := s.serve(badRequestError("failed to validate account", service.ErrAccountNotFound))
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal(badRequest, resp.Errors[0].Code)
+	s.Equal("failed to validate account", resp.Errors[0].Title)
+	s.Equal(service.ErrAccountNotFound.Error(), resp.Errors[0].Details)
+}
+
+// a title only Error keeps the status derived from the wrapped error
+func (s *handleTestSuite) TestWithTitle() {
+	status, resp := s.serve(withTitle("failed to get account", repository.ErrQueryTimeout))
+
+	s.Equal(http.StatusGatewayTimeout, status)
+	s.Equal("failed to get account", resp.Errors[0].Title)
+}
+
+func (s *handleTestSuite) TestValidationError() {
+	status, resp := s.serve(withTitle(failedToCreateAccount, &service.ValidationError{Fields: []util.FieldError{
+		{Field: "idempotency_key", Message: "field is required"},
+		{Field: "document_number", Message: "field is required"},
+	}}))
+
+	s.Equal(http.StatusBadRequest, status)
+	s.Require().Len(resp.Errors, 2)
+	for _, e := range resp.Errors {
+		s.Equal(validationError, e.Code)
+		s.Equal("failed to validate request body", e.Details)
+		s.NotNil(e.Source)
+	}
+	s.Equal("document_number", resp.Errors[1].Source.Field)
+}
+
+func (s *handleTestSuite) TestDefaultTitle() {
+	_, resp := s.serve(errors.New("boom"))
+
+	s.Equal("internal server error", resp.Errors[0].Title)
+}
+
+// serveProblem runs a handler returning err for a request accepting the given media types
+func (s *handleTestSuite) serveProblem(err error, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodPost, "/accounts", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+
+	middleware.RequestID(Handle(func(w http.ResponseWriter, r *http.Request) error { return err })).ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *handleTestSuite) TestProblemJSON() {
+	rec := s.serveProblem(withTitle(failedToCreateAccount, &service.ValidationError{Fields: []util.FieldError{
+		{Field: "idempotency_key", Message: "field is required"},
+	}}), "application/problem+json")
+
+	s.Equal(http.StatusBadRequest, rec.Code)
+	s.Equal(util.ProblemContentType, rec.Header().Get("Content-Type"))
+	s.Equal("Accept", rec.Header().Get("Vary"))
+
+	var p util.ProblemDetails
+	s.Require().NoError(json.NewDecoder(rec.Body).Decode(&p))
+	s.Equal(util.ProblemTypeBaseURI+validationError, p.Type)
+	s.Equal(failedToCreateAccount, p.Title)
+	s.Equal(http.StatusBadRequest, p.Status)
+	s.Equal("failed to validate request body", p.Detail)
+	s.NotEmpty(p.Instance)
+	s.Equal(validationError, p.Code)
+	s.Equal([]util.FieldError{{Field: "idempotency_key", Message: "field is required"}}, p.Errors)
+}
+
+func (s *handleTestSuite) TestProblemJSONWithoutFields() {
+	rec := s.serveProblem(repository.ErrQueryTimeout, "application/json;q=0.5, application/problem+json")
+
+	s.Equal(http.StatusGatewayTimeout, rec.Code)
+	s.Equal(util.ProblemContentType, rec.Header().Get("Content-Type"))
+	s.NotContains(rec.Body.String(), `"errors"`)
+}
+
+// the existing format stays the default, unless problem documents are preferred
+func (s *handleTestSuite) TestNegotiation() {
+	tests := []struct {
+		accept  string
+		problem bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/problem+json, application/json", true},
+		{"application/problem+json;q=0.5, application/json", false},
+		{"application/json;q=0.5, application/problem+json", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json;q=invalid", false},
+	}
+	for _, tt := range tests {
+		s.Run(tt.accept, func() {
+			rec := s.serveProblem(errors.New("boom"), tt.accept)
+
+			s.Equal(http.StatusInternalServerError, rec.Code)
+			if tt.problem {
+				s.Equal(util.ProblemContentType, rec.Header().Get("Content-Type"))
+			} else {
+				s.Equal("application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func (s *handleTestSuite) TestDecodeJSON() {
+	type body struct {
+		Name   string `json:"name"`
+		Amount int    `json:"amount"`
+		Nested struct {
+			Flag bool `json:"flag"`
+		} `json:"nested"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		code        string
+		field       string
+	}{
+		{"valid", "application/json", `{"name": "a", "amount": 1}`, http.StatusOK, "", ""},
+		{"charset", "application/json; charset=UTF-8", `{"name": "a"}`, http.StatusOK, "", ""},
+		{"missing content type", "", `{"name": "a"}`, http.StatusUnsupportedMediaType, unsupportedMediaType, ""},
+		{"wrong content type", "text/plain", `{"name": "a"}`, http.StatusUnsupportedMediaType, unsupportedMediaType, ""},
+		{"wrong charset", "application/json; charset=latin1", `{"name": "a"}`, http.StatusUnsupportedMediaType, unsupportedMediaType, ""},
+		{"empty", "application/json", ``, http.StatusBadRequest, badRequest, ""},
+		{"syntax", "application/json", `{"name": "a",}`, http.StatusBadRequest, badRequest, ""},
+		{"truncated", "application/json", `{"name": "a"`, http.StatusBadRequest, badRequest, ""},
+		{"trailing value", "application/json", `{"name": "a"} {}`, http.StatusBadRequest, badRequest, ""},
+		{"trailing garbage", "application/json", `{"name": "a"} x`, http.StatusBadRequest, badRequest, ""},
+		{"unknown field", "application/json", `{"name": "a", "other": 1}`, http.StatusBadRequest, validationError, "other"},
+		{"wrong type", "application/json", `{"amount": "1"}`, http.StatusBadRequest, validationError, "amount"},
+		{"wrong nested type", "application/json", `{"nested": {"flag": 1}}`, http.StatusBadRequest, validationError, "nested.flag"},
+		{"too large", "application/json", `{"name": "` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, payloadTooLarge, ""},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			req := httptest.NewRequestWithContext(s.T().Context(), http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			http.MaxBytesHandler(Handle(func(w http.ResponseWriter, r *http.Request) error {
+				var b body
+				if err := decodeJSON(w, r, &b); err != nil {
+					return err
+				}
+				writeJSON(w, r, http.StatusOK, b)
+
+				return nil
+			}), 64).ServeHTTP(rec, req)
+
+			s.Equal(tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				return
+			}
+
+			var resp util.ErrorResponse
+			s.Require().NoError(json.NewDecoder(rec.Body).Decode(&resp))
+			s.Require().Len(resp.Errors, 1)
+			s.Equal(tt.code, resp.Errors[0].Code)
+			s.Equal("failed to decode request body", resp.Errors[0].Title)
+			if tt.field == "" {
+				s.Nil(resp.Errors[0].Source)
+			} else {
+				s.Require().NotNil(resp.Errors[0].Source)
+				s.Equal(tt.field, resp.Errors[0].Source.Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/util"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// HealthCheck is a single named dependency check run by the readiness probe. Details returned by
+// Check, if any, are included in the response
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) (details any, err error)
+}
+
+type Health struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler returns a handler whose readiness probe runs every check concurrently,
+// each bounded by timeout
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *Health {
+	return &Health{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// SetShuttingDown makes the readiness probe fail from now on, so that load balancers stop
+// routing new requests while in-flight ones are drained
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live reports that the process is up and serving HTTP
+func (h *Health) Live(w http.ResponseWriter, r *http.Request) {
+	if err := util.WriteJSON(w, http.StatusOK, model.HealthResponse{Status: model.HealthStatusPass}); err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("failed to write response")
+	}
+}
+
+// Ready reports whether the service can accept traffic, returning 503 with the failing
+// checks if any dependency is unhealthy or shutdown has started
+func (h *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	results := make([]model.HealthCheckResult, len(h.checks)+1)
+	results[0] = h.runCheck(r.Context(), HealthCheck{Name: "shutdown", Check: h.checkShutdown})
+
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Go(func() {
+			results[i+1] = h.runCheck(r.Context(), c)
+		})
+	}
+	wg.Wait()
+
+	resp := model.HealthResponse{Status: model.HealthStatusPass, Checks: results}
+	status := http.StatusOK
+	for _, res := range results {
+		if res.Status != model.HealthStatusPass {
+			resp.Status = model.HealthStatusFail
+			status = http.StatusServiceUnavailable
+
+			break
+		}
+	}
+
+	if err := util.WriteJSON(w, status, resp); err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("failed to write response")
+	}
+}
+
+func (h *Health) runCheck(ctx context.Context, c HealthCheck) model.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.Check(ctx)
+	res := model.HealthCheckResult{
+		Name:      c.Name,
+		Status:    model.HealthStatusPass,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		res.Status = model.HealthStatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func (h *Health) checkShutdown(_ context.Context) (any, error) {
+	if h.shuttingDown.Load() {
+		return nil, ErrShuttingDown
+	}
+
+	return nil, nil
+}
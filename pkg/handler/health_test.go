@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type healthTestSuite struct {
+	suite.Suite
+	dbErr     error
+	connector *Health
+	router    *chi.Mux
+	recoder   *httptest.ResponseRecorder
+}
+
+func TestHealthHandler(t *testing.T) {
+	suite.Run(t, new(healthTestSuite))
+}
+
+// Setup test suite
+func (s *healthTestSuite) SetupTest() {
+	s.dbErr = nil
+	s.connector = NewHealthHandler(50*time.Millisecond,
+		HealthCheck{Name: "database", Check: func(ctx context.Context) (any, error) { return nil, s.dbErr }},
+		HealthCheck{Name: "slow", Check: func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		}},
+	)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Get("/healthz", s.connector.Live)
+	s.router.Get("/readyz", s.connector.Ready)
+}
+
+func (s *healthTestSuite) serve(path string) model.HealthResponse {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, path, nil)
+	s.Require().NoError(err)
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	var resp model.HealthResponse
+	s.Require().NoError(json.NewDecoder(s.recoder.Body).Decode(&resp))
+
+	return resp
+}
+
+func (s *healthTestSuite) checkStatus(resp model.HealthResponse, name string) string {
+	for _, c := range resp.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	s.Failf("check not found", "check %q missing from response", name)
+
+	return ""
+}
+
+// Success: Liveness never depends on checks
+//
+// Return: 200
+func (s *healthTestSuite) TestLive() {
+	s.dbErr = errors.New("db down")
+
+	resp := s.serve("/healthz")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Equal(model.HealthStatusPass, resp.Status)
+}
+
+// ServiceUnavailable: A check exceeding the timeout fails readiness
+//
+// Return: 503
+func (s *healthTestSuite) TestReadyCheckTimeout() {
+	resp := s.serve("/readyz")
+
+	s.Equal(http.StatusServiceUnavailable, s.recoder.Code)
+	s.Equal(model.HealthStatusFail, resp.Status)
+	s.Equal(model.HealthStatusPass, s.checkStatus(resp, "database"))
+	s.Equal(model.HealthStatusFail, s.checkStatus(resp, "slow"))
+	s.Equal(model.HealthStatusPass, s.checkStatus(resp, "shutdown"))
+}
+
+// Success: All checks pass
+//
+// Return: 200
+func (s *healthTestSuite) TestReadySuccess() {
+	s.connector = NewHealthHandler(time.Second,
+		HealthCheck{Name: "database", Check: func(ctx context.Context) (any, error) { return nil, nil }},
+		HealthCheck{Name: "migration", Check: func(ctx context.Context) (any, error) {
+			return map[string]int{"current": 3}, nil
+		}},
+	)
+	s.router.Get("/readyz", s.connector.Ready)
+
+	resp := s.serve("/readyz")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Equal(model.HealthStatusPass, resp.Status)
+	s.Len(resp.Checks, 3)
+	s.Contains(resp.Checks, model.HealthCheckResult{
+		Name:      "migration",
+		Status:    model.HealthStatusPass,
+		LatencyMS: resp.Checks[2].LatencyMS,
+		Details:   map[string]any{"current": float64(3)},
+	})
+}
+
+// ServiceUnavailable: Readiness fails once shutdown has started
+//
+// Return: 503
+func (s *healthTestSuite) TestReadyShuttingDown() {
+	s.connector = NewHealthHandler(time.Second)
+	s.connector.SetShuttingDown()
+	s.router.Get("/readyz", s.connector.Ready)
+
+	resp := s.serve("/readyz")
+
+	s.Equal(http.StatusServiceUnavailable, s.recoder.Code)
+	s.Equal(model.HealthStatusFail, s.checkStatus(resp, "shutdown"))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"go-pismo-challenge/pkg/service"
+	"net/http"
+)
+
+type Ledger struct {
+	ledgerService service.LedgerService
+}
+
+func NewLedgerHandler(s service.LedgerService) *Ledger {
+	return &Ledger{
+		ledgerService: s,
+	}
+}
+
+// @Summary Returns the Trial Balance of the Ledger
+// @Description Totals the debits and credits of the journal entries of every ledger account. The balance of asset accounts
+// @Description is their debits minus credits, the balance of revenue accounts the opposite.
+// @Description Balanced tells whether all debits equal all credits.
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Success 200 {object} model.TrialBalance
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /ledger/trial-balance [get]
+func (l *Ledger) TrialBalance(w http.ResponseWriter, r *http.Request) error {
+	trialBalance, err := l.ledgerService.TrialBalance(r.Context())
+	if err != nil {
+		return withTitle("failed to get trial balance", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, trialBalance)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ledgerTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	connector  *Ledger
+	mockLedger *mocks.MockLedgerService
+	router     *chi.Mux
+	recoder    *httptest.ResponseRecorder
+}
+
+func TestLedgerHandler(t *testing.T) {
+	suite.Run(t, new(ledgerTestSuite))
+}
+
+// Setup test suite
+func (s *ledgerTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockLedger = mocks.NewMockLedgerService(s.ctrl)
+
+	s.connector = NewLedgerHandler(s.mockLedger)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Get("/ledger/trial-balance", Handle(s.connector.TrialBalance))
+}
+
+// Assert expectations
+func (s *ledgerTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *ledgerTestSuite) serve() string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, "/ledger/trial-balance", nil)
+	s.Require().NoError(err)
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.Require().NoError(err)
+
+	return string(resBody)
+}
+
+// Success: Get the trial balance
+//
+// Return: 200
+func (s *ledgerTestSuite) TestTrialBalanceSuccess() {
+	expected := model.TrialBalance{
+		Accounts: []model.TrialBalanceAccount{
+			{LedgerAccountID: 1, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset, Debits: 60.5, Balance: 60.5},
+			{LedgerAccountID: 2, Code: "CASH", Type: model.LedgerAccountTypeAsset, Credits: 60.5, Balance: -60.5},
+		},
+		TotalDebits:  60.5,
+		TotalCredits: 60.5,
+		Balanced:     true,
+	}
+
+	s.mockLedger.EXPECT().TrialBalance(gomock.Any()).Return(expected, nil)
+
+	resBody := s.serve()
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// InternalServerError: failed to get the trial balance
+//
+// Return: 500
+func (s *ledgerTestSuite) TestTrialBalanceFailure() {
+	s.mockLedger.EXPECT().TrialBalance(gomock.Any()).Return(model.TrialBalance{}, errors.New("some-db-error"))
+
+	resBody := s.serve()
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("failed to get trial balance", resBody)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/export"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/util"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type Statement struct {
+	statementService service.StatementService
+}
+
+func NewStatementHandler(s service.StatementService) *Statement {
+	return &Statement{
+		statementService: s,
+	}
+}
+
+// @Summary Returns the Statements of an Account
+// @Description List the statements of the closed billing cycles of an Account, latest first. Transactions are only returned by the statement endpoint.
+// @Tags statements
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param   uuid path string true "Account UUID"
+// @Success 200 {array} model.Statement
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /accounts/{uuid}/statements [get]
+func (s *Statement) List(w http.ResponseWriter, r *http.Request) error {
+	accountUUID := chi.URLParam(r, "uuid")
+
+	statements, err := s.statementService.List(r.Context(), accountUUID)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountNotFound) {
+			return notFoundError(accountNotFound, err)
+		}
+
+		return withTitle("failed to list statements", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, statements)
+
+	return nil
+}
+
+// @Summary Returns a Statement
+// @Description Get a Statement of an Account by UUID, with the transactions of its billing cycle.
+// @Description The Accept header selects JSON (the default), CSV, OFX 2.2 or PDF, the documents are sent as attachments.
+// @Description Amounts of CSV and PDF documents are formatted for the locale best matching the Accept-Language header,
+// @Description one of en-US (the default), pt-BR, de, fr or es-ES.
+// @Tags statements
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ofx
+// @Produce application/pdf
+// @Produce application/problem+json
+// @Param   uuid path string true "Account UUID"
+// @Param   id path string true "Statement UUID"
+// @Success 200 {object} model.Statement
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 406 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /accounts/{uuid}/statements/{id} [get]
+func (s *Statement) Get(w http.ResponseWriter, r *http.Request) error {
+	offers := []string{jsonContentType}
+	for _, f := range export.Formats() {
+		offers = append(offers, f.ContentType)
+	}
+
+	contentType, ok := util.NegotiateContentType(r, offers...)
+	if !ok {
+		return &Error{
+			Status: http.StatusNotAcceptable,
+			Code:   notAcceptable,
+			Title:  failedToGetStatement,
+			Err:    fmt.Errorf("supported media types are %s", strings.Join(offers, ", ")),
+		}
+	}
+
+	if format, ok := export.ByContentType(contentType); ok {
+		return s.export(w, r, format)
+	}
+
+	statement, err := s.statementService.Get(r.Context(), chi.URLParam(r, "uuid"), chi.URLParam(r, "id"))
+	if err != nil {
+		return statementError(err)
+	}
+
+	w.Header().Set("Vary", "Accept")
+	writeJSON(w, r, http.StatusOK, statement)
+
+	return nil
+}
+
+// export writes the statement as a document attachment. It is rendered before the status is
+// sent, so that a failure still gets an error response
+func (s *Statement) export(w http.ResponseWriter, r *http.Request, format export.Format) error {
+	statement, err := s.statementService.Export(r.Context(), chi.URLParam(r, "uuid"), chi.URLParam(r, "id"))
+	if err != nil {
+		return statementError(err)
+	}
+
+	locale := export.Locale(r.Header.Get("Accept-Language"))
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, statement, locale); err != nil {
+		return withTitle(failedToGetStatement, err)
+	}
+
+	filename := fmt.Sprintf("statement-%s.%s", statement.PeriodEnd.UTC().Format(time.DateOnly), format.Extension)
+
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Language", locale.String())
+	w.Header().Set("Vary", "Accept, Accept-Language")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("failed to write response")
+	}
+
+	return nil
+}
+
+func statementError(err error) error {
+	if errors.Is(err, service.ErrStatementNotFound) {
+		return notFoundError("statement not found", err)
+	}
+
+	return withTitle(failedToGetStatement, err)
+}
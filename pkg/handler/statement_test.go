@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/export"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type statementTestSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	connector      *Statement
+	mockStatements *mocks.MockStatementService
+	router         *chi.Mux
+	recoder        *httptest.ResponseRecorder
+}
+
+func TestStatementHandler(t *testing.T) {
+	suite.Run(t, new(statementTestSuite))
+}
+
+// Setup test suite
+func (s *statementTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockStatements = mocks.NewMockStatementService(s.ctrl)
+
+	s.connector = NewStatementHandler(s.mockStatements)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Get("/accounts/{uuid}/statements", Handle(s.connector.List))
+	s.router.Get("/accounts/{uuid}/statements/{id}", Handle(s.connector.Get))
+}
+
+// Assert expectations
+func (s *statementTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func mockStatement() model.Statement {
+	accountUUID := getMockUUID()
+	periodEnd := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	return model.Statement{
+		UUID:           uuid.NewV5(accountUUID, periodEnd.Format(time.RFC3339)),
+		AccountUUID:    accountUUID,
+		PeriodStart:    periodEnd.AddDate(0, -1, 0),
+		PeriodEnd:      periodEnd,
+		DueDate:        periodEnd.AddDate(0, 0, 10),
+		Purchases:      60.5,
+		ClosingBalance: 60.5,
+		MinimumPayment: 20,
+		CreatedAt:      periodEnd,
+	}
+}
+
+func (s *statementTestSuite) serve(path string, headers ...string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, path, nil)
+	s.Require().NoError(err)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.Require().NoError(err)
+
+	return string(resBody)
+}
+
+// Success: List the statements of an account
+//
+// Return: 200
+func (s *statementTestSuite) TestListSuccess() {
+	accountUUID := getMockUUID().String()
+	expected := []model.Statement{mockStatement()}
+
+	s.mockStatements.EXPECT().List(gomock.Any(), accountUUID).Return(expected, nil)
+
+	resBody := s.serve("/accounts/" + accountUUID + "/statements")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// NotFound: the account does not exist
+//
+// Return: 404
+func (s *statementTestSuite) TestListAccountNotFound() {
+	accountUUID := getMockUUID().String()
+
+	s.mockStatements.EXPECT().List(gomock.Any(), accountUUID).
+		Return(nil, fmt.Errorf("%w for uuid: '%s'", service.ErrAccountNotFound, accountUUID))
+
+	resBody := s.serve("/accounts/" + accountUUID + "/statements")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("account not found", resBody)
+}
+
+// Success: Get a statement with its transactions
+//
+// Return: 200
+func (s *statementTestSuite) TestGetSuccess() {
+	expected := mockStatement()
+	expected.Transactions = []model.Transaction{{
+		UUID:            uuid.NewV5(uuid.Nil, "trx"),
+		AccountUUID:     expected.AccountUUID,
+		OperationTypeID: 1,
+		Amount:          -60.5,
+		EventDate:       expected.PeriodStart.Add(time.Hour),
+	}}
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), expected.AccountUUID.String(), expected.UUID.String()).Return(expected, nil)
+
+	resBody := s.serve("/accounts/" + expected.AccountUUID.String() + "/statements/" + expected.UUID.String())
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// NotFound: the statement does not exist for the account
+//
+// Return: 404
+func (s *statementTestSuite) TestGetNotFound() {
+	st := mockStatement()
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), st.AccountUUID.String(), st.UUID.String()).
+		Return(model.Statement{}, fmt.Errorf("%w for uuid: '%s'", service.ErrStatementNotFound, st.UUID))
+
+	resBody := s.serve("/accounts/" + st.AccountUUID.String() + "/statements/" + st.UUID.String())
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("statement not found", resBody)
+}
+
+// InternalServerError: failed to get the statement
+//
+// Return: 500
+func (s *statementTestSuite) TestGetFailure() {
+	st := mockStatement()
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), st.AccountUUID.String(), st.UUID.String()).
+		Return(model.Statement{}, errors.New("some-db-error"))
+
+	resBody := s.serve("/accounts/" + st.AccountUUID.String() + "/statements/" + st.UUID.String())
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("internal_error", resBody)
+}
+
+func mockExport() export.Statement {
+	st := mockStatement()
+	st.Transactions = []model.Transaction{{
+		UUID:            uuid.NewV5(uuid.Nil, "trx"),
+		AccountUUID:     st.AccountUUID,
+		OperationTypeID: 1,
+		Amount:          -1060.5,
+		EventDate:       st.PeriodStart.Add(time.Hour),
+	}}
+
+	return export.Statement{Statement: st, OperationTypes: map[int]string{1: "CASH_PURCHASE"}, Currency: "BRL"}
+}
+
+// Success: Export a statement in the format of the Accept header
+//
+// Return: 200
+func (s *statementTestSuite) TestGetExport() {
+	tests := []struct {
+		accept      string
+		contentType string
+		filename    string
+		contains    string
+	}{
+		{"text/csv", export.ContentTypeCSV, "statement-2025-10-05.csv", "CASH_PURCHASE;-1.060,50;BRL"},
+		{"application/x-ofx", export.ContentTypeOFX, "statement-2025-10-05.ofx", "<TRNAMT>-1060.50</TRNAMT>"},
+		{"application/pdf, application/json;q=0.5", export.ContentTypePDF, "statement-2025-10-05.pdf", "(-1.060,50)"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.accept, func() {
+			s.recoder = httptest.NewRecorder()
+			st := mockExport()
+
+			s.mockStatements.EXPECT().Export(gomock.Any(), st.AccountUUID.String(), st.UUID.String()).Return(st, nil)
+
+			resBody := s.serve("/accounts/"+st.AccountUUID.String()+"/statements/"+st.UUID.String(),
+				"Accept", tt.accept, "Accept-Language", "pt-BR,pt;q=0.9,en;q=0.5")
+
+			s.Equal(http.StatusOK, s.recoder.Code)
+			s.Equal(tt.contentType, s.recoder.Header().Get("Content-Type"))
+			s.Equal(`attachment; filename=`+tt.filename, s.recoder.Header().Get("Content-Disposition"))
+			s.Equal("pt-BR", s.recoder.Header().Get("Content-Language"))
+			s.Equal("Accept, Accept-Language", s.recoder.Header().Get("Vary"))
+			s.Contains(resBody, tt.contains)
+		})
+	}
+}
+
+// Success: JSON stays the default for clients accepting anything
+//
+// Return: 200
+func (s *statementTestSuite) TestGetAcceptAny() {
+	st := mockStatement()
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), st.AccountUUID.String(), st.UUID.String()).Return(st, nil)
+
+	s.serve("/accounts/"+st.AccountUUID.String()+"/statements/"+st.UUID.String(), "Accept", "*/*")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.Equal("application/json; charset=utf-8", s.recoder.Header().Get("Content-Type"))
+}
+
+// NotAcceptable: none of the formats is accepted
+//
+// Return: 406
+func (s *statementTestSuite) TestGetNotAcceptable() {
+	st := mockStatement()
+
+	resBody := s.serve("/accounts/"+st.AccountUUID.String()+"/statements/"+st.UUID.String(),
+		"Accept", "image/png, application/json;q=0")
+
+	s.Equal(http.StatusNotAcceptable, s.recoder.Code)
+	s.Regexp("not_acceptable", resBody)
+	s.Regexp("text/csv", resBody)
+}
+
+// NotFound: the exported statement does not exist for the account
+//
+// Return: 404
+func (s *statementTestSuite) TestGetExportNotFound() {
+	st := mockStatement()
+
+	s.mockStatements.EXPECT().Export(gomock.Any(), st.AccountUUID.String(), st.UUID.String()).
+		Return(export.Statement{}, fmt.Errorf("%w for uuid: '%s'", service.ErrStatementNotFound, st.UUID))
+
+	resBody := s.serve("/accounts/"+st.AccountUUID.String()+"/statements/"+st.UUID.String(), "Accept", "text/csv")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Equal("application/json; charset=utf-8", s.recoder.Header().Get("Content-Type"))
+	s.Regexp("statement not found", resBody)
+}
@@ -1,34 +1,30 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"go-pismo-challenge/pkg/model"
-	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/service"
 	"go-pismo-challenge/pkg/util"
 	"net/http"
-	"time"
-
-	"github.com/gofrs/uuid"
-	"github.com/rs/zerolog/log"
 )
 
 type Transaction struct {
-	trxRepo           repository.TransactionConnector
-	accountRepo       repository.AccountConnector
-	operationTypeRepo repository.OperationTypeConnector
+	trxService service.TransactionService
+	batch      TransactionBatchOptions
+}
+
+// TransactionBatchOptions configure the batch endpoint, DefaultMode applies to the batches sent
+// without a mode and a batch holds at most MaxItems transactions
+type TransactionBatchOptions struct {
+	DefaultMode string
+	MaxItems    int
 }
 
-func NewTransactionHandler(
-	t repository.TransactionConnector,
-	a repository.AccountConnector,
-	o repository.OperationTypeConnector,
-) *Transaction {
+func NewTransactionHandler(t service.TransactionService, batch TransactionBatchOptions) *Transaction {
 	return &Transaction{
-		trxRepo:           t,
-		accountRepo:       a,
-		operationTypeRepo: o,
+		trxService: t,
+		batch:      batch,
 	}
 }
 
@@ -37,171 +33,92 @@ func NewTransactionHandler(
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Produce application/problem+json
 // @Param transaction body model.TransactionRequest true "Add transaction request"
 // @Param operation_type_id query string true "User status" Enum(active, inactive, suspended)
 // @Success 201 {object} model.TransactionResponse
 // @Failure 400 {object} util.ErrorResponse
+// @Failure 413 {object} util.ErrorResponse
+// @Failure 415 {object} util.ErrorResponse
 // @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
 // @Router /transactions [post]
-func (t *Transaction) Create(w http.ResponseWriter, r *http.Request) {
+func (t *Transaction) Create(w http.ResponseWriter, r *http.Request) error {
 	var req model.TransactionRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		err = util.WriteJSONError(w, http.StatusInternalServerError, util.ErrorDescription{
-			Status:  http.StatusInternalServerError,
-			Code:    internalError,
-			Title:   "failed to decode request body",
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
-
-		return
+	if err := decodeJSON(w, r, &req); err != nil {
+		return err
 	}
 
-	if vErr := req.Validate(); len(vErr) > 0 {
-		err = util.WriteJSONError(w,
-			http.StatusBadRequest,
-			util.ErrorDescription{
-				Code:    validationError,
-				Status:  http.StatusBadRequest,
-				Title:   failedToCreateTrx,
-				Details: "failed to validate request body",
-			},
-			vErr...)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
+	trx, err := t.trxService.Create(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccountNotFound):
+			return badRequestError("failed to validate account", err)
+		case errors.Is(err, service.ErrOperationTypeNotFound):
+			return badRequestError("failed to validate operation_type_id", err)
+		default:
+			return withTitle(failedToCreateTrx, err)
 		}
-
-		return
 	}
 
-	trxUUID := uuid.NewV5(uuid.Nil, req.IdempotencyKey)
-	if err := t.trxRepo.CheckIdempotency(r.Context(), trxUUID.String()); err != nil {
-		status := http.StatusInternalServerError
-		code := internalError
-		if errors.Is(err, repository.ErrDuplicate) {
-			status = http.StatusBadRequest
-			code = badRequest
-		}
+	writeJSON(w, r, http.StatusCreated, model.TransactionResponse{UUID: trx.UUID.String()})
 
-		err = util.WriteJSONError(w, status, util.ErrorDescription{
-			Status:  status,
-			Code:    code,
-			Title:   failedToCreateTrx,
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
+	return nil
+}
 
-		return
+// @Summary Returns the Result of every Transaction of a Batch
+// @Description Create the transactions of a batch, sent as a JSON array or as one JSON object per line with
+// @Description Content-Type application/x-ndjson. Each transaction is validated and created like by
+// @Description POST /transactions, those failing validation or referencing an account or operation type that
+// @Description does not exist are rejected with their field errors, and those with an idempotency key used
+// @Description before are duplicates. A best_effort batch creates the transactions not rejected, an
+// @Description all_or_nothing batch with a rejected transaction creates none and responds with 422.
+// @Tags transactions
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Produce application/problem+json
+// @Param transactions body []model.TransactionRequest true "Add transactions request"
+// @Param mode query string false "Whether rejected transactions leave the others to be created" Enums(all_or_nothing, best_effort)
+// @Success 200 {object} model.TransactionBatchResult
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 413 {object} util.ErrorResponse
+// @Failure 415 {object} util.ErrorResponse
+// @Failure 422 {object} model.TransactionBatchResult
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /transactions/batch [post]
+func (t *Transaction) CreateBatch(w http.ResponseWriter, r *http.Request) error {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = t.batch.DefaultMode
 	}
-
-	// validate account
-	if _, err := t.accountRepo.Get(r.Context(), req.AccountUUID); err != nil {
-		if errors.Is(err, repository.ErrNoRows) {
-			err = util.WriteJSONError(w, http.StatusBadRequest, util.ErrorDescription{
-				Status:  http.StatusBadRequest,
-				Code:    badRequest,
-				Title:   "failed to validate account",
-				Details: fmt.Sprintf("account not found for account_uuid: '%s'", req.AccountUUID),
-			})
-			if err != nil {
-				log.Error().Err(err).Msg("failed to write error response")
-			}
-
-			return
-		}
-
-		err = util.WriteJSONError(w, http.StatusInternalServerError, util.ErrorDescription{
-			Status:  http.StatusInternalServerError,
-			Code:    internalError,
-			Title:   "failed to create transaction",
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
-
-		return
+	if mode != model.BatchModeAllOrNothing && mode != model.BatchModeBestEffort {
+		return badRequestError("invalid mode", fmt.Errorf("query param 'mode' must be one of [%s, %s]: '%s'",
+			model.BatchModeAllOrNothing, model.BatchModeBestEffort, mode))
 	}
 
-	// validate operation type
-	operationType, err := t.operationTypeRepo.Get(r.Context(), req.OperationTypeID)
+	reqs, decodeErrs, err := util.DecodeJSONList[model.TransactionRequest](w, r, 0, t.batch.MaxItems)
 	if err != nil {
-		if errors.Is(err, repository.ErrNoRows) {
-			err = util.WriteJSONError(w, http.StatusBadRequest, util.ErrorDescription{
-				Status:  http.StatusBadRequest,
-				Code:    badRequest,
-				Title:   "failed to validate operation_type_id",
-				Details: fmt.Sprintf("invalid operation type: %d", req.OperationTypeID),
-			})
-			if err != nil {
-				log.Error().Err(err).Msg("failed to write error response")
-			}
-
-			return
-		}
-
-		err = util.WriteJSONError(w, http.StatusInternalServerError, util.ErrorDescription{
-			Status:  http.StatusInternalServerError,
-			Code:    internalError,
-			Title:   "failed to create transaction",
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
-
-		return
+		return decodeFailure(err)
 	}
-
-	// ignoring the error as we have already validated the field
-	accoutUUID, _ := uuid.FromString(req.AccountUUID)
-	trx := model.Transaction{
-		UUID:            trxUUID,
-		AccountUUID:     accoutUUID,
-		OperationTypeID: req.OperationTypeID,
-		Amount:          resolveAmount(req.Amount, operationType.IsCredit),
-		EventDate:       time.Now(),
-	}
-
-	if err := t.trxRepo.Create(r.Context(), trx); err != nil {
-		err = util.WriteJSONError(w, http.StatusInternalServerError, util.ErrorDescription{
-			Status:  http.StatusInternalServerError,
-			Code:    internalError,
-			Title:   failedToCreateTrx,
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
-
-		return
+	if len(reqs) == 0 {
+		return badRequestError("failed to decode request body", errors.New("request body must contain at least one transaction"))
 	}
 
-	if err := util.WriteJSON(w, http.StatusCreated, model.TransactionResponse{UUID: trxUUID.String()}); err != nil {
-		err = util.WriteJSONError(w, http.StatusInternalServerError, util.ErrorDescription{
-			Status:  http.StatusInternalServerError,
-			Code:    internalError,
-			Title:   "failed to write response",
-			Details: err.Error(),
-		})
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write error response")
-		}
-
-		return
+	result, err := t.trxService.CreateBatch(r.Context(), reqs, decodeErrs, mode)
+	if err != nil {
+		return withTitle(failedToCreateTrx, err)
 	}
-}
 
-// returns negative amount for debit operation type, else positive
-func resolveAmount(amount float64, isCredit bool) float64 {
-	if isCredit {
-		return amount
+	// nothing was stored, the client fixes the rejected transactions and sends the batch again
+	status := http.StatusOK
+	if mode == model.BatchModeAllOrNothing && result.Rejected > 0 {
+		status = http.StatusUnprocessableEntity
 	}
+	writeJSON(w, r, status, result)
 
-	return amount * (-1)
+	return nil
 }
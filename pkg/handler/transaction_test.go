@@ -3,12 +3,16 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go-pismo-challenge/pkg/model"
 	"go-pismo-challenge/pkg/repository"
-	"go-pismo-challenge/pkg/repository/mocks"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"go-pismo-challenge/pkg/util"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -20,13 +24,11 @@ import (
 
 type transactionTestSuite struct {
 	suite.Suite
-	ctrl               *gomock.Controller
-	connector          *Transaction
-	mockAccounts       *mocks.MockAccountConnector
-	mockTrx            *mocks.MockTransactionConnector
-	mockOperationTypes *mocks.MockOperationTypeConnector
-	router             *chi.Mux
-	recoder            *httptest.ResponseRecorder
+	ctrl      *gomock.Controller
+	connector *Transaction
+	mockTrx   *mocks.MockTransactionService
+	router    *chi.Mux
+	recoder   *httptest.ResponseRecorder
 }
 
 func TestTransactionHnadler(t *testing.T) {
@@ -36,15 +38,14 @@ func TestTransactionHnadler(t *testing.T) {
 // Setup test suite
 func (s *transactionTestSuite) SetupTest() {
 	s.ctrl = gomock.NewController(s.T())
-	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
-	s.mockTrx = mocks.NewMockTransactionConnector(s.ctrl)
-	s.mockOperationTypes = mocks.NewMockOperationTypeConnector(s.ctrl)
+	s.mockTrx = mocks.NewMockTransactionService(s.ctrl)
 
-	s.connector = NewTransactionHandler(s.mockTrx, s.mockAccounts, s.mockOperationTypes)
+	s.connector = NewTransactionHandler(s.mockTrx, TransactionBatchOptions{DefaultMode: model.BatchModeBestEffort, MaxItems: 3})
 	s.recoder = httptest.NewRecorder()
 	s.router = chi.NewRouter()
 
-	s.router.Post("/transactions", s.connector.Create)
+	s.router.Post("/transactions", Handle(s.connector.Create))
+	s.router.Post("/transactions/batch", Handle(s.connector.CreateBatch))
 }
 
 // Assert expectations
@@ -65,31 +66,22 @@ func (s *transactionTestSuite) TestCreateTrxSuccess() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 
 	trxUUID := getMockTrxUUID()
-	expectedOperationType := model.OperationType{
-		OperationTypeID: 4,
-		IsCredit:        true,
-	}
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
-
-	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(expectedOperationType, nil)
-
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, nil)
 
 	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, t model.Transaction) error {
+		DoAndReturn(func(ctx context.Context, req model.TransactionRequest) (model.Transaction, error) {
 			// validate fields
-			if t.UUID != trxUUID ||
-				t.AccountUUID.String() != "e2a84838-88de-5fbc-8636-6ef49e26f00a" ||
-				t.Amount != 1.1 ||
-				t.OperationTypeID != 4 {
-				return errors.New("incorrect params")
+			if req.AccountUUID != "e2a84838-88de-5fbc-8636-6ef49e26f00a" ||
+				req.Amount != 1.1 ||
+				req.OperationTypeID != 4 ||
+				req.IdempotencyKey != "bc1f3956-e92e-4666-a5cd-4cbbd937b17f" {
+				return model.Transaction{}, errors.New("incorrect params")
 			}
 
-			return nil
+			return model.Transaction{UUID: trxUUID}, nil
 		})
 
 	s.router.ServeHTTP(s.recoder, req)
@@ -97,7 +89,7 @@ func (s *transactionTestSuite) TestCreateTrxSuccess() {
 	s.Equal(http.StatusCreated, s.recoder.Code)
 	resBody, err := io.ReadAll(s.recoder.Body)
 	s.NoError(err)
-	s.Regexp("uuid", string(resBody))
+	s.Regexp(trxUUID.String(), string(resBody))
 }
 
 // BadRequest: `idempotency_key` field was not passed in the request body
@@ -112,6 +104,11 @@ func (s *transactionTestSuite) TestTransactionBadRequestIdempotency() {
 				"amount": 1.1
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, &service.ValidationError{
+		Fields: []util.FieldError{{Field: "idempotency_key", Message: "field is required"}},
+	})
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -134,10 +131,9 @@ func (s *transactionTestSuite) TestTransactionIdempotencyCheckFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 
-	trxUUID := getMockTrxUUID()
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(repository.ErrDuplicate)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, service.ErrDuplicateRequest)
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -159,6 +155,16 @@ func (s *transactionTestSuite) TestTransactionValidationFailed() {
 				"operation_type_id": -5
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, &service.ValidationError{
+		Fields: []util.FieldError{
+			{Field: "account_uuid", Code: "invalid_uuid", Message: "must be a valid uuid: 'invalid-uuid'"},
+			{Field: "operation_type_id", Code: "too_small", Message: "must be at least 1: -5"},
+			{Field: "amount", Code: "too_small", Message: "must be at least 0: -9"},
+			{Field: "idempotency_key", Code: "required", Message: "field is required"},
+		},
+	})
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -181,8 +187,9 @@ func (s *transactionTestSuite) TestTransactionValidationFailed() {
 					"title": "failed to create transaction",
 					"detail": "failed to validate request body",
 					"source": {
-						"field": "idempotency_key",
-						"message": "field is required"
+						"field": "account_uuid",
+						"code": "invalid_uuid",
+						"message": "must be a valid uuid: 'invalid-uuid'"
 					}
 				},
 				{
@@ -192,8 +199,9 @@ func (s *transactionTestSuite) TestTransactionValidationFailed() {
 					"title": "failed to create transaction",
 					"detail": "failed to validate request body",
 					"source": {
-						"field": "account_uuid",
-						"message": "invalid uuid: 'invalid-uuid'"
+						"field": "operation_type_id",
+						"code": "too_small",
+						"message": "must be at least 1: -5"
 					}
 				},
 				{
@@ -203,8 +211,9 @@ func (s *transactionTestSuite) TestTransactionValidationFailed() {
 					"title": "failed to create transaction",
 					"detail": "failed to validate request body",
 					"source": {
-						"field": "operation_type_id",
-						"message": "field is required and non-negative: -5"
+						"field": "amount",
+						"code": "too_small",
+						"message": "must be at least 0: -9"
 					}
 				},
 				{
@@ -214,8 +223,9 @@ func (s *transactionTestSuite) TestTransactionValidationFailed() {
 					"title": "failed to create transaction",
 					"detail": "failed to validate request body",
 					"source": {
-						"field": "amount",
-						"message": "field should be non-negative: -9.00"
+						"field": "idempotency_key",
+						"code": "required",
+						"message": "field is required"
 					}
 				}
 			]
@@ -237,11 +247,9 @@ func (s *transactionTestSuite) TestTransactionAccountCheckFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 
-	trxUUID := getMockTrxUUID()
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, mockDBError)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, mockDBError)
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -264,11 +272,10 @@ func (s *transactionTestSuite) TestTransactionAccountCheckFailedNotFound() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 
-	trxUUID := getMockTrxUUID()
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, repository.ErrNoRows)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{},
+		fmt.Errorf("%w for account_uuid: '%s'", service.ErrAccountNotFound, "e2a84838-88de-5fbc-8636-6ef49e26f00a"))
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -292,15 +299,10 @@ func (s *transactionTestSuite) TestTransactionOperationTypeFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 
-	trxUUID := getMockTrxUUID()
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
-
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, nil)
-
-	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{}, mockDBError)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, mockDBError)
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -323,15 +325,11 @@ func (s *transactionTestSuite) TestTransactionOperationTypeInvalid() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 
-	trxUUID := getMockTrxUUID()
-
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
-
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, nil)
-
-	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 14).Return(model.OperationType{}, repository.ErrNoRows)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{},
+		fmt.Errorf("%w: %d", service.ErrOperationTypeNotFound, 14))
 
 	s.router.ServeHTTP(s.recoder, req)
 
@@ -355,35 +353,221 @@ func (s *transactionTestSuite) TestTransactionFailed() {
 				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
 			}`))
 	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
 	defer req.Body.Close()
 
-	trxUUID := getMockTrxUUID()
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, mockDBError)
 
-	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), trxUUID.String()).Return(nil)
+	s.router.ServeHTTP(s.recoder, req)
 
-	s.mockAccounts.EXPECT().Get(gomock.Any(), "e2a84838-88de-5fbc-8636-6ef49e26f00a").Return(model.Account{}, nil)
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.NoError(err)
+	s.Regexp("internal_error", string(resBody))
+}
 
-	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 14).Return(model.OperationType{}, nil)
+// GatewayTimeout: Request deadline expired while creating the transaction
+//
+// Return: 504
+func (s *transactionTestSuite) TestTransactionTimeout() {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/transactions",
+		strings.NewReader(
+			`{
+				"account_uuid": "e2a84838-88de-5fbc-8636-6ef49e26f00a",
+				"operation_type_id": 4,
+				"amount": 1.1,
+				"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
+			}`))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	defer req.Body.Close()
 
 	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, t model.Transaction) error {
-			// validate fields
-			if t.UUID != trxUUID ||
-				t.AccountUUID.String() != "e2a84838-88de-5fbc-8636-6ef49e26f00a" ||
-				t.Amount != 1.1 ||
-				t.OperationTypeID != 4 {
-				return errors.New("incorrect params")
-			}
+		Return(model.Transaction{}, fmt.Errorf("%w: %w", repository.ErrQueryTimeout, context.DeadlineExceeded))
 
-			return mockDBError
-		})
+	s.router.ServeHTTP(s.recoder, req)
+
+	s.Equal(http.StatusGatewayTimeout, s.recoder.Code)
+	s.Regexp("request_timeout", s.recoder.Body.String())
+}
+
+// mockBatchItem is a transaction of a batch, formatted with its idempotency key
+const mockBatchItem = `{"account_uuid": "e2a84838-88de-5fbc-8636-6ef49e26f00a", "operation_type_id": 4, "amount": 1.1, ` +
+	`"idempotency_key": "%s"}`
+
+func (s *transactionTestSuite) serveBatch(query, contentType, body string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/transactions/batch"+query, strings.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", contentType)
 
 	s.router.ServeHTTP(s.recoder, req)
 
+	return s.recoder.Body.String()
+}
+
+// Success: The transactions of a JSON array are created in the default mode
+//
+// Return: 200
+func (s *transactionTestSuite) TestCreateBatchSuccess() {
+	result := model.TransactionBatchResult{
+		Mode:       model.BatchModeBestEffort,
+		Created:    1,
+		Duplicates: 1,
+		Items: []model.TransactionBatchItem{
+			{Index: 0, Status: model.BatchItemCreated, UUID: uuid.NewV5(uuid.Nil, "first").String()},
+			{Index: 1, Status: model.BatchItemDuplicate, UUID: uuid.NewV5(uuid.Nil, "second").String()},
+		},
+	}
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), []model.TransactionRequest{
+		{AccountUUID: "e2a84838-88de-5fbc-8636-6ef49e26f00a", OperationTypeID: 4, Amount: 1.1, IdempotencyKey: "first"},
+		{AccountUUID: "e2a84838-88de-5fbc-8636-6ef49e26f00a", OperationTypeID: 4, Amount: 1.1, IdempotencyKey: "second"},
+	}, map[int]util.FieldError{}, model.BatchModeBestEffort).Return(result, nil)
+
+	resBody := s.serveBatch("", "application/json", "["+fmt.Sprintf(mockBatchItem, "first")+",\n"+fmt.Sprintf(mockBatchItem, "second")+"]")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	s.JSONEq(fmt.Sprintf(`{"mode": "best_effort", "created": 1, "duplicates": 1, "rejected": 0, "skipped": 0, "items": [
+		{"index": 0, "status": "created", "uuid": "%s"},
+		{"index": 1, "status": "duplicate", "uuid": "%s"}
+	]}`, result.Items[0].UUID, result.Items[1].UUID), resBody)
+}
+
+// Success: The transactions of newline delimited JSON are created in the mode requested
+//
+// Return: 200
+func (s *transactionTestSuite) TestCreateBatchNDJSON() {
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Len(3), gomock.Len(0), model.BatchModeAllOrNothing).
+		Return(model.TransactionBatchResult{Mode: model.BatchModeAllOrNothing, Created: 3}, nil)
+
+	s.serveBatch("?mode=all_or_nothing", "application/x-ndjson",
+		fmt.Sprintf(mockBatchItem, "first")+"\n"+fmt.Sprintf(mockBatchItem, "second")+"\n\n"+fmt.Sprintf(mockBatchItem, "third")+"\n")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+}
+
+// UnprocessableEntity: An all-or-nothing batch with a rejected transaction created none
+//
+// Return: 422
+func (s *transactionTestSuite) TestCreateBatchRejected() {
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Len(1), gomock.Len(0), model.BatchModeAllOrNothing).
+		Return(model.TransactionBatchResult{Mode: model.BatchModeAllOrNothing, Rejected: 1, Items: []model.TransactionBatchItem{{
+			Index:  0,
+			Status: model.BatchItemRejected,
+			Errors: []util.FieldError{{Field: "account_uuid", Code: "not_found", Message: "account not found"}},
+		}}}, nil)
+
+	resBody := s.serveBatch("?mode=all_or_nothing", "application/json", "["+fmt.Sprintf(mockBatchItem, "first")+"]")
+
+	s.Equal(http.StatusUnprocessableEntity, s.recoder.Code)
+	s.Regexp(`"errors":\[\{"field":"account_uuid","code":"not_found"`, resBody)
+}
+
+// Success: A best-effort batch with a rejected transaction created the others
+//
+// Return: 200
+func (s *transactionTestSuite) TestCreateBatchBestEffortRejected() {
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Len(1), gomock.Len(0), model.BatchModeBestEffort).
+		Return(model.TransactionBatchResult{Mode: model.BatchModeBestEffort, Rejected: 1}, nil)
+
+	s.serveBatch("?mode=best_effort", "application/json", "["+fmt.Sprintf(mockBatchItem, "first")+"]")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+}
+
+// BadRequest: The mode is unknown
+//
+// Returns: 400
+func (s *transactionTestSuite) TestCreateBatchInvalidMode() {
+	resBody := s.serveBatch("?mode=some", "application/json", "["+fmt.Sprintf(mockBatchItem, "first")+"]")
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("invalid mode", resBody)
+}
+
+// Success: The transactions that could not be decoded are passed on as rejected, by their index
+//
+// Return: 200
+func (s *transactionTestSuite) TestCreateBatchInvalidItem() {
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), []model.TransactionRequest{
+		{AccountUUID: "e2a84838-88de-5fbc-8636-6ef49e26f00a", OperationTypeID: 4, Amount: 1.1, IdempotencyKey: "first"},
+		{},
+		{},
+	}, map[int]util.FieldError{
+		1: {Field: "amount", Code: util.FieldCodeInvalidType, Message: "expected number, got string"},
+		2: {Field: "some", Code: util.FieldCodeUnknown, Message: "unknown field"},
+	}, model.BatchModeBestEffort).Return(model.TransactionBatchResult{Mode: model.BatchModeBestEffort, Created: 1, Rejected: 2}, nil)
+
+	s.serveBatch("", "application/x-ndjson",
+		fmt.Sprintf(mockBatchItem, "first")+"\n"+`{"amount": "1.1"}`+"\n"+`{"amount": 1.1, "some": 1}`)
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+}
+
+// BadRequest: An item is malformed JSON, the batch is rejected with its index
+//
+// Returns: 400
+func (s *transactionTestSuite) TestCreateBatchMalformedItem() {
+	for body, details := range map[string]string{
+		"[" + fmt.Sprintf(mockBatchItem, "first") + `, {"amount": 1.1,}]`: "request body contains malformed JSON",
+		"[" + fmt.Sprintf(mockBatchItem, "first") + `, {"amount": 1.1}`:   "request body contains malformed JSON",
+	} {
+		s.recoder = httptest.NewRecorder()
+		resBody := s.serveBatch("", "application/json", body)
+
+		s.Equal(http.StatusBadRequest, s.recoder.Code, body)
+		s.Regexp(regexp.QuoteMeta(details), resBody)
+	}
+}
+
+// BadRequest: The body is not a JSON array, or an empty one
+//
+// Returns: 400
+func (s *transactionTestSuite) TestCreateBatchNotAList() {
+	for body, details := range map[string]string{
+		fmt.Sprintf(mockBatchItem, "first"): "request body must be a JSON array",
+		"[]":                                "request body must contain at least one transaction",
+		"[" + fmt.Sprintf(mockBatchItem, "first") + "] []": "request body must contain a single JSON array",
+	} {
+		s.recoder = httptest.NewRecorder()
+		resBody := s.serveBatch("", "application/json", body)
+
+		s.Equal(http.StatusBadRequest, s.recoder.Code, body)
+		s.Regexp(regexp.QuoteMeta(details), resBody)
+	}
+}
+
+// PayloadTooLarge: The batch holds more transactions than allowed
+//
+// Returns: 413
+func (s *transactionTestSuite) TestCreateBatchTooManyItems() {
+	resBody := s.serveBatch("", "application/x-ndjson", strings.Repeat(fmt.Sprintf(mockBatchItem, "first")+"\n", 4))
+
+	s.Equal(http.StatusRequestEntityTooLarge, s.recoder.Code)
+	s.Regexp("payload_too_large", resBody)
+	s.Regexp("request body must not contain more than 3 items", resBody)
+}
+
+// UnsupportedMediaType: The body is neither JSON nor newline delimited JSON
+//
+// Returns: 415
+func (s *transactionTestSuite) TestCreateBatchUnsupportedMediaType() {
+	resBody := s.serveBatch("", "text/csv", "account_uuid,operation_type_id,amount")
+
+	s.Equal(http.StatusUnsupportedMediaType, s.recoder.Code)
+	s.Regexp("Content-Type must be application/json or application/x-ndjson", resBody)
+}
+
+// InternalServerError: The batch could not be stored
+//
+// Returns: 500
+func (s *transactionTestSuite) TestCreateBatchFailed() {
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(model.TransactionBatchResult{}, errors.New("some-db-error"))
+
+	resBody := s.serveBatch("", "application/json", "["+fmt.Sprintf(mockBatchItem, "first")+"]")
+
 	s.Equal(http.StatusInternalServerError, s.recoder.Code)
-	resBody, err := io.ReadAll(s.recoder.Body)
-	s.NoError(err)
-	s.Regexp("internal_error", string(resBody))
+	s.Regexp("failed to create transaction", resBody)
 }
 
 // Returns mock uuid
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"net/http"
+)
+
+type Transfer struct {
+	transferService service.TransferService
+}
+
+func NewTransferHandler(t service.TransferService) *Transfer {
+	return &Transfer{
+		transferService: t,
+	}
+}
+
+// @Summary Returns the Created Transfer
+// @Description Moves an amount between two accounts, creating a TRANSFER_OUT transaction debiting the from account
+// @Description and a TRANSFER_IN transaction crediting the to account atomically.
+// @Description Transfers from or to a blocked account, or leaving the from account owing more than its credit limit, are rejected with 422.
+// @Description Retrying with the same idempotency_key is rejected as a duplicate.
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param transfer body model.TransferRequest true "Add transfer request"
+// @Success 201 {object} model.Transfer
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 413 {object} util.ErrorResponse
+// @Failure 415 {object} util.ErrorResponse
+// @Failure 422 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /transfers [post]
+func (t *Transfer) Create(w http.ResponseWriter, r *http.Request) error {
+	var req model.TransferRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return err
+	}
+
+	transfer, err := t.transferService.Create(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountNotFound) {
+			return badRequestError("failed to validate account", err)
+		}
+
+		return withTitle("failed to create transfer", err)
+	}
+
+	writeJSON(w, r, http.StatusCreated, transfer)
+
+	return nil
+}
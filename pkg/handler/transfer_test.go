@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"go-pismo-challenge/pkg/util"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+const mockTransferBody = `{
+	"from_account_uuid": "e2a84838-88de-5fbc-8636-6ef49e26f00a",
+	"to_account_uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"amount": 25.5,
+	"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
+}`
+
+type transferTestSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	connector    *Transfer
+	mockTransfer *mocks.MockTransferService
+	router       *chi.Mux
+	recoder      *httptest.ResponseRecorder
+}
+
+func TestTransferHandler(t *testing.T) {
+	suite.Run(t, new(transferTestSuite))
+}
+
+// Setup test suite
+func (s *transferTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockTransfer = mocks.NewMockTransferService(s.ctrl)
+
+	s.connector = NewTransferHandler(s.mockTransfer)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Post("/transfers", Handle(s.connector.Create))
+}
+
+// Assert expectations
+func (s *transferTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *transferTestSuite) serve(body string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodPost, "/transfers", strings.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.Require().NoError(err)
+
+	return string(resBody)
+}
+
+// Success: A transfer was created
+//
+// Return: 201
+func (s *transferTestSuite) TestCreateTransferSuccess() {
+	expected := model.Transfer{
+		UUID:                  uuid.NewV5(uuid.Nil, "transfer"),
+		FromAccountUUID:       uuid.FromStringOrNil("e2a84838-88de-5fbc-8636-6ef49e26f00a"),
+		ToAccountUUID:         uuid.FromStringOrNil("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		Amount:                25.5,
+		DebitTransactionUUID:  uuid.NewV5(uuid.Nil, "debit"),
+		CreditTransactionUUID: uuid.NewV5(uuid.Nil, "credit"),
+		CreatedAt:             time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+
+	s.mockTransfer.EXPECT().Create(gomock.Any(), model.TransferRequest{
+		FromAccountUUID: "e2a84838-88de-5fbc-8636-6ef49e26f00a",
+		ToAccountUUID:   "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		Amount:          25.5,
+		IdempotencyKey:  "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}).Return(expected, nil)
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusCreated, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// BadRequest: Both accounts of the transfer are the same
+//
+// Returns: 400
+func (s *transferTestSuite) TestCreateTransferValidationFailed() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{}, &service.ValidationError{
+		Fields: []util.FieldError{{Field: "to_account_uuid", Code: "not_allowed", Message: "must differ from from_account_uuid"}},
+	})
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("to_account_uuid", resBody)
+	s.Regexp("failed to create transfer", resBody)
+}
+
+// BadRequest: Duplicate request received
+//
+// Returns: 400
+func (s *transferTestSuite) TestCreateTransferDuplicate() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{}, service.ErrDuplicateRequest)
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("duplicate", resBody)
+}
+
+// BadRequest: One of the accounts does not exist
+//
+// Returns: 400
+func (s *transferTestSuite) TestCreateTransferAccountNotFound() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{},
+		fmt.Errorf("%w for to_account_uuid: '6ba7b810-9dad-11d1-80b4-00c04fd430c8'", service.ErrAccountNotFound))
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("failed to validate account", resBody)
+	s.Regexp("to_account_uuid", resBody)
+}
+
+// UnprocessableEntity: The from account is blocked
+//
+// Returns: 422
+func (s *transferTestSuite) TestCreateTransferAccountBlocked() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{},
+		fmt.Errorf("%w: 'e2a84838-88de-5fbc-8636-6ef49e26f00a'", service.ErrAccountBlocked))
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusUnprocessableEntity, s.recoder.Code)
+	s.Regexp("account_blocked", resBody)
+	s.Regexp("failed to create transfer", resBody)
+}
+
+// UnprocessableEntity: The from account would exceed its credit limit
+//
+// Returns: 422
+func (s *transferTestSuite) TestCreateTransferCreditLimitExceeded() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{}, service.ErrCreditLimitExceeded)
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusUnprocessableEntity, s.recoder.Code)
+	s.Regexp("credit_limit_exceeded", resBody)
+}
+
+// InternalServerError: failed to create the transfer
+//
+// Returns: 500
+func (s *transferTestSuite) TestCreateTransferFailed() {
+	s.mockTransfer.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transfer{}, errors.New("some-db-error"))
+
+	resBody := s.serve(mockTransferBody)
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("failed to create transfer", resBody)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const webhookNotFound = "webhook not found"
+
+type Webhook struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) *Webhook {
+	return &Webhook{
+		webhookService: s,
+	}
+}
+
+// @Summary Returns the Created Webhook
+// @Description Registers an endpoint receiving the events of the given types as JSON POST requests. The response holds
+// @Description the secret signing the deliveries, which is not returned again. Each delivery has an X-Webhook-Timestamp
+// @Description header with its unix time and an X-Webhook-Signature header "v1=" followed by the hex encoded
+// @Description HMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret. Failed deliveries are retried
+// @Description with exponential backoff, and the webhook is disabled after repeated failures until it is enabled again.
+// @Description Retrying with the same idempotency_key is rejected as a duplicate.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param webhook body model.WebhookRequest true "Add webhook request"
+// @Success 201 {object} model.Webhook
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 413 {object} util.ErrorResponse
+// @Failure 415 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /webhooks [post]
+func (h *Webhook) Create(w http.ResponseWriter, r *http.Request) error {
+	var req model.WebhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return err
+	}
+
+	webhook, err := h.webhookService.Create(r.Context(), req)
+	if err != nil {
+		return withTitle("failed to create webhook", err)
+	}
+
+	writeJSON(w, r, http.StatusCreated, webhook)
+
+	return nil
+}
+
+// @Summary Returns a Webhook
+// @Description Get a Webhook by UUID, without its secret. Disabled webhooks receive no deliveries.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param   uuid path string true "Webhook UUID"
+// @Success 200 {object} model.Webhook
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /webhooks/{uuid} [get]
+func (h *Webhook) Get(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := h.webhookService.Get(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		return webhookError("failed to get webhook", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, webhook)
+
+	return nil
+}
+
+// @Summary Returns the Enabled Webhook
+// @Description Re-enable a Webhook disabled after repeated failed deliveries, resetting its consecutive failures. The
+// @Description deliveries queued before it was disabled are attempted again, events published while it was disabled
+// @Description are not delivered. The url is checked again and must still resolve to a public address.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param   uuid path string true "Webhook UUID"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /webhooks/{uuid}/enable [post]
+func (h *Webhook) Enable(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := h.webhookService.Enable(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		return webhookError("failed to enable webhook", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, webhook)
+
+	return nil
+}
+
+// @Summary Returns the Delivery Log of a Webhook
+// @Description List the latest 100 attempts to deliver events to a Webhook, newest first.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Produce application/problem+json
+// @Param   uuid path string true "Webhook UUID"
+// @Success 200 {array} model.WebhookDeliveryAttempt
+// @Failure 404 {object} util.ErrorResponse
+// @Failure 500 {object} util.ErrorResponse
+// @Failure 503 {object} util.ErrorResponse
+// @Failure 504 {object} util.ErrorResponse
+// @Router /webhooks/{uuid}/deliveries [get]
+func (h *Webhook) Deliveries(w http.ResponseWriter, r *http.Request) error {
+	attempts, err := h.webhookService.Deliveries(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		return webhookError("failed to list webhook deliveries", err)
+	}
+
+	writeJSON(w, r, http.StatusOK, attempts)
+
+	return nil
+}
+
+func webhookError(title string, err error) error {
+	if errors.Is(err, service.ErrWebhookNotFound) {
+		return notFoundError(webhookNotFound, err)
+	}
+
+	return withTitle(title, err)
+}
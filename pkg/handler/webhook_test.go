@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service"
+	"go-pismo-challenge/pkg/service/mocks"
+	"go-pismo-challenge/pkg/util"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	mockWebhookUUID = "e2a84838-88de-5fbc-8636-6ef49e26f00a"
+	mockWebhookBody = `{
+	"url": "https://example.com/webhooks",
+	"event_types": ["account.created", "transaction.created"],
+	"idempotency_key": "bc1f3956-e92e-4666-a5cd-4cbbd937b17f"
+}`
+)
+
+type webhookTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	connector   *Webhook
+	mockWebhook *mocks.MockWebhookService
+	router      *chi.Mux
+	recoder     *httptest.ResponseRecorder
+}
+
+func TestWebhookHandler(t *testing.T) {
+	suite.Run(t, new(webhookTestSuite))
+}
+
+// Setup test suite
+func (s *webhookTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockWebhook = mocks.NewMockWebhookService(s.ctrl)
+
+	s.connector = NewWebhookHandler(s.mockWebhook)
+	s.recoder = httptest.NewRecorder()
+	s.router = chi.NewRouter()
+
+	s.router.Post("/webhooks", Handle(s.connector.Create))
+	s.router.Get("/webhooks/{uuid}", Handle(s.connector.Get))
+	s.router.Post("/webhooks/{uuid}/enable", Handle(s.connector.Enable))
+	s.router.Get("/webhooks/{uuid}/deliveries", Handle(s.connector.Deliveries))
+}
+
+// Assert expectations
+func (s *webhookTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *webhookTestSuite) serve(method, target, body string) string {
+	req, err := http.NewRequestWithContext(s.T().Context(), method, target, strings.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+
+	s.router.ServeHTTP(s.recoder, req)
+
+	resBody, err := io.ReadAll(s.recoder.Body)
+	s.Require().NoError(err)
+
+	return string(resBody)
+}
+
+func mockWebhook() model.Webhook {
+	return model.Webhook{
+		UUID:       uuid.FromStringOrNil(mockWebhookUUID),
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{model.EventAccountCreated, model.EventTransactionCreated},
+		Enabled:    true,
+		CreatedAt:  time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+}
+
+// Success: A webhook was created, its secret is returned
+//
+// Return: 201
+func (s *webhookTestSuite) TestCreateWebhookSuccess() {
+	expected := mockWebhook()
+	expected.Secret = "whsec_secret"
+
+	s.mockWebhook.EXPECT().Create(gomock.Any(), model.WebhookRequest{
+		URL:            "https://example.com/webhooks",
+		EventTypes:     []string{model.EventAccountCreated, model.EventTransactionCreated},
+		IdempotencyKey: "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}).Return(expected, nil)
+
+	resBody := s.serve(http.MethodPost, "/webhooks", mockWebhookBody)
+
+	s.Equal(http.StatusCreated, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+	s.Regexp("whsec_secret", resBody)
+}
+
+// BadRequest: An event type is unknown
+//
+// Returns: 400
+func (s *webhookTestSuite) TestCreateWebhookValidationFailed() {
+	s.mockWebhook.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Webhook{}, &service.ValidationError{
+		Fields: []util.FieldError{{Field: "event_types[0]", Code: "not_allowed", Message: "must be one of [...]: 'x'"}},
+	})
+
+	resBody := s.serve(http.MethodPost, "/webhooks", mockWebhookBody)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp(`event_types\[0\]`, resBody)
+	s.Regexp("failed to create webhook", resBody)
+}
+
+// BadRequest: Duplicate request received
+//
+// Returns: 400
+func (s *webhookTestSuite) TestCreateWebhookDuplicate() {
+	s.mockWebhook.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Webhook{}, service.ErrDuplicateRequest)
+
+	resBody := s.serve(http.MethodPost, "/webhooks", mockWebhookBody)
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("duplicate", resBody)
+}
+
+// Success: The webhook was found
+//
+// Return: 200
+func (s *webhookTestSuite) TestGetWebhookSuccess() {
+	expected := mockWebhook()
+	s.mockWebhook.EXPECT().Get(gomock.Any(), mockWebhookUUID).Return(expected, nil)
+
+	resBody := s.serve(http.MethodGet, "/webhooks/"+mockWebhookUUID, "")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+	s.NotContains(resBody, "secret")
+}
+
+// NotFound: The webhook does not exist
+//
+// Returns: 404
+func (s *webhookTestSuite) TestGetWebhookNotFound() {
+	s.mockWebhook.EXPECT().Get(gomock.Any(), mockWebhookUUID).
+		Return(model.Webhook{}, fmt.Errorf("%w for uuid: '%s'", service.ErrWebhookNotFound, mockWebhookUUID))
+
+	resBody := s.serve(http.MethodGet, "/webhooks/"+mockWebhookUUID, "")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("webhook not found", resBody)
+}
+
+// Success: The webhook was enabled again
+//
+// Return: 200
+func (s *webhookTestSuite) TestEnableWebhookSuccess() {
+	expected := mockWebhook()
+	s.mockWebhook.EXPECT().Enable(gomock.Any(), mockWebhookUUID).Return(expected, nil)
+
+	resBody := s.serve(http.MethodPost, "/webhooks/"+mockWebhookUUID+"/enable", "")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// BadRequest: The url no longer resolves to a public address
+//
+// Returns: 400
+func (s *webhookTestSuite) TestEnableWebhookForbiddenURL() {
+	s.mockWebhook.EXPECT().Enable(gomock.Any(), mockWebhookUUID).Return(model.Webhook{}, &service.ValidationError{
+		Fields: []util.FieldError{{Field: "url", Code: "not_allowed", Message: "webhook url is not allowed"}},
+	})
+
+	resBody := s.serve(http.MethodPost, "/webhooks/"+mockWebhookUUID+"/enable", "")
+
+	s.Equal(http.StatusBadRequest, s.recoder.Code)
+	s.Regexp("not_allowed", resBody)
+	s.Regexp("failed to enable webhook", resBody)
+}
+
+// NotFound: The webhook does not exist
+//
+// Returns: 404
+func (s *webhookTestSuite) TestEnableWebhookNotFound() {
+	s.mockWebhook.EXPECT().Enable(gomock.Any(), mockWebhookUUID).Return(model.Webhook{}, service.ErrWebhookNotFound)
+
+	resBody := s.serve(http.MethodPost, "/webhooks/"+mockWebhookUUID+"/enable", "")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("webhook not found", resBody)
+}
+
+// Success: The delivery log of the webhook
+//
+// Return: 200
+func (s *webhookTestSuite) TestDeliveriesSuccess() {
+	expected := []model.WebhookDeliveryAttempt{{
+		EventUUID:   uuid.NewV5(uuid.Nil, "event"),
+		EventType:   model.EventAccountCreated,
+		Attempt:     2,
+		StatusCode:  503,
+		Error:       "webhook responded with status 503",
+		DurationMS:  12,
+		AttemptedAt: time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}}
+	s.mockWebhook.EXPECT().Deliveries(gomock.Any(), mockWebhookUUID).Return(expected, nil)
+
+	resBody := s.serve(http.MethodGet, "/webhooks/"+mockWebhookUUID+"/deliveries", "")
+
+	s.Equal(http.StatusOK, s.recoder.Code)
+	expectedJson, err := json.Marshal(expected)
+	s.Require().NoError(err)
+	s.JSONEq(string(expectedJson), resBody)
+}
+
+// NotFound: The webhook does not exist
+//
+// Returns: 404
+func (s *webhookTestSuite) TestDeliveriesNotFound() {
+	s.mockWebhook.EXPECT().Deliveries(gomock.Any(), mockWebhookUUID).Return(nil, service.ErrWebhookNotFound)
+
+	resBody := s.serve(http.MethodGet, "/webhooks/"+mockWebhookUUID+"/deliveries", "")
+
+	s.Equal(http.StatusNotFound, s.recoder.Code)
+	s.Regexp("webhook not found", resBody)
+}
+
+// InternalServerError: failed to list the deliveries
+//
+// Returns: 500
+func (s *webhookTestSuite) TestDeliveriesFailed() {
+	s.mockWebhook.EXPECT().Deliveries(gomock.Any(), mockWebhookUUID).Return(nil, errors.New("some-db-error"))
+
+	resBody := s.serve(http.MethodGet, "/webhooks/"+mockWebhookUUID+"/deliveries", "")
+
+	s.Equal(http.StatusInternalServerError, s.recoder.Code)
+	s.Regexp("failed to list webhook deliveries", resBody)
+}
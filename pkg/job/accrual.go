@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"go-pismo-challenge/pkg/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewAccrualPoster returns the job posting the interest and late fees accrued on unpaid
+// statements every interval. Running it on several instances is safe, each accrual is posted only
+// once
+func NewAccrualPoster(s service.AccrualService, interval time.Duration) *Job {
+	return Every(interval, "accrual poster", func(ctx context.Context) error {
+		posted, err := s.Accrue(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to post accruals, %d posted: %w", posted, err)
+		}
+
+		if posted > 0 {
+			log.Info().Ctx(ctx).Int("posted", posted).Msg("posted accruals")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+// Package job runs the background work of the service.
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job runs its work periodically, see Every
+type Job struct {
+	name     string
+	interval time.Duration
+	work     func(ctx context.Context) error
+}
+
+// Every returns the job name running work right away and then every interval, once the previous
+// run is done. A failed run is logged and retried on the next tick, one interrupted by the
+// context being cancelled on shutdown is not logged: its work is picked up on the next start
+func Every(interval time.Duration, name string, work func(ctx context.Context) error) *Job {
+	return &Job{
+		name:     name,
+		interval: interval,
+		work:     work,
+	}
+}
+
+// Run runs the job until ctx is done
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Job) runOnce(ctx context.Context) {
+	err := j.work(ctx)
+	if err == nil || (errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+		return
+	}
+
+	log.Error().Ctx(ctx).Err(err).Str("job", j.name).Msg("job failed")
+}
@@ -0,0 +1,142 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// captureLog sends the global logger to a buffer until the test ends
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = logger })
+
+	return &buf
+}
+
+// runJob runs the job until it stops, failing the test if it does not stop in time
+func runJob(t *testing.T, ctx context.Context, j *Job) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		j.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not stop after the context was cancelled")
+	}
+}
+
+// the work runs on start and then on every tick, failures are logged and do not stop the job
+func TestEvery(t *testing.T) {
+	logs := captureLog(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	runJob(t, ctx, Every(time.Millisecond, "test job", func(context.Context) error {
+		runs++
+		switch runs {
+		case 1:
+			return errors.New("db error")
+		case 3:
+			cancel()
+		}
+
+		return nil
+	}))
+
+	assert.Equal(t, 3, runs)
+	assert.Contains(t, logs.String(), `"level":"error","error":"db error","job":"test job","message":"job failed"`)
+}
+
+// a run interrupted by shutdown is not a failure
+func TestEveryCanceled(t *testing.T) {
+	logs := captureLog(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runJob(t, ctx, Every(time.Millisecond, "test job", func(ctx context.Context) error {
+		cancel()
+
+		return ctx.Err()
+	}))
+
+	assert.Empty(t, logs.String())
+}
+
+// a canceled context of the work's own is a failure while the job runs on
+func TestEveryCanceledWork(t *testing.T) {
+	logs := captureLog(t)
+
+	Every(time.Millisecond, "test job", func(context.Context) error {
+		return context.Canceled
+	}).runOnce(context.Background())
+
+	assert.Contains(t, logs.String(), `"message":"job failed"`)
+}
+
+func TestAccrualPoster(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockAccrualService(ctrl)
+	j := NewAccrualPoster(svc, time.Minute)
+
+	svc.EXPECT().Accrue(gomock.Any()).Return(3, nil)
+	require.NoError(t, j.work(context.Background()))
+
+	svc.EXPECT().Accrue(gomock.Any()).Return(1, errors.New("db error"))
+	assert.EqualError(t, j.work(context.Background()), "failed to post accruals, 1 posted: db error")
+}
+
+func TestStatementCloser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockStatementService(ctrl)
+	c := clock.NewFake(time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC))
+	j := NewStatementCloser(svc, time.Minute, c)
+
+	svc.EXPECT().CloseCycles(gomock.Any(), c.Now()).Return(2, nil)
+	require.NoError(t, j.work(context.Background()))
+
+	svc.EXPECT().CloseCycles(gomock.Any(), c.Now()).Return(1, errors.New("db error"))
+	assert.EqualError(t, j.work(context.Background()), "failed to close billing cycles, 1 closed: db error")
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockOutboxService(ctrl)
+	j := NewOutboxRelay(svc, time.Minute)
+
+	svc.EXPECT().Relay(gomock.Any()).Return(3, nil)
+	require.NoError(t, j.work(context.Background()))
+
+	svc.EXPECT().Relay(gomock.Any()).Return(0, errors.New("db error"))
+	assert.EqualError(t, j.work(context.Background()), "failed to relay outbox events, 0 published: db error")
+}
+
+func TestWebhookDeliverer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockWebhookService(ctrl)
+	j := NewWebhookDeliverer(svc, time.Minute)
+
+	svc.EXPECT().Deliver(gomock.Any()).Return(3, nil)
+	require.NoError(t, j.work(context.Background()))
+
+	svc.EXPECT().Deliver(gomock.Any()).Return(1, errors.New("db error"))
+	assert.EqualError(t, j.work(context.Background()), "failed to deliver webhooks, 1 delivered: db error")
+}
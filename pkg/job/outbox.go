@@ -0,0 +1,27 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"go-pismo-challenge/pkg/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewOutboxRelay returns the job publishing the events added to the outbox every interval.
+// Running it on several instances is safe, their relays take turns
+func NewOutboxRelay(s service.OutboxService, interval time.Duration) *Job {
+	return Every(interval, "outbox relay", func(ctx context.Context) error {
+		published, err := s.Relay(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to relay outbox events, %d published: %w", published, err)
+		}
+
+		if published > 0 {
+			log.Debug().Ctx(ctx).Int("published", published).Msg("relayed outbox events")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,29 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewStatementCloser returns the job closing the billing cycles that ended by the time of c into
+// statements, every interval. Running it on several instances is safe, each cycle is closed by
+// only one of them
+func NewStatementCloser(s service.StatementService, interval time.Duration, c clock.Clock) *Job {
+	return Every(interval, "statement closer", func(ctx context.Context) error {
+		closed, err := s.CloseCycles(ctx, c.Now())
+		if err != nil {
+			return fmt.Errorf("failed to close billing cycles, %d closed: %w", closed, err)
+		}
+
+		if closed > 0 {
+			log.Info().Ctx(ctx).Int("closed", closed).Msg("closed billing cycles")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"go-pismo-challenge/pkg/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewWebhookDeliverer returns the job attempting the webhook deliveries due every interval.
+// Running it on several instances is safe but may deliver an event more than once, which
+// receivers tolerate by dropping redeliveries by event id
+func NewWebhookDeliverer(s service.WebhookService, interval time.Duration) *Job {
+	return Every(interval, "webhook deliverer", func(ctx context.Context) error {
+		delivered, err := s.Deliver(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to deliver webhooks, %d delivered: %w", delivered, err)
+		}
+
+		if delivered > 0 {
+			log.Debug().Ctx(ctx).Int("delivered", delivered).Msg("delivered webhooks")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+var ErrInvalidMigrationName = errors.New("migration name must contain letters or digits")
+
+const sqlMigrationTemplate = `-- +goose Up
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+`
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty SQL migration skeleton named <next version>_<name>.sql into dir and
+// returns its path. Versions are sequential and zero padded like the existing migrations.
+func Create(dir, name string) (string, error) {
+	snake := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if snake == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMigrationName, name)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return "", fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var last int64
+	for _, f := range files {
+		v, err := goose.NumericComponent(f)
+		if err != nil {
+			return "", fmt.Errorf("invalid migration file %s: %w", f, err)
+		}
+		last = max(last, v)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%06d_%s.sql", last+1, snake))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create migration file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(sqlMigrationTemplate); err != nil {
+		return "", fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,137 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// lockID is the Postgres advisory lock key shared by every migrator of this service. It fits in
+// 32 bits so that it shows up as objid (with classid 0) in pg_locks
+const lockID int64 = 0x5049534D // "PISM"
+
+const lockPollInterval = time.Second
+
+// lockHolder describes the session holding the migration lock, used for logging only
+type lockHolder struct {
+	PID             int64
+	ApplicationName string
+	ClientAddr      string
+	BackendStart    time.Time
+}
+
+// withLock runs fn while holding the migration advisory lock, so that migrators started
+// concurrently (e.g. by several pods) apply migrations one after the other
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.dialect != DialectPostgres {
+		return fn()
+	}
+
+	conn, err := acquireLock(ctx, m.db, m.lockTimeout, lockPollInterval)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := releaseLock(context.WithoutCancel(ctx), conn); err != nil {
+			log.Error().Err(err).Msg("failed to release migration lock")
+		}
+	}()
+
+	return fn()
+}
+
+// acquireLock takes the session level advisory lock on a dedicated connection, polling every
+// pollInterval until timeout. The returned connection must be passed to releaseLock.
+func acquireLock(ctx context.Context, db *sql.DB, timeout, pollInterval time.Duration) (*sql.Conn, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := db.Conn(waitCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+
+	start := time.Now()
+	var lastHolder int64
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(waitCtx, `SELECT pg_try_advisory_lock($1);`, lockID).Scan(&locked); err != nil {
+			conn.Close()
+			if waitCtx.Err() != nil && ctx.Err() == nil {
+				return nil, fmt.Errorf("%w after %s", ErrLockTimeout, timeout)
+			}
+
+			return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		if locked {
+			log.Info().Dur("waited", time.Since(start)).Msg("migration lock acquired")
+
+			return conn, nil
+		}
+
+		if holder, err := currentLockHolder(waitCtx, conn); err != nil {
+			log.Warn().Err(err).Msg("migration lock is held by another session")
+		} else if holder.PID != lastHolder {
+			lastHolder = holder.PID
+			log.Info().
+				Int64("pid", holder.PID).
+				Str("application_name", holder.ApplicationName).
+				Str("client_addr", holder.ClientAddr).
+				Time("backend_start", holder.BackendStart).
+				Dur("timeout", timeout).
+				Msg("waiting for migration lock held by another session")
+		}
+
+		select {
+		case <-waitCtx.Done():
+			conn.Close()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, fmt.Errorf("%w after %s", ErrLockTimeout, timeout)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// releaseLock unlocks and returns the connection to the pool. If unlocking fails the connection
+// is discarded instead, which ends the session and with it the lock
+func releaseLock(ctx context.Context, conn *sql.Conn) error {
+	var unlocked bool
+	err := conn.QueryRowContext(ctx, `SELECT pg_advisory_unlock($1);`, lockID).Scan(&unlocked)
+	if err == nil && unlocked {
+		return conn.Close()
+	}
+
+	if err == nil {
+		err = errors.New("migration lock was not held")
+	}
+
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+
+	return fmt.Errorf("failed to release migration lock: %w", err)
+}
+
+func currentLockHolder(ctx context.Context, conn *sql.Conn) (lockHolder, error) {
+	query := `SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(host(a.client_addr), ''), a.backend_start
+		FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.classid = 0 AND l.objid = $1;`
+
+	var h lockHolder
+	if err := conn.QueryRowContext(ctx, query, lockID).Scan(&h.PID, &h.ApplicationName, &h.ClientAddr, &h.BackendStart); err != nil {
+		return lockHolder{}, fmt.Errorf("failed to look up migration lock holder: %w", err)
+	}
+
+	return h, nil
+}
@@ -0,0 +1,136 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+)
+
+type lockSuite struct {
+	suite.Suite
+	db       *sql.DB
+	mock     sqlmock.Sqlmock
+	migrator *Migrator
+}
+
+func TestLock(t *testing.T) {
+	suite.Run(t, new(lockSuite))
+}
+
+func (s *lockSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.db = db
+	s.mock = mock
+	s.migrator = &Migrator{db: db, dialect: DialectPostgres, lockTimeout: time.Second}
+}
+
+func (s *lockSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *lockSuite) expectTryLock(locked bool) *sqlmock.ExpectedQuery {
+	return s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_lock($1);`)).
+		WithArgs(lockID).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(locked))
+}
+
+func (s *lockSuite) expectHolder(pid int64) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`FROM pg_locks l JOIN pg_stat_activity a`)).
+		WithArgs(lockID).
+		WillReturnRows(sqlmock.NewRows([]string{"pid", "application_name", "client_addr", "backend_start"}).
+			AddRow(pid, "migration", "10.0.0.7", time.Now()))
+}
+
+func (s *lockSuite) expectUnlock() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1);`)).
+		WithArgs(lockID).
+		WillReturnRows(sqlmock.NewRows([]string{"unlocked"}).AddRow(true))
+}
+
+func (s *lockSuite) TestReleasedAfterRun() {
+	s.expectTryLock(true)
+	s.expectUnlock()
+
+	runErr := errors.New("migration failed")
+	err := s.migrator.withLock(s.T().Context(), func() error { return runErr })
+	s.ErrorIs(err, runErr)
+}
+
+// the second run waits while another session holds the lock and proceeds once it is free
+func (s *lockSuite) TestWaitsForHolder() {
+	s.expectTryLock(false)
+	s.expectHolder(42)
+	s.expectTryLock(false)
+	s.expectHolder(42)
+	s.expectTryLock(true)
+
+	conn, err := acquireLock(s.T().Context(), s.db, time.Second, time.Millisecond)
+	s.Require().NoError(err)
+
+	s.expectUnlock()
+	s.NoError(releaseLock(s.T().Context(), conn))
+}
+
+func (s *lockSuite) TestTimeout() {
+	s.expectTryLock(false)
+	s.expectHolder(42)
+	s.expectTryLock(false).WillDelayFor(time.Second)
+
+	_, err := acquireLock(s.T().Context(), s.db, 50*time.Millisecond, time.Millisecond)
+	s.ErrorIs(err, ErrLockTimeout)
+}
+
+// TestConcurrentRuns runs against a real Postgres when TEST_DATABASE_DSN is set
+func (s *lockSuite) TestConcurrentRuns() {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		s.T().Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	s.Require().NoError(err)
+	defer db.Close()
+
+	m := &Migrator{db: db, dialect: DialectPostgres, lockTimeout: 10 * time.Second}
+
+	var running, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			err := m.withLock(context.Background(), func() error {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(1500 * time.Millisecond)
+				running.Add(-1)
+
+				return nil
+			})
+			s.NoError(err)
+		})
+	}
+	wg.Wait()
+
+	s.Zero(overlaps.Load())
+
+	// a run gives up when the lock is held longer than its timeout
+	holder := &Migrator{db: db, dialect: DialectPostgres, lockTimeout: time.Second}
+	err = holder.withLock(context.Background(), func() error {
+		waiter := &Migrator{db: db, dialect: DialectPostgres, lockTimeout: 1500 * time.Millisecond}
+
+		return waiter.withLock(context.Background(), func() error { return nil })
+	})
+	s.ErrorIs(err, ErrLockTimeout)
+}
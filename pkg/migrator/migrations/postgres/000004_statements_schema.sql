@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts.account
+    ADD COLUMN closing_day INTEGER NOT NULL DEFAULT 1 CHECK (closing_day BETWEEN 1 AND 28),
+    ADD COLUMN due_day INTEGER NOT NULL DEFAULT 10 CHECK (due_day BETWEEN 1 AND 28);
+
+CREATE INDEX IF NOT EXISTS transaction_account_event_date_idx ON transactions.transaction (account_uuid, event_date);
+
+CREATE SCHEMA IF NOT EXISTS statements;
+
+CREATE TABLE IF NOT EXISTS statements.statement (
+    id SERIAL PRIMARY KEY,
+    uuid TEXT UNIQUE NOT NULL,
+    account_uuid TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    opening_balance DECIMAL(12,2) NOT NULL,
+    purchases DECIMAL(12,2) NOT NULL,
+    payments DECIMAL(12,2) NOT NULL,
+    closing_balance DECIMAL(12,2) NOT NULL,
+    minimum_payment DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_uuid, period_end)
+);
+
+CREATE TABLE IF NOT EXISTS statements.statement_transaction (
+    statement_uuid TEXT NOT NULL REFERENCES statements.statement (uuid) ON DELETE CASCADE,
+    transaction_uuid TEXT NOT NULL,
+    operation_type_id INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    event_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (statement_uuid, transaction_uuid)
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SCHEMA IF EXISTS statements CASCADE;
+DROP INDEX IF EXISTS transactions.transaction_account_event_date_idx;
+ALTER TABLE accounts.account DROP COLUMN IF EXISTS due_day, DROP COLUMN IF EXISTS closing_day;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO transactions.operation_types (operation_type_id, description, is_credit) values (5, 'FEE', false);
+INSERT INTO transactions.operation_types (operation_type_id, description, is_credit) values (6, 'INTEREST', false);
+-- operation_type_id is SERIAL, move its sequence past the fixed ids
+SELECT setval(pg_get_serial_sequence('transactions.operation_types', 'operation_type_id'), (SELECT MAX(operation_type_id) FROM transactions.operation_types));
+
+CREATE TABLE IF NOT EXISTS accounts.rate_plan (
+    id SERIAL PRIMARY KEY,
+    description TEXT UNIQUE NOT NULL,
+    apr DECIMAL(7,4) NOT NULL CHECK (apr >= 0),
+    compound_daily BOOLEAN NOT NULL,
+    grace_period_days INTEGER NOT NULL CHECK (grace_period_days >= 0),
+    late_fee DECIMAL(12,2) NOT NULL CHECK (late_fee >= 0)
+);
+
+INSERT INTO accounts.rate_plan (description, apr, compound_daily, grace_period_days, late_fee)
+    values ('STANDARD', 29.99, true, 3, 10.00);
+INSERT INTO accounts.rate_plan (description, apr, compound_daily, grace_period_days, late_fee)
+    values ('PREFERRED', 19.99, false, 5, 0);
+
+ALTER TABLE accounts.account
+    ADD COLUMN rate_plan_id INTEGER NOT NULL DEFAULT 1 REFERENCES accounts.rate_plan (id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE accounts.account DROP COLUMN IF EXISTS rate_plan_id;
+DROP TABLE IF EXISTS accounts.rate_plan;
+DELETE FROM transactions.operation_types WHERE description IN ('FEE', 'INTEREST');
+
+-- +goose StatementEnd
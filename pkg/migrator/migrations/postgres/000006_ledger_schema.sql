@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SCHEMA IF NOT EXISTS ledger;
+
+CREATE TABLE IF NOT EXISTS ledger.account (
+    id SERIAL PRIMARY KEY,
+    code TEXT UNIQUE NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('asset', 'revenue'))
+);
+
+INSERT INTO ledger.account (code, type) values ('CUSTOMER_RECEIVABLE', 'asset');
+INSERT INTO ledger.account (code, type) values ('CASH', 'asset');
+INSERT INTO ledger.account (code, type) values ('REVENUE', 'revenue');
+INSERT INTO ledger.account (code, type) values ('FEES', 'revenue');
+
+CREATE TABLE IF NOT EXISTS ledger.entry (
+    id SERIAL PRIMARY KEY,
+    transaction_uuid TEXT NOT NULL,
+    account_uuid TEXT NOT NULL,
+    ledger_account_id INTEGER NOT NULL REFERENCES ledger.account (id),
+    debit DECIMAL(10,2) NOT NULL CHECK (debit >= 0),
+    credit DECIMAL(10,2) NOT NULL CHECK (credit >= 0),
+    posted_at TIMESTAMP NOT NULL,
+    UNIQUE (transaction_uuid, ledger_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS entry_account_posted_at_idx ON ledger.entry (account_uuid, ledger_account_id, posted_at);
+
+-- post the transactions created so far: the customer receivable against cash, fees or revenue
+INSERT INTO ledger.entry (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at)
+    SELECT uuid, account_uuid, 1, GREATEST(-amount, 0), GREATEST(amount, 0), event_date FROM transactions.transaction;
+INSERT INTO ledger.entry (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at)
+    SELECT uuid, account_uuid, CASE operation_type_id WHEN 5 THEN 4 WHEN 6 THEN 3 ELSE 2 END,
+        GREATEST(amount, 0), GREATEST(-amount, 0), event_date FROM transactions.transaction;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SCHEMA IF EXISTS ledger CASCADE;
+
+-- +goose StatementEnd
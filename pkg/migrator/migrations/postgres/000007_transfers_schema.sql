@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO transactions.operation_types (operation_type_id, description, is_credit) values (7, 'TRANSFER_OUT', false);
+INSERT INTO transactions.operation_types (operation_type_id, description, is_credit) values (8, 'TRANSFER_IN', true);
+-- operation_type_id is SERIAL, move its sequence past the fixed ids
+SELECT setval(pg_get_serial_sequence('transactions.operation_types', 'operation_type_id'), (SELECT MAX(operation_type_id) FROM transactions.operation_types));
+
+CREATE SCHEMA IF NOT EXISTS transfers;
+
+CREATE TABLE IF NOT EXISTS transfers.transfer (
+    id SERIAL PRIMARY KEY,
+    uuid TEXT UNIQUE NOT NULL,
+    from_account_uuid TEXT NOT NULL,
+    to_account_uuid TEXT NOT NULL CHECK (to_account_uuid <> from_account_uuid),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    debit_transaction_uuid TEXT UNIQUE NOT NULL,
+    credit_transaction_uuid TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SCHEMA IF EXISTS transfers CASCADE;
+DELETE FROM transactions.operation_types WHERE description IN ('TRANSFER_OUT', 'TRANSFER_IN');
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SCHEMA IF NOT EXISTS outbox;
+
+CREATE TABLE IF NOT EXISTS outbox.event (
+    id BIGSERIAL PRIMARY KEY,
+    uuid TEXT UNIQUE NOT NULL,
+    type TEXT NOT NULL,
+    account_uuid TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+-- pending events are relayed in order per account
+CREATE INDEX IF NOT EXISTS event_pending_idx ON outbox.event (account_uuid, id) WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SCHEMA IF EXISTS outbox CASCADE;
+
+-- +goose StatementEnd
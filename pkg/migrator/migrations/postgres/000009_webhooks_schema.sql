@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SCHEMA IF NOT EXISTS webhooks;
+
+CREATE TABLE IF NOT EXISTS webhooks.subscription (
+    id SERIAL PRIMARY KEY,
+    uuid TEXT UNIQUE NOT NULL,
+    url TEXT NOT NULL,
+    -- comma separated
+    event_types TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    disabled_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhooks.delivery (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_uuid TEXT NOT NULL REFERENCES webhooks.subscription (uuid),
+    event_uuid TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    UNIQUE (webhook_uuid, event_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS delivery_due_idx ON webhooks.delivery (next_attempt_at) WHERE delivered_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks.delivery_attempt (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhooks.delivery (id),
+    webhook_uuid TEXT NOT NULL,
+    event_uuid TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    -- 0 when the endpoint did not respond
+    status_code INTEGER NOT NULL,
+    error TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    attempted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS delivery_attempt_webhook_idx ON webhooks.delivery_attempt (webhook_uuid, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP SCHEMA IF EXISTS webhooks CASCADE;
+
+-- +goose StatementEnd
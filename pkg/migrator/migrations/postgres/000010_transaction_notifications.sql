@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the event streams of accounts read the transactions created after the last one they sent
+CREATE INDEX IF NOT EXISTS transaction_account_idx ON transactions.transaction (account_uuid, id);
+
+-- listeners of the channel are notified with the account uuid once the transaction commits
+CREATE OR REPLACE FUNCTION transactions.notify_transaction_created() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('transaction_created', NEW.account_uuid);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_created_notify AFTER INSERT ON transactions.transaction
+    FOR EACH ROW EXECUTE FUNCTION transactions.notify_transaction_created();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS transaction_created_notify ON transactions.transaction;
+DROP FUNCTION IF EXISTS transactions.notify_transaction_created();
+DROP INDEX IF EXISTS transactions.transaction_account_idx;
+
+-- +goose StatementEnd
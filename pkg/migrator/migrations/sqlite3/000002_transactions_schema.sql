@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS operation_types (
+    operation_type_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    description TEXT UNIQUE NOT NULL,
+    is_credit BOOLEAN NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT UNIQUE NOT NULL,
+    account_uuid TEXT NOT NULL,
+    operation_type_id INTEGER NOT NULL,
+    amount DECIMAL(10,2) DEFAULT 0,
+    event_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS operation_types;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO operation_types (description, is_credit) values ('CASH_PURCHASE', false);
+INSERT INTO operation_types (description, is_credit) values ('INSTALLMENT_PURCHASE', false);
+INSERT INTO operation_types (description, is_credit) values ('WITHDRAWAL', false);
+INSERT INTO operation_types (description, is_credit) values ('PAYMENT', true);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM operation_types;
+
+-- +goose StatementEnd
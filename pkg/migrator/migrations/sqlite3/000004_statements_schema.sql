@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts ADD COLUMN closing_day INTEGER NOT NULL DEFAULT 1 CHECK (closing_day BETWEEN 1 AND 28);
+ALTER TABLE accounts ADD COLUMN due_day INTEGER NOT NULL DEFAULT 10 CHECK (due_day BETWEEN 1 AND 28);
+
+CREATE INDEX IF NOT EXISTS transactions_account_event_date_idx ON transactions (account_uuid, event_date);
+
+CREATE TABLE IF NOT EXISTS statements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT UNIQUE NOT NULL,
+    account_uuid TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    opening_balance DECIMAL(12,2) NOT NULL,
+    purchases DECIMAL(12,2) NOT NULL,
+    payments DECIMAL(12,2) NOT NULL,
+    closing_balance DECIMAL(12,2) NOT NULL,
+    minimum_payment DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_uuid, period_end)
+);
+
+CREATE TABLE IF NOT EXISTS statement_transactions (
+    statement_uuid TEXT NOT NULL REFERENCES statements (uuid) ON DELETE CASCADE,
+    transaction_uuid TEXT NOT NULL,
+    operation_type_id INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    event_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (statement_uuid, transaction_uuid)
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS statement_transactions;
+DROP TABLE IF EXISTS statements;
+DROP INDEX IF EXISTS transactions_account_event_date_idx;
+ALTER TABLE accounts DROP COLUMN due_day;
+ALTER TABLE accounts DROP COLUMN closing_day;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO operation_types (operation_type_id, description, is_credit) values (5, 'FEE', false);
+INSERT INTO operation_types (operation_type_id, description, is_credit) values (6, 'INTEREST', false);
+
+CREATE TABLE IF NOT EXISTS rate_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    description TEXT UNIQUE NOT NULL,
+    apr DECIMAL(7,4) NOT NULL CHECK (apr >= 0),
+    compound_daily BOOLEAN NOT NULL,
+    grace_period_days INTEGER NOT NULL CHECK (grace_period_days >= 0),
+    late_fee DECIMAL(12,2) NOT NULL CHECK (late_fee >= 0)
+);
+
+INSERT INTO rate_plans (description, apr, compound_daily, grace_period_days, late_fee)
+    values ('STANDARD', 29.99, true, 3, 10.00);
+INSERT INTO rate_plans (description, apr, compound_daily, grace_period_days, late_fee)
+    values ('PREFERRED', 19.99, false, 5, 0);
+
+-- without a foreign key, sqlite cannot add a referencing column with a default
+ALTER TABLE accounts ADD COLUMN rate_plan_id INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE accounts DROP COLUMN rate_plan_id;
+DROP TABLE IF EXISTS rate_plans;
+DELETE FROM operation_types WHERE description IN ('FEE', 'INTEREST');
+
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT UNIQUE NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('asset', 'revenue'))
+);
+
+INSERT INTO ledger_accounts (code, type) values ('CUSTOMER_RECEIVABLE', 'asset');
+INSERT INTO ledger_accounts (code, type) values ('CASH', 'asset');
+INSERT INTO ledger_accounts (code, type) values ('REVENUE', 'revenue');
+INSERT INTO ledger_accounts (code, type) values ('FEES', 'revenue');
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_uuid TEXT NOT NULL,
+    account_uuid TEXT NOT NULL,
+    ledger_account_id INTEGER NOT NULL REFERENCES ledger_accounts (id),
+    debit DECIMAL(10,2) NOT NULL CHECK (debit >= 0),
+    credit DECIMAL(10,2) NOT NULL CHECK (credit >= 0),
+    posted_at TIMESTAMP NOT NULL,
+    UNIQUE (transaction_uuid, ledger_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_posted_at_idx ON ledger_entries (account_uuid, ledger_account_id, posted_at);
+
+-- post the transactions created so far: the customer receivable against cash, fees or revenue
+INSERT INTO ledger_entries (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at)
+    SELECT uuid, account_uuid, 1, MAX(-amount, 0), MAX(amount, 0), event_date FROM transactions;
+INSERT INTO ledger_entries (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at)
+    SELECT uuid, account_uuid, CASE operation_type_id WHEN 5 THEN 4 WHEN 6 THEN 3 ELSE 2 END,
+        MAX(amount, 0), MAX(-amount, 0), event_date FROM transactions;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+
+-- +goose StatementEnd
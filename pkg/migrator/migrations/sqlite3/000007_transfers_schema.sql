@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO operation_types (operation_type_id, description, is_credit) values (7, 'TRANSFER_OUT', false);
+INSERT INTO operation_types (operation_type_id, description, is_credit) values (8, 'TRANSFER_IN', true);
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT UNIQUE NOT NULL,
+    from_account_uuid TEXT NOT NULL,
+    to_account_uuid TEXT NOT NULL CHECK (to_account_uuid <> from_account_uuid),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    debit_transaction_uuid TEXT UNIQUE NOT NULL,
+    credit_transaction_uuid TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS transfers;
+DELETE FROM operation_types WHERE description IN ('TRANSFER_OUT', 'TRANSFER_IN');
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the event streams of accounts read the transactions created after the last one they sent,
+-- SQLite has no notifications and the streams poll
+CREATE INDEX IF NOT EXISTS transactions_account_idx ON transactions (account_uuid, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS transactions_account_idx;
+
+-- +goose StatementEnd
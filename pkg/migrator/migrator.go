@@ -2,37 +2,381 @@ package migrator
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"go-pismo-challenge/pkg/database"
 	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
+	goosedb "github.com/pressly/goose/v3/database"
 )
 
-func Run(ctx context.Context, dsn string, migrationTable string, migrationFS fs.FS) error {
-	db, err := database.NewConnection(dsn, 1)
+// Dialects supported by the migrator, named like the goose dialects. The migrations of each
+// dialect live in migrations/<dialect> within the migration FS
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite3"
+)
+
+// Dialects lists every supported dialect, a migration has to be written for each of them
+var Dialects = []string{DialectPostgres, DialectSQLite}
+
+var (
+	ErrUnknownDialect = errors.New("unknown migration dialect")
+	ErrInvalidTable   = errors.New("invalid migration table name")
+)
+
+type Migrator struct {
+	db          *sql.DB
+	fsys        fs.FS
+	dir         string
+	dialect     string
+	table       table
+	store       goosedb.Store
+	lockTimeout time.Duration
+}
+
+type Options struct {
+	Dialect string
+	// Table the applied versions are tracked in, optionally qualified by a schema. Both are quoted
+	// in the SQL, so they are case sensitive and may hold any character
+	Table string
+	// LockTimeout is how long commands changing the schema wait for the migration lock. Only
+	// Postgres is locked, SQLite serialises writers itself
+	LockTimeout time.Duration
+}
+
+// MigrationStatus describes a migration file and whether it is applied to the database
+type MigrationStatus struct {
+	Version   int64
+	Source    string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// New configures goose to apply the migrations of opts.Dialect found in migrationFS to db
+func New(db *sql.DB, opts Options, migrationFS fs.FS) (*Migrator, error) {
+	dir, err := Dir(opts.Dialect)
 	if err != nil {
-		return fmt.Errorf("failed to establish db connection: %w", err)
+		return nil, err
 	}
 
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf(
+	table, err := parseTable(opts.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := goose.SetDialect(opts.Dialect); err != nil {
+		return nil, fmt.Errorf(
 			"unable to apply sql dialect to migrations package %q: %w",
-			"postgres",
+			opts.Dialect,
 			err,
 		)
 	}
 
-	goose.SetTableName(migrationTable)
+	// goose formats the table name into its queries as is
+	goose.SetTableName(table.quoted())
 	goose.SetLogger(&logger{})
 	goose.SetVerbose(true)
 
 	goose.SetBaseFS(migrationFS)
 
-	if err := goose.UpContext(ctx, db, "migrations"); err != nil {
+	store, err := goosedb.NewStore(goosedb.Dialect(opts.Dialect), table.quoted())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration store: %w", err)
+	}
+
+	return &Migrator{
+		db:          db,
+		fsys:        migrationFS,
+		dir:         dir,
+		dialect:     opts.Dialect,
+		table:       table,
+		store:       store,
+		lockTimeout: opts.LockTimeout,
+	}, nil
+}
+
+// Dir returns the directory of the migrations of dialect within the migration FS
+func Dir(dialect string) (string, error) {
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+		return path.Join("migrations", dialect), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDialect, dialect)
+	}
+}
+
+// ValidateTable returns ErrInvalidTable unless name is a table name, e.g. goose_db_version, or
+// one qualified by a schema, e.g. public.goose_db_version. The first "." separates the schema
+func ValidateTable(name string) error {
+	_, err := parseTable(name)
+
+	return err
+}
+
+// table is the version table, schema is empty when the name is not qualified
+type table struct {
+	schema string
+	name   string
+}
+
+func parseTable(name string) (table, error) {
+	var t table
+	if schema, rest, ok := strings.Cut(name, "."); ok {
+		t = table{schema: schema, name: rest}
+		if schema == "" {
+			return table{}, fmt.Errorf("%w: %q has an empty schema", ErrInvalidTable, name)
+		}
+	} else {
+		t = table{name: name}
+	}
+
+	switch {
+	case t.name == "":
+		return table{}, fmt.Errorf("%w: %q has an empty table name", ErrInvalidTable, name)
+	case strings.Contains(t.name, "."):
+		return table{}, fmt.Errorf("%w: %q has more than a schema and a table name", ErrInvalidTable, name)
+	case strings.ContainsRune(name, 0):
+		return table{}, fmt.Errorf("%w: %q contains a NUL character", ErrInvalidTable, name)
+	}
+
+	return t, nil
+}
+
+// quoted returns the table as a quoted identifier, qualified by its schema if any
+func (t table) quoted() string {
+	if t.schema == "" {
+		return quoteIdentifier(t.name)
+	}
+
+	return quoteIdentifier(t.schema) + "." + quoteIdentifier(t.name)
+}
+
+// quoteIdentifier quotes name as an SQL identifier, both Postgres and SQLite escape a double quote
+// by doubling it
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	err := m.withLock(ctx, func() error {
+		return goose.UpContext(ctx, m.db, m.dir)
+	})
+	if err != nil {
 		return fmt.Errorf("unable to apply database migrations: %w", err)
 	}
 
 	return nil
 }
+
+// UpTo applies pending migrations up to and including version
+func (m *Migrator) UpTo(ctx context.Context, version int64) error {
+	err := m.withLock(ctx, func() error {
+		return goose.UpToContext(ctx, m.db, m.dir, version)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to apply database migrations up to %d: %w", version, err)
+	}
+
+	return nil
+}
+
+// Down rolls back the current migration
+func (m *Migrator) Down(ctx context.Context) error {
+	err := m.withLock(ctx, func() error {
+		return goose.DownContext(ctx, m.db, m.dir)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to roll back database migration: %w", err)
+	}
+
+	return nil
+}
+
+// DownTo rolls back migrations until version is the current one
+func (m *Migrator) DownTo(ctx context.Context, version int64) error {
+	err := m.withLock(ctx, func() error {
+		return goose.DownToContext(ctx, m.db, m.dir, version)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to roll back database migrations to %d: %w", version, err)
+	}
+
+	return nil
+}
+
+// Redo rolls back the current migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) error {
+	err := m.withLock(ctx, func() error {
+		return goose.RedoContext(ctx, m.db, m.dir)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to redo database migration: %w", err)
+	}
+
+	return nil
+}
+
+// Version returns the current migration version of the database, 0 if none was applied
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return applied.current, nil
+}
+
+// Status returns every known migration file with its state in the database
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]MigrationStatus, 0, len(migrations))
+	for _, mig := range migrations {
+		st := MigrationStatus{Version: mig.Version, Source: path.Base(mig.Source)}
+		if applied.versions[mig.Version] {
+			st.Applied = true
+
+			r, err := m.store.GetMigration(ctx, m.db, mig.Version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get migration %d: %w", mig.Version, err)
+			}
+			st.AppliedAt = r.Timestamp
+		}
+		res = append(res, st)
+	}
+
+	return res, nil
+}
+
+func (m *Migrator) collect() (goose.Migrations, error) {
+	migrations, err := goose.CollectMigrations(m.dir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+type appliedVersions struct {
+	current  int64
+	versions map[int64]bool
+}
+
+// applied reads the version table without creating it. Like goose, the most recent row of each
+// version tells whether it is applied or was rolled back, and the current version is the most
+// recently applied one.
+func (m *Migrator) applied(ctx context.Context) (appliedVersions, error) {
+	res := appliedVersions{versions: map[int64]bool{}}
+
+	exists, err := m.tableExists(ctx)
+	if err != nil {
+		return res, err
+	}
+	if !exists {
+		return res, nil
+	}
+
+	rows, err := m.store.ListMigrations(ctx, m.db)
+	if err != nil {
+		return res, err
+	}
+
+	seen := map[int64]bool{}
+	currentFound := false
+	for _, r := range rows {
+		if seen[r.Version] {
+			continue
+		}
+		seen[r.Version] = true
+
+		if r.IsApplied && r.Version > 0 {
+			res.versions[r.Version] = true
+		}
+		if r.IsApplied && !currentFound {
+			res.current = r.Version
+			currentFound = true
+		}
+	}
+
+	return res, nil
+}
+
+// tableExists reports whether the version table was created yet. goose formats the table name
+// into a string literal to check it, which breaks on quoted names, so the catalogs are queried
+// with the name as a parameter
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+	var (
+		query string
+		args  []any
+	)
+	switch {
+	case m.dialect == DialectPostgres:
+		query = `SELECT EXISTS (SELECT 1 FROM pg_tables WHERE schemaname = COALESCE($1, current_schema()) AND tablename = $2);`
+		args = []any{sql.NullString{String: m.table.schema, Valid: m.table.schema != ""}, m.table.name}
+	case m.table.schema != "":
+		// the schema of an SQLite table is the database it is attached as
+		query = `SELECT EXISTS (SELECT 1 FROM ` + quoteIdentifier(m.table.schema) + `.sqlite_master WHERE type = 'table' AND name = ?);`
+		args = []any{m.table.name}
+	default:
+		query = `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?);`
+		args = []any{m.table.name}
+	}
+
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check if table exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+// sortedApplied returns the applied versions in descending order
+func (a appliedVersions) sortedApplied() []int64 {
+	versions := make([]int64, 0, len(a.versions))
+	for v := range a.versions {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	return versions
+}
+
+// LatestVersion returns the highest migration version of dialect found in migrationFS, i.e. the
+// newest schema this binary knows about
+func LatestVersion(migrationFS fs.FS, dialect string) (int64, error) {
+	dir, err := Dir(dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := fs.Glob(migrationFS, path.Join(dir, "*.sql"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var latest int64
+	for _, f := range files {
+		v, err := goose.NumericComponent(f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file %s: %w", f, err)
+		}
+		latest = max(latest, v)
+	}
+
+	return latest, nil
+}
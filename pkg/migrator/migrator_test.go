@@ -0,0 +1,369 @@
+package migrator
+
+import (
+	"fmt"
+	"go-pismo-challenge/pkg/database"
+	"go-pismo-challenge/pkg/model"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/suite"
+)
+
+const testMigration = `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE t%[1]d (id int);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE t%[1]d;
+-- +goose StatementEnd
+`
+
+type migratorSuite struct {
+	suite.Suite
+	db       sqlmock.Sqlmock
+	migrator *Migrator
+}
+
+func TestMigrator(t *testing.T) {
+	suite.Run(t, new(migratorSuite))
+}
+
+func (s *migratorSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	fsys := fstest.MapFS{}
+	for i, name := range []string{"000001_one.sql", "000002_two.sql", "000003_three.sql"} {
+		fsys["migrations/postgres/"+name] = &fstest.MapFile{Data: []byte(formatMigration(i + 1))}
+	}
+
+	s.migrator, err = New(db, Options{Dialect: DialectPostgres, Table: "public.goose_db_version"}, fsys)
+	s.Require().NoError(err)
+	s.db = mock
+}
+
+func (s *migratorSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+// expects the version table lookup, rows are (version, is_applied) ordered by id desc
+func (s *migratorSuite) expectVersions(rows ...[2]any) {
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pg_tables`)).
+		WithArgs("public", "goose_db_version").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	result := sqlmock.NewRows([]string{"version_id", "is_applied"})
+	for _, r := range rows {
+		result.AddRow(r[0], r[1])
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT version_id, is_applied from "public"."goose_db_version" ORDER BY id DESC`)).
+		WillReturnRows(result)
+}
+
+func (s *migratorSuite) TestVersionIgnoresRolledBack() {
+	// 2 was applied and rolled back afterwards
+	s.expectVersions([2]any{2, false}, [2]any{2, true}, [2]any{1, true}, [2]any{0, true})
+
+	v, err := s.migrator.Version(s.T().Context())
+	s.NoError(err)
+	s.Equal(int64(1), v)
+}
+
+func (s *migratorSuite) TestVersionWithoutTable() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pg_tables`)).
+		WithArgs("public", "goose_db_version").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	v, err := s.migrator.Version(s.T().Context())
+	s.NoError(err)
+	s.Equal(int64(0), v)
+}
+
+func (s *migratorSuite) TestPlanUp() {
+	s.expectVersions([2]any{1, true}, [2]any{0, true})
+
+	plan, err := s.migrator.PlanUp(s.T().Context(), goose.MaxVersion)
+	s.Require().NoError(err)
+	s.Require().Len(plan, 2)
+	s.Equal(int64(2), plan[0].Version)
+	s.Equal("000002_two.sql", plan[0].Source)
+	s.Equal(DirectionUp, plan[0].Direction)
+	s.Equal("CREATE TABLE t2 (id int);", plan[0].SQL)
+	s.Equal(int64(3), plan[1].Version)
+}
+
+func (s *migratorSuite) TestPlanUpTo() {
+	s.expectVersions([2]any{0, true})
+
+	plan, err := s.migrator.PlanUp(s.T().Context(), 2)
+	s.Require().NoError(err)
+	s.Len(plan, 2)
+}
+
+func (s *migratorSuite) TestPlanDownTo() {
+	s.expectVersions([2]any{3, true}, [2]any{2, true}, [2]any{1, true}, [2]any{0, true})
+
+	plan, err := s.migrator.PlanDownTo(s.T().Context(), 1)
+	s.Require().NoError(err)
+	s.Require().Len(plan, 2)
+	s.Equal(int64(3), plan[0].Version)
+	s.Equal("DROP TABLE t3;", plan[0].SQL)
+	s.Equal(int64(2), plan[1].Version)
+}
+
+func (s *migratorSuite) TestPlanRedo() {
+	s.expectVersions([2]any{2, true}, [2]any{1, true}, [2]any{0, true})
+
+	plan, err := s.migrator.PlanRedo(s.T().Context())
+	s.Require().NoError(err)
+	s.Require().Len(plan, 2)
+	s.Equal(DirectionDown, plan[0].Direction)
+	s.Equal("DROP TABLE t2;", plan[0].SQL)
+	s.Equal(DirectionUp, plan[1].Direction)
+	s.Equal("CREATE TABLE t2 (id int);", plan[1].SQL)
+}
+
+func (s *migratorSuite) TestCreate() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "000007_existing.sql"), []byte(formatMigration(7)), 0o600))
+
+	path, err := Create(dir, "Add Card Limits")
+	s.Require().NoError(err)
+	s.Equal(filepath.Join(dir, "000008_add_card_limits.sql"), path)
+
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Contains(string(content), "-- +goose Up")
+	s.Contains(string(content), "-- +goose Down")
+
+	_, err = Create(dir, "--")
+	s.ErrorIs(err, ErrInvalidMigrationName)
+}
+
+func formatMigration(version int) string {
+	return fmt.Sprintf(testMigration, version)
+}
+
+func (s *migratorSuite) TestLatestVersion() {
+	v, err := LatestVersion(FS, DialectPostgres)
+	s.Require().NoError(err)
+	s.GreaterOrEqual(v, int64(3))
+
+	// every dialect has to know the same versions
+	for _, d := range Dialects {
+		dv, err := LatestVersion(FS, d)
+		s.Require().NoError(err)
+		s.Equal(v, dv, d)
+	}
+
+	v, err = LatestVersion(s.migrator.fsys, DialectPostgres)
+	s.Require().NoError(err)
+	s.Equal(int64(3), v)
+}
+
+func (s *migratorSuite) TestSQLite() {
+	ctx := s.T().Context()
+	db, err := database.NewSQLiteConnection(ctx, database.SQLiteOptions{
+		Path:         filepath.Join(s.T().TempDir(), "pismo.db"),
+		MaxOpenConns: 1,
+	})
+	s.Require().NoError(err)
+	defer db.Close()
+
+	m, err := New(db, Options{Dialect: DialectSQLite, Table: "goose_db_version"}, FS)
+	s.Require().NoError(err)
+
+	v, err := m.Version(ctx)
+	s.Require().NoError(err)
+	s.Zero(v)
+
+	s.Require().NoError(m.Up(ctx))
+
+	latest, err := LatestVersion(FS, DialectSQLite)
+	s.Require().NoError(err)
+
+	status, err := m.Status(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(status, int(latest))
+	for _, st := range status {
+		s.True(st.Applied, st.Source)
+	}
+
+	s.Require().NoError(m.DownTo(ctx, 1))
+	v, err = m.Version(ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(1), v)
+}
+
+// the service posts accruals and transfers with the operation type ids of the model, the
+// migrations have to seed exactly those
+var seededOperationTypes = map[int]string{
+	model.OperationTypeFee:         "FEE",
+	model.OperationTypeInterest:    "INTEREST",
+	model.OperationTypeTransferOut: "TRANSFER_OUT",
+	model.OperationTypeTransferIn:  "TRANSFER_IN",
+}
+
+func (s *migratorSuite) TestSeededOperationTypes() {
+	for _, d := range Dialects {
+		dir, err := Dir(d)
+		s.Require().NoError(err)
+
+		files, err := fs.Glob(FS, path.Join(dir, "*.sql"))
+		s.Require().NoError(err)
+
+		var all strings.Builder
+		for _, f := range files {
+			content, err := fs.ReadFile(FS, f)
+			s.Require().NoError(err)
+			all.Write(content)
+		}
+
+		for id, description := range seededOperationTypes {
+			s.Contains(all.String(), fmt.Sprintf("values (%d, '%s',", id, description), d)
+		}
+	}
+
+	ctx := s.T().Context()
+	db, err := database.NewSQLiteConnection(ctx, database.SQLiteOptions{
+		Path:         filepath.Join(s.T().TempDir(), "pismo.db"),
+		MaxOpenConns: 1,
+	})
+	s.Require().NoError(err)
+	defer db.Close()
+
+	m, err := New(db, Options{Dialect: DialectSQLite, Table: "goose_db_version"}, FS)
+	s.Require().NoError(err)
+	s.Require().NoError(m.Up(ctx))
+
+	for id, description := range seededOperationTypes {
+		var got string
+		err := db.QueryRowContext(ctx,
+			`SELECT description FROM operation_types WHERE operation_type_id = ?`, id,
+		).Scan(&got)
+		s.Require().NoError(err, description)
+		s.Equal(description, got)
+	}
+}
+
+func (s *migratorSuite) TestUnknownDialect() {
+	_, err := New(nil, Options{Dialect: "mysql", Table: "goose_db_version"}, FS)
+	s.ErrorIs(err, ErrUnknownDialect)
+}
+
+func (s *migratorSuite) TestValidateTable() {
+	tests := []struct {
+		name   string
+		quoted string
+	}{
+		{"goose_db_version", `"goose_db_version"`},
+		{"public.goose_db_version", `"public"."goose_db_version"`},
+		{"Migrations.Versions", `"Migrations"."Versions"`},
+		{"db versions", `"db versions"`},
+		{"db-versions", `"db-versions"`},
+		{"1versions", `"1versions"`},
+		{`bad"name`, `"bad""name"`},
+		{`"versions"`, `"""versions"""`},
+		{"goose_db_version; DROP TABLE accounts.accounts", `"goose_db_version; DROP TABLE accounts"."accounts"`},
+		{"pg_tables' OR '1'='1", `"pg_tables' OR '1'='1"`},
+	}
+	for _, tt := range tests {
+		s.NoError(ValidateTable(tt.name), tt.name)
+
+		table, err := parseTable(tt.name)
+		s.Require().NoError(err, tt.name)
+		s.Equal(tt.quoted, table.quoted(), tt.name)
+	}
+
+	for _, name := range []string{
+		"",
+		"public.",
+		".versions",
+		"a.b.c",
+		"versions\x00",
+	} {
+		s.ErrorIs(ValidateTable(name), ErrInvalidTable, name)
+	}
+
+	_, err := New(nil, Options{Dialect: DialectPostgres, Table: "public."}, FS)
+	s.ErrorIs(err, ErrInvalidTable)
+}
+
+// names that need quoting are quoted in the queries of goose, and looked up as they are
+func (s *migratorSuite) TestQuotedTable() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	defer db.Close()
+
+	m, err := New(db, Options{Dialect: DialectPostgres, Table: `Pismo."Schema Versions"`}, s.migrator.fsys)
+	s.Require().NoError(err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pg_tables`)).
+		WithArgs("Pismo", `"Schema Versions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version_id, is_applied from "Pismo"."""Schema Versions""" ORDER BY id DESC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}).AddRow(2, true).AddRow(0, true))
+
+	v, err := m.Version(s.T().Context())
+	s.Require().NoError(err)
+	s.Equal(int64(2), v)
+
+	// without a schema the table is looked up in the current one
+	m, err = New(db, Options{Dialect: DialectPostgres, Table: "Versions"}, s.migrator.fsys)
+	s.Require().NoError(err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM pg_tables`)).
+		WithArgs(nil, "Versions").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	v, err = m.Version(s.T().Context())
+	s.Require().NoError(err)
+	s.Zero(v)
+	s.NoError(mock.ExpectationsWereMet())
+}
+
+// the version table is created, tracked and found under names that need quoting
+func (s *migratorSuite) TestSQLiteQuotedTable() {
+	ctx := s.T().Context()
+	db, err := database.NewSQLiteConnection(ctx, database.SQLiteOptions{
+		Path:         filepath.Join(s.T().TempDir(), "pismo.db"),
+		MaxOpenConns: 1,
+	})
+	s.Require().NoError(err)
+	defer db.Close()
+
+	for _, name := range []string{"Schema Versions", "main.schema-versions", `db"versions`} {
+		m, err := New(db, Options{Dialect: DialectSQLite, Table: name}, FS)
+		s.Require().NoError(err, name)
+
+		s.Require().NoError(m.UpTo(ctx, 2), name)
+		v, err := m.Version(ctx)
+		s.Require().NoError(err, name)
+		s.Equal(int64(2), v, name)
+
+		s.Require().NoError(m.DownTo(ctx, 0), name)
+	}
+
+	var tables []string
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE '%versions' ORDER BY name;`)
+	s.Require().NoError(err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		s.Require().NoError(rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	s.Require().NoError(rows.Err())
+	s.Equal([]string{"Schema Versions", `db"versions`, "schema-versions"}, tables)
+}
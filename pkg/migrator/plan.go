@@ -0,0 +1,164 @@
+package migrator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// PlannedMigration is a migration that a command would run, used for dry runs
+type PlannedMigration struct {
+	Version   int64
+	Source    string
+	Direction string
+	SQL       string
+}
+
+// PlanUp returns the pending migrations that UpTo(target) would apply, in order.
+// Use goose.MaxVersion as target for Up.
+func (m *Migrator) PlanUp(ctx context.Context, target int64) ([]PlannedMigration, error) {
+	migrations, err := m.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedMigration
+	for _, mig := range migrations {
+		if mig.Version <= applied.current || mig.Version > target {
+			continue
+		}
+
+		p, err := m.plan(mig, DirectionUp)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, p)
+	}
+
+	return plan, nil
+}
+
+// PlanDown returns the migration that Down would roll back
+func (m *Migrator) PlanDown(ctx context.Context) ([]PlannedMigration, error) {
+	return m.planDown(ctx, -1, 1)
+}
+
+// PlanDownTo returns the migrations that DownTo(target) would roll back, in order
+func (m *Migrator) PlanDownTo(ctx context.Context, target int64) ([]PlannedMigration, error) {
+	return m.planDown(ctx, target, -1)
+}
+
+// PlanRedo returns the current migration rolled back and applied again
+func (m *Migrator) PlanRedo(ctx context.Context) ([]PlannedMigration, error) {
+	down, err := m.PlanDown(ctx)
+	if err != nil || len(down) == 0 {
+		return down, err
+	}
+
+	up := down[0]
+	up.Direction = DirectionUp
+	up.SQL, err = readSection(m.fsys, path.Join(m.dir, up.Source), DirectionUp)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(down, up), nil
+}
+
+// planDown returns applied migrations above target in descending order, at most limit of them
+// when limit > 0
+func (m *Migrator) planDown(ctx context.Context, target int64, limit int) ([]PlannedMigration, error) {
+	migrations, err := m.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedMigration
+	for _, v := range applied.sortedApplied() {
+		if v <= target || (limit > 0 && len(plan) == limit) {
+			break
+		}
+
+		mig, err := migrations.Current(v)
+		if err != nil {
+			return nil, fmt.Errorf("applied migration %d not found in migration files: %w", v, err)
+		}
+
+		p, err := m.plan(mig, DirectionDown)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, p)
+	}
+
+	return plan, nil
+}
+
+func (m *Migrator) plan(mig *goose.Migration, direction string) (PlannedMigration, error) {
+	sql, err := readSection(m.fsys, mig.Source, direction)
+	if err != nil {
+		return PlannedMigration{}, err
+	}
+
+	return PlannedMigration{
+		Version:   mig.Version,
+		Source:    path.Base(mig.Source),
+		Direction: direction,
+		SQL:       sql,
+	}, nil
+}
+
+// readSection returns the statements of the "-- +goose Up" or "-- +goose Down" section of a
+// SQL migration file, without goose annotations
+func readSection(fsys fs.FS, source string, direction string) (string, error) {
+	content, err := fs.ReadFile(fsys, source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migration %s: %w", source, err)
+	}
+
+	var out strings.Builder
+	inSection := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if annotation, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose "); ok {
+			switch strings.ToLower(strings.TrimSpace(annotation)) {
+			case DirectionUp, DirectionDown:
+				inSection = strings.EqualFold(strings.TrimSpace(annotation), direction)
+			}
+
+			continue
+		}
+
+		if inSection {
+			out.WriteString(line)
+			out.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to parse migration %s: %w", source, err)
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
@@ -2,14 +2,36 @@ package model
 
 import (
 	"go-pismo-challenge/pkg/util"
+	"go-pismo-challenge/pkg/validate"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
+// Billing cycle and rate plan of accounts created without one, they match the column defaults
+const (
+	DefaultClosingDay = 1
+	DefaultDueDay     = 10
+	DefaultRatePlanID = 1
+)
+
+// Account statuses, a blocked account can neither send nor receive transfers
+const (
+	AccountStatusActive  = "active"
+	AccountStatusBlocked = "blocked"
+)
+
 type AccountRequest struct {
-	DocumentNumber string `json:"document_number" example:"some-string" format:"string"`
-	IdempotencyKey string `json:"idempotency_key" example:"some-string" format:"string"`
+	DocumentNumber string `json:"document_number" example:"some-string" format:"string" validate:"required"`
+	IdempotencyKey string `json:"idempotency_key" example:"some-string" format:"string" validate:"required"`
+	// ClosingDay and DueDay are the days of the month the billing cycle closes and its
+	// statement is due, capped at 28 so that every month has them
+	ClosingDay int `json:"closing_day,omitempty" example:"1" format:"int64" validate:"omitempty,min=1,max=28"`
+	DueDay     int `json:"due_day,omitempty" example:"10" format:"int64" validate:"omitempty,min=1,max=28"`
+	// RatePlanID is the rate plan interest and late fees accrue by
+	RatePlanID int `json:"rate_plan_id,omitempty" example:"1" format:"int64" validate:"omitempty,min=1"`
+	// CreditLimit is the most the account may owe after a transfer out of it, 0 means no limit
+	CreditLimit float64 `json:"credit_limit,omitempty" example:"1000" format:"float64" validate:"omitempty,min=0,max=9999999999.99,decimals=2"`
 }
 
 type AccountResponse struct {
@@ -19,23 +41,14 @@ type AccountResponse struct {
 type Account struct {
 	UUID           uuid.UUID `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
 	DocumentNumber string    `json:"document_number" example:"some-string" format:"string"`
+	ClosingDay     int       `json:"closing_day" example:"1" format:"int64"`
+	DueDay         int       `json:"due_day" example:"10" format:"int64"`
+	RatePlanID     int       `json:"rate_plan_id" example:"1" format:"int64"`
+	Status         string    `json:"status" example:"active" format:"string"`
+	CreditLimit    float64   `json:"credit_limit" example:"1000" format:"float64"`
 	CreatedAt      time.Time `json:"created_at" example:"2025-10-01T06:22:46.931755Z" format:"time"`
 }
 
 func (a AccountRequest) Validate() []util.FieldError {
-	err := make([]util.FieldError, 0)
-	if a.IdempotencyKey == "" {
-		err = append(err, util.FieldError{
-			Field:   "idempotency_key",
-			Message: "field is required",
-		})
-	}
-	if a.DocumentNumber == "" {
-		err = append(err, util.FieldError{
-			Field:   "document_number",
-			Message: "field is required",
-		})
-	}
-
-	return err
+	return validate.Struct(a)
 }
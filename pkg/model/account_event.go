@@ -0,0 +1,24 @@
+package model
+
+import "github.com/gofrs/uuid"
+
+// types of the events of the activity stream of an account
+const (
+	AccountEventTransaction = "transaction"
+	AccountEventBalance     = "balance"
+)
+
+// AccountEvent is an event of the activity stream of an account, Data is a Transaction or an
+// AccountBalance. ID is the id of the last transaction of the account sent on the stream, which a
+// client resumes the stream after
+type AccountEvent struct {
+	ID   int64
+	Type string
+	Data any
+}
+
+// AccountBalance is the amount the account owes, the balance of its receivable in the ledger
+type AccountBalance struct {
+	AccountUUID uuid.UUID `json:"account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	Balance     float64   `json:"balance" example:"60.5" format:"float64"`
+}
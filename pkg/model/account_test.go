@@ -3,6 +3,8 @@ package model
 import (
 	"go-pismo-challenge/pkg/util"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAccountRequest_Validate tests the Validate method of AccountRequest
@@ -27,7 +29,7 @@ func TestAccountRequest_Validate(t *testing.T) {
 				IdempotencyKey: "abcde-12345",
 			},
 			wantErr: []util.FieldError{
-				{Field: "document_number", Message: "field is required"},
+				{Field: "document_number", Code: "required", Message: "field is required"},
 			},
 		},
 		{
@@ -37,7 +39,7 @@ func TestAccountRequest_Validate(t *testing.T) {
 				IdempotencyKey: "",
 			},
 			wantErr: []util.FieldError{
-				{Field: "idempotency_key", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
 			},
 		},
 		{
@@ -47,25 +49,49 @@ func TestAccountRequest_Validate(t *testing.T) {
 				IdempotencyKey: "",
 			},
 			wantErr: []util.FieldError{
-				{Field: "idempotency_key", Message: "field is required"},
-				{Field: "document_number", Message: "field is required"},
+				{Field: "document_number", Code: "required", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
+			},
+		},
+		{
+			name: "Valid billing cycle",
+			accountReq: AccountRequest{
+				DocumentNumber: "12345",
+				IdempotencyKey: "abcde-12345",
+				ClosingDay:     28,
+				DueDay:         1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Billing cycle days out of range",
+			accountReq: AccountRequest{
+				DocumentNumber: "12345",
+				IdempotencyKey: "abcde-12345",
+				ClosingDay:     29,
+				DueDay:         -1,
+			},
+			wantErr: []util.FieldError{
+				{Field: "closing_day", Code: "too_large", Message: "must be at most 28: 29"},
+				{Field: "due_day", Code: "too_small", Message: "must be at least 1: -1"},
+			},
+		},
+		{
+			name: "Invalid credit limit",
+			accountReq: AccountRequest{
+				DocumentNumber: "12345",
+				IdempotencyKey: "abcde-12345",
+				CreditLimit:    100.005,
+			},
+			wantErr: []util.FieldError{
+				{Field: "credit_limit", Code: "too_many_decimals", Message: "must have at most 2 decimal places: 100.005"},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotErr := tt.accountReq.Validate()
-			if len(gotErr) != len(tt.wantErr) {
-				t.Errorf("Expected %d errors, got %d", len(tt.wantErr), len(gotErr))
-
-				return
-			}
-			for i, err := range gotErr {
-				if err.Field != tt.wantErr[i].Field || err.Message != tt.wantErr[i].Message {
-					t.Errorf("Expected error %v, got %v", tt.wantErr[i], err)
-				}
-			}
+			assert.Equal(t, tt.wantErr, tt.accountReq.Validate())
 		})
 	}
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Accrual is interest or a late fee accrued on the unpaid balance of a statement. It is posted
+// as a transaction of its operation type with the idempotency key, so it is posted only once
+type Accrual struct {
+	Date            time.Time `json:"date" example:"2025-10-16T00:00:00Z" format:"time"`
+	OperationTypeID int       `json:"operation_type_id" example:"6" format:"int64"`
+	Amount          float64   `json:"amount" example:"0.58" format:"float64"`
+	// Balance is the unpaid balance the interest of the day accrued on, zero for late fees
+	Balance        float64 `json:"balance,omitempty" example:"706.5" format:"float64"`
+	IdempotencyKey string  `json:"idempotency_key" example:"interest:550e8400-e29b-41d4-a716-446655440000:2025-10-16" format:"string"`
+	Posted         bool    `json:"posted" example:"false"`
+}
+
+// AccrualPreview lists the accruals of the latest statement of an account as of a time, the
+// statement uuid is null and the list empty for accounts without statements
+type AccrualPreview struct {
+	AccountUUID   uuid.UUID  `json:"account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	StatementUUID *uuid.UUID `json:"statement_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	RatePlan      RatePlan   `json:"rate_plan"`
+	AsOf          time.Time  `json:"as_of" example:"2025-10-20T06:22:46.931755Z" format:"time"`
+	Accruals      []Accrual  `json:"accruals"`
+}
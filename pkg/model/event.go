@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// types of the events published to downstream systems
+const (
+	EventAccountCreated     = "account.created"
+	EventTransactionCreated = "transaction.created"
+)
+
+// Event tells downstream systems that an account or a transaction was created, Data holds the
+// created entity. Events are delivered at least once, consumers drop the ones they have seen by
+// UUID, and the events of an account are delivered in the order they were created
+type Event struct {
+	UUID        uuid.UUID       `json:"uuid"`
+	Type        string          `json:"type"`
+	AccountUUID uuid.UUID       `json:"account_uuid"`
+	Data        json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// OutboxEvent is an event kept in the outbox until it is published, ID orders the events as
+// they were added
+type OutboxEvent struct {
+	Event
+	ID            int64
+	Attempts      int
+	NextAttemptAt time.Time
+}
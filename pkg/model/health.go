@@ -0,0 +1,19 @@
+package model
+
+const (
+	HealthStatusPass = "pass"
+	HealthStatusFail = "fail"
+)
+
+type HealthResponse struct {
+	Status string              `json:"status" example:"pass" enums:"pass,fail"`
+	Checks []HealthCheckResult `json:"checks,omitempty"`
+}
+
+type HealthCheckResult struct {
+	Name      string  `json:"name" example:"database"`
+	Status    string  `json:"status" example:"pass" enums:"pass,fail"`
+	LatencyMS float64 `json:"latency_ms" example:"1.25" format:"float64"`
+	Error     string  `json:"error,omitempty" example:"context deadline exceeded"`
+	Details   any     `json:"details,omitempty"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Ledger accounts seeded by the migrations. Transactions debit or credit the receivable of
+// the customer against cash, or against revenue and fees for interest and late fees
+const (
+	LedgerAccountReceivable = 1
+	LedgerAccountCash       = 2
+	LedgerAccountRevenue    = 3
+	LedgerAccountFees       = 4
+)
+
+// Ledger account types, asset accounts have a debit balance and revenue accounts a credit one
+const (
+	LedgerAccountTypeAsset   = "asset"
+	LedgerAccountTypeRevenue = "revenue"
+)
+
+// JournalEntry debits or credits a ledger account for a transaction of an account. Every
+// transaction is posted as entries whose debits equal their credits
+type JournalEntry struct {
+	TransactionUUID uuid.UUID `json:"transaction_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	AccountUUID     uuid.UUID `json:"account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	LedgerAccountID int       `json:"ledger_account_id" example:"1" format:"int64"`
+	Debit           float64   `json:"debit" example:"60.5" format:"float64"`
+	Credit          float64   `json:"credit" example:"0" format:"float64"`
+	PostedAt        time.Time `json:"posted_at" example:"2025-10-01T06:22:46.931755Z" format:"time"`
+}
+
+// TrialBalanceAccount totals the entries of a ledger account. Balance is the difference of
+// debits and credits on the side the account type increases with
+type TrialBalanceAccount struct {
+	LedgerAccountID int     `json:"ledger_account_id" example:"1" format:"int64"`
+	Code            string  `json:"code" example:"CUSTOMER_RECEIVABLE" format:"string"`
+	Type            string  `json:"type" example:"asset" format:"string"`
+	Debits          float64 `json:"debits" example:"160.5" format:"float64"`
+	Credits         float64 `json:"credits" example:"100" format:"float64"`
+	Balance         float64 `json:"balance" example:"60.5" format:"float64"`
+}
+
+// TrialBalance lists every ledger account, it is balanced when the debits of all entries equal
+// their credits
+type TrialBalance struct {
+	Accounts     []TrialBalanceAccount `json:"accounts"`
+	TotalDebits  float64               `json:"total_debits" example:"260.5" format:"float64"`
+	TotalCredits float64               `json:"total_credits" example:"260.5" format:"float64"`
+	Balanced     bool                  `json:"balanced" example:"true"`
+}
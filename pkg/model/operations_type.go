@@ -1,6 +1,16 @@
 package model
 
+// Operation types of the transactions posted by the service, seeded by the migrations with these
+// fixed ids
+const (
+	OperationTypeFee         = 5
+	OperationTypeInterest    = 6
+	OperationTypeTransferOut = 7
+	OperationTypeTransferIn  = 8
+)
+
 type OperationType struct {
-	OperationTypeID int  `json:"operation_type_id"`
-	IsCredit        bool `json:"is_credit"`
+	OperationTypeID int    `json:"operation_type_id"`
+	Description     string `json:"description"`
+	IsCredit        bool   `json:"is_credit"`
 }
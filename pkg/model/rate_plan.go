@@ -0,0 +1,14 @@
+package model
+
+// RatePlan is how interest and late fees accrue on the unpaid balance of a statement. APR is the
+// annual percentage rate, accrued daily and compounded with the interest of the previous days
+// if CompoundDaily. Payments count as on time until GracePeriodDays after the due date, later
+// the LateFee is charged unless the minimum payment was made
+type RatePlan struct {
+	ID              int     `json:"id" example:"1" format:"int64"`
+	Description     string  `json:"description" example:"STANDARD" format:"string"`
+	APR             float64 `json:"apr" example:"29.99" format:"float64"`
+	CompoundDaily   bool    `json:"compound_daily" example:"true"`
+	GracePeriodDays int     `json:"grace_period_days" example:"3" format:"int64"`
+	LateFee         float64 `json:"late_fee" example:"10" format:"float64"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Statement is the closed billing cycle of an account, covering the transactions with an event
+// date in [PeriodStart, PeriodEnd). Purchases and payments are the totals of the debit and
+// credit transactions, so the closing balance, the amount owed, is
+// OpeningBalance + Purchases - Payments
+type Statement struct {
+	UUID           uuid.UUID     `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	AccountUUID    uuid.UUID     `json:"account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	PeriodStart    time.Time     `json:"period_start" example:"2025-09-01T00:00:00Z" format:"time"`
+	PeriodEnd      time.Time     `json:"period_end" example:"2025-10-01T00:00:00Z" format:"time"`
+	DueDate        time.Time     `json:"due_date" example:"2025-10-10T00:00:00Z" format:"time"`
+	OpeningBalance float64       `json:"opening_balance" example:"100" format:"float64"`
+	Purchases      float64       `json:"purchases" example:"60.5" format:"float64"`
+	Payments       float64       `json:"payments" example:"100" format:"float64"`
+	ClosingBalance float64       `json:"closing_balance" example:"60.5" format:"float64"`
+	MinimumPayment float64       `json:"minimum_payment" example:"20" format:"float64"`
+	CreatedAt      time.Time     `json:"created_at" example:"2025-10-01T00:05:00Z" format:"time"`
+	Transactions   []Transaction `json:"transactions,omitempty"`
+}
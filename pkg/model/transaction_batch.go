@@ -0,0 +1,39 @@
+package model
+
+import "go-pismo-challenge/pkg/util"
+
+// modes of a transaction batch, an all-or-nothing batch stores none of its transactions if any
+// is rejected while a best-effort batch stores the others
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeBestEffort   = "best_effort"
+)
+
+// statuses of the transactions of a batch
+const (
+	BatchItemCreated   = "created"
+	BatchItemDuplicate = "duplicate"
+	BatchItemRejected  = "rejected"
+	// BatchItemSkipped is a valid transaction not stored as its all-or-nothing batch was rejected
+	BatchItemSkipped = "skipped"
+)
+
+// TransactionBatchItem is the result of a transaction of a batch, Index is its position in the
+// batch. UUID is set unless the transaction was rejected, and Errors lists why it was
+type TransactionBatchItem struct {
+	Index  int               `json:"index" example:"0" format:"int64"`
+	Status string            `json:"status" example:"created" enums:"created,duplicate,rejected,skipped"`
+	UUID   string            `json:"uuid,omitempty" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	Errors []util.FieldError `json:"errors,omitempty"`
+}
+
+// TransactionBatchResult holds the result of every transaction of a batch, in the order sent,
+// and how many have each status
+type TransactionBatchResult struct {
+	Mode       string                 `json:"mode" example:"best_effort" enums:"all_or_nothing,best_effort"`
+	Created    int                    `json:"created" example:"2" format:"int64"`
+	Duplicates int                    `json:"duplicates" example:"0" format:"int64"`
+	Rejected   int                    `json:"rejected" example:"1" format:"int64"`
+	Skipped    int                    `json:"skipped" example:"0" format:"int64"`
+	Items      []TransactionBatchItem `json:"items"`
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"go-pismo-challenge/pkg/util"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestTransactionRequest_Validate tests the Validate method of TransactionRequest
@@ -31,7 +33,7 @@ func TestTransactionRequest_Validate(t *testing.T) {
 				IdempotencyKey:  "",
 			},
 			wantErr: []util.FieldError{
-				{Field: "idempotency_key", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
 			},
 		},
 		{
@@ -43,7 +45,7 @@ func TestTransactionRequest_Validate(t *testing.T) {
 				IdempotencyKey:  "some-string",
 			},
 			wantErr: []util.FieldError{
-				{Field: "account_uuid", Message: "invalid UUID format"},
+				{Field: "account_uuid", Code: "invalid_uuid", Message: "must be a valid uuid: 'invalid-uuid-format'"},
 			},
 		},
 		{
@@ -55,7 +57,7 @@ func TestTransactionRequest_Validate(t *testing.T) {
 				IdempotencyKey:  "some-string",
 			},
 			wantErr: []util.FieldError{
-				{Field: "operation_type_id", Message: "must be a positive integer: -1"},
+				{Field: "operation_type_id", Code: "too_small", Message: "must be at least 1: -1"},
 			},
 		},
 		{
@@ -67,7 +69,40 @@ func TestTransactionRequest_Validate(t *testing.T) {
 				IdempotencyKey:  "some-string",
 			},
 			wantErr: []util.FieldError{
-				{Field: "amount", Message: "must be a non-negative value: -50.00"},
+				{Field: "amount", Code: "too_small", Message: "must be at least 0: -50"},
+			},
+		},
+		{
+			name: "Amount above DECIMAL(10,2)",
+			transactionReq: TransactionRequest{
+				AccountUUID:     "550e8400-e29b-41d4-a716-446655440000",
+				OperationTypeID: 1,
+				Amount:          100000000,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "amount", Code: "too_large", Message: "must be at most 99999999.99: 100000000"},
+			},
+		},
+		{
+			name: "Amount with more than two decimal places",
+			transactionReq: TransactionRequest{
+				AccountUUID:     "550e8400-e29b-41d4-a716-446655440000",
+				OperationTypeID: 1,
+				Amount:          10.125,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "amount", Code: "too_many_decimals", Message: "must have at most 2 decimal places: 10.125"},
+			},
+		},
+		{
+			name:           "All fields missing",
+			transactionReq: TransactionRequest{},
+			wantErr: []util.FieldError{
+				{Field: "account_uuid", Code: "required", Message: "field is required"},
+				{Field: "operation_type_id", Code: "required", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
 			},
 		},
 	}
@@ -75,17 +110,7 @@ func TestTransactionRequest_Validate(t *testing.T) {
 	// loop over the test cases
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotErr := tt.transactionReq.Validate()
-			if len(gotErr) != len(tt.wantErr) {
-				t.Errorf("Expected %d errors, got %d", len(tt.wantErr), len(gotErr))
-
-				return
-			}
-			for i, err := range gotErr {
-				if err.Field != tt.wantErr[i].Field {
-					t.Errorf("Expected error %v, got %v", tt.wantErr[i], err)
-				}
-			}
+			assert.Equal(t, tt.wantErr, tt.transactionReq.Validate())
 		})
 	}
 }
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"go-pismo-challenge/pkg/util"
+	"go-pismo-challenge/pkg/validate"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type TransferRequest struct {
+	FromAccountUUID string  `json:"from_account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid" validate:"required,uuid"`
+	ToAccountUUID   string  `json:"to_account_uuid" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8" format:"uuid" validate:"required,uuid"`
+	Amount          float64 `json:"amount" example:"25.5" format:"float64" validate:"required,min=0,max=99999999.99,decimals=2"`
+	IdempotencyKey  string  `json:"idempotency_key" example:"some-string" format:"string" validate:"required"`
+}
+
+// Validate checks the fields of the request, and that the amount moves between two accounts
+func (t TransferRequest) Validate() []util.FieldError {
+	errs := validate.Struct(t)
+
+	from := uuid.FromStringOrNil(t.FromAccountUUID)
+	if from != uuid.Nil && from == uuid.FromStringOrNil(t.ToAccountUUID) {
+		errs = append(errs, util.FieldError{
+			Field:   "to_account_uuid",
+			Code:    validate.CodeNotAllowed,
+			Message: "must differ from from_account_uuid",
+		})
+	}
+
+	return errs
+}
+
+// Transfer moves an amount between two accounts as a pair of transactions created together, a
+// TRANSFER_OUT debiting the from account and a TRANSFER_IN crediting the to account
+type Transfer struct {
+	UUID                  uuid.UUID `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	FromAccountUUID       uuid.UUID `json:"from_account_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	ToAccountUUID         uuid.UUID `json:"to_account_uuid" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8" format:"uuid"`
+	Amount                float64   `json:"amount" example:"25.5" format:"float64"`
+	DebitTransactionUUID  uuid.UUID `json:"debit_transaction_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	CreditTransactionUUID uuid.UUID `json:"credit_transaction_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	CreatedAt             time.Time `json:"created_at" example:"2025-10-01T06:22:46.931755Z" format:"time"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"go-pismo-challenge/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTransferRequest_Validate tests the Validate method of TransferRequest
+func TestTransferRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		transferReq TransferRequest
+		wantErr     []util.FieldError // expected validation errors
+	}{
+		{
+			name: "Valid transfer request",
+			transferReq: TransferRequest{
+				FromAccountUUID: "550e8400-e29b-41d4-a716-446655440000",
+				ToAccountUUID:   "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				Amount:          25.5,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: nil, // no errors expected
+		},
+		{
+			name: "Same account in another case",
+			transferReq: TransferRequest{
+				FromAccountUUID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				ToAccountUUID:   "6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+				Amount:          25.5,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "to_account_uuid", Code: "not_allowed", Message: "must differ from from_account_uuid"},
+			},
+		},
+		{
+			name: "Invalid UUID format",
+			transferReq: TransferRequest{
+				FromAccountUUID: "invalid-uuid-format",
+				ToAccountUUID:   "invalid-uuid-format",
+				Amount:          25.5,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "from_account_uuid", Code: "invalid_uuid", Message: "must be a valid uuid: 'invalid-uuid-format'"},
+				{Field: "to_account_uuid", Code: "invalid_uuid", Message: "must be a valid uuid: 'invalid-uuid-format'"},
+			},
+		},
+		{
+			name: "Amount with more than two decimal places",
+			transferReq: TransferRequest{
+				FromAccountUUID: "550e8400-e29b-41d4-a716-446655440000",
+				ToAccountUUID:   "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				Amount:          10.125,
+				IdempotencyKey:  "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "amount", Code: "too_many_decimals", Message: "must have at most 2 decimal places: 10.125"},
+			},
+		},
+		{
+			name:        "All fields missing",
+			transferReq: TransferRequest{},
+			wantErr: []util.FieldError{
+				{Field: "from_account_uuid", Code: "required", Message: "field is required"},
+				{Field: "to_account_uuid", Code: "required", Message: "field is required"},
+				{Field: "amount", Code: "required", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
+			},
+		},
+	}
+
+	// loop over the test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.transferReq.Validate())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-pismo-challenge/pkg/util"
+	"go-pismo-challenge/pkg/validate"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// EventTypes are the types of events webhooks can subscribe to
+var EventTypes = []string{EventAccountCreated, EventTransactionCreated} //nolint:gochecknoglobals // read only
+
+type WebhookRequest struct {
+	URL            string   `json:"url" example:"https://example.com/webhooks" validate:"required,max=2048,regex=^https?://[^/\\s]+"`
+	EventTypes     []string `json:"event_types" example:"account.created,transaction.created" validate:"required,min=1,max=8"`
+	IdempotencyKey string   `json:"idempotency_key" example:"some-string" format:"string" validate:"required"`
+}
+
+// Validate checks the fields of the request, and that every event type is known
+func (w WebhookRequest) Validate() []util.FieldError {
+	errs := validate.Struct(w)
+
+	for i, eventType := range w.EventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			errs = append(errs, util.FieldError{
+				Field:   fmt.Sprintf("event_types[%d]", i),
+				Code:    validate.CodeNotAllowed,
+				Message: fmt.Sprintf("must be one of [%s]: '%s'", strings.Join(EventTypes, ", "), eventType),
+			})
+		}
+	}
+
+	return errs
+}
+
+// Webhook subscribes an endpoint to events of the given types. Deliveries are signed with the
+// secret, which is only returned when the webhook is created. Webhooks failing
+// ConsecutiveFailures deliveries in a row are disabled
+type Webhook struct {
+	UUID                uuid.UUID  `json:"uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	URL                 string     `json:"url" example:"https://example.com/webhooks" format:"string"`
+	EventTypes          []string   `json:"event_types" example:"account.created,transaction.created"`
+	Secret              string     `json:"secret,omitempty" example:"whsec_5f2b..." format:"string"`
+	Enabled             bool       `json:"enabled" example:"true"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0" format:"int64"`
+	CreatedAt           time.Time  `json:"created_at" example:"2025-10-01T06:22:46.931755Z" format:"time"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty" example:"2025-10-02T06:22:46.931755Z" format:"time"`
+}
+
+// Subscribes tells whether the webhook receives events of the type
+func (w Webhook) Subscribes(eventType string) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+// WebhookDelivery is an event queued for delivery to a webhook, Payload is the body posted
+type WebhookDelivery struct {
+	ID            int64
+	WebhookUUID   uuid.UUID
+	EventUUID     uuid.UUID
+	EventType     string
+	Payload       json.RawMessage
+	Attempts      int
+	NextAttemptAt time.Time
+}
+
+// WebhookDeliveryAttempt is an entry of the delivery log of a webhook, StatusCode is 0 when the
+// endpoint did not respond
+type WebhookDeliveryAttempt struct {
+	EventUUID   uuid.UUID `json:"event_uuid" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	EventType   string    `json:"event_type" example:"transaction.created" format:"string"`
+	Attempt     int       `json:"attempt" example:"1" format:"int64"`
+	StatusCode  int       `json:"status_code" example:"200" format:"int64"`
+	Error       string    `json:"error,omitempty" example:"webhook responded with status 503" format:"string"`
+	Succeeded   bool      `json:"succeeded" example:"true"`
+	DurationMS  int64     `json:"duration_ms" example:"42" format:"int64"`
+	AttemptedAt time.Time `json:"attempted_at" example:"2025-10-01T06:22:46.931755Z" format:"time"`
+}
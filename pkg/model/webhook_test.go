@@ -0,0 +1,70 @@
+package model
+
+import (
+	"go-pismo-challenge/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhookRequest_Validate tests the Validate method of WebhookRequest
+func TestWebhookRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhookReq WebhookRequest
+		wantErr    []util.FieldError // expected validation errors
+	}{
+		{
+			name: "Valid webhook request",
+			webhookReq: WebhookRequest{
+				URL:            "https://example.com/webhooks",
+				EventTypes:     []string{"account.created", "transaction.created"},
+				IdempotencyKey: "some-string",
+			},
+			wantErr: nil, // no errors expected
+		},
+		{
+			name: "Unknown event type",
+			webhookReq: WebhookRequest{
+				URL:            "http://localhost:8080",
+				EventTypes:     []string{"account.created", "account.deleted"},
+				IdempotencyKey: "some-string",
+			},
+			wantErr: []util.FieldError{
+				{
+					Field:   "event_types[1]",
+					Code:    "not_allowed",
+					Message: "must be one of [account.created, transaction.created]: 'account.deleted'",
+				},
+			},
+		},
+		{
+			name: "Not an http url and no event types",
+			webhookReq: WebhookRequest{
+				URL:            "ftp://example.com",
+				EventTypes:     []string{},
+				IdempotencyKey: "some-string",
+			},
+			wantErr: []util.FieldError{
+				{Field: "url", Code: "pattern_mismatch", Message: "must match pattern '^https?://[^/\\s]+'"},
+				{Field: "event_types", Code: "too_small", Message: "must have at least 1 items: 0"},
+			},
+		},
+		{
+			name:       "All fields missing",
+			webhookReq: WebhookRequest{},
+			wantErr: []util.FieldError{
+				{Field: "url", Code: "required", Message: "field is required"},
+				{Field: "event_types", Code: "required", Message: "field is required"},
+				{Field: "idempotency_key", Code: "required", Message: "field is required"},
+			},
+		},
+	}
+
+	// loop over the test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.webhookReq.Validate())
+		})
+	}
+}
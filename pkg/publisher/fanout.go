@@ -0,0 +1,30 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+)
+
+type fanoutPublisher struct {
+	publishers []Publisher
+}
+
+// NewFanout publishes each event to all of ps. The event fails if any of them fails, and is
+// published again to all of them
+func NewFanout(ps ...Publisher) Publisher {
+	return &fanoutPublisher{
+		publishers: ps,
+	}
+}
+
+func (p *fanoutPublisher) Publish(ctx context.Context, e model.Event) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,14 @@
+// Package publisher delivers the events relayed from the outbox to downstream systems.
+package publisher
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/publisher_mock.go -source=publisher.go
+type Publisher interface {
+	// Publish delivers the event. An error means it may not have been delivered, and it is
+	// published again later
+	Publish(ctx context.Context, e model.Event) error
+}
@@ -0,0 +1,310 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockEvent() model.Event {
+	return model.Event{
+		UUID:        uuid.NewV5(uuid.Nil, "event"),
+		Type:        model.EventAccountCreated,
+		AccountUUID: uuid.NewV5(uuid.Nil, "account"),
+		Data:        []byte(`{"document_number":"12345678900"}`),
+		CreatedAt:   time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewWriterPublisher(&buf)
+
+	require.NoError(t, p.Publish(context.Background(), mockEvent()))
+	require.NoError(t, p.Publish(context.Background(), mockEvent()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	expected, err := json.Marshal(mockEvent())
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), lines[0])
+}
+
+func TestWriterPublisherCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, NewWriterPublisher(&buf).Publish(ctx, mockEvent()), context.Canceled)
+	assert.Zero(t, buf.Len())
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	event := mockEvent()
+	require.NoError(t, NewWebhookPublisher(srv.URL, time.Second).Publish(context.Background(), event))
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, event.UUID.String(), got.Header.Get("X-Event-ID"))
+	assert.Equal(t, model.EventAccountCreated, got.Header.Get("X-Event-Type"))
+	expected, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(body))
+}
+
+func TestWebhookPublisherStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookPublisher(srv.URL, time.Second).Publish(context.Background(), mockEvent())
+	assert.EqualError(t, err, "webhook responded with status 503")
+}
+
+func TestWebhookPublisherTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	err := NewWebhookPublisher(srv.URL, 10*time.Millisecond).Publish(context.Background(), mockEvent())
+	assert.ErrorContains(t, err, "failed to post event")
+}
+
+func mockDelivery() model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:          7,
+		WebhookUUID: uuid.NewV5(uuid.Nil, "webhook"),
+		EventUUID:   uuid.NewV5(uuid.Nil, "event"),
+		EventType:   model.EventAccountCreated,
+		Payload:     []byte(`{"type":"account.created"}`),
+	}
+}
+
+// localSender posts to the test servers listening on loopback addresses
+var localSender = WebhookSenderOptions{Timeout: time.Second, AllowLocal: true} //nolint:gochecknoglobals // read only
+
+func TestWebhookSender(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := clock.NewFake(time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC))
+	delivery := mockDelivery()
+	status, err := NewWebhookSender(localSender, c).Send(context.Background(), srv.URL, "whsec_secret", delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, delivery.EventUUID.String(), got.Header.Get("X-Event-ID"))
+	assert.Equal(t, model.EventAccountCreated, got.Header.Get("X-Event-Type"))
+	assert.Equal(t, "1759302000", got.Header.Get("X-Webhook-Timestamp"))
+	assert.Equal(t, string(delivery.Payload), string(body))
+
+	// receivers verify the signature of the timestamp and body they received
+	mac := hmac.New(sha256.New, []byte("whsec_secret"))
+	mac.Write([]byte(got.Header.Get("X-Webhook-Timestamp") + "." + string(body)))
+	assert.Equal(t, "v1="+hex.EncodeToString(mac.Sum(nil)), got.Header.Get("X-Webhook-Signature"))
+}
+
+func TestWebhookSenderStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	status, err := NewWebhookSender(localSender, clock.System()).Send(context.Background(), srv.URL, "whsec_secret", mockDelivery())
+	assert.EqualError(t, err, "webhook responded with status 500")
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestWebhookSenderUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	status, err := NewWebhookSender(localSender, clock.System()).Send(context.Background(), srv.URL, "whsec_secret", mockDelivery())
+	assert.ErrorContains(t, err, "failed to post event")
+	assert.Zero(t, status)
+}
+
+// the addresses are checked when dialing, whatever the url resolved to when the webhook was created
+func TestWebhookSenderLocalAddress(t *testing.T) {
+	called := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender := NewWebhookSender(WebhookSenderOptions{Timeout: time.Second}, clock.System())
+	status, err := sender.Send(context.Background(), srv.URL, "whsec_secret", mockDelivery())
+	require.ErrorIs(t, err, ErrForbiddenURL)
+	assert.ErrorContains(t, err, "address 127.0.0.1 is not public")
+	assert.Zero(t, status)
+	assert.False(t, called)
+
+	status, err = sender.Send(context.Background(), "http://93.184.215.14/webhooks", "whsec_secret", mockDelivery())
+	require.ErrorIs(t, err, ErrForbiddenURL)
+	assert.Zero(t, status)
+}
+
+func TestWebhookSenderCheckURL(t *testing.T) {
+	sender := NewWebhookSender(WebhookSenderOptions{Timeout: time.Second}, clock.System())
+	for _, u := range []string{
+		"https://93.184.215.14/webhooks",
+		"https://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:8443/webhooks",
+	} {
+		assert.NoError(t, sender.CheckURL(context.Background(), u), u)
+	}
+
+	for _, u := range []string{
+		"http://93.184.215.14/webhooks",
+		"ftp://93.184.215.14/webhooks",
+		"https:///webhooks",
+		"https://127.0.0.1/webhooks",
+		"https://localhost:8080/webhooks",
+		"https://[::1]/webhooks",
+		"https://[::ffff:127.0.0.1]/webhooks",
+		"https://0.0.0.0/webhooks",
+		"https://10.1.2.3/webhooks",
+		"https://172.16.0.1/webhooks",
+		"https://192.168.0.10/webhooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[fe80::1]/webhooks",
+		"https://[fd00::1]/webhooks",
+		"https://%zz/webhooks",
+	} {
+		assert.ErrorIs(t, sender.CheckURL(context.Background(), u), ErrForbiddenURL, u)
+	}
+
+	local := NewWebhookSender(localSender, clock.System())
+	assert.NoError(t, local.CheckURL(context.Background(), "http://localhost:8080/webhooks"))
+	assert.NoError(t, local.CheckURL(context.Background(), "https://10.1.2.3/webhooks"))
+	assert.ErrorIs(t, local.CheckURL(context.Background(), "ftp://localhost/webhooks"), ErrForbiddenURL)
+}
+
+func TestCheckAddr(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   string
+		public bool
+	}{
+		{"public ipv4", "93.184.215.14", true},
+		{"public ipv6", "2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"public ipv4-mapped", "::ffff:93.184.215.14", true},
+		{"unspecified ipv4", "0.0.0.0", false},
+		{"this network", "0.1.2.3", false},
+		{"private 10/8", "10.1.2.3", false},
+		{"carrier-grade nat", "100.64.0.1", false},
+		{"carrier-grade nat end", "100.127.255.254", false},
+		{"after carrier-grade nat", "100.128.0.1", true},
+		{"loopback", "127.0.0.1", false},
+		{"loopback range", "127.1.2.3", false},
+		{"link-local", "169.254.169.254", false},
+		{"private 172.16/12", "172.16.0.1", false},
+		{"ietf protocol assignments", "192.0.0.8", false},
+		{"documentation 192.0.2/24", "192.0.2.1", false},
+		{"6to4 relay", "192.88.99.1", false},
+		{"private 192.168/16", "192.168.0.10", false},
+		{"benchmarking", "198.18.0.1", false},
+		{"benchmarking end", "198.19.255.254", false},
+		{"documentation 198.51.100/24", "198.51.100.1", false},
+		{"documentation 203.0.113/24", "203.0.113.1", false},
+		{"multicast ipv4", "224.0.0.1", false},
+		{"multicast ipv4 global", "239.1.2.3", false},
+		{"reserved", "240.0.0.1", false},
+		{"broadcast", "255.255.255.255", false},
+		{"unspecified ipv6", "::", false},
+		{"loopback ipv6", "::1", false},
+		{"ipv4-mapped loopback", "::ffff:127.0.0.1", false},
+		{"ipv4-mapped private", "::ffff:10.1.2.3", false},
+		{"ipv4-mapped carrier-grade nat", "::ffff:100.64.0.1", false},
+		{"ipv4-compatible", "::10.1.2.3", false},
+		{"nat64", "64:ff9b::a01:203", false},
+		{"local nat64", "64:ff9b:1::1", false},
+		{"discard", "100::1", false},
+		{"teredo", "2001::1", false},
+		{"ietf protocol assignments ipv6", "2001:1::1", false},
+		{"documentation ipv6", "2001:db8::1", false},
+		{"6to4", "2002:a01:203::1", false},
+		{"documentation 3fff::/20", "3fff::1", false},
+		{"unique local", "fd00::1", false},
+		{"link-local ipv6", "fe80::1", false},
+		{"multicast ipv6", "ff02::1", false},
+		{"multicast ipv6 global", "ff0e::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAddr(netip.MustParseAddr(tt.addr))
+			if tt.public {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrForbiddenURL)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("whsec_secret", 1759302000, []byte(`{}`))
+
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, Sign("whsec_secret", 1759302000, []byte(`{}`)))
+	assert.NotEqual(t, signature, Sign("whsec_other", 1759302000, []byte(`{}`)))
+	assert.NotEqual(t, signature, Sign("whsec_secret", 1759302001, []byte(`{}`)))
+	assert.NotEqual(t, signature, Sign("whsec_secret", 1759302000, []byte(`{ }`)))
+}
+
+// failingPublisher fails every event
+type failingPublisher struct{ err error }
+
+func (p failingPublisher) Publish(context.Context, model.Event) error { return p.err }
+
+func TestFanout(t *testing.T) {
+	var first, second bytes.Buffer
+	require.NoError(t, NewFanout(NewWriterPublisher(&first), NewWriterPublisher(&second)).Publish(context.Background(), mockEvent()))
+	assert.Equal(t, first.String(), second.String())
+	assert.NotZero(t, first.Len())
+
+	// the publishers after a failed one still receive the event
+	mockError := errors.New("webhook responded with status 503")
+	var after bytes.Buffer
+	err := NewFanout(failingPublisher{mockError}, NewWriterPublisher(&after)).Publish(context.Background(), mockEvent())
+	assert.ErrorIs(t, err, mockError)
+	assert.NotZero(t, after.Len())
+}
@@ -0,0 +1,212 @@
+package publisher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenURL is returned for webhook urls deliveries are not posted to, as they are not
+// https or point to addresses that are not public
+var ErrForbiddenURL = errors.New("webhook url is not allowed")
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/sender_mock.go -source=sender.go
+type WebhookSender interface {
+	// Send posts the payload of the delivery to url signed with secret, returning the status of
+	// the response or 0 if there was none. Any status other than 2xx fails the delivery
+	Send(ctx context.Context, url, secret string, d model.WebhookDelivery) (int, error)
+	// CheckURL returns ErrForbiddenURL if deliveries to url would be refused, resolving its host
+	CheckURL(ctx context.Context, url string) error
+}
+
+// WebhookSenderOptions configure the deliveries: a delivery waits up to Timeout for the
+// response. Only https urls resolving to public addresses are posted to, unless AllowLocal is set
+// for local development, which allows http urls and any address
+type WebhookSenderOptions struct {
+	Timeout    time.Duration
+	AllowLocal bool
+}
+
+type webhookSender struct {
+	client     *http.Client
+	resolver   *net.Resolver
+	clock      clock.Clock
+	allowLocal bool
+}
+
+// NewWebhookSender signs deliveries with the time of c. The addresses are checked again when
+// dialing, so that a host resolving to another address after CheckURL, or a redirect, does not
+// reach the internal network
+func NewWebhookSender(opts WebhookSenderOptions, c clock.Clock) WebhookSender {
+	s := &webhookSender{
+		resolver:   net.DefaultResolver,
+		clock:      c,
+		allowLocal: opts.AllowLocal,
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout, Control: s.checkDial}
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	// a proxy would dial the webhook instead, out of reach of the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	s.client = &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+
+			return s.checkScheme(req.URL)
+		},
+	}
+
+	return s
+}
+
+// Send sets the X-Webhook-Timestamp header to the unix time of the attempt and the
+// X-Webhook-Signature header to "v1=" followed by its Sign, receivers reject old timestamps to
+// prevent replays
+func (s *webhookSender) Send(ctx context.Context, webhookURL, secret string, d model.WebhookDelivery) (int, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrForbiddenURL, err)
+	}
+	// webhooks registered before the scheme was checked
+	if err := s.checkScheme(u); err != nil {
+		return 0, err
+	}
+
+	timestamp := s.clock.Now().Unix()
+
+	header := eventHeader(d.EventUUID.String(), d.EventType)
+	header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	header.Set("X-Webhook-Signature", "v1="+Sign(secret, timestamp, d.Payload))
+
+	return post(ctx, s.client, webhookURL, d.Payload, header)
+}
+
+func (s *webhookSender) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrForbiddenURL, err)
+	}
+	if err := s.checkScheme(u); err != nil {
+		return err
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: host is missing", ErrForbiddenURL)
+	}
+	if s.allowLocal {
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve host '%s'", ErrForbiddenURL, u.Hostname())
+	}
+	for _, addr := range addrs {
+		if err := checkAddr(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkScheme allows https urls, and http ones for local development
+func (s *webhookSender) checkScheme(u *url.URL) error {
+	if u.Scheme == "https" || (s.allowLocal && u.Scheme == "http") {
+		return nil
+	}
+
+	return fmt.Errorf("%w: scheme must be https, got '%s'", ErrForbiddenURL, u.Scheme)
+}
+
+// checkDial is the Control of the dialer, it runs once the address to connect to is resolved
+func (s *webhookSender) checkDial(_, address string, _ syscall.RawConn) error {
+	if s.allowLocal {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrForbiddenURL, err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrForbiddenURL, err)
+	}
+
+	return checkAddr(addr)
+}
+
+// specialPurposePrefixes are the global unicast blocks reserved for special purposes, which do not
+// reach the endpoint of an integrator on the internet
+var specialPurposePrefixes = []netip.Prefix{ //nolint:gochecknoglobals // read only
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space of carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+	netip.MustParsePrefix("::/96"),           // IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, reaches IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local NAT64
+	netip.MustParsePrefix("100::/64"),        // discard
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, e.g. Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, reaches IPv4 addresses
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+}
+
+// checkAddr returns ErrForbiddenURL unless addr is a public address: a global unicast address
+// that is not private nor reserved for special purposes. IPv4-mapped IPv6 addresses are checked
+// as the IPv4 address they map
+func checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() && !isSpecialPurpose(addr) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: address %s is not public", ErrForbiddenURL, addr)
+}
+
+func isSpecialPurpose(addr netip.Addr) bool {
+	for _, prefix := range specialPurposePrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp and body joined by a dot, keyed with
+// the secret of the webhook
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"io"
+	"net/http"
+	"time"
+)
+
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher posts each event as JSON to url, waiting up to timeout for the response.
+// Any status other than 2xx fails the delivery
+func NewWebhookPublisher(url string, timeout time.Duration) Publisher {
+	return &webhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, e model.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	_, err = post(ctx, p.client, p.url, body, eventHeader(e.UUID.String(), e.Type))
+
+	return err
+}
+
+// eventHeader returns the headers identifying the event posted
+func eventHeader(eventUUID, eventType string) http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	// receivers drop redeliveries by event id
+	header.Set("X-Event-ID", eventUUID)
+	header.Set("X-Event-Type", eventType)
+
+	return header
+}
+
+// post posts the body to url, returning the status of the response or 0 if there was none. Any
+// status other than 2xx is an error
+func post(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header = header
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post event: %w", err)
+	}
+	defer res.Body.Close()
+
+	// drain the body so that the connection is reused
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,37 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"io"
+	"sync"
+)
+
+type writerPublisher struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterPublisher writes each event to w as a line of JSON, e.g. to stdout or a file
+func NewWriterPublisher(w io.Writer) Publisher {
+	return &writerPublisher{
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (p *writerPublisher) Publish(ctx context.Context, e model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enc.Encode(e); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
@@ -17,6 +17,12 @@ type AccountConnector interface {
 	Create(ctx context.Context, a model.Account) error
 	CheckIdempotency(ctx context.Context, accountUUID string) error
 	Get(ctx context.Context, uuid string) (model.Account, error)
+	// GetForUpdate returns the account like Get, locking it until the end of the unit of work
+	// running in ctx. Callers locking several accounts lock them ordered by uuid, so that
+	// concurrent units of work cannot deadlock
+	GetForUpdate(ctx context.Context, uuid string) (model.Account, error)
+	// List returns all accounts ordered by creation
+	List(ctx context.Context) ([]model.Account, error)
 }
 
 func NewAccountRepo(db *sql.DB) AccountConnector {
@@ -25,12 +31,26 @@ func NewAccountRepo(db *sql.DB) AccountConnector {
 	}
 }
 
+// Create stores the account, created_at is stored in UTC as the column has no time zone
 func (a *accountRepo) Create(ctx context.Context, account model.Account) error {
-	insertSQL := `INSERT INTO accounts.account (uuid, document_number, created_at) values ($1, $2, $3) ON CONFLICT (uuid) DO NOTHING;`
+	insertSQL := `INSERT INTO accounts.account (uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at)
+					values ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (uuid) DO NOTHING;`
 
-	_, err := a.db.ExecContext(ctx, insertSQL, account.UUID.String(), account.DocumentNumber, account.CreatedAt)
+	ctx, span := startSpan(ctx, "account.create", insertSQL)
+	defer span.End()
+
+	_, err := Conn(ctx, a.db).ExecContext(ctx, insertSQL,
+		account.UUID.String(),
+		account.DocumentNumber,
+		account.ClosingDay,
+		account.DueDay,
+		account.RatePlanID,
+		account.Status,
+		account.CreditLimit,
+		account.CreatedAt.UTC(),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to insert account: %w", err)
+		return RecordError(span, fmt.Errorf("failed to insert account: %w", DBError(ctx, err)))
 	}
 
 	return nil
@@ -39,13 +59,16 @@ func (a *accountRepo) Create(ctx context.Context, account model.Account) error {
 func (a *accountRepo) CheckIdempotency(ctx context.Context, accountUUID string) error {
 	checkIdempotencySQL := `SELECT COALESCE(COUNT(*), 0) as count FROM accounts.account where uuid = $1;`
 
-	rows := a.db.QueryRowContext(ctx, checkIdempotencySQL, accountUUID)
+	ctx, span := startSpan(ctx, "account.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	rows := Conn(ctx, a.db).QueryRowContext(ctx, checkIdempotencySQL, accountUUID)
 	if rows.Err() != nil {
-		return fmt.Errorf("failed to query: %w", rows.Err())
+		return RecordError(span, fmt.Errorf("failed to query: %w", DBError(ctx, rows.Err())))
 	}
 	var count uint64
 	if err := rows.Scan(&count); err != nil {
-		return fmt.Errorf("failed to scan while idempotency check: %w", err)
+		return RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", DBError(ctx, err)))
 	}
 
 	if count > 0 {
@@ -56,24 +79,79 @@ func (a *accountRepo) CheckIdempotency(ctx context.Context, accountUUID string)
 }
 
 func (a *accountRepo) Get(ctx context.Context, uuid string) (model.Account, error) {
-	getAccount := `SELECT uuid, document_number, created_at FROM accounts.account where uuid = $1;`
+	getAccount := `SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at FROM accounts.account where uuid = $1;`
+
+	return a.get(ctx, "account.get", getAccount, uuid)
+}
+
+func (a *accountRepo) GetForUpdate(ctx context.Context, uuid string) (model.Account, error) {
+	getAccount := `SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at FROM accounts.account
+					where uuid = $1 FOR UPDATE;`
 
-	rows := a.db.QueryRowContext(ctx, getAccount, uuid)
+	return a.get(ctx, "account.get_for_update", getAccount, uuid)
+}
+
+func (a *accountRepo) get(ctx context.Context, name, getAccount, uuid string) (model.Account, error) {
+	ctx, span := startSpan(ctx, name, getAccount)
+	defer span.End()
+
+	rows := Conn(ctx, a.db).QueryRowContext(ctx, getAccount, uuid)
 	if rows.Err() != nil {
-		return model.Account{}, fmt.Errorf("failed to query account: %w", rows.Err())
+		return model.Account{}, RecordError(span, fmt.Errorf("failed to query account: %w", DBError(ctx, rows.Err())))
 	}
 	var account model.Account
 	if err := rows.Scan(
 		&account.UUID,
 		&account.DocumentNumber,
+		&account.ClosingDay,
+		&account.DueDay,
+		&account.RatePlanID,
+		&account.Status,
+		&account.CreditLimit,
 		&account.CreatedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return model.Account{}, ErrNoRows
 		}
 
-		return model.Account{}, fmt.Errorf("failed to scan account: %w", err)
+		return model.Account{}, RecordError(span, fmt.Errorf("failed to scan account: %w", DBError(ctx, err)))
 	}
 
 	return account, nil
 }
+
+func (a *accountRepo) List(ctx context.Context) ([]model.Account, error) {
+	listAccounts := `SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at FROM accounts.account ORDER BY id;`
+
+	ctx, span := startSpan(ctx, "account.list", listAccounts)
+	defer span.End()
+
+	rows, err := Conn(ctx, a.db).QueryContext(ctx, listAccounts)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query accounts: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var accounts []model.Account
+	for rows.Next() {
+		var account model.Account
+		if err := rows.Scan(
+			&account.UUID,
+			&account.DocumentNumber,
+			&account.ClosingDay,
+			&account.DueDay,
+			&account.RatePlanID,
+			&account.Status,
+			&account.CreditLimit,
+			&account.CreatedAt,
+		); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan account: %w", DBError(ctx, err)))
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate accounts: %w", DBError(ctx, err)))
+	}
+
+	return accounts, nil
+}
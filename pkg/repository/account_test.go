@@ -11,6 +11,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-jose/go-jose/v4/testutils/require"
 	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -43,15 +44,25 @@ func (s *accountSuite) TestCreateSuccess() {
 	request := model.Account{
 		UUID:           mockUUID,
 		DocumentNumber: "doc",
+		ClosingDay:     5,
+		DueDay:         15,
+		RatePlanID:     2,
+		Status:         model.AccountStatusActive,
+		CreditLimit:    1000,
 		CreatedAt:      now,
 	}
 
-	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account (uuid, document_number, created_at) 
-				values ($1, $2, $3) ON CONFLICT (uuid) DO NOTHING`)).
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account (uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (uuid) DO NOTHING`)).
 		WithArgs(
 			request.UUID.String(),
 			request.DocumentNumber,
-			request.CreatedAt,
+			request.ClosingDay,
+			request.DueDay,
+			request.RatePlanID,
+			request.Status,
+			request.CreditLimit,
+			request.CreatedAt.UTC(),
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := s.repo.Create(ctx, request)
@@ -67,15 +78,25 @@ func (s *accountSuite) TestCreateError() {
 	request := model.Account{
 		UUID:           mockUUID,
 		DocumentNumber: "doc",
+		ClosingDay:     5,
+		DueDay:         15,
+		RatePlanID:     2,
+		Status:         model.AccountStatusActive,
+		CreditLimit:    1000,
 		CreatedAt:      now,
 	}
 
-	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account (uuid, document_number, created_at) 
-				values ($1, $2, $3) ON CONFLICT (uuid) DO NOTHING`)).
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account (uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (uuid) DO NOTHING`)).
 		WithArgs(
 			request.UUID.String(),
 			request.DocumentNumber,
-			request.CreatedAt,
+			request.ClosingDay,
+			request.DueDay,
+			request.RatePlanID,
+			request.Status,
+			request.CreditLimit,
+			request.CreatedAt.UTC(),
 		).WillReturnError(mockError)
 
 	err := s.repo.Create(ctx, request)
@@ -91,20 +112,36 @@ func (s *accountSuite) TestGetAccountSuccess() {
 	expected := model.Account{
 		UUID:           mockUUID,
 		DocumentNumber: "abc",
+		ClosingDay:     5,
+		DueDay:         15,
+		RatePlanID:     2,
+		Status:         model.AccountStatusActive,
+		CreditLimit:    1000,
 		CreatedAt:      now,
 	}
-	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, created_at FROM accounts.account where uuid = $1;`)).
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at
+					FROM accounts.account where uuid = $1;`)).
 		WithArgs(mockUUID.String()).
 		WillReturnRows(
 			sqlmock.NewRows(
 				[]string{
 					"uuid",
 					"document_number",
+					"closing_day",
+					"due_day",
+					"rate_plan_id",
+					"status",
+					"credit_limit",
 					"created_at",
 				}).
 				AddRow(
 					expected.UUID.String(),
 					expected.DocumentNumber,
+					expected.ClosingDay,
+					expected.DueDay,
+					expected.RatePlanID,
+					expected.Status,
+					expected.CreditLimit,
 					expected.CreatedAt,
 				))
 
@@ -118,7 +155,8 @@ func (s *accountSuite) TestGetAccountError() {
 	mockUUID := uuid.NewV5(uuid.Nil, "")
 	mockError := errors.New("db error")
 
-	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, created_at FROM accounts.account where uuid = $1;`)).
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at
+					FROM accounts.account where uuid = $1;`)).
 		WithArgs(mockUUID.String()).
 		WillReturnError(mockError)
 
@@ -128,6 +166,33 @@ func (s *accountSuite) TestGetAccountError() {
 	s.Equal(got, model.Account{})
 }
 
+func (s *accountSuite) TestGetForUpdateSuccess() {
+	ctx := context.Background()
+	mockUUID := uuid.NewV5(uuid.Nil, "")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at
+					FROM accounts.account where uuid = $1 FOR UPDATE;`)).
+		WithArgs(mockUUID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "document_number", "closing_day", "due_day", "rate_plan_id", "status", "credit_limit", "created_at"}).
+			AddRow(mockUUID.String(), "abc", 5, 15, 1, model.AccountStatusActive, 0, time.Now()))
+
+	got, err := s.repo.GetForUpdate(ctx, mockUUID.String())
+	s.NoError(err)
+	s.Equal(mockUUID, got.UUID)
+}
+
+func (s *accountSuite) TestGetForUpdateMissing() {
+	ctx := context.Background()
+	mockUUID := uuid.NewV5(uuid.Nil, "")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE`)).
+		WithArgs(mockUUID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "document_number", "closing_day", "due_day", "rate_plan_id", "status", "credit_limit", "created_at"}))
+
+	_, err := s.repo.GetForUpdate(ctx, mockUUID.String())
+	s.ErrorIs(err, ErrNoRows)
+}
+
 func (s *accountSuite) TestCheckIdempotencySuccess() {
 	ctx := context.Background()
 	mockUUID := uuid.NewV5(uuid.Nil, "")
@@ -166,3 +231,74 @@ func (s *accountSuite) TestCheckIdempotencyError() {
 	s.Error(err)
 	s.True(errors.Is(err, mockError))
 }
+
+func (s *accountSuite) TestGetAccountDeadlineExceeded() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mockUUID := uuid.NewV5(uuid.Nil, "")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at
+					FROM accounts.account where uuid = $1;`)).
+		WithArgs(mockUUID.String()).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "document_number", "closing_day", "due_day", "rate_plan_id", "status", "credit_limit", "created_at"}))
+
+	_, err := s.repo.Get(ctx, mockUUID.String())
+	s.ErrorIs(err, ErrQueryTimeout)
+}
+
+func (s *accountSuite) TestCreateQueryCanceled() {
+	ctx := context.Background()
+	request := model.Account{
+		UUID:           uuid.NewV5(uuid.Nil, ""),
+		DocumentNumber: "doc",
+		CreatedAt:      time.Now(),
+	}
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account (uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at)`)).
+		WithArgs(
+			request.UUID.String(),
+			request.DocumentNumber,
+			request.ClosingDay,
+			request.DueDay,
+			request.RatePlanID,
+			request.Status,
+			request.CreditLimit,
+			request.CreatedAt.UTC(),
+		).WillReturnError(&pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"})
+
+	err := s.repo.Create(ctx, request)
+	s.ErrorIs(err, ErrQueryCanceled)
+}
+
+func (s *accountSuite) TestListSuccess() {
+	ctx := context.Background()
+	now := time.Now()
+	expected := []model.Account{
+		{UUID: uuid.NewV5(uuid.Nil, "1"), DocumentNumber: "abc", ClosingDay: 1, DueDay: 10, RatePlanID: 1, Status: model.AccountStatusActive, CreatedAt: now},
+		{UUID: uuid.NewV5(uuid.Nil, "2"), DocumentNumber: "def", ClosingDay: 5, DueDay: 15, RatePlanID: 2, Status: model.AccountStatusBlocked, CreditLimit: 500, CreatedAt: now},
+	}
+
+	rows := sqlmock.NewRows([]string{"uuid", "document_number", "closing_day", "due_day", "rate_plan_id", "status", "credit_limit", "created_at"})
+	for _, a := range expected {
+		rows.AddRow(a.UUID.String(), a.DocumentNumber, a.ClosingDay, a.DueDay, a.RatePlanID, a.Status, a.CreditLimit, a.CreatedAt)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at
+					FROM accounts.account ORDER BY id;`)).
+		WillReturnRows(rows)
+
+	got, err := s.repo.List(ctx)
+	s.NoError(err)
+	s.Equal(expected, got)
+}
+
+func (s *accountSuite) TestListError() {
+	ctx := context.Background()
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM accounts.account ORDER BY id;`)).WillReturnError(mockError)
+
+	got, err := s.repo.List(ctx)
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
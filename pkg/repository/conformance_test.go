@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/database"
+	"go-pismo-challenge/pkg/migrator"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/repositorytest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestConformance runs against a real Postgres when TEST_DATABASE_DSN is set, applying the
+// migrations first
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	opts := database.Options{
+		DSN:                   dsn,
+		MaxOpenConns:          4,
+		MaxIdleConns:          4,
+		ConnectMaxWait:        10 * time.Second,
+		ConnectInitialBackoff: 100 * time.Millisecond,
+		ConnectMaxBackoff:     time.Second,
+	}
+
+	db, err := database.NewConnection(context.Background(), opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrator.New(db, migrator.Options{
+		Dialect:     migrator.DialectPostgres,
+		Table:       "public.goose_db_version",
+		LockTimeout: time.Minute,
+	}, migrator.FS)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(context.Background()))
+
+	suite.Run(t, &repositorytest.Suite{
+		New: func() repositorytest.Repositories {
+			return repositorytest.Repositories{
+				Accounts:       repository.NewAccountRepo(db),
+				Transactions:   repository.NewTransactionRepo(db),
+				OperationTypes: repository.NewOperationTypeRepo(db),
+				Statements:     repository.NewStatementRepo(db),
+				RatePlans:      repository.NewRatePlanRepo(db),
+				Ledger:         repository.NewLedgerRepo(db),
+				Transfers:      repository.NewTransferRepo(db),
+				Outbox:         repository.NewOutboxRepo(db),
+				Webhooks:       repository.NewWebhookRepo(db),
+				UnitOfWork:     repository.NewUnitOfWork(db, 3),
+			}
+		},
+	})
+}
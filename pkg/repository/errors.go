@@ -1,8 +1,37 @@
 package repository
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
 
 var (
 	ErrDuplicate = errors.New("duplicate request received")
 	ErrNoRows    = errors.New("no rows found")
+	// ErrQueryTimeout is returned when the request deadline expired while the query was running
+	ErrQueryTimeout = errors.New("query timed out")
+	// ErrQueryCanceled is returned when the database cancelled the query, e.g. on statement_timeout
+	ErrQueryCanceled = errors.New("query canceled by database")
 )
+
+// postgres SQLSTATE query_canceled
+const pqQueryCanceled = "57014"
+
+// DBError tags errors caused by deadlines so that callers can tell them apart from other
+// failures. The deadline of ctx is checked first as drivers report a cancelled query in
+// different ways.
+func DBError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrQueryTimeout, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqQueryCanceled {
+		return fmt.Errorf("%w: %w", ErrQueryCanceled, err)
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ledgerRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/ledger_mock.go -source=ledger.go
+type LedgerConnector interface {
+	// Post stores the journal entries of one or more transactions with multi-row inserts, it has
+	// to run in the unit of work creating the transactions for both to be stored atomically.
+	// Entries posted before are left untouched
+	Post(ctx context.Context, entries []model.JournalEntry) error
+	// Balance returns the debits minus the credits of the account in the ledger account, of the
+	// entries posted before the given time
+	Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error)
+	// TrialBalance returns the debits and credits of every ledger account, ordered by id. The
+	// balance is left to the caller
+	TrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error)
+}
+
+func NewLedgerRepo(db *sql.DB) LedgerConnector {
+	return &ledgerRepo{
+		db,
+	}
+}
+
+// Post stores the entries batchRows at a time, posted_at is stored in UTC as the column has no
+// time zone
+func (r *ledgerRepo) Post(ctx context.Context, entries []model.JournalEntry) error {
+	insertSQL := `INSERT INTO ledger.entry (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at) values `
+
+	ctx, span := startSpan(ctx, "ledger.post", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(entries, batchRows) {
+		values := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*6)
+		for _, entry := range chunk {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+			args = append(args,
+				entry.TransactionUUID.String(),
+				entry.AccountUUID.String(),
+				entry.LedgerAccountID,
+				entry.Debit,
+				entry.Credit,
+				entry.PostedAt.UTC(),
+			)
+		}
+
+		_, err := Conn(ctx, r.db).ExecContext(ctx,
+			insertSQL+strings.Join(values, ", ")+" ON CONFLICT (transaction_uuid, ledger_account_id) DO NOTHING;", args...)
+		if err != nil {
+			return RecordError(span, fmt.Errorf("failed to insert journal entries: %w", DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *ledgerRepo) Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error) {
+	balanceSQL := `SELECT COALESCE(SUM(debit - credit), 0) FROM ledger.entry
+					WHERE account_uuid = $1 AND ledger_account_id = $2 AND posted_at < $3;`
+
+	ctx, span := startSpan(ctx, "ledger.balance", balanceSQL)
+	defer span.End()
+
+	var balance float64
+	if err := Conn(ctx, r.db).QueryRowContext(ctx, balanceSQL, accountUUID, ledgerAccountID, before.UTC()).Scan(&balance); err != nil {
+		return 0, RecordError(span, fmt.Errorf("failed to scan ledger balance: %w", DBError(ctx, err)))
+	}
+
+	return balance, nil
+}
+
+func (r *ledgerRepo) TrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	trialBalanceSQL := `SELECT a.id, a.code, a.type, COALESCE(SUM(e.debit), 0), COALESCE(SUM(e.credit), 0)
+					FROM ledger.account a LEFT JOIN ledger.entry e ON e.ledger_account_id = a.id
+					GROUP BY a.id, a.code, a.type ORDER BY a.id;`
+
+	ctx, span := startSpan(ctx, "ledger.trial_balance", trialBalanceSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, trialBalanceSQL)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query trial balance: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var accounts []model.TrialBalanceAccount
+	for rows.Next() {
+		var a model.TrialBalanceAccount
+		if err := rows.Scan(&a.LedgerAccountID, &a.Code, &a.Type, &a.Debits, &a.Credits); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan trial balance: %w", DBError(ctx, err)))
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate trial balance: %w", DBError(ctx, err)))
+	}
+
+	return accounts, nil
+}
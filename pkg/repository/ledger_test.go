@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ledgerSuite struct {
+	suite.Suite
+	repo LedgerConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestLedger(t *testing.T) {
+	suite.Run(t, new(ledgerSuite))
+}
+
+func (s *ledgerSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewLedgerRepo(db)
+	s.db = mock
+}
+
+func (s *ledgerSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func mockJournal() []model.JournalEntry {
+	trxUUID := uuid.NewV5(uuid.Nil, "trx")
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	postedAt := time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC)
+
+	return []model.JournalEntry{
+		{TransactionUUID: trxUUID, AccountUUID: accountUUID, LedgerAccountID: model.LedgerAccountReceivable, Debit: 11.9, PostedAt: postedAt},
+		{TransactionUUID: trxUUID, AccountUUID: accountUUID, LedgerAccountID: model.LedgerAccountCash, Credit: 11.9, PostedAt: postedAt},
+	}
+}
+
+func (s *ledgerSuite) TestPostSuccess() {
+	entries := mockJournal()
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO ledger.entry (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at) `+
+		`values ($1, $2, $3, $4, $5, $6), ($7, $8, $9, $10, $11, $12) ON CONFLICT (transaction_uuid, ledger_account_id) DO NOTHING;`)).
+		WithArgs(
+			entries[0].TransactionUUID.String(), entries[0].AccountUUID.String(), model.LedgerAccountReceivable, 11.9, 0.0, entries[0].PostedAt,
+			entries[1].TransactionUUID.String(), entries[1].AccountUUID.String(), model.LedgerAccountCash, 0.0, 11.9, entries[1].PostedAt,
+		).WillReturnResult(sqlmock.NewResult(2, 2))
+
+	s.NoError(s.repo.Post(context.Background(), entries))
+}
+
+// Entries of more than batchRows are inserted batchRows at a time
+func (s *ledgerSuite) TestPostChunked() {
+	entries := make([]model.JournalEntry, batchRows+1)
+	for i := range entries {
+		entries[i] = model.JournalEntry{TransactionUUID: uuid.NewV5(uuid.Nil, strconv.Itoa(i)), LedgerAccountID: model.LedgerAccountCash}
+	}
+
+	s.db.ExpectExec(regexp.QuoteMeta(`($2995, $2996, $2997, $2998, $2999, $3000) ON CONFLICT`)).WillReturnResult(sqlmock.NewResult(0, batchRows))
+	s.db.ExpectExec(regexp.QuoteMeta(`values ($1, $2, $3, $4, $5, $6) ON CONFLICT`)).
+		WithArgs(entries[batchRows].TransactionUUID.String(), uuid.Nil.String(), model.LedgerAccountCash, 0.0, 0.0, time.Time{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.Post(context.Background(), entries))
+}
+
+func (s *ledgerSuite) TestPostError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO ledger.entry`)).WillReturnError(mockError)
+
+	err := s.repo.Post(context.Background(), mockJournal())
+	s.ErrorIs(err, mockError)
+}
+
+func (s *ledgerSuite) TestBalanceSuccess() {
+	accountUUID := uuid.NewV5(uuid.Nil, "account").String()
+	before := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(debit - credit), 0) FROM ledger.entry
+					WHERE account_uuid = $1 AND ledger_account_id = $2 AND posted_at < $3;`)).
+		WithArgs(accountUUID, model.LedgerAccountReceivable, before).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(60.5))
+
+	// the time is queried in UTC
+	got, err := s.repo.Balance(context.Background(), accountUUID, model.LedgerAccountReceivable,
+		before.In(time.FixedZone("UTC-3", -3*60*60)))
+	s.NoError(err)
+	s.InDelta(60.5, got, 0.001)
+}
+
+func (s *ledgerSuite) TestBalanceError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM ledger.entry`)).WillReturnError(mockError)
+
+	_, err := s.repo.Balance(context.Background(), uuid.NewV5(uuid.Nil, "account").String(), model.LedgerAccountReceivable, time.Now())
+	s.ErrorIs(err, mockError)
+}
+
+func (s *ledgerSuite) TestTrialBalanceSuccess() {
+	expected := []model.TrialBalanceAccount{
+		{LedgerAccountID: 1, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset, Debits: 160.5, Credits: 100},
+		{LedgerAccountID: 2, Code: "CASH", Type: model.LedgerAccountTypeAsset, Debits: 100, Credits: 150.5},
+		{LedgerAccountID: 3, Code: "REVENUE", Type: model.LedgerAccountTypeRevenue},
+		{LedgerAccountID: 4, Code: "FEES", Type: model.LedgerAccountTypeRevenue, Credits: 10},
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "code", "type", "debits", "credits"})
+	for _, a := range expected {
+		rows.AddRow(a.LedgerAccountID, a.Code, a.Type, a.Debits, a.Credits)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT a.id, a.code, a.type, COALESCE(SUM(e.debit), 0), COALESCE(SUM(e.credit), 0)
+					FROM ledger.account a LEFT JOIN ledger.entry e ON e.ledger_account_id = a.id
+					GROUP BY a.id, a.code, a.type ORDER BY a.id;`)).
+		WillReturnRows(rows)
+
+	got, err := s.repo.TrialBalance(context.Background())
+	s.NoError(err)
+	s.Equal(expected, got)
+}
+
+func (s *ledgerSuite) TestTrialBalanceError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM ledger.account`)).WillReturnError(mockError)
+
+	got, err := s.repo.TrialBalance(context.Background())
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
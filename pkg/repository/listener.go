@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// transactionChannel is notified with the account uuid of every transaction inserted, see the
+// transaction_created_notify trigger
+const transactionChannel = "transaction_created"
+
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	// a dead connection is only noticed when writing to it
+	listenerPingInterval = 90 * time.Second
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/listener_mock.go -source=listener.go
+type TransactionListener interface {
+	// Listen calls notify with the account uuid of every transaction committed, until ctx is
+	// done. notify is called with an empty account uuid when notifications may have been missed,
+	// and every account has to catch up. notify must not block
+	Listen(ctx context.Context, notify func(accountUUID string)) error
+}
+
+type transactionListener struct {
+	dsn string
+}
+
+// NewTransactionListener returns a listener of the notifications of the database, on a connection
+// of its own that is re-established when lost
+func NewTransactionListener(dsn string) TransactionListener {
+	return &transactionListener{
+		dsn: dsn,
+	}
+}
+
+func (l *transactionListener) Listen(ctx context.Context, notify func(accountUUID string)) error {
+	listener := pq.NewListener(l.dsn, listenerMinReconnect, listenerMaxReconnect, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn().Err(err).Str("channel", transactionChannel).Msg("transaction listener connection failed")
+		}
+	})
+	// closing the listener stops it from reconnecting, and unblocks Listen while the database is
+	// unreachable
+	stop := context.AfterFunc(ctx, func() {
+		_ = listener.Close()
+	})
+	defer func() {
+		if stop() {
+			_ = listener.Close()
+		}
+	}()
+
+	if err := listener.Listen(transactionChannel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return fmt.Errorf("failed to listen on %s: %w", transactionChannel, err)
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return nil
+			}
+			// nil is sent once the connection is re-established, notifications sent while it was
+			// lost are not redelivered
+			if n == nil {
+				notify("")
+
+				continue
+			}
+			notify(n.Extra)
+		case <-ticker.C:
+			go func() {
+				_ = listener.Ping()
+			}()
+		}
+	}
+}
+
+type pollingListener struct {
+	interval time.Duration
+}
+
+// NewPollingListener returns a listener for the backends without notifications, which calls
+// notify for every account each interval. An interval of 0 disables polling
+func NewPollingListener(interval time.Duration) TransactionListener {
+	return &pollingListener{
+		interval: interval,
+	}
+}
+
+func (l *pollingListener) Listen(ctx context.Context, notify func(accountUUID string)) error {
+	if l.interval <= 0 {
+		<-ctx.Done()
+
+		return nil
+	}
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			notify("")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type listenerSuite struct {
+	suite.Suite
+}
+
+func TestListener(t *testing.T) {
+	suite.Run(t, new(listenerSuite))
+}
+
+// Every account is notified each interval until ctx is done
+func (s *listenerSuite) TestPollingListener() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notified := make(chan string, 1)
+	done := make(chan error)
+	go func() {
+		done <- NewPollingListener(time.Millisecond).Listen(ctx, func(accountUUID string) {
+			select {
+			case notified <- accountUUID:
+			default:
+			}
+		})
+	}()
+
+	s.Empty(<-notified)
+	cancel()
+	s.NoError(<-done)
+}
+
+// An interval of 0 disables polling
+func (s *listenerSuite) TestPollingListenerDisabled() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := NewPollingListener(0).Listen(ctx, func(string) {
+		s.Fail("notified with polling disabled")
+	})
+	s.NoError(err)
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"maps"
+	"slices"
+	"sync"
+)
+
+type accountRepo struct {
+	mu       sync.RWMutex
+	accounts map[string]model.Account
+}
+
+func NewAccountRepo() repository.AccountConnector {
+	return &accountRepo{
+		accounts: map[string]model.Account{},
+	}
+}
+
+// Create stores the account, an account with the same uuid is left untouched
+func (a *accountRepo) Create(ctx context.Context, account model.Account) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := account.UUID.String()
+	if _, ok := a.accounts[key]; !ok {
+		a.accounts[key] = account
+		onRollback(ctx, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+
+			delete(a.accounts, key)
+		})
+	}
+
+	return nil
+}
+
+func (a *accountRepo) CheckIdempotency(ctx context.Context, accountUUID string) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if _, ok := a.accounts[accountUUID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (a *accountRepo) Get(ctx context.Context, uuid string) (model.Account, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.Account{}, err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	account, ok := a.accounts[uuid]
+	if !ok {
+		return model.Account{}, repository.ErrNoRows
+	}
+
+	return account, nil
+}
+
+// GetForUpdate returns the account like Get. Units of work run one at a time, so the account is
+// locked until the unit of work reading it ends
+func (a *accountRepo) GetForUpdate(ctx context.Context, uuid string) (model.Account, error) {
+	return a.Get(ctx, uuid)
+}
+
+func (a *accountRepo) List(ctx context.Context) ([]model.Account, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return slices.SortedFunc(maps.Values(a.accounts), func(x, y model.Account) int {
+		return cmp.Or(x.CreatedAt.Compare(y.CreatedAt), cmp.Compare(x.UUID.String(), y.UUID.String()))
+	}), nil
+}
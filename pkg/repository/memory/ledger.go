@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"sync"
+	"time"
+)
+
+// DefaultLedgerAccounts are the ledger accounts seeded by the database migrations
+var DefaultLedgerAccounts = []model.TrialBalanceAccount{
+	{LedgerAccountID: model.LedgerAccountReceivable, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset},
+	{LedgerAccountID: model.LedgerAccountCash, Code: "CASH", Type: model.LedgerAccountTypeAsset},
+	{LedgerAccountID: model.LedgerAccountRevenue, Code: "REVENUE", Type: model.LedgerAccountTypeRevenue},
+	{LedgerAccountID: model.LedgerAccountFees, Code: "FEES", Type: model.LedgerAccountTypeRevenue},
+}
+
+// ledgerKey identifies an entry, a transaction is posted once per ledger account
+type ledgerKey struct {
+	transactionUUID string
+	ledgerAccountID int
+}
+
+type ledgerRepo struct {
+	mu      sync.RWMutex
+	entries map[ledgerKey]model.JournalEntry
+}
+
+func NewLedgerRepo() repository.LedgerConnector {
+	return &ledgerRepo{
+		entries: map[ledgerKey]model.JournalEntry{},
+	}
+}
+
+// Post stores the entries, an entry of the same transaction and ledger account is left untouched
+func (l *ledgerRepo) Post(ctx context.Context, entries []model.JournalEntry) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, entry := range entries {
+		key := ledgerKey{entry.TransactionUUID.String(), entry.LedgerAccountID}
+		if _, ok := l.entries[key]; !ok {
+			l.entries[key] = entry
+			onRollback(ctx, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+
+				delete(l.entries, key)
+			})
+		}
+	}
+
+	return nil
+}
+
+func (l *ledgerRepo) Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error) {
+	if err := ctxError(ctx); err != nil {
+		return 0, err
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var balance float64
+	for _, entry := range l.entries {
+		if entry.AccountUUID.String() == accountUUID && entry.LedgerAccountID == ledgerAccountID && entry.PostedAt.Before(before) {
+			balance += entry.Debit - entry.Credit
+		}
+	}
+
+	return balance, nil
+}
+
+func (l *ledgerRepo) TrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	accounts := make([]model.TrialBalanceAccount, len(DefaultLedgerAccounts))
+	copy(accounts, DefaultLedgerAccounts)
+	for _, entry := range l.entries {
+		for i := range accounts {
+			if accounts[i].LedgerAccountID == entry.LedgerAccountID {
+				accounts[i].Debits += entry.Debit
+				accounts[i].Credits += entry.Credit
+			}
+		}
+	}
+
+	return accounts, nil
+}
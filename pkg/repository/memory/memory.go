@@ -0,0 +1,62 @@
+// Package memory implements the repository connectors in memory, for local development and
+// tests. It follows the error semantics of the Postgres implementation.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"sync"
+)
+
+// ctxError reports a done context the way the Postgres implementation reports an interrupted
+// query
+func ctxError(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", repository.ErrQueryTimeout, err)
+	}
+
+	return err
+}
+
+// journalKey is the context key of the journal of the running unit of work
+type journalKey struct{}
+
+// journal collects how to undo every change made within a unit of work, the changes are
+// undone in reverse order when it fails
+type journal struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+// onRollback registers undo to revert a change made with ctx when its unit of work fails.
+// Changes made outside a unit of work are kept. undo runs without any lock held, it locks the
+// repository itself
+func onRollback(ctx context.Context, undo func()) {
+	j, ok := ctx.Value(journalKey{}).(*journal)
+	if !ok {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.undo = append(j.undo, undo)
+}
+
+func (j *journal) rollback() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, undo := range slices.Backward(j.undo) {
+		undo()
+	}
+	j.undo = nil
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"go-pismo-challenge/pkg/repository/repositorytest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConformance(t *testing.T) {
+	suite.Run(t, &repositorytest.Suite{
+		New: func() repositorytest.Repositories {
+			return repositorytest.Repositories{
+				Accounts:       NewAccountRepo(),
+				Transactions:   NewTransactionRepo(),
+				OperationTypes: NewOperationTypeRepo(),
+				Statements:     NewStatementRepo(),
+				RatePlans:      NewRatePlanRepo(),
+				Ledger:         NewLedgerRepo(),
+				Transfers:      NewTransferRepo(),
+				Outbox:         NewOutboxRepo(),
+				Webhooks:       NewWebhookRepo(),
+				UnitOfWork:     NewUnitOfWork(),
+			}
+		},
+	})
+}
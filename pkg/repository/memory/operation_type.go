@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"maps"
+	"slices"
+)
+
+// DefaultOperationTypes are the operation types seeded by the database migrations
+var DefaultOperationTypes = []model.OperationType{
+	{OperationTypeID: 1, Description: "CASH_PURCHASE", IsCredit: false},
+	{OperationTypeID: 2, Description: "INSTALLMENT_PURCHASE", IsCredit: false},
+	{OperationTypeID: 3, Description: "WITHDRAWAL", IsCredit: false},
+	{OperationTypeID: 4, Description: "PAYMENT", IsCredit: true},
+	{OperationTypeID: model.OperationTypeFee, Description: "FEE", IsCredit: false},
+	{OperationTypeID: model.OperationTypeInterest, Description: "INTEREST", IsCredit: false},
+	{OperationTypeID: model.OperationTypeTransferOut, Description: "TRANSFER_OUT", IsCredit: false},
+	{OperationTypeID: model.OperationTypeTransferIn, Description: "TRANSFER_IN", IsCredit: true},
+}
+
+// operationRepo is read only, so it needs no locking
+type operationRepo struct {
+	types map[int]model.OperationType
+}
+
+// NewOperationTypeRepo serves the given operation types, DefaultOperationTypes when none are
+// given
+func NewOperationTypeRepo(types ...model.OperationType) repository.OperationTypeConnector {
+	if len(types) == 0 {
+		types = DefaultOperationTypes
+	}
+
+	o := &operationRepo{types: make(map[int]model.OperationType, len(types))}
+	for _, ot := range types {
+		o.types[ot.OperationTypeID] = ot
+	}
+
+	return o
+}
+
+func (o *operationRepo) Get(ctx context.Context, id int) (model.OperationType, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.OperationType{}, err
+	}
+
+	ot, ok := o.types[id]
+	if !ok {
+		return model.OperationType{}, repository.ErrNoRows
+	}
+
+	return ot, nil
+}
+
+func (o *operationRepo) List(ctx context.Context) ([]model.OperationType, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	return slices.SortedFunc(maps.Values(o.types), func(x, y model.OperationType) int {
+		return cmp.Compare(x.OperationTypeID, y.OperationTypeID)
+	}), nil
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// outboxEvent is a stored event, published is zero until it is published. Events are kept
+// once added as ids are positions, rolledBack hides the events of failed units of work
+type outboxEvent struct {
+	model.OutboxEvent
+	published  time.Time
+	rolledBack bool
+}
+
+type outboxRepo struct {
+	mu     sync.RWMutex
+	events []*outboxEvent
+	uuids  map[string]bool
+}
+
+func NewOutboxRepo() repository.OutboxConnector {
+	return &outboxRepo{
+		uuids: map[string]bool{},
+	}
+}
+
+func (r *outboxRepo) Add(ctx context.Context, e model.Event) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.add(ctx, e)
+
+	return nil
+}
+
+// AddBatch stores the events in the order given, like Add
+func (r *outboxRepo) AddBatch(ctx context.Context, events []model.Event) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range events {
+		r.add(ctx, e)
+	}
+
+	return nil
+}
+
+// add stores the event unless its uuid is taken. The caller holds the lock
+func (r *outboxRepo) add(ctx context.Context, e model.Event) {
+	if r.uuids[e.UUID.String()] {
+		return
+	}
+	r.uuids[e.UUID.String()] = true
+
+	stored := &outboxEvent{OutboxEvent: model.OutboxEvent{
+		Event:         e,
+		ID:            int64(len(r.events) + 1),
+		NextAttemptAt: e.CreatedAt,
+	}}
+	r.events = append(r.events, stored)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		stored.rolledBack = true
+		delete(r.uuids, e.UUID.String())
+	})
+}
+
+func (r *outboxRepo) Pending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// events are kept ordered by id, an account is blocked from its first event not due onwards
+	blocked := map[uuid.UUID]bool{}
+	var events []model.OutboxEvent
+	for _, e := range r.events {
+		if len(events) == limit {
+			break
+		}
+		if !e.published.IsZero() || e.rolledBack {
+			continue
+		}
+		if e.NextAttemptAt.After(now) {
+			blocked[e.AccountUUID] = true
+		}
+		if !blocked[e.AccountUUID] {
+			events = append(events, e.OutboxEvent)
+		}
+	}
+
+	return events, nil
+}
+
+// LockRelay has nothing to lock, units of work run one at a time
+func (r *outboxRepo) LockRelay(ctx context.Context) (bool, error) {
+	if err := ctxError(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e := r.get(id); e != nil {
+		published := e.published
+		e.published = at
+		onRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			e.published = published
+		})
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, _ string) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e := r.get(id); e != nil {
+		attempts, next := e.Attempts, e.NextAttemptAt
+		e.Attempts++
+		e.NextAttemptAt = nextAttemptAt
+		onRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			e.Attempts, e.NextAttemptAt = attempts, next
+		})
+	}
+
+	return nil
+}
+
+// get returns the event with the id unless rolled back, ids are positions in events starting at 1
+func (r *outboxRepo) get(id int64) *outboxEvent {
+	if id < 1 || id > int64(len(r.events)) || r.events[id-1].rolledBack {
+		return nil
+	}
+
+	return r.events[id-1]
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+// DefaultRatePlans are the rate plans seeded by the database migrations
+var DefaultRatePlans = []model.RatePlan{
+	{ID: 1, Description: "STANDARD", APR: 29.99, CompoundDaily: true, GracePeriodDays: 3, LateFee: 10},
+	{ID: 2, Description: "PREFERRED", APR: 19.99, CompoundDaily: false, GracePeriodDays: 5, LateFee: 0},
+}
+
+// ratePlanRepo is read only, so it needs no locking
+type ratePlanRepo struct {
+	plans map[int]model.RatePlan
+}
+
+// NewRatePlanRepo serves the given rate plans, DefaultRatePlans when none are given
+func NewRatePlanRepo(plans ...model.RatePlan) repository.RatePlanConnector {
+	if len(plans) == 0 {
+		plans = DefaultRatePlans
+	}
+
+	r := &ratePlanRepo{plans: make(map[int]model.RatePlan, len(plans))}
+	for _, plan := range plans {
+		r.plans[plan.ID] = plan
+	}
+
+	return r
+}
+
+func (r *ratePlanRepo) Get(ctx context.Context, id int) (model.RatePlan, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.RatePlan{}, err
+	}
+
+	plan, ok := r.plans[id]
+	if !ok {
+		return model.RatePlan{}, repository.ErrNoRows
+	}
+
+	return plan, nil
+}
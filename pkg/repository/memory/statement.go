@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"sync"
+)
+
+type statementRepo struct {
+	mu sync.RWMutex
+	// statements of every account, ordered by period
+	statements map[string][]model.Statement
+}
+
+func NewStatementRepo() repository.StatementConnector {
+	return &statementRepo{
+		statements: map[string][]model.Statement{},
+	}
+}
+
+func (r *statementRepo) Create(ctx context.Context, s model.Statement) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, statements := range r.statements {
+		if slices.ContainsFunc(statements, func(e model.Statement) bool { return e.UUID == s.UUID }) {
+			return repository.ErrDuplicate
+		}
+	}
+
+	statements := r.statements[s.AccountUUID.String()]
+	i, found := slices.BinarySearchFunc(statements, s, func(e, t model.Statement) int {
+		return e.PeriodEnd.Compare(t.PeriodEnd)
+	})
+	if found {
+		return repository.ErrDuplicate
+	}
+
+	// a transaction is listed once per statement
+	trxUUIDs := map[string]bool{}
+	for _, trx := range s.Transactions {
+		if trxUUIDs[trx.UUID.String()] {
+			return repository.ErrDuplicate
+		}
+		trxUUIDs[trx.UUID.String()] = true
+	}
+
+	key := s.AccountUUID.String()
+	s.Transactions = slices.Clone(s.Transactions)
+	r.statements[key] = slices.Insert(statements, i, s)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.statements[key] = slices.DeleteFunc(r.statements[key], func(e model.Statement) bool { return e.UUID == s.UUID })
+	})
+
+	return nil
+}
+
+func (r *statementRepo) Latest(ctx context.Context, accountUUID string) (model.Statement, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.Statement{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statements := r.statements[accountUUID]
+	if len(statements) == 0 {
+		return model.Statement{}, repository.ErrNoRows
+	}
+
+	latest := statements[len(statements)-1]
+	latest.Transactions = nil
+
+	return latest, nil
+}
+
+func (r *statementRepo) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statements := r.statements[accountUUID]
+	list := make([]model.Statement, 0, len(statements))
+	for _, s := range slices.Backward(statements) {
+		s.Transactions = nil
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+func (r *statementRepo) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.Statement{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.statements[accountUUID] {
+		if s.UUID.String() == statementUUID {
+			s.Transactions = slices.Clone(s.Transactions)
+
+			return s, nil
+		}
+	}
+
+	return model.Statement{}, repository.ErrNoRows
+}
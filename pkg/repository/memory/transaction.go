@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+type transactionRepo struct {
+	mu           sync.RWMutex
+	transactions map[string]model.StoredTransaction
+	lastID       int64
+}
+
+func NewTransactionRepo() repository.TransactionConnector {
+	return &transactionRepo{
+		transactions: map[string]model.StoredTransaction{},
+	}
+}
+
+// Create stores the transaction, a transaction with the same uuid is left untouched
+func (t *transactionRepo) Create(ctx context.Context, transaction model.Transaction) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.create(ctx, transaction)
+
+	return nil
+}
+
+// CreateBatch stores the transactions in the order given, like Create
+func (t *transactionRepo) CreateBatch(ctx context.Context, transactions []model.Transaction) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, transaction := range transactions {
+		t.create(ctx, transaction)
+	}
+
+	return nil
+}
+
+// create stores the transaction unless its uuid is taken, ids are not reused once rolled back
+// like the ids of a sequence. The caller holds the lock
+func (t *transactionRepo) create(ctx context.Context, transaction model.Transaction) {
+	key := transaction.UUID.String()
+	if _, ok := t.transactions[key]; ok {
+		return
+	}
+
+	t.lastID++
+	t.transactions[key] = model.StoredTransaction{Transaction: transaction, ID: t.lastID}
+	onRollback(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.transactions, key)
+	})
+}
+
+func (t *transactionRepo) CheckIdempotency(ctx context.Context, trxUUID string) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.transactions[trxUUID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (t *transactionRepo) ListExisting(ctx context.Context, trxUUIDs []string) ([]string, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var existing []string
+	for _, trxUUID := range trxUUIDs {
+		if _, ok := t.transactions[trxUUID]; ok {
+			existing = append(existing, trxUUID)
+		}
+	}
+
+	return existing, nil
+}
+
+func (t *transactionRepo) ListByPeriod(ctx context.Context, accountUUID string, from, to time.Time) ([]model.Transaction, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var transactions []model.Transaction
+	for _, trx := range t.transactions {
+		if trx.AccountUUID.String() == accountUUID && !trx.EventDate.Before(from) && trx.EventDate.Before(to) {
+			transactions = append(transactions, trx.Transaction)
+		}
+	}
+	slices.SortFunc(transactions, func(x, y model.Transaction) int {
+		return cmp.Or(x.EventDate.Compare(y.EventDate), cmp.Compare(x.UUID.String(), y.UUID.String()))
+	})
+
+	return transactions, nil
+}
+
+func (t *transactionRepo) ListAfter(ctx context.Context, accountUUID string, afterID int64, limit int) ([]model.StoredTransaction, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var transactions []model.StoredTransaction
+	for _, trx := range t.transactions {
+		if trx.AccountUUID.String() == accountUUID && trx.ID > afterID {
+			transactions = append(transactions, trx)
+		}
+	}
+	slices.SortFunc(transactions, func(x, y model.StoredTransaction) int {
+		return cmp.Compare(x.ID, y.ID)
+	})
+
+	return transactions[:min(limit, len(transactions))], nil
+}
+
+func (t *transactionRepo) LatestID(ctx context.Context, accountUUID string) (int64, error) {
+	if err := ctxError(ctx); err != nil {
+		return 0, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var id int64
+	for _, trx := range t.transactions {
+		if trx.AccountUUID.String() == accountUUID {
+			id = max(id, trx.ID)
+		}
+	}
+
+	return id, nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"sync"
+)
+
+type transferRepo struct {
+	mu        sync.RWMutex
+	transfers map[string]model.Transfer
+}
+
+func NewTransferRepo() repository.TransferConnector {
+	return &transferRepo{
+		transfers: map[string]model.Transfer{},
+	}
+}
+
+func (r *transferRepo) Create(ctx context.Context, t model.Transfer) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.transfers[t.UUID.String()]; ok {
+		return repository.ErrDuplicate
+	}
+	key := t.UUID.String()
+	r.transfers[key] = t
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		delete(r.transfers, key)
+	})
+
+	return nil
+}
+
+func (r *transferRepo) CheckIdempotency(ctx context.Context, transferUUID string) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.transfers[transferUUID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
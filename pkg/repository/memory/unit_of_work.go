@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"go-pismo-challenge/pkg/repository"
+	"sync"
+)
+
+type unitOfWork struct {
+	// mu serialises the units of work, so that what one reads cannot change before it commits
+	mu sync.Mutex
+}
+
+// NewUnitOfWork runs units of work on the memory repositories. Changes made within a unit of
+// work are undone when fn fails or panics, like a rolled back transaction. Units of work run one
+// at a time, which isolates them like serializable transactions, and nested units of work join
+// the outer one. Changes made outside a unit of work are not isolated
+func NewUnitOfWork() repository.UnitOfWork {
+	return &unitOfWork{}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) (err error) {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Value(journalKey{}).(*journal); ok {
+		return fn(ctx)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	j := &journal{}
+	defer func() {
+		if p := recover(); p != nil {
+			j.rollback()
+			panic(p)
+		}
+		if err != nil {
+			j.rollback()
+		}
+	}()
+
+	return fn(context.WithValue(ctx, journalKey{}, j))
+}
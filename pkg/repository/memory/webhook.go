@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+// webhookDelivery is a queued delivery, delivered is zero until it is delivered. Deliveries
+// are kept once queued as ids are positions, rolledBack hides the deliveries of failed units of
+// work
+type webhookDelivery struct {
+	model.WebhookDelivery
+	delivered  time.Time
+	rolledBack bool
+}
+
+// webhookAttempt is an entry of the delivery log
+type webhookAttempt struct {
+	model.WebhookDeliveryAttempt
+	webhookUUID string
+}
+
+type webhookRepo struct {
+	mu         sync.RWMutex
+	webhooks   []*model.Webhook
+	deliveries []*webhookDelivery
+	queued     map[string]bool
+	attempts   []*webhookAttempt
+}
+
+func NewWebhookRepo() repository.WebhookConnector {
+	return &webhookRepo{
+		queued: map[string]bool{},
+	}
+}
+
+func (r *webhookRepo) Create(ctx context.Context, w model.Webhook) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.get(w.UUID.String()) != nil {
+		return repository.ErrDuplicate
+	}
+	w.EventTypes = slices.Clone(w.EventTypes)
+	stored := &w
+	r.webhooks = append(r.webhooks, stored)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.webhooks = slices.DeleteFunc(r.webhooks, func(w *model.Webhook) bool { return w == stored })
+	})
+
+	return nil
+}
+
+func (r *webhookRepo) CheckIdempotency(ctx context.Context, webhookUUID string) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.get(webhookUUID) != nil {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Get(ctx context.Context, uuid string) (model.Webhook, error) {
+	if err := ctxError(ctx); err != nil {
+		return model.Webhook{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w := r.get(uuid)
+	if w == nil {
+		return model.Webhook{}, repository.ErrNoRows
+	}
+
+	return copyWebhook(w), nil
+}
+
+func (r *webhookRepo) ListEnabled(ctx context.Context) ([]model.Webhook, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var webhooks []model.Webhook
+	for _, w := range r.webhooks {
+		if w.Enabled {
+			webhooks = append(webhooks, copyWebhook(w))
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepo) UpdateStatus(ctx context.Context, w model.Webhook) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored := r.get(w.UUID.String()); stored != nil {
+		previous := *stored
+		stored.Enabled = w.Enabled
+		stored.ConsecutiveFailures = w.ConsecutiveFailures
+		stored.DisabledAt = w.DisabledAt
+		onRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			*stored = previous
+		})
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Enqueue(ctx context.Context, d model.WebhookDelivery) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := d.WebhookUUID.String() + "/" + d.EventUUID.String()
+	if r.queued[key] {
+		return nil
+	}
+	r.queued[key] = true
+
+	d.ID = int64(len(r.deliveries) + 1)
+	stored := &webhookDelivery{WebhookDelivery: d}
+	r.deliveries = append(r.deliveries, stored)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		stored.rolledBack = true
+		delete(r.queued, key)
+	})
+
+	return nil
+}
+
+func (r *webhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []model.WebhookDelivery
+	for _, d := range r.deliveries {
+		if len(deliveries) == limit {
+			break
+		}
+		if !d.delivered.IsZero() || d.rolledBack || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if w := r.get(d.WebhookUUID.String()); w != nil && w.Enabled {
+			deliveries = append(deliveries, d.WebhookDelivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepo) RecordAttempt(ctx context.Context, d model.WebhookDelivery, a model.WebhookDeliveryAttempt) error {
+	if err := ctxError(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d.ID >= 1 && d.ID <= int64(len(r.deliveries)) && !r.deliveries[d.ID-1].rolledBack {
+		stored := r.deliveries[d.ID-1]
+		previous := *stored
+		stored.Attempts = d.Attempts
+		stored.NextAttemptAt = d.NextAttemptAt
+		if a.Succeeded {
+			stored.delivered = a.AttemptedAt
+		}
+		onRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			*stored = previous
+		})
+	}
+
+	attempt := &webhookAttempt{WebhookDeliveryAttempt: a, webhookUUID: d.WebhookUUID.String()}
+	r.attempts = append(r.attempts, attempt)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.attempts = slices.DeleteFunc(r.attempts, func(a *webhookAttempt) bool { return a == attempt })
+	})
+
+	return nil
+}
+
+func (r *webhookRepo) ListAttempts(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDeliveryAttempt, error) {
+	if err := ctxError(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attempts []model.WebhookDeliveryAttempt
+	for _, a := range slices.Backward(r.attempts) {
+		if len(attempts) == limit {
+			break
+		}
+		if a.webhookUUID == webhookUUID {
+			attempts = append(attempts, a.WebhookDeliveryAttempt)
+		}
+	}
+
+	return attempts, nil
+}
+
+// get returns the stored webhook with the uuid, or nil
+func (r *webhookRepo) get(uuid string) *model.Webhook {
+	for _, w := range r.webhooks {
+		if w.UUID.String() == uuid {
+			return w
+		}
+	}
+
+	return nil
+}
+
+// copyWebhook returns a copy of the stored webhook that callers can modify
+func copyWebhook(w *model.Webhook) model.Webhook {
+	c := *w
+	c.EventTypes = slices.Clone(w.EventTypes)
+
+	return c
+}
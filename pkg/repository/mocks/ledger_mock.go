@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ledger.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/ledger_mock.go -source=ledger.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLedgerConnector is a mock of LedgerConnector interface.
+type MockLedgerConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockLedgerConnectorMockRecorder is the mock recorder for MockLedgerConnector.
+type MockLedgerConnectorMockRecorder struct {
+	mock *MockLedgerConnector
+}
+
+// NewMockLedgerConnector creates a new mock instance.
+func NewMockLedgerConnector(ctrl *gomock.Controller) *MockLedgerConnector {
+	mock := &MockLedgerConnector{ctrl: ctrl}
+	mock.recorder = &MockLedgerConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerConnector) EXPECT() *MockLedgerConnectorMockRecorder {
+	return m.recorder
+}
+
+// Balance mocks base method.
+func (m *MockLedgerConnector) Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Balance", ctx, accountUUID, ledgerAccountID, before)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Balance indicates an expected call of Balance.
+func (mr *MockLedgerConnectorMockRecorder) Balance(ctx, accountUUID, ledgerAccountID, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Balance", reflect.TypeOf((*MockLedgerConnector)(nil).Balance), ctx, accountUUID, ledgerAccountID, before)
+}
+
+// Post mocks base method.
+func (m *MockLedgerConnector) Post(ctx context.Context, entries []model.JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Post", ctx, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Post indicates an expected call of Post.
+func (mr *MockLedgerConnectorMockRecorder) Post(ctx, entries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockLedgerConnector)(nil).Post), ctx, entries)
+}
+
+// TrialBalance mocks base method.
+func (m *MockLedgerConnector) TrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrialBalance", ctx)
+	ret0, _ := ret[0].([]model.TrialBalanceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrialBalance indicates an expected call of TrialBalance.
+func (mr *MockLedgerConnectorMockRecorder) TrialBalance(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrialBalance", reflect.TypeOf((*MockLedgerConnector)(nil).TrialBalance), ctx)
+}
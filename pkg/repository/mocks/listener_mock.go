@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: listener.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/listener_mock.go -source=listener.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTransactionListener is a mock of TransactionListener interface.
+type MockTransactionListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionListenerMockRecorder
+	isgomock struct{}
+}
+
+// MockTransactionListenerMockRecorder is the mock recorder for MockTransactionListener.
+type MockTransactionListenerMockRecorder struct {
+	mock *MockTransactionListener
+}
+
+// NewMockTransactionListener creates a new mock instance.
+func NewMockTransactionListener(ctrl *gomock.Controller) *MockTransactionListener {
+	mock := &MockTransactionListener{ctrl: ctrl}
+	mock.recorder = &MockTransactionListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionListener) EXPECT() *MockTransactionListenerMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockTransactionListener) Listen(ctx context.Context, notify func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx, notify)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockTransactionListenerMockRecorder) Listen(ctx, notify any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockTransactionListener)(nil).Listen), ctx, notify)
+}
@@ -55,3 +55,18 @@ func (mr *MockOperationTypeConnectorMockRecorder) Get(ctx, id any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOperationTypeConnector)(nil).Get), ctx, id)
 }
+
+// List mocks base method.
+func (m *MockOperationTypeConnector) List(ctx context.Context) ([]model.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]model.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOperationTypeConnectorMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOperationTypeConnector)(nil).List), ctx)
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/outbox_mock.go -source=outbox.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxConnector is a mock of OutboxConnector interface.
+type MockOutboxConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxConnectorMockRecorder is the mock recorder for MockOutboxConnector.
+type MockOutboxConnectorMockRecorder struct {
+	mock *MockOutboxConnector
+}
+
+// NewMockOutboxConnector creates a new mock instance.
+func NewMockOutboxConnector(ctrl *gomock.Controller) *MockOutboxConnector {
+	mock := &MockOutboxConnector{ctrl: ctrl}
+	mock.recorder = &MockOutboxConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxConnector) EXPECT() *MockOutboxConnectorMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockOutboxConnector) Add(ctx context.Context, e model.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockOutboxConnectorMockRecorder) Add(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockOutboxConnector)(nil).Add), ctx, e)
+}
+
+// AddBatch mocks base method.
+func (m *MockOutboxConnector) AddBatch(ctx context.Context, events []model.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBatch", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockOutboxConnectorMockRecorder) AddBatch(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockOutboxConnector)(nil).AddBatch), ctx, events)
+}
+
+// LockRelay mocks base method.
+func (m *MockOutboxConnector) LockRelay(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockRelay", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockRelay indicates an expected call of LockRelay.
+func (mr *MockOutboxConnectorMockRecorder) LockRelay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockRelay", reflect.TypeOf((*MockOutboxConnector)(nil).LockRelay), ctx)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxConnector) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, id, nextAttemptAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxConnectorMockRecorder) MarkFailed(ctx, id, nextAttemptAt, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxConnector)(nil).MarkFailed), ctx, id, nextAttemptAt, lastError)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxConnector) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxConnectorMockRecorder) MarkPublished(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxConnector)(nil).MarkPublished), ctx, id, at)
+}
+
+// Pending mocks base method.
+func (m *MockOutboxConnector) Pending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, now, limit)
+	ret0, _ := ret[0].([]model.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockOutboxConnectorMockRecorder) Pending(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockOutboxConnector)(nil).Pending), ctx, now, limit)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rate_plan.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/rate_plan_mock.go -source=rate_plan.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRatePlanConnector is a mock of RatePlanConnector interface.
+type MockRatePlanConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockRatePlanConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockRatePlanConnectorMockRecorder is the mock recorder for MockRatePlanConnector.
+type MockRatePlanConnectorMockRecorder struct {
+	mock *MockRatePlanConnector
+}
+
+// NewMockRatePlanConnector creates a new mock instance.
+func NewMockRatePlanConnector(ctrl *gomock.Controller) *MockRatePlanConnector {
+	mock := &MockRatePlanConnector{ctrl: ctrl}
+	mock.recorder = &MockRatePlanConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRatePlanConnector) EXPECT() *MockRatePlanConnectorMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockRatePlanConnector) Get(ctx context.Context, id int) (model.RatePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(model.RatePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRatePlanConnectorMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRatePlanConnector)(nil).Get), ctx, id)
+}
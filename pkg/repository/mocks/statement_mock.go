@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: statement.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/statement_mock.go -source=statement.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStatementConnector is a mock of StatementConnector interface.
+type MockStatementConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockStatementConnectorMockRecorder is the mock recorder for MockStatementConnector.
+type MockStatementConnectorMockRecorder struct {
+	mock *MockStatementConnector
+}
+
+// NewMockStatementConnector creates a new mock instance.
+func NewMockStatementConnector(ctrl *gomock.Controller) *MockStatementConnector {
+	mock := &MockStatementConnector{ctrl: ctrl}
+	mock.recorder = &MockStatementConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementConnector) EXPECT() *MockStatementConnectorMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockStatementConnector) Create(ctx context.Context, s model.Statement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockStatementConnectorMockRecorder) Create(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStatementConnector)(nil).Create), ctx, s)
+}
+
+// Get mocks base method.
+func (m *MockStatementConnector) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, accountUUID, statementUUID)
+	ret0, _ := ret[0].(model.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatementConnectorMockRecorder) Get(ctx, accountUUID, statementUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatementConnector)(nil).Get), ctx, accountUUID, statementUUID)
+}
+
+// Latest mocks base method.
+func (m *MockStatementConnector) Latest(ctx context.Context, accountUUID string) (model.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Latest", ctx, accountUUID)
+	ret0, _ := ret[0].(model.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Latest indicates an expected call of Latest.
+func (mr *MockStatementConnectorMockRecorder) Latest(ctx, accountUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Latest", reflect.TypeOf((*MockStatementConnector)(nil).Latest), ctx, accountUUID)
+}
+
+// List mocks base method.
+func (m *MockStatementConnector) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, accountUUID)
+	ret0, _ := ret[0].([]model.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStatementConnectorMockRecorder) List(ctx, accountUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStatementConnector)(nil).List), ctx, accountUUID)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+	isgomock struct{}
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	model "go-pismo-challenge/pkg/model"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -68,3 +69,77 @@ func (mr *MockTransactionConnectorMockRecorder) Create(ctx, a any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransactionConnector)(nil).Create), ctx, a)
 }
+
+// CreateBatch mocks base method.
+func (m *MockTransactionConnector) CreateBatch(ctx context.Context, transactions []model.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, transactions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockTransactionConnectorMockRecorder) CreateBatch(ctx, transactions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockTransactionConnector)(nil).CreateBatch), ctx, transactions)
+}
+
+// LatestID mocks base method.
+func (m *MockTransactionConnector) LatestID(ctx context.Context, accountUUID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestID", ctx, accountUUID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestID indicates an expected call of LatestID.
+func (mr *MockTransactionConnectorMockRecorder) LatestID(ctx, accountUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestID", reflect.TypeOf((*MockTransactionConnector)(nil).LatestID), ctx, accountUUID)
+}
+
+// ListAfter mocks base method.
+func (m *MockTransactionConnector) ListAfter(ctx context.Context, accountUUID string, afterID int64, limit int) ([]model.StoredTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, accountUUID, afterID, limit)
+	ret0, _ := ret[0].([]model.StoredTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockTransactionConnectorMockRecorder) ListAfter(ctx, accountUUID, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockTransactionConnector)(nil).ListAfter), ctx, accountUUID, afterID, limit)
+}
+
+// ListByPeriod mocks base method.
+func (m *MockTransactionConnector) ListByPeriod(ctx context.Context, accountUUID string, from, to time.Time) ([]model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPeriod", ctx, accountUUID, from, to)
+	ret0, _ := ret[0].([]model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPeriod indicates an expected call of ListByPeriod.
+func (mr *MockTransactionConnectorMockRecorder) ListByPeriod(ctx, accountUUID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPeriod", reflect.TypeOf((*MockTransactionConnector)(nil).ListByPeriod), ctx, accountUUID, from, to)
+}
+
+// ListExisting mocks base method.
+func (m *MockTransactionConnector) ListExisting(ctx context.Context, trxUUIDs []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExisting", ctx, trxUUIDs)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExisting indicates an expected call of ListExisting.
+func (mr *MockTransactionConnectorMockRecorder) ListExisting(ctx, trxUUIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExisting", reflect.TypeOf((*MockTransactionConnector)(nil).ListExisting), ctx, trxUUIDs)
+}
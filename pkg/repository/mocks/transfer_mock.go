@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transfer.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/transfer_mock.go -source=transfer.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTransferConnector is a mock of TransferConnector interface.
+type MockTransferConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockTransferConnectorMockRecorder is the mock recorder for MockTransferConnector.
+type MockTransferConnectorMockRecorder struct {
+	mock *MockTransferConnector
+}
+
+// NewMockTransferConnector creates a new mock instance.
+func NewMockTransferConnector(ctrl *gomock.Controller) *MockTransferConnector {
+	mock := &MockTransferConnector{ctrl: ctrl}
+	mock.recorder = &MockTransferConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferConnector) EXPECT() *MockTransferConnectorMockRecorder {
+	return m.recorder
+}
+
+// CheckIdempotency mocks base method.
+func (m *MockTransferConnector) CheckIdempotency(ctx context.Context, transferUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckIdempotency", ctx, transferUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckIdempotency indicates an expected call of CheckIdempotency.
+func (mr *MockTransferConnectorMockRecorder) CheckIdempotency(ctx, transferUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIdempotency", reflect.TypeOf((*MockTransferConnector)(nil).CheckIdempotency), ctx, transferUUID)
+}
+
+// Create mocks base method.
+func (m *MockTransferConnector) Create(ctx context.Context, t model.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTransferConnectorMockRecorder) Create(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransferConnector)(nil).Create), ctx, t)
+}
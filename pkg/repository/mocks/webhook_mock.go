@@ -0,0 +1,173 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/webhook_mock.go -source=webhook.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhookConnector is a mock of WebhookConnector interface.
+type MockWebhookConnector struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookConnectorMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookConnectorMockRecorder is the mock recorder for MockWebhookConnector.
+type MockWebhookConnectorMockRecorder struct {
+	mock *MockWebhookConnector
+}
+
+// NewMockWebhookConnector creates a new mock instance.
+func NewMockWebhookConnector(ctrl *gomock.Controller) *MockWebhookConnector {
+	mock := &MockWebhookConnector{ctrl: ctrl}
+	mock.recorder = &MockWebhookConnectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookConnector) EXPECT() *MockWebhookConnectorMockRecorder {
+	return m.recorder
+}
+
+// CheckIdempotency mocks base method.
+func (m *MockWebhookConnector) CheckIdempotency(ctx context.Context, webhookUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckIdempotency", ctx, webhookUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckIdempotency indicates an expected call of CheckIdempotency.
+func (mr *MockWebhookConnectorMockRecorder) CheckIdempotency(ctx, webhookUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIdempotency", reflect.TypeOf((*MockWebhookConnector)(nil).CheckIdempotency), ctx, webhookUUID)
+}
+
+// Create mocks base method.
+func (m *MockWebhookConnector) Create(ctx context.Context, w model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookConnectorMockRecorder) Create(ctx, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookConnector)(nil).Create), ctx, w)
+}
+
+// DueDeliveries mocks base method.
+func (m *MockWebhookConnector) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueDeliveries indicates an expected call of DueDeliveries.
+func (mr *MockWebhookConnectorMockRecorder) DueDeliveries(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueDeliveries", reflect.TypeOf((*MockWebhookConnector)(nil).DueDeliveries), ctx, now, limit)
+}
+
+// Enqueue mocks base method.
+func (m *MockWebhookConnector) Enqueue(ctx context.Context, d model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockWebhookConnectorMockRecorder) Enqueue(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockWebhookConnector)(nil).Enqueue), ctx, d)
+}
+
+// Get mocks base method.
+func (m *MockWebhookConnector) Get(ctx context.Context, uuid string) (model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, uuid)
+	ret0, _ := ret[0].(model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWebhookConnectorMockRecorder) Get(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWebhookConnector)(nil).Get), ctx, uuid)
+}
+
+// ListAttempts mocks base method.
+func (m *MockWebhookConnector) ListAttempts(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDeliveryAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttempts", ctx, webhookUUID, limit)
+	ret0, _ := ret[0].([]model.WebhookDeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttempts indicates an expected call of ListAttempts.
+func (mr *MockWebhookConnectorMockRecorder) ListAttempts(ctx, webhookUUID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttempts", reflect.TypeOf((*MockWebhookConnector)(nil).ListAttempts), ctx, webhookUUID, limit)
+}
+
+// ListEnabled mocks base method.
+func (m *MockWebhookConnector) ListEnabled(ctx context.Context) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEnabled", ctx)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEnabled indicates an expected call of ListEnabled.
+func (mr *MockWebhookConnectorMockRecorder) ListEnabled(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnabled", reflect.TypeOf((*MockWebhookConnector)(nil).ListEnabled), ctx)
+}
+
+// RecordAttempt mocks base method.
+func (m *MockWebhookConnector) RecordAttempt(ctx context.Context, d model.WebhookDelivery, a model.WebhookDeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", ctx, d, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *MockWebhookConnectorMockRecorder) RecordAttempt(ctx, d, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockWebhookConnector)(nil).RecordAttempt), ctx, d, a)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockWebhookConnector) UpdateStatus(ctx context.Context, w model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockWebhookConnectorMockRecorder) UpdateStatus(ctx, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockWebhookConnector)(nil).UpdateStatus), ctx, w)
+}
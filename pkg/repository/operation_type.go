@@ -15,6 +15,8 @@ type operationRepo struct {
 //go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/operation_type_mock.go -source=operation_type.go
 type OperationTypeConnector interface {
 	Get(ctx context.Context, id int) (model.OperationType, error)
+	// List returns all operation types ordered by id
+	List(ctx context.Context) ([]model.OperationType, error)
 }
 
 func NewOperationTypeRepo(db *sql.DB) OperationTypeConnector {
@@ -24,23 +26,54 @@ func NewOperationTypeRepo(db *sql.DB) OperationTypeConnector {
 }
 
 func (o *operationRepo) Get(ctx context.Context, id int) (model.OperationType, error) {
-	checkIdempotencySQL := `SELECT operation_type_id, is_credit FROM transactions.operation_types WHERE operation_type_id = $1;`
+	checkIdempotencySQL := `SELECT operation_type_id, description, is_credit FROM transactions.operation_types WHERE operation_type_id = $1;`
 
-	rows := o.db.QueryRowContext(ctx, checkIdempotencySQL, id)
+	ctx, span := startSpan(ctx, "operation_type.get", checkIdempotencySQL)
+	defer span.End()
+
+	rows := Conn(ctx, o.db).QueryRowContext(ctx, checkIdempotencySQL, id)
 	if rows.Err() != nil {
-		return model.OperationType{}, fmt.Errorf("failed to query: %w", rows.Err())
+		return model.OperationType{}, RecordError(span, fmt.Errorf("failed to query: %w", DBError(ctx, rows.Err())))
 	}
 	var ot model.OperationType
 	if err := rows.Scan(
 		&ot.OperationTypeID,
+		&ot.Description,
 		&ot.IsCredit,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return model.OperationType{}, ErrNoRows
 		}
 
-		return model.OperationType{}, fmt.Errorf("failed to scan while idempotency check: %w", err)
+		return model.OperationType{}, RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", DBError(ctx, err)))
 	}
 
 	return ot, nil
 }
+
+func (o *operationRepo) List(ctx context.Context) ([]model.OperationType, error) {
+	listOperationTypesSQL := `SELECT operation_type_id, description, is_credit FROM transactions.operation_types ORDER BY operation_type_id;`
+
+	ctx, span := startSpan(ctx, "operation_type.list", listOperationTypesSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, o.db).QueryContext(ctx, listOperationTypesSQL)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query operation types: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var types []model.OperationType
+	for rows.Next() {
+		var ot model.OperationType
+		if err := rows.Scan(&ot.OperationTypeID, &ot.Description, &ot.IsCredit); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan operation type: %w", DBError(ctx, err)))
+		}
+		types = append(types, ot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate operation types: %w", DBError(ctx, err)))
+	}
+
+	return types, nil
+}
@@ -39,18 +39,22 @@ func (s *operationTypeSuite) TestGetOperationTypeSuccess() {
 
 	expected := model.OperationType{
 		OperationTypeID: 1,
+		Description:     "CASH_PURCHASE",
 		IsCredit:        true,
 	}
-	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT operation_type_id, is_credit FROM transactions.operation_types WHERE operation_type_id = $1;`)).
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT operation_type_id, description, is_credit FROM transactions.operation_types
+					WHERE operation_type_id = $1;`)).
 		WithArgs(expected.OperationTypeID).
 		WillReturnRows(
 			sqlmock.NewRows(
 				[]string{
 					"operation_type_id",
+					"description",
 					"is_credit",
 				}).
 				AddRow(
 					expected.OperationTypeID,
+					expected.Description,
 					expected.IsCredit,
 				))
 
@@ -63,7 +67,8 @@ func (s *operationTypeSuite) TestGetOperationTypeError() {
 	ctx := context.Background()
 	mockError := errors.New("db error")
 
-	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT operation_type_id, is_credit FROM transactions.operation_types WHERE operation_type_id = $1;`)).
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT operation_type_id, description, is_credit FROM transactions.operation_types
+					WHERE operation_type_id = $1;`)).
 		WithArgs(1).
 		WillReturnError(mockError)
 
@@ -72,3 +77,22 @@ func (s *operationTypeSuite) TestGetOperationTypeError() {
 	s.True(errors.Is(err, mockError))
 	s.Equal(got, model.OperationType{})
 }
+
+func (s *operationTypeSuite) TestListSuccess() {
+	expected := []model.OperationType{
+		{OperationTypeID: 1, Description: "CASH_PURCHASE"},
+		{OperationTypeID: 4, Description: "PAYMENT", IsCredit: true},
+	}
+
+	rows := sqlmock.NewRows([]string{"operation_type_id", "description", "is_credit"})
+	for _, ot := range expected {
+		rows.AddRow(ot.OperationTypeID, ot.Description, ot.IsCredit)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT operation_type_id, description, is_credit FROM transactions.operation_types
+					ORDER BY operation_type_id;`)).
+		WillReturnRows(rows)
+
+	got, err := s.repo.List(context.Background())
+	s.NoError(err)
+	s.Equal(expected, got)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"slices"
+	"strings"
+	"time"
+)
+
+// outboxRelayLockID is the Postgres advisory lock key of the outbox relays, distinct from the one
+// of the migrators
+const outboxRelayLockID int64 = 0x5049534E // "PISN"
+
+type outboxRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/outbox_mock.go -source=outbox.go
+type OutboxConnector interface {
+	// Add stores the event until it is published, it has to run in the unit of work creating the
+	// entity of the event for both to be stored atomically. Events added before are left untouched
+	Add(ctx context.Context, e model.Event) error
+	// AddBatch stores the events like Add with multi-row inserts, their ids follow the order given
+	AddBatch(ctx context.Context, events []model.Event) error
+	// Pending returns up to limit unpublished events ordered by id. Events of an account with an
+	// unpublished event not due for another attempt until after now are left out, so that the
+	// events of an account are published in order
+	Pending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error)
+	// LockRelay takes the lock of the outbox relay until the end of the running unit of work, and
+	// returns false without waiting if another relay holds it. A relay reads, publishes and marks
+	// pending events holding the lock, so that relays of several instances do not publish the same
+	// events twice or the events of an account out of order. It has to run in a unit of work
+	LockRelay(ctx context.Context) (bool, error)
+	MarkPublished(ctx context.Context, id int64, at time.Time) error
+	// MarkFailed counts a failed attempt to publish the event, which is due again at nextAttemptAt
+	MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+}
+
+func NewOutboxRepo(db *sql.DB) OutboxConnector {
+	return &outboxRepo{
+		db,
+	}
+}
+
+// Add stores the event, times are stored in UTC as the columns have no time zone
+func (r *outboxRepo) Add(ctx context.Context, e model.Event) error {
+	insertSQL := `INSERT INTO outbox.event (uuid, type, account_uuid, payload, created_at, next_attempt_at)
+					values ($1, $2, $3, $4, $5, $5) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "outbox.add", insertSQL)
+	defer span.End()
+
+	_, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		e.UUID.String(),
+		e.Type,
+		e.AccountUUID.String(),
+		string(e.Data),
+		e.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert outbox event: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+// AddBatch stores the events batchRows at a time, in UTC like Add
+func (r *outboxRepo) AddBatch(ctx context.Context, events []model.Event) error {
+	insertSQL := `INSERT INTO outbox.event (uuid, type, account_uuid, payload, created_at, next_attempt_at) values `
+
+	ctx, span := startSpan(ctx, "outbox.add_batch", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(events, batchRows) {
+		values := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*5)
+		for _, e := range chunk {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+5))
+			args = append(args, e.UUID.String(), e.Type, e.AccountUUID.String(), string(e.Data), e.CreatedAt.UTC())
+		}
+
+		_, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL+strings.Join(values, ", ")+" ON CONFLICT (uuid) DO NOTHING;", args...)
+		if err != nil {
+			return RecordError(span, fmt.Errorf("failed to insert outbox events: %w", DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) Pending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	pendingSQL := `SELECT e.id, e.uuid, e.type, e.account_uuid, e.payload, e.created_at, e.attempts, e.next_attempt_at
+					FROM outbox.event e WHERE e.published_at IS NULL AND NOT EXISTS (
+						SELECT 1 FROM outbox.event b WHERE b.account_uuid = e.account_uuid AND b.published_at IS NULL
+						AND b.id <= e.id AND b.next_attempt_at > $1)
+					ORDER BY e.id LIMIT $2;`
+
+	ctx, span := startSpan(ctx, "outbox.pending", pendingSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, pendingSQL, now.UTC(), limit)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query outbox events: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var events []model.OutboxEvent
+	for rows.Next() {
+		var e model.OutboxEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.UUID,
+			&e.Type,
+			&e.AccountUUID,
+			&e.Data,
+			&e.CreatedAt,
+			&e.Attempts,
+			&e.NextAttemptAt,
+		); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan outbox event: %w", DBError(ctx, err)))
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate outbox events: %w", DBError(ctx, err)))
+	}
+
+	return events, nil
+}
+
+// LockRelay takes a transaction level advisory lock, released when the unit of work ends
+func (r *outboxRepo) LockRelay(ctx context.Context) (bool, error) {
+	lockSQL := `SELECT pg_try_advisory_xact_lock($1);`
+
+	ctx, span := startSpan(ctx, "outbox.lock_relay", lockSQL)
+	defer span.End()
+
+	var locked bool
+	if err := Conn(ctx, r.db).QueryRowContext(ctx, lockSQL, outboxRelayLockID).Scan(&locked); err != nil {
+		return false, RecordError(span, fmt.Errorf("failed to lock outbox relay: %w", DBError(ctx, err)))
+	}
+
+	return locked, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	updateSQL := `UPDATE outbox.event SET published_at = $2 WHERE id = $1;`
+
+	ctx, span := startSpan(ctx, "outbox.mark_published", updateSQL)
+	defer span.End()
+
+	if _, err := Conn(ctx, r.db).ExecContext(ctx, updateSQL, id, at.UTC()); err != nil {
+		return RecordError(span, fmt.Errorf("failed to mark outbox event published: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	updateSQL := `UPDATE outbox.event SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1;`
+
+	ctx, span := startSpan(ctx, "outbox.mark_failed", updateSQL)
+	defer span.End()
+
+	if _, err := Conn(ctx, r.db).ExecContext(ctx, updateSQL, id, nextAttemptAt.UTC(), lastError); err != nil {
+		return RecordError(span, fmt.Errorf("failed to mark outbox event failed: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type outboxSuite struct {
+	suite.Suite
+	repo OutboxConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestOutbox(t *testing.T) {
+	suite.Run(t, new(outboxSuite))
+}
+
+func (s *outboxSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewOutboxRepo(db)
+	s.db = mock
+}
+
+func (s *outboxSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func mockEvent() model.Event {
+	return model.Event{
+		UUID:        uuid.NewV5(uuid.Nil, "event"),
+		Type:        model.EventTransactionCreated,
+		AccountUUID: uuid.NewV5(uuid.Nil, "account"),
+		Data:        []byte(`{"amount":-60.5}`),
+		CreatedAt:   time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+}
+
+func (s *outboxSuite) TestAddSuccess() {
+	event := mockEvent()
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO outbox.event (uuid, type, account_uuid, payload, created_at, next_attempt_at)
+					values ($1, $2, $3, $4, $5, $5) ON CONFLICT (uuid) DO NOTHING;`)).
+		WithArgs(event.UUID.String(), event.Type, event.AccountUUID.String(), string(event.Data), event.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// the time is stored in UTC
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC-3", -3*60*60))
+	s.NoError(s.repo.Add(context.Background(), event))
+}
+
+func (s *outboxSuite) TestAddError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO outbox.event`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.Add(context.Background(), mockEvent()), mockError)
+}
+
+func (s *outboxSuite) TestAddBatchSuccess() {
+	first, second := mockEvent(), mockEvent()
+	second.UUID = uuid.NewV5(uuid.Nil, "second")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO outbox.event (uuid, type, account_uuid, payload, created_at, next_attempt_at) `+
+		`values ($1, $2, $3, $4, $5, $5), ($6, $7, $8, $9, $10, $10) ON CONFLICT (uuid) DO NOTHING;`)).
+		WithArgs(
+			first.UUID.String(), first.Type, first.AccountUUID.String(), string(first.Data), first.CreatedAt,
+			second.UUID.String(), second.Type, second.AccountUUID.String(), string(second.Data), second.CreatedAt,
+		).WillReturnResult(sqlmock.NewResult(2, 2))
+
+	s.NoError(s.repo.AddBatch(context.Background(), []model.Event{first, second}))
+}
+
+// Batches larger than batchRows are inserted batchRows at a time
+func (s *outboxSuite) TestAddBatchChunked() {
+	events := make([]model.Event, batchRows+1)
+	for i := range events {
+		events[i] = mockEvent()
+		events[i].UUID = uuid.NewV5(uuid.Nil, strconv.Itoa(i))
+	}
+
+	s.db.ExpectExec(regexp.QuoteMeta(`($2496, $2497, $2498, $2499, $2500, $2500) ON CONFLICT`)).WillReturnResult(sqlmock.NewResult(0, batchRows))
+	s.db.ExpectExec(regexp.QuoteMeta(`values ($1, $2, $3, $4, $5, $5) ON CONFLICT`)).
+		WithArgs(events[batchRows].UUID.String(), model.EventTransactionCreated, events[batchRows].AccountUUID.String(),
+			string(events[batchRows].Data), events[batchRows].CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.AddBatch(context.Background(), events))
+}
+
+func (s *outboxSuite) TestAddBatchError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO outbox.event`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.AddBatch(context.Background(), []model.Event{mockEvent()}), mockError)
+}
+
+func (s *outboxSuite) TestPendingSuccess() {
+	event := mockEvent()
+	now := time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC)
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT e.id, e.uuid, e.type, e.account_uuid, e.payload, e.created_at, e.attempts, e.next_attempt_at
+					FROM outbox.event e WHERE e.published_at IS NULL AND NOT EXISTS (
+						SELECT 1 FROM outbox.event b WHERE b.account_uuid = e.account_uuid AND b.published_at IS NULL
+						AND b.id <= e.id AND b.next_attempt_at > $1)
+					ORDER BY e.id LIMIT $2;`)).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "type", "account_uuid", "payload", "created_at", "attempts", "next_attempt_at"}).
+			AddRow(7, event.UUID.String(), event.Type, event.AccountUUID.String(), event.Data, event.CreatedAt, 2, now))
+
+	got, err := s.repo.Pending(context.Background(), now, 100)
+	s.NoError(err)
+	s.Equal([]model.OutboxEvent{{Event: event, ID: 7, Attempts: 2, NextAttemptAt: now}}, got)
+}
+
+func (s *outboxSuite) TestPendingError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM outbox.event e`)).WillReturnError(mockError)
+
+	got, err := s.repo.Pending(context.Background(), time.Now(), 100)
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
+
+func (s *outboxSuite) TestLockRelay() {
+	for _, locked := range []bool{true, false} {
+		s.db.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1);`)).
+			WithArgs(outboxRelayLockID).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(locked))
+
+		got, err := s.repo.LockRelay(context.Background())
+		s.Require().NoError(err)
+		s.Equal(locked, got)
+	}
+}
+
+func (s *outboxSuite) TestLockRelayError() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1);`)).
+		WillReturnError(errors.New("some-db-error"))
+
+	_, err := s.repo.LockRelay(context.Background())
+	s.ErrorContains(err, "failed to lock outbox relay")
+}
+
+func (s *outboxSuite) TestMarkPublishedSuccess() {
+	at := time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC)
+
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE outbox.event SET published_at = $2 WHERE id = $1;`)).
+		WithArgs(int64(7), at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.MarkPublished(context.Background(), 7, at))
+}
+
+func (s *outboxSuite) TestMarkFailedSuccess() {
+	nextAttemptAt := time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC)
+
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE outbox.event SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1;`)).
+		WithArgs(int64(7), nextAttemptAt, "connection refused").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.MarkFailed(context.Background(), 7, nextAttemptAt, "connection refused"))
+}
+
+func (s *outboxSuite) TestMarkFailedError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE outbox.event`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.MarkFailed(context.Background(), 7, time.Now(), "connection refused"), mockError)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+)
+
+type ratePlanRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/rate_plan_mock.go -source=rate_plan.go
+type RatePlanConnector interface {
+	Get(ctx context.Context, id int) (model.RatePlan, error)
+}
+
+func NewRatePlanRepo(db *sql.DB) RatePlanConnector {
+	return &ratePlanRepo{
+		db,
+	}
+}
+
+func (r *ratePlanRepo) Get(ctx context.Context, id int) (model.RatePlan, error) {
+	getRatePlanSQL := `SELECT id, description, apr, compound_daily, grace_period_days, late_fee FROM accounts.rate_plan WHERE id = $1;`
+
+	ctx, span := startSpan(ctx, "rate_plan.get", getRatePlanSQL)
+	defer span.End()
+
+	var plan model.RatePlan
+	if err := Conn(ctx, r.db).QueryRowContext(ctx, getRatePlanSQL, id).Scan(
+		&plan.ID,
+		&plan.Description,
+		&plan.APR,
+		&plan.CompoundDaily,
+		&plan.GracePeriodDays,
+		&plan.LateFee,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RatePlan{}, ErrNoRows
+		}
+
+		return model.RatePlan{}, RecordError(span, fmt.Errorf("failed to scan rate plan: %w", DBError(ctx, err)))
+	}
+
+	return plan, nil
+}
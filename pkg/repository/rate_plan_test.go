@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ratePlanSuite struct {
+	suite.Suite
+	repo RatePlanConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestRatePlan(t *testing.T) {
+	suite.Run(t, new(ratePlanSuite))
+}
+
+func (s *ratePlanSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewRatePlanRepo(db)
+	s.db = mock
+}
+
+func (s *ratePlanSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func (s *ratePlanSuite) TestGetSuccess() {
+	expected := model.RatePlan{ID: 1, Description: "STANDARD", APR: 29.99, CompoundDaily: true, GracePeriodDays: 3, LateFee: 10}
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT id, description, apr, compound_daily, grace_period_days, late_fee FROM accounts.rate_plan
+					WHERE id = $1;`)).
+		WithArgs(expected.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "apr", "compound_daily", "grace_period_days", "late_fee"}).
+			AddRow(expected.ID, expected.Description, expected.APR, expected.CompoundDaily, expected.GracePeriodDays, expected.LateFee))
+
+	got, err := s.repo.Get(context.Background(), expected.ID)
+	s.NoError(err)
+	s.Equal(expected, got)
+}
+
+func (s *ratePlanSuite) TestGetMissing() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM accounts.rate_plan`)).
+		WithArgs(999).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "description", "apr", "compound_daily", "grace_period_days", "late_fee"}))
+
+	_, err := s.repo.Get(context.Background(), 999)
+	s.ErrorIs(err, ErrNoRows)
+}
+
+func (s *ratePlanSuite) TestGetError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM accounts.rate_plan`)).
+		WithArgs(1).
+		WillReturnError(mockError)
+
+	got, err := s.repo.Get(context.Background(), 1)
+	s.ErrorIs(err, mockError)
+	s.Equal(model.RatePlan{}, got)
+}
@@ -0,0 +1,1013 @@
+// Package repositorytest provides a conformance suite every implementation of the repository
+// connectors has to pass.
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type Repositories struct {
+	Accounts       repository.AccountConnector
+	Transactions   repository.TransactionConnector
+	OperationTypes repository.OperationTypeConnector
+	Statements     repository.StatementConnector
+	RatePlans      repository.RatePlanConnector
+	Ledger         repository.LedgerConnector
+	Transfers      repository.TransferConnector
+	Outbox         repository.OutboxConnector
+	Webhooks       repository.WebhookConnector
+	UnitOfWork     repository.UnitOfWork
+}
+
+// Suite runs the conformance tests against the repositories returned by New, which is called
+// before every test. Tests only use fresh uuids, so they may share a database.
+type Suite struct {
+	suite.Suite
+	New func() Repositories
+
+	repos Repositories
+}
+
+func (s *Suite) SetupTest() {
+	s.repos = s.New()
+}
+
+func (s *Suite) newAccount() model.Account {
+	return model.Account{
+		UUID:           uuid.Must(uuid.NewV4()),
+		DocumentNumber: "12345678900",
+		ClosingDay:     5,
+		DueDay:         15,
+		RatePlanID:     2,
+		Status:         model.AccountStatusActive,
+		CreditLimit:    1500.25,
+		// the database stores microseconds without time zone
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func (s *Suite) newTransaction(accountUUID uuid.UUID) model.Transaction {
+	return model.Transaction{
+		UUID:            uuid.Must(uuid.NewV4()),
+		AccountUUID:     accountUUID,
+		OperationTypeID: 1,
+		Amount:          12.5,
+		EventDate:       time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func (s *Suite) assertAccount(want, got model.Account) {
+	s.Equal(want.UUID, got.UUID)
+	s.Equal(want.DocumentNumber, got.DocumentNumber)
+	s.Equal(want.ClosingDay, got.ClosingDay)
+	s.Equal(want.DueDay, got.DueDay)
+	s.Equal(want.RatePlanID, got.RatePlanID)
+	s.Equal(want.Status, got.Status)
+	s.InDelta(want.CreditLimit, got.CreditLimit, 0)
+	s.True(want.CreatedAt.Equal(got.CreatedAt), "created_at: want %s, got %s", want.CreatedAt, got.CreatedAt)
+}
+
+func (s *Suite) TestAccountCreateGet() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	s.Require().NoError(s.repos.Accounts.Create(ctx, account))
+
+	got, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.assertAccount(account, got)
+}
+
+// creating an existing account is not an error and keeps the stored one
+func (s *Suite) TestAccountCreateExisting() {
+	ctx := context.Background()
+	account := s.newAccount()
+	s.Require().NoError(s.repos.Accounts.Create(ctx, account))
+
+	other := account
+	other.DocumentNumber = "other"
+	s.Require().NoError(s.repos.Accounts.Create(ctx, other))
+
+	got, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.assertAccount(account, got)
+}
+
+func (s *Suite) TestAccountCheckIdempotency() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	s.NoError(s.repos.Accounts.CheckIdempotency(ctx, account.UUID.String()))
+
+	s.Require().NoError(s.repos.Accounts.Create(ctx, account))
+	s.ErrorIs(s.repos.Accounts.CheckIdempotency(ctx, account.UUID.String()), repository.ErrDuplicate)
+}
+
+func (s *Suite) TestAccountGetMissing() {
+	_, err := s.repos.Accounts.Get(context.Background(), uuid.Must(uuid.NewV4()).String())
+	s.ErrorIs(err, repository.ErrNoRows)
+}
+
+func (s *Suite) TestAccountGetForUpdate() {
+	ctx := context.Background()
+	account := s.newAccount()
+	s.Require().NoError(s.repos.Accounts.Create(ctx, account))
+
+	s.Require().NoError(s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		got, err := s.repos.Accounts.GetForUpdate(ctx, account.UUID.String())
+		s.Require().NoError(err)
+		s.assertAccount(account, got)
+
+		_, err = s.repos.Accounts.GetForUpdate(ctx, uuid.Must(uuid.NewV4()).String())
+		s.ErrorIs(err, repository.ErrNoRows)
+
+		return nil
+	}))
+}
+
+func (s *Suite) TestTransactionCheckIdempotency() {
+	ctx := context.Background()
+	account := s.newAccount()
+	s.Require().NoError(s.repos.Accounts.Create(ctx, account))
+	trx := s.newTransaction(account.UUID)
+
+	s.NoError(s.repos.Transactions.CheckIdempotency(ctx, trx.UUID.String()))
+
+	s.Require().NoError(s.repos.Transactions.Create(ctx, trx))
+	s.ErrorIs(s.repos.Transactions.CheckIdempotency(ctx, trx.UUID.String()), repository.ErrDuplicate)
+
+	// creating it again is not an error
+	s.NoError(s.repos.Transactions.Create(ctx, trx))
+}
+
+func (s *Suite) TestAccountList() {
+	ctx := context.Background()
+	first, second := s.newAccount(), s.newAccount()
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	s.Require().NoError(s.repos.Accounts.Create(ctx, first))
+	s.Require().NoError(s.repos.Accounts.Create(ctx, second))
+
+	accounts, err := s.repos.Accounts.List(ctx)
+	s.Require().NoError(err)
+
+	// other tests may share the database, only the order of the accounts created here is known
+	var got []model.Account
+	for _, a := range accounts {
+		if a.UUID == first.UUID || a.UUID == second.UUID {
+			got = append(got, a)
+		}
+	}
+	s.Require().Len(got, 2)
+	s.assertAccount(first, got[0])
+	s.assertAccount(second, got[1])
+}
+
+func (s *Suite) TestTransactionListByPeriod() {
+	ctx := context.Background()
+	account, other := s.newAccount(), s.newAccount()
+	from := time.Date(2025, time.September, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	var want []model.Transaction
+	for _, eventDate := range []time.Time{
+		from.Add(-time.Microsecond),
+		from,
+		from.Add(36 * time.Hour),
+		to.Add(-time.Microsecond),
+		to,
+	} {
+		trx := s.newTransaction(account.UUID)
+		trx.EventDate = eventDate
+		s.Require().NoError(s.repos.Transactions.Create(ctx, trx))
+		if !eventDate.Before(from) && eventDate.Before(to) {
+			want = append(want, trx)
+		}
+	}
+
+	otherTrx := s.newTransaction(other.UUID)
+	otherTrx.EventDate = from
+	s.Require().NoError(s.repos.Transactions.Create(ctx, otherTrx))
+
+	got, err := s.repos.Transactions.ListByPeriod(ctx, account.UUID.String(), from, to)
+	s.Require().NoError(err)
+	s.Require().Len(got, len(want))
+	for i := range want {
+		s.assertTransaction(want[i], got[i])
+	}
+
+	got, err = s.repos.Transactions.ListByPeriod(ctx, uuid.Must(uuid.NewV4()).String(), from, to)
+	s.Require().NoError(err)
+	s.Empty(got)
+}
+
+func (s *Suite) TestTransactionListAfter() {
+	ctx := context.Background()
+	account, other := s.newAccount(), s.newAccount()
+
+	latest, err := s.repos.Transactions.LatestID(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.Zero(latest)
+
+	var want []model.Transaction
+	for range 3 {
+		trx := s.newTransaction(account.UUID)
+		s.Require().NoError(s.repos.Transactions.Create(ctx, trx))
+		s.Require().NoError(s.repos.Transactions.Create(ctx, s.newTransaction(other.UUID)))
+		want = append(want, trx)
+	}
+
+	got, err := s.repos.Transactions.ListAfter(ctx, account.UUID.String(), 0, 2)
+	s.Require().NoError(err)
+	s.Require().Len(got, 2)
+	s.assertTransaction(want[0], got[0].Transaction)
+	s.assertTransaction(want[1], got[1].Transaction)
+	s.Less(got[0].ID, got[1].ID)
+
+	rest, err := s.repos.Transactions.ListAfter(ctx, account.UUID.String(), got[1].ID, 2)
+	s.Require().NoError(err)
+	s.Require().Len(rest, 1)
+	s.assertTransaction(want[2], rest[0].Transaction)
+
+	latest, err = s.repos.Transactions.LatestID(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.Equal(rest[0].ID, latest)
+
+	none, err := s.repos.Transactions.ListAfter(ctx, account.UUID.String(), latest, 2)
+	s.Require().NoError(err)
+	s.Empty(none)
+}
+
+// A batch is stored in order, leaving out the transactions stored before
+func (s *Suite) TestTransactionCreateBatch() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	stored := s.newTransaction(account.UUID)
+	s.Require().NoError(s.repos.Transactions.Create(ctx, stored))
+
+	changed := stored
+	changed.Amount = 99
+	batch := []model.Transaction{s.newTransaction(account.UUID), changed, s.newTransaction(account.UUID)}
+	missing := s.newTransaction(account.UUID)
+
+	existing, err := s.repos.Transactions.ListExisting(ctx, []string{batch[0].UUID.String(), stored.UUID.String(), missing.UUID.String()})
+	s.Require().NoError(err)
+	s.Equal([]string{stored.UUID.String()}, existing)
+
+	s.Require().NoError(s.repos.Transactions.CreateBatch(ctx, batch))
+
+	got, err := s.repos.Transactions.ListAfter(ctx, account.UUID.String(), 0, 10)
+	s.Require().NoError(err)
+	s.Require().Len(got, 3)
+	s.assertTransaction(stored, got[0].Transaction)
+	s.assertTransaction(batch[0], got[1].Transaction)
+	s.assertTransaction(batch[2], got[2].Transaction)
+	s.Less(got[1].ID, got[2].ID)
+
+	existing, err = s.repos.Transactions.ListExisting(ctx, nil)
+	s.Require().NoError(err)
+	s.Empty(existing)
+}
+
+func (s *Suite) assertTransaction(want, got model.Transaction) {
+	s.Equal(want.UUID, got.UUID)
+	s.Equal(want.AccountUUID, got.AccountUUID)
+	s.Equal(want.OperationTypeID, got.OperationTypeID)
+	s.InDelta(want.Amount, got.Amount, 0.001)
+	s.True(want.EventDate.Equal(got.EventDate), "event_date: want %s, got %s", want.EventDate, got.EventDate)
+}
+
+// newStatement returns a statement of the account for the month closing at periodEnd, with
+// a purchase and a payment
+func (s *Suite) newStatement(accountUUID uuid.UUID, periodEnd time.Time) model.Statement {
+	purchase := s.newTransaction(accountUUID)
+	purchase.Amount = -60.5
+	purchase.EventDate = periodEnd.AddDate(0, 0, -20)
+
+	payment := s.newTransaction(accountUUID)
+	payment.OperationTypeID = 4
+	payment.Amount = 100
+	payment.EventDate = periodEnd.AddDate(0, 0, -10)
+
+	return model.Statement{
+		UUID:           uuid.Must(uuid.NewV4()),
+		AccountUUID:    accountUUID,
+		PeriodStart:    periodEnd.AddDate(0, -1, 0),
+		PeriodEnd:      periodEnd,
+		DueDate:        periodEnd.AddDate(0, 0, 9),
+		OpeningBalance: 150.25,
+		Purchases:      60.5,
+		Payments:       100,
+		ClosingBalance: 110.75,
+		MinimumPayment: 20,
+		CreatedAt:      time.Now().UTC().Truncate(time.Microsecond),
+		Transactions:   []model.Transaction{purchase, payment},
+	}
+}
+
+func (s *Suite) assertStatement(want, got model.Statement) {
+	s.Equal(want.UUID, got.UUID)
+	s.Equal(want.AccountUUID, got.AccountUUID)
+	for name, times := range map[string][2]time.Time{
+		"period_start": {want.PeriodStart, got.PeriodStart},
+		"period_end":   {want.PeriodEnd, got.PeriodEnd},
+		"due_date":     {want.DueDate, got.DueDate},
+		"created_at":   {want.CreatedAt, got.CreatedAt},
+	} {
+		s.True(times[0].Equal(times[1]), "%s: want %s, got %s", name, times[0], times[1])
+	}
+	s.InDelta(want.OpeningBalance, got.OpeningBalance, 0.001)
+	s.InDelta(want.Purchases, got.Purchases, 0.001)
+	s.InDelta(want.Payments, got.Payments, 0.001)
+	s.InDelta(want.ClosingBalance, got.ClosingBalance, 0.001)
+	s.InDelta(want.MinimumPayment, got.MinimumPayment, 0.001)
+
+	s.Require().Len(got.Transactions, len(want.Transactions))
+	for i := range want.Transactions {
+		s.assertTransaction(want.Transactions[i], got.Transactions[i])
+	}
+}
+
+func (s *Suite) TestStatementCreateGet() {
+	ctx := context.Background()
+	account := s.newAccount()
+	statement := s.newStatement(account.UUID, time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC))
+
+	s.Require().NoError(s.repos.Statements.Create(ctx, statement))
+
+	got, err := s.repos.Statements.Get(ctx, account.UUID.String(), statement.UUID.String())
+	s.Require().NoError(err)
+	s.assertStatement(statement, got)
+
+	// statements belong to their account
+	_, err = s.repos.Statements.Get(ctx, uuid.Must(uuid.NewV4()).String(), statement.UUID.String())
+	s.ErrorIs(err, repository.ErrNoRows)
+
+	_, err = s.repos.Statements.Get(ctx, account.UUID.String(), uuid.Must(uuid.NewV4()).String())
+	s.ErrorIs(err, repository.ErrNoRows)
+}
+
+func (s *Suite) TestStatementCreateDuplicate() {
+	ctx := context.Background()
+	account := s.newAccount()
+	statement := s.newStatement(account.UUID, time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.repos.Statements.Create(ctx, statement))
+
+	s.ErrorIs(s.repos.Statements.Create(ctx, statement), repository.ErrDuplicate)
+
+	// a second statement for the same period
+	other := s.newStatement(account.UUID, statement.PeriodEnd)
+	s.ErrorIs(s.repos.Statements.Create(ctx, other), repository.ErrDuplicate)
+}
+
+func (s *Suite) TestStatementLatestList() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	_, err := s.repos.Statements.Latest(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrNoRows)
+
+	list, err := s.repos.Statements.List(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.Empty(list)
+
+	september := s.newStatement(account.UUID, time.Date(2025, time.September, 5, 0, 0, 0, 0, time.UTC))
+	october := s.newStatement(account.UUID, time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC))
+	s.Require().NoError(s.repos.Statements.Create(ctx, october))
+	s.Require().NoError(s.repos.Statements.Create(ctx, september))
+	october.Transactions, september.Transactions = nil, nil
+
+	latest, err := s.repos.Statements.Latest(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.assertStatement(october, latest)
+
+	list, err = s.repos.Statements.List(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.Require().Len(list, 2)
+	s.assertStatement(october, list[0])
+	s.assertStatement(september, list[1])
+}
+
+func (s *Suite) TestStatementUnitOfWork() {
+	ctx := context.Background()
+	account := s.newAccount()
+	statement := s.newStatement(account.UUID, time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC))
+
+	// a snapshot failing halfway leaves no statement behind
+	statement.Transactions = append(statement.Transactions, statement.Transactions[0])
+	err := s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		return s.repos.Statements.Create(ctx, statement)
+	})
+	s.Require().Error(err)
+
+	_, err = s.repos.Statements.Latest(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrNoRows)
+}
+
+func (s *Suite) TestTransferCreate() {
+	ctx := context.Background()
+	transfer := model.Transfer{
+		UUID:                  uuid.Must(uuid.NewV4()),
+		FromAccountUUID:       uuid.Must(uuid.NewV4()),
+		ToAccountUUID:         uuid.Must(uuid.NewV4()),
+		Amount:                25.5,
+		DebitTransactionUUID:  uuid.Must(uuid.NewV4()),
+		CreditTransactionUUID: uuid.Must(uuid.NewV4()),
+		CreatedAt:             time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	s.NoError(s.repos.Transfers.CheckIdempotency(ctx, transfer.UUID.String()))
+
+	s.Require().NoError(s.repos.Transfers.Create(ctx, transfer))
+	s.ErrorIs(s.repos.Transfers.CheckIdempotency(ctx, transfer.UUID.String()), repository.ErrDuplicate)
+	s.ErrorIs(s.repos.Transfers.Create(ctx, transfer), repository.ErrDuplicate)
+}
+
+func (s *Suite) newEvent(accountUUID uuid.UUID, createdAt time.Time) model.Event {
+	return model.Event{
+		UUID:        uuid.Must(uuid.NewV4()),
+		Type:        model.EventTransactionCreated,
+		AccountUUID: accountUUID,
+		Data:        []byte(`{"amount": -60.5}`),
+		CreatedAt:   createdAt,
+	}
+}
+
+// drainOutbox publishes the events left pending by earlier runs, which would fill the batches
+func (s *Suite) drainOutbox(ctx context.Context) {
+	farFuture := time.Now().AddDate(100, 0, 0)
+	for {
+		pending, err := s.repos.Outbox.Pending(ctx, farFuture, 100)
+		s.Require().NoError(err)
+		if len(pending) == 0 {
+			return
+		}
+
+		for _, e := range pending {
+			s.Require().NoError(s.repos.Outbox.MarkPublished(ctx, e.ID, time.Now()))
+		}
+	}
+}
+
+func (s *Suite) assertEvents(want []model.Event, got []model.OutboxEvent) {
+	s.Require().Len(got, len(want))
+	for i := range want {
+		s.Equal(want[i].UUID, got[i].UUID)
+		s.Equal(want[i].Type, got[i].Type)
+		s.Equal(want[i].AccountUUID, got[i].AccountUUID)
+		s.JSONEq(string(want[i].Data), string(got[i].Data))
+		s.True(want[i].CreatedAt.Equal(got[i].CreatedAt), "created_at %s != %s", want[i].CreatedAt, got[i].CreatedAt)
+		if i > 0 {
+			s.Greater(got[i].ID, got[i-1].ID)
+		}
+	}
+}
+
+// The relay lock is taken within a unit of work, and released with it so that the next relay
+// takes it again
+func (s *Suite) TestOutboxLockRelay() {
+	ctx := context.Background()
+	for range 2 {
+		err := s.repos.UnitOfWork.Do(ctx, sql.LevelReadCommitted, func(ctx context.Context) error {
+			locked, err := s.repos.Outbox.LockRelay(ctx)
+			s.True(locked)
+
+			return err
+		})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *Suite) TestOutboxPending() {
+	ctx := context.Background()
+	s.drainOutbox(ctx)
+
+	account, other := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	first, second, third := s.newEvent(account, createdAt), s.newEvent(other, createdAt), s.newEvent(account, createdAt)
+	for _, e := range []model.Event{first, second, third} {
+		s.Require().NoError(s.repos.Outbox.Add(ctx, e))
+	}
+
+	// adding an event again keeps the stored one
+	again := first
+	again.Data = []byte(`{"amount": 99}`)
+	s.Require().NoError(s.repos.Outbox.Add(ctx, again))
+
+	pending, err := s.repos.Outbox.Pending(ctx, createdAt, 10)
+	s.Require().NoError(err)
+	s.assertEvents([]model.Event{first, second, third}, pending)
+	s.Zero(pending[0].Attempts)
+
+	// a failed event holds back the later events of its account until it is due again
+	retryAt := createdAt.Add(time.Minute)
+	s.Require().NoError(s.repos.Outbox.MarkFailed(ctx, pending[0].ID, retryAt, "connection refused"))
+
+	got, err := s.repos.Outbox.Pending(ctx, createdAt, 10)
+	s.Require().NoError(err)
+	s.assertEvents([]model.Event{second}, got)
+
+	s.Require().NoError(s.repos.Outbox.MarkPublished(ctx, pending[1].ID, createdAt))
+	got, err = s.repos.Outbox.Pending(ctx, createdAt, 10)
+	s.Require().NoError(err)
+	s.Empty(got)
+
+	got, err = s.repos.Outbox.Pending(ctx, retryAt, 10)
+	s.Require().NoError(err)
+	s.assertEvents([]model.Event{first, third}, got)
+	s.Equal(1, got[0].Attempts)
+	s.True(retryAt.Equal(got[0].NextAttemptAt), "next_attempt_at %s != %s", retryAt, got[0].NextAttemptAt)
+
+	got, err = s.repos.Outbox.Pending(ctx, retryAt, 1)
+	s.Require().NoError(err)
+	s.assertEvents([]model.Event{first}, got)
+
+	s.drainOutbox(ctx)
+}
+
+// A batch larger than any chunk of the multi-row inserts is stored in order, leaving out the
+// events added before
+func (s *Suite) TestOutboxAddBatch() {
+	ctx := context.Background()
+	s.drainOutbox(ctx)
+
+	account := uuid.Must(uuid.NewV4())
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	stored := s.newEvent(account, createdAt)
+	s.Require().NoError(s.repos.Outbox.Add(ctx, stored))
+
+	changed := stored
+	changed.Data = []byte(`{"amount": 99}`)
+	batch := []model.Event{changed}
+	for range 1200 {
+		batch = append(batch, s.newEvent(account, createdAt))
+	}
+	s.Require().NoError(s.repos.Outbox.AddBatch(ctx, batch))
+
+	pending, err := s.repos.Outbox.Pending(ctx, createdAt, 2000)
+	s.Require().NoError(err)
+	s.assertEvents(append([]model.Event{stored}, batch[1:]...), pending)
+
+	s.drainOutbox(ctx)
+}
+
+func (s *Suite) newWebhook() model.Webhook {
+	return model.Webhook{
+		UUID:       uuid.Must(uuid.NewV4()),
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{model.EventAccountCreated, model.EventTransactionCreated},
+		Secret:     "whsec_secret",
+		Enabled:    true,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func (s *Suite) assertWebhook(want, got model.Webhook) {
+	s.Equal(want.UUID, got.UUID)
+	s.Equal(want.URL, got.URL)
+	s.Equal(want.EventTypes, got.EventTypes)
+	s.Equal(want.Secret, got.Secret)
+	s.Equal(want.Enabled, got.Enabled)
+	s.Equal(want.ConsecutiveFailures, got.ConsecutiveFailures)
+	s.True(want.CreatedAt.Equal(got.CreatedAt), "created_at: want %s, got %s", want.CreatedAt, got.CreatedAt)
+	if want.DisabledAt == nil {
+		s.Nil(got.DisabledAt)
+	} else if s.NotNil(got.DisabledAt) {
+		s.True(want.DisabledAt.Equal(*got.DisabledAt), "disabled_at: want %s, got %s", want.DisabledAt, got.DisabledAt)
+	}
+}
+
+func (s *Suite) TestWebhookCreateGet() {
+	ctx := context.Background()
+	webhook := s.newWebhook()
+
+	s.NoError(s.repos.Webhooks.CheckIdempotency(ctx, webhook.UUID.String()))
+	s.Require().NoError(s.repos.Webhooks.Create(ctx, webhook))
+	s.ErrorIs(s.repos.Webhooks.CheckIdempotency(ctx, webhook.UUID.String()), repository.ErrDuplicate)
+	s.ErrorIs(s.repos.Webhooks.Create(ctx, webhook), repository.ErrDuplicate)
+
+	got, err := s.repos.Webhooks.Get(ctx, webhook.UUID.String())
+	s.Require().NoError(err)
+	s.assertWebhook(webhook, got)
+
+	_, err = s.repos.Webhooks.Get(ctx, uuid.Must(uuid.NewV4()).String())
+	s.ErrorIs(err, repository.ErrNoRows)
+
+	enabled, err := s.repos.Webhooks.ListEnabled(ctx)
+	s.Require().NoError(err)
+	s.True(slices.ContainsFunc(enabled, func(w model.Webhook) bool { return w.UUID == webhook.UUID }))
+
+	disabledAt := webhook.CreatedAt.Add(time.Hour)
+	webhook.Enabled, webhook.ConsecutiveFailures, webhook.DisabledAt = false, 10, &disabledAt
+	s.Require().NoError(s.repos.Webhooks.UpdateStatus(ctx, webhook))
+
+	got, err = s.repos.Webhooks.Get(ctx, webhook.UUID.String())
+	s.Require().NoError(err)
+	s.assertWebhook(webhook, got)
+
+	enabled, err = s.repos.Webhooks.ListEnabled(ctx)
+	s.Require().NoError(err)
+	s.False(slices.ContainsFunc(enabled, func(w model.Webhook) bool { return w.UUID == webhook.UUID }))
+}
+
+// dueDeliveries returns the deliveries to the webhook due at now, leaving out the ones of other
+// tests sharing the database
+func (s *Suite) dueDeliveries(ctx context.Context, webhookUUID uuid.UUID, now time.Time) []model.WebhookDelivery {
+	due, err := s.repos.Webhooks.DueDeliveries(ctx, now, 1000)
+	s.Require().NoError(err)
+
+	return slices.DeleteFunc(due, func(d model.WebhookDelivery) bool { return d.WebhookUUID != webhookUUID })
+}
+
+func (s *Suite) TestWebhookDeliveries() {
+	ctx := context.Background()
+	webhook := s.newWebhook()
+	s.Require().NoError(s.repos.Webhooks.Create(ctx, webhook))
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	retryAt := now.Add(time.Minute)
+	first := model.WebhookDelivery{
+		WebhookUUID:   webhook.UUID,
+		EventUUID:     uuid.Must(uuid.NewV4()),
+		EventType:     model.EventAccountCreated,
+		Payload:       []byte(`{"type": "account.created"}`),
+		NextAttemptAt: now,
+	}
+	second := first
+	second.EventUUID, second.NextAttemptAt = uuid.Must(uuid.NewV4()), retryAt
+	for _, d := range []model.WebhookDelivery{first, second} {
+		s.Require().NoError(s.repos.Webhooks.Enqueue(ctx, d))
+	}
+
+	// enqueuing an event again keeps the queued delivery
+	again := first
+	again.Payload = []byte(`{"type": "other"}`)
+	s.Require().NoError(s.repos.Webhooks.Enqueue(ctx, again))
+
+	due := s.dueDeliveries(ctx, webhook.UUID, now)
+	s.Require().Len(due, 1)
+	s.Equal(first.EventUUID, due[0].EventUUID)
+	s.Equal(first.EventType, due[0].EventType)
+	s.JSONEq(string(first.Payload), string(due[0].Payload))
+	s.Zero(due[0].Attempts)
+
+	failed := due[0]
+	failed.Attempts, failed.NextAttemptAt = 1, retryAt
+	failure := model.WebhookDeliveryAttempt{
+		EventUUID:   first.EventUUID,
+		EventType:   first.EventType,
+		Attempt:     1,
+		StatusCode:  503,
+		Error:       "webhook responded with status 503",
+		DurationMS:  12,
+		AttemptedAt: now,
+	}
+	s.Require().NoError(s.repos.Webhooks.RecordAttempt(ctx, failed, failure))
+	s.Empty(s.dueDeliveries(ctx, webhook.UUID, now))
+
+	due = s.dueDeliveries(ctx, webhook.UUID, retryAt)
+	s.Require().Len(due, 2)
+	s.Equal([]uuid.UUID{first.EventUUID, second.EventUUID}, []uuid.UUID{due[0].EventUUID, due[1].EventUUID})
+	s.Equal(1, due[0].Attempts)
+	s.True(retryAt.Equal(due[0].NextAttemptAt), "next_attempt_at %s != %s", retryAt, due[0].NextAttemptAt)
+
+	delivered := due[0]
+	delivered.Attempts = 2
+	success := model.WebhookDeliveryAttempt{
+		EventUUID:   first.EventUUID,
+		EventType:   first.EventType,
+		Attempt:     2,
+		StatusCode:  200,
+		Succeeded:   true,
+		DurationMS:  8,
+		AttemptedAt: retryAt,
+	}
+	s.Require().NoError(s.repos.Webhooks.RecordAttempt(ctx, delivered, success))
+
+	due = s.dueDeliveries(ctx, webhook.UUID, retryAt)
+	s.Require().Len(due, 1)
+	s.Equal(second.EventUUID, due[0].EventUUID)
+
+	attempts, err := s.repos.Webhooks.ListAttempts(ctx, webhook.UUID.String(), 10)
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 2)
+	for i, want := range []model.WebhookDeliveryAttempt{success, failure} {
+		s.True(want.AttemptedAt.Equal(attempts[i].AttemptedAt), "attempted_at %s != %s", want.AttemptedAt, attempts[i].AttemptedAt)
+		attempts[i].AttemptedAt = want.AttemptedAt
+		s.Equal(want, attempts[i])
+	}
+
+	attempts, err = s.repos.Webhooks.ListAttempts(ctx, webhook.UUID.String(), 1)
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 1)
+	s.Equal(2, attempts[0].Attempt)
+
+	// deliveries to a disabled webhook are not due
+	webhook.Enabled = false
+	s.Require().NoError(s.repos.Webhooks.UpdateStatus(ctx, webhook))
+	s.Empty(s.dueDeliveries(ctx, webhook.UUID, retryAt))
+}
+
+// newJournal returns the entries of a purchase of the account, debiting its receivable
+// against cash
+func (s *Suite) newJournal(accountUUID uuid.UUID, amount float64, postedAt time.Time) []model.JournalEntry {
+	trxUUID := uuid.Must(uuid.NewV4())
+
+	return []model.JournalEntry{
+		{TransactionUUID: trxUUID, AccountUUID: accountUUID, LedgerAccountID: model.LedgerAccountReceivable, Debit: amount, PostedAt: postedAt},
+		{TransactionUUID: trxUUID, AccountUUID: accountUUID, LedgerAccountID: model.LedgerAccountCash, Credit: amount, PostedAt: postedAt},
+	}
+}
+
+func (s *Suite) TestLedgerPostBalance() {
+	ctx := context.Background()
+	account, other := s.newAccount(), s.newAccount()
+	before := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	purchase := s.newJournal(account.UUID, 60.5, before.Add(-time.Microsecond))
+	s.Require().NoError(s.repos.Ledger.Post(ctx, purchase))
+	s.Require().NoError(s.repos.Ledger.Post(ctx, s.newJournal(account.UUID, 10, before)))
+	s.Require().NoError(s.repos.Ledger.Post(ctx, s.newJournal(other.UUID, 20, before.AddDate(0, 0, -1))))
+
+	// posting the entries of a transaction again is not an error and keeps the stored ones
+	again := slices.Clone(purchase)
+	again[0].Debit, again[1].Credit = 99, 99
+	s.Require().NoError(s.repos.Ledger.Post(ctx, again))
+
+	receivable, err := s.repos.Ledger.Balance(ctx, account.UUID.String(), model.LedgerAccountReceivable, before)
+	s.Require().NoError(err)
+	s.InDelta(60.5, receivable, 0.001)
+
+	cash, err := s.repos.Ledger.Balance(ctx, account.UUID.String(), model.LedgerAccountCash, before.AddDate(0, 0, 1))
+	s.Require().NoError(err)
+	s.InDelta(-70.5, cash, 0.001)
+
+	none, err := s.repos.Ledger.Balance(ctx, uuid.Must(uuid.NewV4()).String(), model.LedgerAccountReceivable, before)
+	s.Require().NoError(err)
+	s.Zero(none)
+}
+
+// The entries of many transactions larger than any chunk of the multi-row inserts are posted at
+// once, leaving out the entries posted before
+func (s *Suite) TestLedgerPostBatch() {
+	ctx := context.Background()
+	account := s.newAccount()
+	postedAt := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	posted := s.newJournal(account.UUID, 10, postedAt)
+	s.Require().NoError(s.repos.Ledger.Post(ctx, posted))
+
+	again := slices.Clone(posted)
+	again[0].Debit, again[1].Credit = 99, 99
+	entries := again
+	for range 600 {
+		entries = append(entries, s.newJournal(account.UUID, 1.5, postedAt)...)
+	}
+	s.Require().NoError(s.repos.Ledger.Post(ctx, entries))
+
+	receivable, err := s.repos.Ledger.Balance(ctx, account.UUID.String(), model.LedgerAccountReceivable, postedAt.AddDate(0, 0, 1))
+	s.Require().NoError(err)
+	s.InDelta(910, receivable, 0.001)
+
+	cash, err := s.repos.Ledger.Balance(ctx, account.UUID.String(), model.LedgerAccountCash, postedAt.AddDate(0, 0, 1))
+	s.Require().NoError(err)
+	s.InDelta(-910, cash, 0.001)
+}
+
+// other tests may share the database, only the change of the totals is known
+func (s *Suite) TestLedgerTrialBalance() {
+	ctx := context.Background()
+
+	initial, err := s.repos.Ledger.TrialBalance(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(initial, 4)
+	for i, want := range []struct {
+		code, typ string
+	}{
+		{"CUSTOMER_RECEIVABLE", model.LedgerAccountTypeAsset},
+		{"CASH", model.LedgerAccountTypeAsset},
+		{"REVENUE", model.LedgerAccountTypeRevenue},
+		{"FEES", model.LedgerAccountTypeRevenue},
+	} {
+		s.Equal(i+1, initial[i].LedgerAccountID)
+		s.Equal(want.code, initial[i].Code)
+		s.Equal(want.typ, initial[i].Type)
+	}
+
+	account := s.newAccount()
+	s.Require().NoError(s.repos.Ledger.Post(ctx, s.newJournal(account.UUID, 60.5, time.Now().UTC())))
+
+	got, err := s.repos.Ledger.TrialBalance(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(got, 4)
+	s.InDelta(initial[0].Debits+60.5, got[0].Debits, 0.001)
+	s.InDelta(initial[0].Credits, got[0].Credits, 0.001)
+	s.InDelta(initial[1].Debits, got[1].Debits, 0.001)
+	s.InDelta(initial[1].Credits+60.5, got[1].Credits, 0.001)
+}
+
+// the operation types seeded by the migrations
+var operationTypes = []model.OperationType{ //nolint:gochecknoglobals // read only fixture
+	{OperationTypeID: 1, Description: "CASH_PURCHASE", IsCredit: false},
+	{OperationTypeID: 2, Description: "INSTALLMENT_PURCHASE", IsCredit: false},
+	{OperationTypeID: 3, Description: "WITHDRAWAL", IsCredit: false},
+	{OperationTypeID: 4, Description: "PAYMENT", IsCredit: true},
+	{OperationTypeID: model.OperationTypeFee, Description: "FEE", IsCredit: false},
+	{OperationTypeID: model.OperationTypeInterest, Description: "INTEREST", IsCredit: false},
+	{OperationTypeID: model.OperationTypeTransferOut, Description: "TRANSFER_OUT", IsCredit: false},
+	{OperationTypeID: model.OperationTypeTransferIn, Description: "TRANSFER_IN", IsCredit: true},
+}
+
+func (s *Suite) TestOperationTypeGet() {
+	ctx := context.Background()
+	for _, want := range operationTypes {
+		ot, err := s.repos.OperationTypes.Get(ctx, want.OperationTypeID)
+		s.Require().NoError(err)
+		s.Equal(want, ot)
+	}
+
+	_, err := s.repos.OperationTypes.Get(ctx, 999)
+	s.ErrorIs(err, repository.ErrNoRows)
+}
+
+func (s *Suite) TestOperationTypeList() {
+	got, err := s.repos.OperationTypes.List(context.Background())
+	s.Require().NoError(err)
+	s.Equal(operationTypes, got)
+}
+
+func (s *Suite) TestRatePlanGet() {
+	ctx := context.Background()
+	for _, want := range []model.RatePlan{
+		{ID: 1, Description: "STANDARD", APR: 29.99, CompoundDaily: true, GracePeriodDays: 3, LateFee: 10},
+		{ID: 2, Description: "PREFERRED", APR: 19.99, CompoundDaily: false, GracePeriodDays: 5, LateFee: 0},
+	} {
+		plan, err := s.repos.RatePlans.Get(ctx, want.ID)
+		s.Require().NoError(err)
+		s.Equal(want, plan)
+	}
+
+	_, err := s.repos.RatePlans.Get(ctx, 999)
+	s.ErrorIs(err, repository.ErrNoRows)
+}
+
+func (s *Suite) TestExpiredDeadline() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	account := s.newAccount()
+	s.ErrorIs(s.repos.Accounts.Create(ctx, account), repository.ErrQueryTimeout)
+
+	_, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.OperationTypes.Get(ctx, 1)
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.RatePlans.Get(ctx, 1)
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.Statements.List(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.Ledger.TrialBalance(ctx)
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.Outbox.Pending(ctx, time.Now(), 1)
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+
+	_, err = s.repos.Webhooks.DueDeliveries(ctx, time.Now(), 1)
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+}
+
+func (s *Suite) TestConcurrentAccess() {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			account := s.newAccount()
+			s.NoError(s.repos.Accounts.Create(ctx, account))
+			s.ErrorIs(s.repos.Accounts.CheckIdempotency(ctx, account.UUID.String()), repository.ErrDuplicate)
+
+			got, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+			s.NoError(err)
+			s.Equal(account.UUID, got.UUID)
+
+			s.NoError(s.repos.Transactions.Create(ctx, s.newTransaction(account.UUID)))
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Suite) TestUnitOfWork() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	err := s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		if err := s.repos.Accounts.Create(ctx, account); err != nil {
+			return err
+		}
+
+		// reads within the unit of work see its own writes
+		return s.repos.Accounts.CheckIdempotency(ctx, account.UUID.String())
+	})
+	s.ErrorIs(err, repository.ErrDuplicate)
+
+	_, err = s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrNoRows, "failed unit of work was not rolled back")
+
+	s.Require().NoError(s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		return s.repos.Accounts.Create(ctx, account)
+	}))
+
+	got, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.Require().NoError(err)
+	s.assertAccount(account, got)
+}
+
+// every change of a failed unit of work is rolled back, whichever repository made it
+func (s *Suite) TestUnitOfWorkRollbackEveryRepository() {
+	ctx := context.Background()
+	s.drainOutbox(ctx)
+
+	account := s.newAccount()
+	trx := s.newTransaction(account.UUID)
+	journal := s.newJournal(account.UUID, 60.5, time.Now().UTC().Truncate(time.Microsecond))
+	event := s.newEvent(account.UUID, time.Now().UTC().Truncate(time.Microsecond))
+	transfer := model.Transfer{
+		UUID:                  uuid.Must(uuid.NewV4()),
+		FromAccountUUID:       account.UUID,
+		ToAccountUUID:         uuid.Must(uuid.NewV4()),
+		Amount:                60.5,
+		DebitTransactionUUID:  trx.UUID,
+		CreditTransactionUUID: uuid.Must(uuid.NewV4()),
+		CreatedAt:             time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	failure := errors.New("boom")
+	err := s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		if err := s.repos.Transactions.CreateBatch(ctx, []model.Transaction{trx}); err != nil {
+			return err
+		}
+		if err := s.repos.Ledger.Post(ctx, journal); err != nil {
+			return err
+		}
+		if err := s.repos.Transfers.Create(ctx, transfer); err != nil {
+			return err
+		}
+		if err := s.repos.Outbox.Add(ctx, event); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	s.Require().ErrorIs(err, failure)
+
+	s.NoError(s.repos.Transactions.CheckIdempotency(ctx, trx.UUID.String()))
+	s.NoError(s.repos.Transfers.CheckIdempotency(ctx, transfer.UUID.String()))
+
+	receivable, err := s.repos.Ledger.Balance(ctx, account.UUID.String(), model.LedgerAccountReceivable, time.Now().AddDate(0, 0, 1))
+	s.Require().NoError(err)
+	s.Zero(receivable)
+
+	pending, err := s.repos.Outbox.Pending(ctx, time.Now().AddDate(0, 0, 1), 10)
+	s.Require().NoError(err)
+	s.Empty(pending)
+
+	// the event can be added again once rolled back
+	s.Require().NoError(s.repos.Outbox.Add(ctx, event))
+	pending, err = s.repos.Outbox.Pending(ctx, time.Now().AddDate(0, 0, 1), 10)
+	s.Require().NoError(err)
+	s.assertEvents([]model.Event{event}, pending)
+
+	s.drainOutbox(ctx)
+}
+
+func (s *Suite) TestUnitOfWorkRollbackOnPanic() {
+	ctx := context.Background()
+	account := s.newAccount()
+
+	s.Panics(func() {
+		_ = s.repos.UnitOfWork.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+			if err := s.repos.Accounts.Create(ctx, account); err != nil {
+				return err
+			}
+
+			panic(errors.New("boom"))
+		})
+	})
+
+	_, err := s.repos.Accounts.Get(ctx, account.UUID.String())
+	s.ErrorIs(err, repository.ErrNoRows)
+}
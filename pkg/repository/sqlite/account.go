@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+type accountRepo struct {
+	db *sql.DB
+}
+
+func NewAccountRepo(db *sql.DB) repository.AccountConnector {
+	return &accountRepo{
+		db,
+	}
+}
+
+// Create stores the account, created_at is stored in UTC so that times compare as text
+func (a *accountRepo) Create(ctx context.Context, account model.Account) error {
+	insertSQL := `INSERT INTO accounts (uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at)
+					values (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "account.create", insertSQL)
+	defer span.End()
+
+	_, err := repository.Conn(ctx, a.db).ExecContext(ctx, insertSQL,
+		account.UUID.String(),
+		account.DocumentNumber,
+		account.ClosingDay,
+		account.DueDay,
+		account.RatePlanID,
+		account.Status,
+		account.CreditLimit,
+		account.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert account: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (a *accountRepo) CheckIdempotency(ctx context.Context, accountUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM accounts where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "account.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := repository.Conn(ctx, a.db).QueryRowContext(ctx, checkIdempotencySQL, accountUUID).Scan(&count); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", repository.DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (a *accountRepo) Get(ctx context.Context, uuid string) (model.Account, error) {
+	getAccount := `SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at FROM accounts where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "account.get", getAccount)
+	defer span.End()
+
+	var account model.Account
+	if err := repository.Conn(ctx, a.db).QueryRowContext(ctx, getAccount, uuid).Scan(
+		&account.UUID,
+		&account.DocumentNumber,
+		&account.ClosingDay,
+		&account.DueDay,
+		&account.RatePlanID,
+		&account.Status,
+		&account.CreditLimit,
+		&account.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Account{}, repository.ErrNoRows
+		}
+
+		return model.Account{}, repository.RecordError(span, fmt.Errorf("failed to scan account: %w", repository.DBError(ctx, err)))
+	}
+
+	return account, nil
+}
+
+// GetForUpdate returns the account like Get. SQLite locks the whole database for a writing
+// transaction, so there is no row to lock
+func (a *accountRepo) GetForUpdate(ctx context.Context, uuid string) (model.Account, error) {
+	return a.Get(ctx, uuid)
+}
+
+func (a *accountRepo) List(ctx context.Context) ([]model.Account, error) {
+	listAccounts := `SELECT uuid, document_number, closing_day, due_day, rate_plan_id, status, credit_limit, created_at FROM accounts ORDER BY id;`
+
+	ctx, span := startSpan(ctx, "account.list", listAccounts)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, a.db).QueryContext(ctx, listAccounts)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query accounts: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var accounts []model.Account
+	for rows.Next() {
+		var account model.Account
+		if err := rows.Scan(
+			&account.UUID,
+			&account.DocumentNumber,
+			&account.ClosingDay,
+			&account.DueDay,
+			&account.RatePlanID,
+			&account.Status,
+			&account.CreditLimit,
+			&account.CreatedAt,
+		); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan account: %w", repository.DBError(ctx, err)))
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate accounts: %w", repository.DBError(ctx, err)))
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ledgerRepo struct {
+	db *sql.DB
+}
+
+func NewLedgerRepo(db *sql.DB) repository.LedgerConnector {
+	return &ledgerRepo{
+		db,
+	}
+}
+
+// Post stores the entries batchRows at a time, posted_at is stored in UTC so that times compare
+// as text
+func (r *ledgerRepo) Post(ctx context.Context, entries []model.JournalEntry) error {
+	insertSQL := `INSERT INTO ledger_entries (transaction_uuid, account_uuid, ledger_account_id, debit, credit, posted_at) values `
+
+	ctx, span := startSpan(ctx, "ledger.post", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(entries, batchRows) {
+		args := make([]any, 0, len(chunk)*6)
+		for _, entry := range chunk {
+			args = append(args,
+				entry.TransactionUUID.String(),
+				entry.AccountUUID.String(),
+				entry.LedgerAccountID,
+				entry.Debit,
+				entry.Credit,
+				entry.PostedAt.UTC(),
+			)
+		}
+
+		values := strings.Repeat("(?, ?, ?, ?, ?, ?), ", len(chunk)-1) + "(?, ?, ?, ?, ?, ?)"
+		_, err := repository.Conn(ctx, r.db).ExecContext(ctx,
+			insertSQL+values+" ON CONFLICT (transaction_uuid, ledger_account_id) DO NOTHING;", args...)
+		if err != nil {
+			return repository.RecordError(span, fmt.Errorf("failed to insert journal entries: %w", repository.DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *ledgerRepo) Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error) {
+	balanceSQL := `SELECT COALESCE(SUM(debit - credit), 0) FROM ledger_entries
+					WHERE account_uuid = ? AND ledger_account_id = ? AND posted_at < ?;`
+
+	ctx, span := startSpan(ctx, "ledger.balance", balanceSQL)
+	defer span.End()
+
+	var balance float64
+	if err := repository.Conn(ctx, r.db).QueryRowContext(ctx, balanceSQL, accountUUID, ledgerAccountID, before.UTC()).Scan(&balance); err != nil {
+		return 0, repository.RecordError(span, fmt.Errorf("failed to scan ledger balance: %w", repository.DBError(ctx, err)))
+	}
+
+	return balance, nil
+}
+
+func (r *ledgerRepo) TrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	trialBalanceSQL := `SELECT a.id, a.code, a.type, COALESCE(SUM(e.debit), 0), COALESCE(SUM(e.credit), 0)
+					FROM ledger_accounts a LEFT JOIN ledger_entries e ON e.ledger_account_id = a.id
+					GROUP BY a.id, a.code, a.type ORDER BY a.id;`
+
+	ctx, span := startSpan(ctx, "ledger.trial_balance", trialBalanceSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, trialBalanceSQL)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query trial balance: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var accounts []model.TrialBalanceAccount
+	for rows.Next() {
+		var a model.TrialBalanceAccount
+		if err := rows.Scan(&a.LedgerAccountID, &a.Code, &a.Type, &a.Debits, &a.Credits); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan trial balance: %w", repository.DBError(ctx, err)))
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate trial balance: %w", repository.DBError(ctx, err)))
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+type operationRepo struct {
+	db *sql.DB
+}
+
+func NewOperationTypeRepo(db *sql.DB) repository.OperationTypeConnector {
+	return &operationRepo{
+		db,
+	}
+}
+
+func (o *operationRepo) Get(ctx context.Context, id int) (model.OperationType, error) {
+	getOperationTypeSQL := `SELECT operation_type_id, description, is_credit FROM operation_types WHERE operation_type_id = ?;`
+
+	ctx, span := startSpan(ctx, "operation_type.get", getOperationTypeSQL)
+	defer span.End()
+
+	var ot model.OperationType
+	if err := repository.Conn(ctx, o.db).QueryRowContext(ctx, getOperationTypeSQL, id).Scan(
+		&ot.OperationTypeID,
+		&ot.Description,
+		&ot.IsCredit,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.OperationType{}, repository.ErrNoRows
+		}
+
+		return model.OperationType{}, repository.RecordError(span, fmt.Errorf("failed to scan operation type: %w", repository.DBError(ctx, err)))
+	}
+
+	return ot, nil
+}
+
+func (o *operationRepo) List(ctx context.Context) ([]model.OperationType, error) {
+	listOperationTypesSQL := `SELECT operation_type_id, description, is_credit FROM operation_types ORDER BY operation_type_id;`
+
+	ctx, span := startSpan(ctx, "operation_type.list", listOperationTypesSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, o.db).QueryContext(ctx, listOperationTypesSQL)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query operation types: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var types []model.OperationType
+	for rows.Next() {
+		var ot model.OperationType
+		if err := rows.Scan(&ot.OperationTypeID, &ot.Description, &ot.IsCredit); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan operation type: %w", repository.DBError(ctx, err)))
+		}
+		types = append(types, ot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate operation types: %w", repository.DBError(ctx, err)))
+	}
+
+	return types, nil
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+type outboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) repository.OutboxConnector {
+	return &outboxRepo{
+		db,
+	}
+}
+
+// Add stores the event, times are stored in UTC so that they compare as text
+func (r *outboxRepo) Add(ctx context.Context, e model.Event) error {
+	insertSQL := `INSERT INTO outbox_events (uuid, type, account_uuid, payload, created_at, next_attempt_at)
+					values (?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "outbox.add", insertSQL)
+	defer span.End()
+
+	_, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		e.UUID.String(),
+		e.Type,
+		e.AccountUUID.String(),
+		string(e.Data),
+		e.CreatedAt.UTC(),
+		e.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert outbox event: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+// AddBatch stores the events batchRows at a time, in UTC like Add
+func (r *outboxRepo) AddBatch(ctx context.Context, events []model.Event) error {
+	insertSQL := `INSERT INTO outbox_events (uuid, type, account_uuid, payload, created_at, next_attempt_at) values `
+
+	ctx, span := startSpan(ctx, "outbox.add_batch", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(events, batchRows) {
+		args := make([]any, 0, len(chunk)*6)
+		for _, e := range chunk {
+			args = append(args, e.UUID.String(), e.Type, e.AccountUUID.String(), string(e.Data), e.CreatedAt.UTC(), e.CreatedAt.UTC())
+		}
+
+		values := strings.Repeat("(?, ?, ?, ?, ?, ?), ", len(chunk)-1) + "(?, ?, ?, ?, ?, ?)"
+		_, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL+values+" ON CONFLICT (uuid) DO NOTHING;", args...)
+		if err != nil {
+			return repository.RecordError(span, fmt.Errorf("failed to insert outbox events: %w", repository.DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) Pending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	pendingSQL := `SELECT e.id, e.uuid, e.type, e.account_uuid, e.payload, e.created_at, e.attempts, e.next_attempt_at
+					FROM outbox_events e WHERE e.published_at IS NULL AND NOT EXISTS (
+						SELECT 1 FROM outbox_events b WHERE b.account_uuid = e.account_uuid AND b.published_at IS NULL
+						AND b.id <= e.id AND b.next_attempt_at > ?)
+					ORDER BY e.id LIMIT ?;`
+
+	ctx, span := startSpan(ctx, "outbox.pending", pendingSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, pendingSQL, now.UTC(), limit)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query outbox events: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var events []model.OutboxEvent
+	for rows.Next() {
+		var e model.OutboxEvent
+		// the payload is text, which scans into []byte but not into json.RawMessage
+		var payload []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.UUID,
+			&e.Type,
+			&e.AccountUUID,
+			&payload,
+			&e.CreatedAt,
+			&e.Attempts,
+			&e.NextAttemptAt,
+		); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan outbox event: %w", repository.DBError(ctx, err)))
+		}
+		e.Data = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate outbox events: %w", repository.DBError(ctx, err)))
+	}
+
+	return events, nil
+}
+
+// LockRelay has nothing to lock, the units of work of SQLite begin immediate transactions which
+// hold the write lock of the database until they end
+func (r *outboxRepo) LockRelay(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	updateSQL := `UPDATE outbox_events SET published_at = ? WHERE id = ?;`
+
+	ctx, span := startSpan(ctx, "outbox.mark_published", updateSQL)
+	defer span.End()
+
+	if _, err := repository.Conn(ctx, r.db).ExecContext(ctx, updateSQL, at.UTC(), id); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to mark outbox event published: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	updateSQL := `UPDATE outbox_events SET attempts = attempts + 1, next_attempt_at = ?, last_error = ? WHERE id = ?;`
+
+	ctx, span := startSpan(ctx, "outbox.mark_failed", updateSQL)
+	defer span.End()
+
+	if _, err := repository.Conn(ctx, r.db).ExecContext(ctx, updateSQL, nextAttemptAt.UTC(), lastError, id); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to mark outbox event failed: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+type ratePlanRepo struct {
+	db *sql.DB
+}
+
+func NewRatePlanRepo(db *sql.DB) repository.RatePlanConnector {
+	return &ratePlanRepo{
+		db,
+	}
+}
+
+func (r *ratePlanRepo) Get(ctx context.Context, id int) (model.RatePlan, error) {
+	getRatePlanSQL := `SELECT id, description, apr, compound_daily, grace_period_days, late_fee FROM rate_plans WHERE id = ?;`
+
+	ctx, span := startSpan(ctx, "rate_plan.get", getRatePlanSQL)
+	defer span.End()
+
+	var plan model.RatePlan
+	if err := repository.Conn(ctx, r.db).QueryRowContext(ctx, getRatePlanSQL, id).Scan(
+		&plan.ID,
+		&plan.Description,
+		&plan.APR,
+		&plan.CompoundDaily,
+		&plan.GracePeriodDays,
+		&plan.LateFee,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RatePlan{}, repository.ErrNoRows
+		}
+
+		return model.RatePlan{}, repository.RecordError(span, fmt.Errorf("failed to scan rate plan: %w", repository.DBError(ctx, err)))
+	}
+
+	return plan, nil
+}
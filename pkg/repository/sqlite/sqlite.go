@@ -0,0 +1,20 @@
+// Package sqlite implements the repository connectors on an SQLite database, for demos and
+// local development without Postgres. It follows the error semantics of the Postgres
+// implementation.
+package sqlite
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/repository"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "go-pismo-challenge/pkg/repository/sqlite"
+
+// startSpan starts a client span for a single SQLite repository call, see repository.StartSpan
+func startSpan(ctx context.Context, name string, query string) (context.Context, trace.Span) {
+	return repository.StartSpan(ctx, otel.Tracer(tracerName), semconv.DBSystemNameSQLite, name, query)
+}
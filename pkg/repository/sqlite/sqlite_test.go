@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"go-pismo-challenge/pkg/database"
+	"go-pismo-challenge/pkg/migrator"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/repositorytest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConformance(t *testing.T) {
+	db, err := database.NewSQLiteConnection(context.Background(), database.SQLiteOptions{
+		Path:         filepath.Join(t.TempDir(), "pismo.db"),
+		MaxOpenConns: 4,
+		BusyTimeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrator.New(db, migrator.Options{Dialect: migrator.DialectSQLite, Table: "goose_db_version"}, migrator.FS)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(context.Background()))
+
+	suite.Run(t, &repositorytest.Suite{
+		New: func() repositorytest.Repositories {
+			return repositorytest.Repositories{
+				Accounts:       NewAccountRepo(db),
+				Transactions:   NewTransactionRepo(db),
+				OperationTypes: NewOperationTypeRepo(db),
+				Statements:     NewStatementRepo(db),
+				RatePlans:      NewRatePlanRepo(db),
+				Ledger:         NewLedgerRepo(db),
+				Transfers:      NewTransferRepo(db),
+				Outbox:         NewOutboxRepo(db),
+				Webhooks:       NewWebhookRepo(db),
+				UnitOfWork:     repository.NewUnitOfWork(db, 0),
+			}
+		},
+	})
+}
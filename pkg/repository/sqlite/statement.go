@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+type statementRepo struct {
+	db *sql.DB
+}
+
+func NewStatementRepo(db *sql.DB) repository.StatementConnector {
+	return &statementRepo{
+		db,
+	}
+}
+
+const statementColumns = `uuid, account_uuid, period_start, period_end, due_date, opening_balance, purchases, payments,
+					closing_balance, minimum_payment, created_at`
+
+// Create stores the statement, times are stored in UTC so that they compare as text
+func (r *statementRepo) Create(ctx context.Context, s model.Statement) error {
+	insertSQL := `INSERT INTO statements (` + statementColumns + `)
+					values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "statement.create", insertSQL)
+	defer span.End()
+
+	res, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		s.UUID.String(),
+		s.AccountUUID.String(),
+		s.PeriodStart.UTC(),
+		s.PeriodEnd.UTC(),
+		s.DueDate.UTC(),
+		s.OpeningBalance,
+		s.Purchases,
+		s.Payments,
+		s.ClosingBalance,
+		s.MinimumPayment,
+		s.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert statement: %w", repository.DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert statement: %w", err))
+	}
+	if affected == 0 {
+		return repository.ErrDuplicate
+	}
+
+	insertTrxSQL := `INSERT INTO statement_transactions (statement_uuid, transaction_uuid, operation_type_id, amount, event_date)
+					values (?, ?, ?, ?, ?);`
+	for _, trx := range s.Transactions {
+		_, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertTrxSQL,
+			s.UUID.String(),
+			trx.UUID.String(),
+			trx.OperationTypeID,
+			trx.Amount,
+			trx.EventDate.UTC(),
+		)
+		if err != nil {
+			return repository.RecordError(span, fmt.Errorf("failed to insert statement transaction: %w", repository.DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *statementRepo) Latest(ctx context.Context, accountUUID string) (model.Statement, error) {
+	latestSQL := `SELECT ` + statementColumns + ` FROM statements
+					where account_uuid = ? ORDER BY period_end DESC LIMIT 1;`
+
+	ctx, span := startSpan(ctx, "statement.latest", latestSQL)
+	defer span.End()
+
+	statement, err := scanStatement(repository.Conn(ctx, r.db).QueryRowContext(ctx, latestSQL, accountUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Statement{}, repository.ErrNoRows
+		}
+
+		return model.Statement{}, repository.RecordError(span, fmt.Errorf("failed to scan statement: %w", repository.DBError(ctx, err)))
+	}
+
+	return statement, nil
+}
+
+func (r *statementRepo) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	listSQL := `SELECT ` + statementColumns + ` FROM statements
+					where account_uuid = ? ORDER BY period_end DESC;`
+
+	ctx, span := startSpan(ctx, "statement.list", listSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, listSQL, accountUUID)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query statements: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var statements []model.Statement
+	for rows.Next() {
+		statement, err := scanStatement(rows)
+		if err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan statement: %w", repository.DBError(ctx, err)))
+		}
+		statements = append(statements, statement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate statements: %w", repository.DBError(ctx, err)))
+	}
+
+	return statements, nil
+}
+
+func (r *statementRepo) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	getSQL := `SELECT ` + statementColumns + ` FROM statements where account_uuid = ? and uuid = ?;`
+
+	ctx, span := startSpan(ctx, "statement.get", getSQL)
+	defer span.End()
+
+	statement, err := scanStatement(repository.Conn(ctx, r.db).QueryRowContext(ctx, getSQL, accountUUID, statementUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Statement{}, repository.ErrNoRows
+		}
+
+		return model.Statement{}, repository.RecordError(span, fmt.Errorf("failed to scan statement: %w", repository.DBError(ctx, err)))
+	}
+
+	listTrxSQL := `SELECT transaction_uuid, operation_type_id, amount, event_date FROM statement_transactions
+					where statement_uuid = ? ORDER BY event_date, transaction_uuid;`
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, listTrxSQL, statementUUID)
+	if err != nil {
+		return model.Statement{}, repository.RecordError(span,
+			fmt.Errorf("failed to query statement transactions: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trx := model.Transaction{AccountUUID: statement.AccountUUID}
+		if err := rows.Scan(&trx.UUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return model.Statement{}, repository.RecordError(span,
+				fmt.Errorf("failed to scan statement transaction: %w", repository.DBError(ctx, err)))
+		}
+		statement.Transactions = append(statement.Transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Statement{}, repository.RecordError(span, fmt.Errorf("failed to iterate statement transactions: %w", repository.DBError(ctx, err)))
+	}
+
+	return statement, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanStatement scans a row of statementColumns
+func scanStatement(row rowScanner) (model.Statement, error) {
+	var s model.Statement
+	err := row.Scan(
+		&s.UUID,
+		&s.AccountUUID,
+		&s.PeriodStart,
+		&s.PeriodEnd,
+		&s.DueDate,
+		&s.OpeningBalance,
+		&s.Purchases,
+		&s.Payments,
+		&s.ClosingBalance,
+		&s.MinimumPayment,
+		&s.CreatedAt,
+	)
+
+	return s, err
+}
@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+// batchRows bounds the rows of a multi-row statement, keeping its parameters well within the
+// limit of SQLite
+const batchRows = 500
+
+type transactionRepo struct {
+	db *sql.DB
+}
+
+func NewTransactionRepo(db *sql.DB) repository.TransactionConnector {
+	return &transactionRepo{
+		db,
+	}
+}
+
+// Create stores the transaction, event_date is stored in UTC so that times compare as text
+func (t *transactionRepo) Create(ctx context.Context, transaction model.Transaction) error {
+	insertSQL := `INSERT INTO transactions (uuid, account_uuid, operation_type_id, amount, event_date)
+					values (?, ?, ?, ?, ?) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "transaction.create", insertSQL)
+	defer span.End()
+
+	_, err := repository.Conn(ctx, t.db).ExecContext(ctx, insertSQL,
+		transaction.UUID.String(),
+		transaction.AccountUUID.String(),
+		transaction.OperationTypeID,
+		transaction.Amount,
+		transaction.EventDate.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert transaction: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+// CreateBatch stores the transactions batchRows at a time, in UTC like Create
+func (t *transactionRepo) CreateBatch(ctx context.Context, transactions []model.Transaction) error {
+	insertSQL := `INSERT INTO transactions (uuid, account_uuid, operation_type_id, amount, event_date) values `
+
+	ctx, span := startSpan(ctx, "transaction.create_batch", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(transactions, batchRows) {
+		args := make([]any, 0, len(chunk)*5)
+		for _, trx := range chunk {
+			args = append(args, trx.UUID.String(), trx.AccountUUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate.UTC())
+		}
+
+		values := strings.Repeat("(?, ?, ?, ?, ?), ", len(chunk)-1) + "(?, ?, ?, ?, ?)"
+		_, err := repository.Conn(ctx, t.db).ExecContext(ctx, insertSQL+values+" ON CONFLICT (uuid) DO NOTHING;", args...)
+		if err != nil {
+			return repository.RecordError(span, fmt.Errorf("failed to insert transactions: %w", repository.DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (t *transactionRepo) CheckIdempotency(ctx context.Context, trxUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM transactions where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "transaction.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := repository.Conn(ctx, t.db).QueryRowContext(ctx, checkIdempotencySQL, trxUUID).Scan(&count); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", repository.DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+// ListExisting queries the uuids batchRows at a time, SQLite has no array parameters
+func (t *transactionRepo) ListExisting(ctx context.Context, trxUUIDs []string) ([]string, error) {
+	listSQL := `SELECT uuid FROM transactions where uuid IN `
+
+	ctx, span := startSpan(ctx, "transaction.list_existing", listSQL)
+	defer span.End()
+
+	var existing []string
+	for chunk := range slices.Chunk(trxUUIDs, batchRows) {
+		args := make([]any, 0, len(chunk))
+		for _, trxUUID := range chunk {
+			args = append(args, trxUUID)
+		}
+
+		found, err := t.listExisting(ctx, listSQL+"("+strings.Repeat("?, ", len(chunk)-1)+"?);", args)
+		if err != nil {
+			return nil, repository.RecordError(span, err)
+		}
+		existing = append(existing, found...)
+	}
+
+	return existing, nil
+}
+
+func (t *transactionRepo) listExisting(ctx context.Context, listSQL string, args []any) ([]string, error) {
+	rows, err := repository.Conn(ctx, t.db).QueryContext(ctx, listSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", repository.DBError(ctx, err))
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var trxUUID string
+		if err := rows.Scan(&trxUUID); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction uuid: %w", repository.DBError(ctx, err))
+		}
+		existing = append(existing, trxUUID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transactions: %w", repository.DBError(ctx, err))
+	}
+
+	return existing, nil
+}
+
+func (t *transactionRepo) ListByPeriod(ctx context.Context, accountUUID string, from, to time.Time) ([]model.Transaction, error) {
+	listSQL := `SELECT uuid, account_uuid, operation_type_id, amount, event_date FROM transactions
+					where account_uuid = ? and event_date >= ? and event_date < ? ORDER BY event_date, id;`
+
+	ctx, span := startSpan(ctx, "transaction.list_by_period", listSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, t.db).QueryContext(ctx, listSQL, accountUUID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query transactions: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var transactions []model.Transaction
+	for rows.Next() {
+		var trx model.Transaction
+		if err := rows.Scan(&trx.UUID, &trx.AccountUUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan transaction: %w", repository.DBError(ctx, err)))
+		}
+		transactions = append(transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate transactions: %w", repository.DBError(ctx, err)))
+	}
+
+	return transactions, nil
+}
+
+func (t *transactionRepo) ListAfter(ctx context.Context, accountUUID string, afterID int64, limit int) ([]model.StoredTransaction, error) {
+	listSQL := `SELECT id, uuid, account_uuid, operation_type_id, amount, event_date FROM transactions
+					where account_uuid = ? and id > ? ORDER BY id LIMIT ?;`
+
+	ctx, span := startSpan(ctx, "transaction.list_after", listSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, t.db).QueryContext(ctx, listSQL, accountUUID, afterID, limit)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query transactions: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var transactions []model.StoredTransaction
+	for rows.Next() {
+		var trx model.StoredTransaction
+		if err := rows.Scan(&trx.ID, &trx.UUID, &trx.AccountUUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan transaction: %w", repository.DBError(ctx, err)))
+		}
+		transactions = append(transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate transactions: %w", repository.DBError(ctx, err)))
+	}
+
+	return transactions, nil
+}
+
+func (t *transactionRepo) LatestID(ctx context.Context, accountUUID string) (int64, error) {
+	latestSQL := `SELECT COALESCE(MAX(id), 0) FROM transactions where account_uuid = ?;`
+
+	ctx, span := startSpan(ctx, "transaction.latest_id", latestSQL)
+	defer span.End()
+
+	var id int64
+	if err := repository.Conn(ctx, t.db).QueryRowContext(ctx, latestSQL, accountUUID).Scan(&id); err != nil {
+		return 0, repository.RecordError(span, fmt.Errorf("failed to scan latest transaction id: %w", repository.DBError(ctx, err)))
+	}
+
+	return id, nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+type transferRepo struct {
+	db *sql.DB
+}
+
+func NewTransferRepo(db *sql.DB) repository.TransferConnector {
+	return &transferRepo{
+		db,
+	}
+}
+
+// Create stores the transfer, created_at is stored in UTC so that times compare as text
+func (r *transferRepo) Create(ctx context.Context, t model.Transfer) error {
+	insertSQL := `INSERT INTO transfers (uuid, from_account_uuid, to_account_uuid, amount, debit_transaction_uuid,
+					credit_transaction_uuid, created_at) values (?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "transfer.create", insertSQL)
+	defer span.End()
+
+	res, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		t.UUID.String(),
+		t.FromAccountUUID.String(),
+		t.ToAccountUUID.String(),
+		t.Amount,
+		t.DebitTransactionUUID.String(),
+		t.CreditTransactionUUID.String(),
+		t.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert transfer: %w", repository.DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert transfer: %w", err))
+	}
+	if affected == 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *transferRepo) CheckIdempotency(ctx context.Context, transferUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM transfers where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "transfer.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := repository.Conn(ctx, r.db).QueryRowContext(ctx, checkIdempotencySQL, transferUUID).Scan(&count); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", repository.DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"strings"
+	"time"
+)
+
+type webhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepo(db *sql.DB) repository.WebhookConnector {
+	return &webhookRepo{
+		db,
+	}
+}
+
+const webhookColumns = `uuid, url, event_types, secret, enabled, consecutive_failures, created_at, disabled_at`
+
+// Create stores the webhook with its event types comma separated, times are stored in UTC so that they
+// compare as text
+func (r *webhookRepo) Create(ctx context.Context, w model.Webhook) error {
+	insertSQL := `INSERT INTO webhook_subscriptions (` + webhookColumns + `)
+					values (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "webhook.create", insertSQL)
+	defer span.End()
+
+	res, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		w.UUID.String(),
+		w.URL,
+		strings.Join(w.EventTypes, ","),
+		w.Secret,
+		w.Enabled,
+		w.ConsecutiveFailures,
+		w.CreatedAt.UTC(),
+		nullTime(w.DisabledAt),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert webhook: %w", repository.DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert webhook: %w", err))
+	}
+	if affected == 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) CheckIdempotency(ctx context.Context, webhookUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM webhook_subscriptions where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "webhook.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := repository.Conn(ctx, r.db).QueryRowContext(ctx, checkIdempotencySQL, webhookUUID).Scan(&count); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", repository.DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return repository.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Get(ctx context.Context, uuid string) (model.Webhook, error) {
+	getSQL := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions where uuid = ?;`
+
+	ctx, span := startSpan(ctx, "webhook.get", getSQL)
+	defer span.End()
+
+	w, err := scanWebhook(repository.Conn(ctx, r.db).QueryRowContext(ctx, getSQL, uuid))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, repository.ErrNoRows
+		}
+
+		return model.Webhook{}, repository.RecordError(span, fmt.Errorf("failed to scan webhook: %w", repository.DBError(ctx, err)))
+	}
+
+	return w, nil
+}
+
+func (r *webhookRepo) ListEnabled(ctx context.Context) ([]model.Webhook, error) {
+	listSQL := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions where enabled ORDER BY id;`
+
+	ctx, span := startSpan(ctx, "webhook.list_enabled", listSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, listSQL)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query webhooks: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan webhook: %w", repository.DBError(ctx, err)))
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate webhooks: %w", repository.DBError(ctx, err)))
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepo) UpdateStatus(ctx context.Context, w model.Webhook) error {
+	updateSQL := `UPDATE webhook_subscriptions SET enabled = ?, consecutive_failures = ?, disabled_at = ? WHERE uuid = ?;`
+
+	ctx, span := startSpan(ctx, "webhook.update_status", updateSQL)
+	defer span.End()
+
+	_, err := repository.Conn(ctx, r.db).ExecContext(ctx, updateSQL, w.Enabled, w.ConsecutiveFailures, nullTime(w.DisabledAt), w.UUID.String())
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to update webhook status: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Enqueue(ctx context.Context, d model.WebhookDelivery) error {
+	insertSQL := `INSERT INTO webhook_deliveries (webhook_uuid, event_uuid, event_type, payload, next_attempt_at)
+					values (?, ?, ?, ?, ?) ON CONFLICT (webhook_uuid, event_uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "webhook.enqueue", insertSQL)
+	defer span.End()
+
+	_, err := repository.Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		d.WebhookUUID.String(),
+		d.EventUUID.String(),
+		d.EventType,
+		string(d.Payload),
+		d.NextAttemptAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert webhook delivery: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	dueSQL := `SELECT d.id, d.webhook_uuid, d.event_uuid, d.event_type, d.payload, d.attempts, d.next_attempt_at
+					FROM webhook_deliveries d JOIN webhook_subscriptions s ON s.uuid = d.webhook_uuid
+					WHERE d.delivered_at IS NULL AND d.next_attempt_at <= ? AND s.enabled
+					ORDER BY d.id LIMIT ?;`
+
+	ctx, span := startSpan(ctx, "webhook.due_deliveries", dueSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, dueSQL, now.UTC(), limit)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query webhook deliveries: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		// the payload is text, which scans into []byte but not into json.RawMessage
+		var payload []byte
+		if err := rows.Scan(
+			&d.ID,
+			&d.WebhookUUID,
+			&d.EventUUID,
+			&d.EventType,
+			&payload,
+			&d.Attempts,
+			&d.NextAttemptAt,
+		); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan webhook delivery: %w", repository.DBError(ctx, err)))
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate webhook deliveries: %w", repository.DBError(ctx, err)))
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepo) RecordAttempt(ctx context.Context, d model.WebhookDelivery, a model.WebhookDeliveryAttempt) error {
+	updateSQL := `UPDATE webhook_deliveries SET attempts = ?, next_attempt_at = ?, delivered_at = ? WHERE id = ?;`
+	insertSQL := `INSERT INTO webhook_delivery_attempts (delivery_id, webhook_uuid, event_uuid, event_type, attempt, status_code,
+					error, succeeded, duration_ms, attempted_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+	ctx, span := startSpan(ctx, "webhook.record_attempt", updateSQL)
+	defer span.End()
+
+	var deliveredAt *time.Time
+	if a.Succeeded {
+		deliveredAt = &a.AttemptedAt
+	}
+
+	conn := repository.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, updateSQL, d.Attempts, d.NextAttemptAt.UTC(), nullTime(deliveredAt), d.ID); err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to update webhook delivery: %w", repository.DBError(ctx, err)))
+	}
+
+	_, err := conn.ExecContext(ctx, insertSQL,
+		d.ID,
+		d.WebhookUUID.String(),
+		a.EventUUID.String(),
+		a.EventType,
+		a.Attempt,
+		a.StatusCode,
+		a.Error,
+		a.Succeeded,
+		a.DurationMS,
+		a.AttemptedAt.UTC(),
+	)
+	if err != nil {
+		return repository.RecordError(span, fmt.Errorf("failed to insert webhook delivery attempt: %w", repository.DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) ListAttempts(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDeliveryAttempt, error) {
+	listSQL := `SELECT event_uuid, event_type, attempt, status_code, error, succeeded, duration_ms, attempted_at
+					FROM webhook_delivery_attempts where webhook_uuid = ? ORDER BY id DESC LIMIT ?;`
+
+	ctx, span := startSpan(ctx, "webhook.list_attempts", listSQL)
+	defer span.End()
+
+	rows, err := repository.Conn(ctx, r.db).QueryContext(ctx, listSQL, webhookUUID, limit)
+	if err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to query webhook delivery attempts: %w", repository.DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var attempts []model.WebhookDeliveryAttempt
+	for rows.Next() {
+		var a model.WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&a.EventUUID,
+			&a.EventType,
+			&a.Attempt,
+			&a.StatusCode,
+			&a.Error,
+			&a.Succeeded,
+			&a.DurationMS,
+			&a.AttemptedAt,
+		); err != nil {
+			return nil, repository.RecordError(span, fmt.Errorf("failed to scan webhook delivery attempt: %w", repository.DBError(ctx, err)))
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, repository.RecordError(span, fmt.Errorf("failed to iterate webhook delivery attempts: %w", repository.DBError(ctx, err)))
+	}
+
+	return attempts, nil
+}
+
+// scanWebhook scans a row of webhookColumns
+func scanWebhook(row rowScanner) (model.Webhook, error) {
+	var w model.Webhook
+	var eventTypes string
+	err := row.Scan(
+		&w.UUID,
+		&w.URL,
+		&eventTypes,
+		&w.Secret,
+		&w.Enabled,
+		&w.ConsecutiveFailures,
+		&w.CreatedAt,
+		&w.DisabledAt,
+	)
+	w.EventTypes = strings.Split(eventTypes, ",")
+
+	return w, err
+}
+
+// nullTime returns the time in UTC, or nil to store NULL
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.UTC()
+}
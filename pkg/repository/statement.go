@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+)
+
+type statementRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/statement_mock.go -source=statement.go
+type StatementConnector interface {
+	// Create stores the statement with a snapshot of its transactions, it has to run in a unit of
+	// work for both to be stored atomically. ErrDuplicate is returned if the statement or one of
+	// the account for the same period exists
+	Create(ctx context.Context, s model.Statement) error
+	// Latest returns the statement of the account with the latest period, without transactions
+	Latest(ctx context.Context, accountUUID string) (model.Statement, error)
+	// List returns the statements of the account, latest period first and without transactions
+	List(ctx context.Context, accountUUID string) ([]model.Statement, error)
+	// Get returns the statement of the account with its transactions
+	Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error)
+}
+
+func NewStatementRepo(db *sql.DB) StatementConnector {
+	return &statementRepo{
+		db,
+	}
+}
+
+const statementColumns = `uuid, account_uuid, period_start, period_end, due_date, opening_balance, purchases, payments,
+					closing_balance, minimum_payment, created_at`
+
+// Create stores the statement, times are stored in UTC as the columns have no time zone
+func (r *statementRepo) Create(ctx context.Context, s model.Statement) error {
+	insertSQL := `INSERT INTO statements.statement (` + statementColumns + `)
+					values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "statement.create", insertSQL)
+	defer span.End()
+
+	res, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		s.UUID.String(),
+		s.AccountUUID.String(),
+		s.PeriodStart.UTC(),
+		s.PeriodEnd.UTC(),
+		s.DueDate.UTC(),
+		s.OpeningBalance,
+		s.Purchases,
+		s.Payments,
+		s.ClosingBalance,
+		s.MinimumPayment,
+		s.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert statement: %w", DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert statement: %w", err))
+	}
+	if affected == 0 {
+		return ErrDuplicate
+	}
+
+	insertTrxSQL := `INSERT INTO statements.statement_transaction (statement_uuid, transaction_uuid, operation_type_id, amount, event_date)
+					values ($1, $2, $3, $4, $5);`
+	for _, trx := range s.Transactions {
+		_, err := Conn(ctx, r.db).ExecContext(ctx, insertTrxSQL,
+			s.UUID.String(),
+			trx.UUID.String(),
+			trx.OperationTypeID,
+			trx.Amount,
+			trx.EventDate.UTC(),
+		)
+		if err != nil {
+			return RecordError(span, fmt.Errorf("failed to insert statement transaction: %w", DBError(ctx, err)))
+		}
+	}
+
+	return nil
+}
+
+func (r *statementRepo) Latest(ctx context.Context, accountUUID string) (model.Statement, error) {
+	latestSQL := `SELECT ` + statementColumns + ` FROM statements.statement
+					where account_uuid = $1 ORDER BY period_end DESC LIMIT 1;`
+
+	ctx, span := startSpan(ctx, "statement.latest", latestSQL)
+	defer span.End()
+
+	statement, err := scanStatement(Conn(ctx, r.db).QueryRowContext(ctx, latestSQL, accountUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Statement{}, ErrNoRows
+		}
+
+		return model.Statement{}, RecordError(span, fmt.Errorf("failed to scan statement: %w", DBError(ctx, err)))
+	}
+
+	return statement, nil
+}
+
+func (r *statementRepo) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	listSQL := `SELECT ` + statementColumns + ` FROM statements.statement
+					where account_uuid = $1 ORDER BY period_end DESC;`
+
+	ctx, span := startSpan(ctx, "statement.list", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, listSQL, accountUUID)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query statements: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var statements []model.Statement
+	for rows.Next() {
+		statement, err := scanStatement(rows)
+		if err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan statement: %w", DBError(ctx, err)))
+		}
+		statements = append(statements, statement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate statements: %w", DBError(ctx, err)))
+	}
+
+	return statements, nil
+}
+
+func (r *statementRepo) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	getSQL := `SELECT ` + statementColumns + ` FROM statements.statement where account_uuid = $1 and uuid = $2;`
+
+	ctx, span := startSpan(ctx, "statement.get", getSQL)
+	defer span.End()
+
+	statement, err := scanStatement(Conn(ctx, r.db).QueryRowContext(ctx, getSQL, accountUUID, statementUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Statement{}, ErrNoRows
+		}
+
+		return model.Statement{}, RecordError(span, fmt.Errorf("failed to scan statement: %w", DBError(ctx, err)))
+	}
+
+	listTrxSQL := `SELECT transaction_uuid, operation_type_id, amount, event_date FROM statements.statement_transaction
+					where statement_uuid = $1 ORDER BY event_date, transaction_uuid;`
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, listTrxSQL, statementUUID)
+	if err != nil {
+		return model.Statement{}, RecordError(span, fmt.Errorf("failed to query statement transactions: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		trx := model.Transaction{AccountUUID: statement.AccountUUID}
+		if err := rows.Scan(&trx.UUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return model.Statement{}, RecordError(span, fmt.Errorf("failed to scan statement transaction: %w", DBError(ctx, err)))
+		}
+		statement.Transactions = append(statement.Transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Statement{}, RecordError(span, fmt.Errorf("failed to iterate statement transactions: %w", DBError(ctx, err)))
+	}
+
+	return statement, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanStatement scans a row of statementColumns
+func scanStatement(row rowScanner) (model.Statement, error) {
+	var s model.Statement
+	err := row.Scan(
+		&s.UUID,
+		&s.AccountUUID,
+		&s.PeriodStart,
+		&s.PeriodEnd,
+		&s.DueDate,
+		&s.OpeningBalance,
+		&s.Purchases,
+		&s.Payments,
+		&s.ClosingBalance,
+		&s.MinimumPayment,
+		&s.CreatedAt,
+	)
+
+	return s, err
+}
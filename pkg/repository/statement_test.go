@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type statementSuite struct {
+	suite.Suite
+	repo StatementConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestStatement(t *testing.T) {
+	suite.Run(t, new(statementSuite))
+}
+
+func (s *statementSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewStatementRepo(db)
+	s.db = mock
+}
+
+func (s *statementSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+var statementRowColumns = []string{ //nolint:gochecknoglobals // test fixture
+	"uuid", "account_uuid", "period_start", "period_end", "due_date", "opening_balance", "purchases", "payments",
+	"closing_balance", "minimum_payment", "created_at",
+}
+
+func mockStatement() model.Statement {
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	periodEnd := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	return model.Statement{
+		UUID:           uuid.NewV5(accountUUID, periodEnd.Format(time.RFC3339)),
+		AccountUUID:    accountUUID,
+		PeriodStart:    periodEnd.AddDate(0, -1, 0),
+		PeriodEnd:      periodEnd,
+		DueDate:        periodEnd.AddDate(0, 0, 10),
+		OpeningBalance: 100,
+		Purchases:      60.5,
+		Payments:       100,
+		ClosingBalance: 60.5,
+		MinimumPayment: 20,
+		CreatedAt:      periodEnd.Add(time.Minute),
+		Transactions: []model.Transaction{
+			{UUID: uuid.NewV5(uuid.Nil, "1"), AccountUUID: accountUUID, OperationTypeID: 1, Amount: -60.5, EventDate: periodEnd.AddDate(0, 0, -20)},
+			{UUID: uuid.NewV5(uuid.Nil, "2"), AccountUUID: accountUUID, OperationTypeID: 4, Amount: 100, EventDate: periodEnd.AddDate(0, 0, -10)},
+		},
+	}
+}
+
+func statementRow(st model.Statement) *sqlmock.Rows {
+	return sqlmock.NewRows(statementRowColumns).AddRow(
+		st.UUID.String(), st.AccountUUID.String(), st.PeriodStart, st.PeriodEnd, st.DueDate, st.OpeningBalance,
+		st.Purchases, st.Payments, st.ClosingBalance, st.MinimumPayment, st.CreatedAt,
+	)
+}
+
+func (s *statementSuite) expectInsert(st model.Statement) *sqlmock.ExpectedExec {
+	return s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO statements.statement (uuid, account_uuid, period_start, period_end, due_date,
+					opening_balance, purchases, payments, closing_balance, minimum_payment, created_at)
+					values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT DO NOTHING;`)).
+		WithArgs(
+			st.UUID.String(), st.AccountUUID.String(), st.PeriodStart, st.PeriodEnd, st.DueDate, st.OpeningBalance,
+			st.Purchases, st.Payments, st.ClosingBalance, st.MinimumPayment, st.CreatedAt,
+		)
+}
+
+func (s *statementSuite) TestCreateSuccess() {
+	st := mockStatement()
+
+	s.expectInsert(st).WillReturnResult(sqlmock.NewResult(1, 1))
+	for _, trx := range st.Transactions {
+		s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO statements.statement_transaction
+					(statement_uuid, transaction_uuid, operation_type_id, amount, event_date) values ($1, $2, $3, $4, $5);`)).
+			WithArgs(st.UUID.String(), trx.UUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	s.NoError(s.repo.Create(context.Background(), st))
+}
+
+// no row is inserted when the statement or one for the same period exists
+func (s *statementSuite) TestCreateDuplicate() {
+	st := mockStatement()
+
+	s.expectInsert(st).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s.ErrorIs(s.repo.Create(context.Background(), st), ErrDuplicate)
+}
+
+func (s *statementSuite) TestCreateTransactionError() {
+	st := mockStatement()
+	mockError := errors.New("db error")
+
+	s.expectInsert(st).WillReturnResult(sqlmock.NewResult(1, 1))
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO statements.statement_transaction`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.Create(context.Background(), st), mockError)
+}
+
+func (s *statementSuite) TestLatestSuccess() {
+	st := mockStatement()
+	st.Transactions = nil
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 ORDER BY period_end DESC LIMIT 1;`)).
+		WithArgs(st.AccountUUID.String()).
+		WillReturnRows(statementRow(st))
+
+	got, err := s.repo.Latest(context.Background(), st.AccountUUID.String())
+	s.NoError(err)
+	s.Equal(st, got)
+}
+
+func (s *statementSuite) TestLatestNoRows() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 ORDER BY period_end DESC LIMIT 1;`)).
+		WillReturnRows(sqlmock.NewRows(statementRowColumns))
+
+	_, err := s.repo.Latest(context.Background(), uuid.NewV5(uuid.Nil, "account").String())
+	s.ErrorIs(err, ErrNoRows)
+}
+
+func (s *statementSuite) TestListSuccess() {
+	st := mockStatement()
+	st.Transactions = nil
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 ORDER BY period_end DESC;`)).
+		WithArgs(st.AccountUUID.String()).
+		WillReturnRows(statementRow(st))
+
+	got, err := s.repo.List(context.Background(), st.AccountUUID.String())
+	s.NoError(err)
+	s.Equal([]model.Statement{st}, got)
+}
+
+func (s *statementSuite) TestGetSuccess() {
+	st := mockStatement()
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 and uuid = $2;`)).
+		WithArgs(st.AccountUUID.String(), st.UUID.String()).
+		WillReturnRows(statementRow(st))
+
+	rows := sqlmock.NewRows([]string{"transaction_uuid", "operation_type_id", "amount", "event_date"})
+	for _, trx := range st.Transactions {
+		rows.AddRow(trx.UUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT transaction_uuid, operation_type_id, amount, event_date FROM statements.statement_transaction
+					where statement_uuid = $1 ORDER BY event_date, transaction_uuid;`)).
+		WithArgs(st.UUID.String()).
+		WillReturnRows(rows)
+
+	got, err := s.repo.Get(context.Background(), st.AccountUUID.String(), st.UUID.String())
+	s.NoError(err)
+	s.Equal(st, got)
+}
+
+func (s *statementSuite) TestGetNoRows() {
+	st := mockStatement()
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 and uuid = $2;`)).
+		WithArgs(st.AccountUUID.String(), st.UUID.String()).
+		WillReturnRows(sqlmock.NewRows(statementRowColumns))
+
+	_, err := s.repo.Get(context.Background(), st.AccountUUID.String(), st.UUID.String())
+	s.ErrorIs(err, ErrNoRows)
+}
+
+func (s *statementSuite) TestGetDeadlineExceeded() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	st := mockStatement()
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM statements.statement where account_uuid = $1 and uuid = $2;`)).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows(statementRowColumns))
+
+	_, err := s.repo.Get(ctx, st.AccountUUID.String(), st.UUID.String())
+	s.ErrorIs(err, ErrQueryTimeout)
+}
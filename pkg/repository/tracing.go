@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "go-pismo-challenge/pkg/repository"
+
+// startSpan starts a client span for a single Postgres repository call, see StartSpan
+func startSpan(ctx context.Context, name string, query string) (context.Context, trace.Span) {
+	return StartSpan(ctx, otel.Tracer(tracerName), semconv.DBSystemNamePostgreSQL, name, query)
+}
+
+// StartSpan starts a client span for a single repository call on the database system. name
+// identifies the statement (e.g. "account.create") and is used as both span name and
+// db.statement.name
+func StartSpan(ctx context.Context, tracer trace.Tracer, system attribute.KeyValue, name, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			system,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(query),
+			attribute.String("db.statement.name", name),
+		),
+	)
+}
+
+// RecordError marks the span as failed and returns err unchanged. Expected outcomes such as
+// ErrNoRows and ErrDuplicate are not recorded as span errors.
+func RecordError(span trace.Span, err error) error {
+	if err == nil || errors.Is(err, ErrNoRows) || errors.Is(err, ErrDuplicate) {
+		return err
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	return err
+}
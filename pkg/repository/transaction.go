@@ -5,8 +5,17 @@ import (
 	"database/sql"
 	"fmt"
 	"go-pismo-challenge/pkg/model"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// batchRows bounds the rows of a multi-row insert, keeping its parameters well within the limit
+// of the database
+const batchRows = 500
+
 type transactionRepo struct {
 	db *sql.DB
 }
@@ -14,7 +23,20 @@ type transactionRepo struct {
 //go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/transaction_mock.go -source=transaction.go
 type TransactionConnector interface {
 	Create(ctx context.Context, a model.Transaction) error
+	// CreateBatch stores the transactions with multi-row inserts, their ids follow the order given.
+	// Transactions with the uuid of a stored one are left out
+	CreateBatch(ctx context.Context, transactions []model.Transaction) error
 	CheckIdempotency(ctx context.Context, trxUUID string) error
+	// ListExisting returns the uuids among trxUUIDs of the transactions stored
+	ListExisting(ctx context.Context, trxUUIDs []string) ([]string, error)
+	// ListByPeriod returns the transactions of the account with an event date in [from, to),
+	// ordered by event date
+	ListByPeriod(ctx context.Context, accountUUID string, from, to time.Time) ([]model.Transaction, error)
+	// ListAfter returns up to limit transactions of the account with an id greater than afterID,
+	// ordered by id
+	ListAfter(ctx context.Context, accountUUID string, afterID int64, limit int) ([]model.StoredTransaction, error)
+	// LatestID returns the id of the last transaction of the account, 0 if it has none
+	LatestID(ctx context.Context, accountUUID string) (int64, error)
 }
 
 func NewTransactionRepo(db *sql.DB) TransactionConnector {
@@ -23,19 +45,48 @@ func NewTransactionRepo(db *sql.DB) TransactionConnector {
 	}
 }
 
+// Create stores the transaction, event_date is stored in UTC as the column has no time zone
 func (a *transactionRepo) Create(ctx context.Context, transaction model.Transaction) error {
 	insertSQL := `INSERT INTO transactions.transaction (uuid, account_uuid, operation_type_id, amount, event_date) 
 					values ($1, $2, $3, $4, $5) ON CONFLICT (uuid) DO NOTHING;`
 
-	_, err := a.db.ExecContext(ctx, insertSQL,
+	ctx, span := startSpan(ctx, "transaction.create", insertSQL)
+	defer span.End()
+
+	_, err := Conn(ctx, a.db).ExecContext(ctx, insertSQL,
 		transaction.UUID.String(),
 		transaction.AccountUUID.String(),
 		transaction.OperationTypeID,
 		transaction.Amount,
-		transaction.EventDate,
+		transaction.EventDate.UTC(),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert transaction: %w", err)
+		return RecordError(span, fmt.Errorf("failed to insert transaction: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+// CreateBatch stores the transactions batchRows at a time, in UTC like Create
+func (a *transactionRepo) CreateBatch(ctx context.Context, transactions []model.Transaction) error {
+	insertSQL := `INSERT INTO transactions.transaction (uuid, account_uuid, operation_type_id, amount, event_date) values `
+
+	ctx, span := startSpan(ctx, "transaction.create_batch", insertSQL)
+	defer span.End()
+
+	for chunk := range slices.Chunk(transactions, batchRows) {
+		values := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*5)
+		for _, trx := range chunk {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+			args = append(args, trx.UUID.String(), trx.AccountUUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate.UTC())
+		}
+
+		_, err := Conn(ctx, a.db).ExecContext(ctx, insertSQL+strings.Join(values, ", ")+" ON CONFLICT (uuid) DO NOTHING;", args...)
+		if err != nil {
+			return RecordError(span, fmt.Errorf("failed to insert transactions: %w", DBError(ctx, err)))
+		}
 	}
 
 	return nil
@@ -44,13 +95,16 @@ func (a *transactionRepo) Create(ctx context.Context, transaction model.Transact
 func (a *transactionRepo) CheckIdempotency(ctx context.Context, trxUUID string) error {
 	checkIdempotencySQL := `SELECT COALESCE(COUNT(*), 0) as count FROM transactions.transaction where uuid = $1;`
 
-	rows := a.db.QueryRowContext(ctx, checkIdempotencySQL, trxUUID)
+	ctx, span := startSpan(ctx, "transaction.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	rows := Conn(ctx, a.db).QueryRowContext(ctx, checkIdempotencySQL, trxUUID)
 	if rows.Err() != nil {
-		return fmt.Errorf("failed to query: %w", rows.Err())
+		return RecordError(span, fmt.Errorf("failed to query: %w", DBError(ctx, rows.Err())))
 	}
 	var count uint64
 	if err := rows.Scan(&count); err != nil {
-		return fmt.Errorf("failed to scan while idempotency check: %w", err)
+		return RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", DBError(ctx, err)))
 	}
 
 	if count > 0 {
@@ -59,3 +113,100 @@ func (a *transactionRepo) CheckIdempotency(ctx context.Context, trxUUID string)
 
 	return nil
 }
+
+func (a *transactionRepo) ListExisting(ctx context.Context, trxUUIDs []string) ([]string, error) {
+	listSQL := `SELECT uuid FROM transactions.transaction where uuid = ANY($1);`
+
+	ctx, span := startSpan(ctx, "transaction.list_existing", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, a.db).QueryContext(ctx, listSQL, pq.Array(trxUUIDs))
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query transactions: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var trxUUID string
+		if err := rows.Scan(&trxUUID); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan transaction uuid: %w", DBError(ctx, err)))
+		}
+		existing = append(existing, trxUUID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate transactions: %w", DBError(ctx, err)))
+	}
+
+	return existing, nil
+}
+
+func (a *transactionRepo) ListByPeriod(ctx context.Context, accountUUID string, from, to time.Time) ([]model.Transaction, error) {
+	listSQL := `SELECT uuid, account_uuid, operation_type_id, amount, event_date FROM transactions.transaction
+					where account_uuid = $1 and event_date >= $2 and event_date < $3 ORDER BY event_date, id;`
+
+	ctx, span := startSpan(ctx, "transaction.list_by_period", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, a.db).QueryContext(ctx, listSQL, accountUUID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query transactions: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var transactions []model.Transaction
+	for rows.Next() {
+		var trx model.Transaction
+		if err := rows.Scan(&trx.UUID, &trx.AccountUUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan transaction: %w", DBError(ctx, err)))
+		}
+		transactions = append(transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate transactions: %w", DBError(ctx, err)))
+	}
+
+	return transactions, nil
+}
+
+func (a *transactionRepo) ListAfter(ctx context.Context, accountUUID string, afterID int64, limit int) ([]model.StoredTransaction, error) {
+	listSQL := `SELECT id, uuid, account_uuid, operation_type_id, amount, event_date FROM transactions.transaction
+					where account_uuid = $1 and id > $2 ORDER BY id LIMIT $3;`
+
+	ctx, span := startSpan(ctx, "transaction.list_after", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, a.db).QueryContext(ctx, listSQL, accountUUID, afterID, limit)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query transactions: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var transactions []model.StoredTransaction
+	for rows.Next() {
+		var trx model.StoredTransaction
+		if err := rows.Scan(&trx.ID, &trx.UUID, &trx.AccountUUID, &trx.OperationTypeID, &trx.Amount, &trx.EventDate); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan transaction: %w", DBError(ctx, err)))
+		}
+		transactions = append(transactions, trx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate transactions: %w", DBError(ctx, err)))
+	}
+
+	return transactions, nil
+}
+
+func (a *transactionRepo) LatestID(ctx context.Context, accountUUID string) (int64, error) {
+	latestSQL := `SELECT COALESCE(MAX(id), 0) FROM transactions.transaction where account_uuid = $1;`
+
+	ctx, span := startSpan(ctx, "transaction.latest_id", latestSQL)
+	defer span.End()
+
+	var id int64
+	if err := Conn(ctx, a.db).QueryRowContext(ctx, latestSQL, accountUUID).Scan(&id); err != nil {
+		return 0, RecordError(span, fmt.Errorf("failed to scan latest transaction id: %w", DBError(ctx, err)))
+	}
+
+	return id, nil
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"go-pismo-challenge/pkg/model"
 	"regexp"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-jose/go-jose/v4/testutils/require"
 	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -55,7 +57,7 @@ func (s *transactionSuite) TestCreateSuccess() {
 			request.AccountUUID.String(),
 			request.OperationTypeID,
 			request.Amount,
-			request.EventDate,
+			request.EventDate.UTC(),
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := s.repo.Create(ctx, request)
@@ -83,7 +85,7 @@ func (s *transactionSuite) TestCreateError() {
 			request.AccountUUID.String(),
 			request.OperationTypeID,
 			request.Amount,
-			request.EventDate,
+			request.EventDate.UTC(),
 		).WillReturnError(mockError)
 
 	err := s.repo.Create(ctx, request)
@@ -91,6 +93,70 @@ func (s *transactionSuite) TestCreateError() {
 	s.True(errors.Is(err, mockError))
 }
 
+func (s *transactionSuite) TestCreateBatchSuccess() {
+	now := time.Now()
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	transactions := []model.Transaction{
+		{UUID: uuid.NewV5(uuid.Nil, "1"), AccountUUID: accountUUID, OperationTypeID: 1, Amount: -10, EventDate: now},
+		{UUID: uuid.NewV5(uuid.Nil, "2"), AccountUUID: accountUUID, OperationTypeID: 4, Amount: 5.5, EventDate: now},
+	}
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO transactions.transaction (uuid, account_uuid, operation_type_id, amount, event_date) `+
+		`values ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10) ON CONFLICT (uuid) DO NOTHING;`)).
+		WithArgs(
+			transactions[0].UUID.String(), accountUUID.String(), 1, -10.0, now.UTC(),
+			transactions[1].UUID.String(), accountUUID.String(), 4, 5.5, now.UTC(),
+		).WillReturnResult(sqlmock.NewResult(2, 2))
+
+	s.NoError(s.repo.CreateBatch(context.Background(), transactions))
+}
+
+// Batches larger than batchRows are inserted batchRows at a time
+func (s *transactionSuite) TestCreateBatchChunked() {
+	transactions := make([]model.Transaction, batchRows+1)
+	for i := range transactions {
+		transactions[i] = model.Transaction{UUID: uuid.NewV5(uuid.Nil, strconv.Itoa(i)), OperationTypeID: 1, Amount: -1}
+	}
+
+	s.db.ExpectExec(regexp.QuoteMeta(`($2496, $2497, $2498, $2499, $2500) ON CONFLICT`)).WillReturnResult(sqlmock.NewResult(0, batchRows))
+	s.db.ExpectExec(regexp.QuoteMeta(`values ($1, $2, $3, $4, $5) ON CONFLICT`)).
+		WithArgs(transactions[batchRows].UUID.String(), uuid.Nil.String(), 1, -1.0, time.Time{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.CreateBatch(context.Background(), transactions))
+}
+
+func (s *transactionSuite) TestCreateBatchError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO transactions.transaction`)).WillReturnError(mockError)
+
+	err := s.repo.CreateBatch(context.Background(), []model.Transaction{{UUID: uuid.NewV5(uuid.Nil, "1")}})
+	s.ErrorIs(err, mockError)
+}
+
+func (s *transactionSuite) TestListExistingSuccess() {
+	trxUUIDs := []string{uuid.NewV5(uuid.Nil, "1").String(), uuid.NewV5(uuid.Nil, "2").String()}
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid FROM transactions.transaction where uuid = ANY($1);`)).
+		WithArgs(pq.Array(trxUUIDs)).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow(trxUUIDs[1]))
+
+	got, err := s.repo.ListExisting(context.Background(), trxUUIDs)
+	s.NoError(err)
+	s.Equal(trxUUIDs[1:], got)
+}
+
+func (s *transactionSuite) TestListExistingError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM transactions.transaction`)).WillReturnError(mockError)
+
+	got, err := s.repo.ListExisting(context.Background(), []string{"some-uuid"})
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
+
 func (s *transactionSuite) TestCheckIdempotencySuccess() {
 	ctx := context.Background()
 	mockUUID := uuid.NewV5(uuid.Nil, "")
@@ -129,3 +195,85 @@ func (s *transactionSuite) TestCheckIdempotencyError() {
 	s.Error(err)
 	s.True(errors.Is(err, mockError))
 }
+
+func (s *transactionSuite) TestListByPeriodSuccess() {
+	ctx := context.Background()
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	from := time.Date(2025, time.September, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	expected := []model.Transaction{
+		{UUID: uuid.NewV5(uuid.Nil, "1"), AccountUUID: accountUUID, OperationTypeID: 1, Amount: -10, EventDate: from},
+		{UUID: uuid.NewV5(uuid.Nil, "2"), AccountUUID: accountUUID, OperationTypeID: 4, Amount: 5.5, EventDate: from.Add(time.Hour)},
+	}
+
+	rows := sqlmock.NewRows([]string{"uuid", "account_uuid", "operation_type_id", "amount", "event_date"})
+	for _, trx := range expected {
+		rows.AddRow(trx.UUID.String(), trx.AccountUUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, account_uuid, operation_type_id, amount, event_date FROM transactions.transaction
+					where account_uuid = $1 and event_date >= $2 and event_date < $3 ORDER BY event_date, id;`)).
+		WithArgs(accountUUID.String(), from, to).
+		WillReturnRows(rows)
+
+	// the period is queried in UTC
+	loc := time.FixedZone("UTC-3", -3*60*60)
+	got, err := s.repo.ListByPeriod(ctx, accountUUID.String(), from.In(loc), to.In(loc))
+	s.NoError(err)
+	s.Equal(expected, got)
+}
+
+func (s *transactionSuite) TestListByPeriodError() {
+	ctx := context.Background()
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM transactions.transaction`)).WillReturnError(mockError)
+
+	got, err := s.repo.ListByPeriod(ctx, uuid.NewV5(uuid.Nil, "account").String(), time.Now(), time.Now())
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
+
+func (s *transactionSuite) TestListAfterSuccess() {
+	ctx := context.Background()
+	accountUUID := uuid.NewV5(uuid.Nil, "account")
+	eventDate := time.Date(2025, time.September, 5, 0, 0, 0, 0, time.UTC)
+	expected := []model.StoredTransaction{
+		{Transaction: model.Transaction{UUID: uuid.NewV5(uuid.Nil, "1"), AccountUUID: accountUUID, OperationTypeID: 1, Amount: -10,
+			EventDate: eventDate}, ID: 8},
+		{Transaction: model.Transaction{UUID: uuid.NewV5(uuid.Nil, "2"), AccountUUID: accountUUID, OperationTypeID: 4, Amount: 5.5,
+			EventDate: eventDate.Add(time.Hour)}, ID: 11},
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "uuid", "account_uuid", "operation_type_id", "amount", "event_date"})
+	for _, trx := range expected {
+		rows.AddRow(trx.ID, trx.UUID.String(), trx.AccountUUID.String(), trx.OperationTypeID, trx.Amount, trx.EventDate)
+	}
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT id, uuid, account_uuid, operation_type_id, amount, event_date FROM transactions.transaction
+					where account_uuid = $1 and id > $2 ORDER BY id LIMIT $3;`)).
+		WithArgs(accountUUID.String(), int64(7), 100).
+		WillReturnRows(rows)
+
+	got, err := s.repo.ListAfter(ctx, accountUUID.String(), 7, 100)
+	s.NoError(err)
+	s.Equal(expected, got)
+}
+
+func (s *transactionSuite) TestListAfterError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM transactions.transaction`)).WillReturnError(mockError)
+
+	got, err := s.repo.ListAfter(context.Background(), "some-uuid", 0, 100)
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
+
+func (s *transactionSuite) TestLatestIDSuccess() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(id), 0) FROM transactions.transaction where account_uuid = $1;`)).
+		WithArgs("some-uuid").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(42))
+
+	got, err := s.repo.LatestID(context.Background(), "some-uuid")
+	s.NoError(err)
+	s.Equal(int64(42), got)
+}
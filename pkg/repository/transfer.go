@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+)
+
+type transferRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/transfer_mock.go -source=transfer.go
+type TransferConnector interface {
+	// Create stores the transfer linking its transactions, it has to run in the unit of work
+	// creating them for all to be stored atomically. ErrDuplicate is returned if it exists
+	Create(ctx context.Context, t model.Transfer) error
+	CheckIdempotency(ctx context.Context, transferUUID string) error
+}
+
+func NewTransferRepo(db *sql.DB) TransferConnector {
+	return &transferRepo{
+		db,
+	}
+}
+
+// Create stores the transfer, created_at is stored in UTC as the column has no time zone
+func (r *transferRepo) Create(ctx context.Context, t model.Transfer) error {
+	insertSQL := `INSERT INTO transfers.transfer (uuid, from_account_uuid, to_account_uuid, amount, debit_transaction_uuid,
+					credit_transaction_uuid, created_at) values ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "transfer.create", insertSQL)
+	defer span.End()
+
+	res, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		t.UUID.String(),
+		t.FromAccountUUID.String(),
+		t.ToAccountUUID.String(),
+		t.Amount,
+		t.DebitTransactionUUID.String(),
+		t.CreditTransactionUUID.String(),
+		t.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert transfer: %w", DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert transfer: %w", err))
+	}
+	if affected == 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *transferRepo) CheckIdempotency(ctx context.Context, transferUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM transfers.transfer where uuid = $1;`
+
+	ctx, span := startSpan(ctx, "transfer.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := Conn(ctx, r.db).QueryRowContext(ctx, checkIdempotencySQL, transferUUID).Scan(&count); err != nil {
+		return RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type transferSuite struct {
+	suite.Suite
+	repo TransferConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestTransfer(t *testing.T) {
+	suite.Run(t, new(transferSuite))
+}
+
+func (s *transferSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewTransferRepo(db)
+	s.db = mock
+}
+
+func (s *transferSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func mockTransfer() model.Transfer {
+	transferUUID := uuid.NewV5(uuid.Nil, "transfer")
+
+	return model.Transfer{
+		UUID:                  transferUUID,
+		FromAccountUUID:       uuid.NewV5(uuid.Nil, "from"),
+		ToAccountUUID:         uuid.NewV5(uuid.Nil, "to"),
+		Amount:                25.5,
+		DebitTransactionUUID:  uuid.NewV5(transferUUID, "debit"),
+		CreditTransactionUUID: uuid.NewV5(transferUUID, "credit"),
+		CreatedAt:             time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+}
+
+func (s *transferSuite) expectInsert(t model.Transfer) *sqlmock.ExpectedExec {
+	return s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO transfers.transfer (uuid, from_account_uuid, to_account_uuid, amount,
+					debit_transaction_uuid, credit_transaction_uuid, created_at)
+					values ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING;`)).
+		WithArgs(
+			t.UUID.String(),
+			t.FromAccountUUID.String(),
+			t.ToAccountUUID.String(),
+			t.Amount,
+			t.DebitTransactionUUID.String(),
+			t.CreditTransactionUUID.String(),
+			t.CreatedAt,
+		)
+}
+
+func (s *transferSuite) TestCreateSuccess() {
+	t := mockTransfer()
+	s.expectInsert(t).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	s.NoError(s.repo.Create(context.Background(), t))
+}
+
+func (s *transferSuite) TestCreateDuplicate() {
+	t := mockTransfer()
+	s.expectInsert(t).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s.ErrorIs(s.repo.Create(context.Background(), t), ErrDuplicate)
+}
+
+func (s *transferSuite) TestCreateError() {
+	t := mockTransfer()
+	mockError := errors.New("db error")
+	s.expectInsert(t).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.Create(context.Background(), t), mockError)
+}
+
+func (s *transferSuite) TestCheckIdempotency() {
+	transferUUID := uuid.NewV5(uuid.Nil, "transfer").String()
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) as count FROM transfers.transfer where uuid = $1;`)).
+		WithArgs(transferUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.NoError(s.repo.CheckIdempotency(context.Background(), transferUUID))
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM transfers.transfer`)).
+		WithArgs(transferUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	s.ErrorIs(s.repo.CheckIdempotency(context.Background(), transferUUID), ErrDuplicate)
+}
+
+func (s *transferSuite) TestCheckIdempotencyError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM transfers.transfer`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.CheckIdempotency(context.Background(), uuid.NewV5(uuid.Nil, "transfer").String()), mockError)
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// postgres SQLSTATE serialization_failure
+const pqSerializationFailure = "40001"
+
+// base wait before retrying a transaction that failed to serialize, grows with each attempt
+const serializationRetryBackoff = 10 * time.Millisecond
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/unit_of_work_mock.go -source=unit_of_work.go
+type UnitOfWork interface {
+	// Do runs fn in a database transaction with the given isolation level. Repository calls
+	// made with the ctx passed to fn take part in the transaction, which is committed when fn
+	// returns nil and rolled back otherwise. Calling Do within fn joins the outer transaction.
+	Do(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error
+}
+
+// DBTX is implemented by both *sql.DB and *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction of the unit of work running in ctx, or db outside of one
+func Conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+type unitOfWork struct {
+	db         *sql.DB
+	maxRetries int
+}
+
+// NewUnitOfWork runs units of work on db, retrying up to maxRetries times when the database
+// aborts the transaction with a serialization failure
+func NewUnitOfWork(db *sql.DB, maxRetries int) UnitOfWork {
+	return &unitOfWork{
+		db:         db,
+		maxRetries: maxRetries,
+	}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) error {
+	// a nested unit of work joins the outer one, which retries as a whole
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := u.run(ctx, isolation, fn)
+		if err == nil || !isSerializationFailure(err) || attempt >= u.maxRetries {
+			return err
+		}
+
+		log.Warn().Ctx(ctx).Err(err).Int("attempt", attempt+1).Msg("retrying transaction after serialization failure")
+
+		// jittered so that the conflicting transactions do not collide again
+		backoff := serializationRetryBackoff * time.Duration(attempt+1)
+		backoff += rand.N(backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// run executes a single attempt, rolling back when fn fails or panics
+func (u *unitOfWork) run(ctx context.Context, isolation sql.IsolationLevel, fn func(ctx context.Context) error) (err error) {
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", DBError(ctx, err))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Error().Ctx(ctx).Err(rbErr).Msg("failed to roll back transaction after panic")
+			}
+
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			log.Error().Ctx(ctx).Err(rbErr).Msg("failed to roll back transaction")
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", DBError(ctx, err))
+	}
+
+	return nil
+}
+
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && pqErr.Code == pqSerializationFailure
+}
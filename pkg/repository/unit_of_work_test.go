@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+)
+
+type unitOfWorkSuite struct {
+	suite.Suite
+	uow      UnitOfWork
+	accounts AccountConnector
+	db       sqlmock.Sqlmock
+}
+
+func TestUnitOfWork(t *testing.T) {
+	suite.Run(t, new(unitOfWorkSuite))
+}
+
+func (s *unitOfWorkSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.uow = NewUnitOfWork(db, 2)
+	s.accounts = NewAccountRepo(db)
+	s.db = mock
+}
+
+func (s *unitOfWorkSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func (s *unitOfWorkSuite) expectCreate(err error) {
+	exp := s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO accounts.account`))
+	if err != nil {
+		exp.WillReturnError(err)
+
+		return
+	}
+	exp.WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func (s *unitOfWorkSuite) create(ctx context.Context) error {
+	return s.accounts.Create(ctx, model.Account{UUID: uuid.Must(uuid.NewV4()), CreatedAt: time.Now()})
+}
+
+// repository calls made within the unit of work run in its transaction
+func (s *unitOfWorkSuite) TestCommit() {
+	s.db.ExpectBegin()
+	s.expectCreate(nil)
+	s.expectCreate(nil)
+	s.db.ExpectCommit()
+
+	err := s.uow.Do(context.Background(), sql.LevelSerializable, func(ctx context.Context) error {
+		if err := s.create(ctx); err != nil {
+			return err
+		}
+
+		// a nested unit of work joins the outer transaction
+		return s.uow.Do(ctx, sql.LevelSerializable, s.create)
+	})
+	s.NoError(err)
+}
+
+func (s *unitOfWorkSuite) TestRollbackOnError() {
+	s.db.ExpectBegin()
+	s.expectCreate(nil)
+	s.db.ExpectRollback()
+
+	errFailed := errors.New("failed")
+	err := s.uow.Do(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+		if err := s.create(ctx); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	s.ErrorIs(err, errFailed)
+}
+
+func (s *unitOfWorkSuite) TestRollbackOnPanic() {
+	s.db.ExpectBegin()
+	s.db.ExpectRollback()
+
+	s.PanicsWithValue("boom", func() {
+		_ = s.uow.Do(context.Background(), sql.LevelDefault, func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+}
+
+func (s *unitOfWorkSuite) TestRetrySerializationFailure() {
+	serializationFailure := &pq.Error{Code: pqSerializationFailure}
+
+	// the statement fails on the first attempt, the commit on the second
+	s.db.ExpectBegin()
+	s.expectCreate(serializationFailure)
+	s.db.ExpectRollback()
+	s.db.ExpectBegin()
+	s.expectCreate(nil)
+	s.db.ExpectCommit().WillReturnError(serializationFailure)
+	s.db.ExpectBegin()
+	s.expectCreate(nil)
+	s.db.ExpectCommit()
+
+	attempts := 0
+	err := s.uow.Do(context.Background(), sql.LevelSerializable, func(ctx context.Context) error {
+		attempts++
+
+		return s.create(ctx)
+	})
+	s.NoError(err)
+	s.Equal(3, attempts)
+}
+
+func (s *unitOfWorkSuite) TestRetriesExhausted() {
+	serializationFailure := &pq.Error{Code: pqSerializationFailure}
+	for range 3 {
+		s.db.ExpectBegin()
+		s.expectCreate(serializationFailure)
+		s.db.ExpectRollback()
+	}
+
+	err := s.uow.Do(context.Background(), sql.LevelSerializable, s.create)
+
+	var pqErr *pq.Error
+	s.Require().ErrorAs(err, &pqErr)
+	s.Equal(pqSerializationFailure, string(pqErr.Code))
+}
+
+// other errors are returned without retrying
+func (s *unitOfWorkSuite) TestNoRetryOnOtherErrors() {
+	s.db.ExpectBegin()
+	s.expectCreate(&pq.Error{Code: "23505"})
+	s.db.ExpectRollback()
+
+	err := s.uow.Do(context.Background(), sql.LevelSerializable, s.create)
+	s.Error(err)
+}
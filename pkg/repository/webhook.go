@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"strings"
+	"time"
+)
+
+type webhookRepo struct {
+	db *sql.DB
+}
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/webhook_mock.go -source=webhook.go
+type WebhookConnector interface {
+	// Create stores the webhook. ErrDuplicate is returned if it exists
+	Create(ctx context.Context, w model.Webhook) error
+	CheckIdempotency(ctx context.Context, webhookUUID string) error
+	// Get returns the webhook with its secret, ErrNoRows is returned if it does not exist
+	Get(ctx context.Context, uuid string) (model.Webhook, error)
+	// ListEnabled returns the enabled webhooks ordered by creation
+	ListEnabled(ctx context.Context) ([]model.Webhook, error)
+	// UpdateStatus stores whether the webhook is enabled, its consecutive failures and when it was
+	// disabled
+	UpdateStatus(ctx context.Context, w model.Webhook) error
+	// Enqueue queues the delivery of an event to a webhook, a delivery of the same event to the
+	// webhook queued before is left untouched
+	Enqueue(ctx context.Context, d model.WebhookDelivery) error
+	// DueDeliveries returns up to limit undelivered deliveries to enabled webhooks due at now,
+	// ordered by id
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	// RecordAttempt adds the attempt to the delivery log and stores the attempts and next attempt
+	// of the delivery, which is marked delivered if the attempt succeeded. It has to run in a unit
+	// of work for both to be stored atomically
+	RecordAttempt(ctx context.Context, d model.WebhookDelivery, a model.WebhookDeliveryAttempt) error
+	// ListAttempts returns up to limit attempts to deliver events to the webhook, newest first
+	ListAttempts(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDeliveryAttempt, error)
+}
+
+func NewWebhookRepo(db *sql.DB) WebhookConnector {
+	return &webhookRepo{
+		db,
+	}
+}
+
+const webhookColumns = `uuid, url, event_types, secret, enabled, consecutive_failures, created_at, disabled_at`
+
+// Create stores the webhook with its event types comma separated, times are stored in UTC as the
+// columns have no time zone
+func (r *webhookRepo) Create(ctx context.Context, w model.Webhook) error {
+	insertSQL := `INSERT INTO webhooks.subscription (` + webhookColumns + `)
+					values ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "webhook.create", insertSQL)
+	defer span.End()
+
+	res, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		w.UUID.String(),
+		w.URL,
+		strings.Join(w.EventTypes, ","),
+		w.Secret,
+		w.Enabled,
+		w.ConsecutiveFailures,
+		w.CreatedAt.UTC(),
+		nullTime(w.DisabledAt),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert webhook: %w", DBError(ctx, err)))
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert webhook: %w", err))
+	}
+	if affected == 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) CheckIdempotency(ctx context.Context, webhookUUID string) error {
+	checkIdempotencySQL := `SELECT COUNT(*) as count FROM webhooks.subscription where uuid = $1;`
+
+	ctx, span := startSpan(ctx, "webhook.check_idempotency", checkIdempotencySQL)
+	defer span.End()
+
+	var count uint64
+	if err := Conn(ctx, r.db).QueryRowContext(ctx, checkIdempotencySQL, webhookUUID).Scan(&count); err != nil {
+		return RecordError(span, fmt.Errorf("failed to scan while idempotency check: %w", DBError(ctx, err)))
+	}
+
+	if count > 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Get(ctx context.Context, uuid string) (model.Webhook, error) {
+	getSQL := `SELECT ` + webhookColumns + ` FROM webhooks.subscription where uuid = $1;`
+
+	ctx, span := startSpan(ctx, "webhook.get", getSQL)
+	defer span.End()
+
+	w, err := scanWebhook(Conn(ctx, r.db).QueryRowContext(ctx, getSQL, uuid))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, ErrNoRows
+		}
+
+		return model.Webhook{}, RecordError(span, fmt.Errorf("failed to scan webhook: %w", DBError(ctx, err)))
+	}
+
+	return w, nil
+}
+
+func (r *webhookRepo) ListEnabled(ctx context.Context) ([]model.Webhook, error) {
+	listSQL := `SELECT ` + webhookColumns + ` FROM webhooks.subscription where enabled ORDER BY id;`
+
+	ctx, span := startSpan(ctx, "webhook.list_enabled", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, listSQL)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query webhooks: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan webhook: %w", DBError(ctx, err)))
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate webhooks: %w", DBError(ctx, err)))
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepo) UpdateStatus(ctx context.Context, w model.Webhook) error {
+	updateSQL := `UPDATE webhooks.subscription SET enabled = $2, consecutive_failures = $3, disabled_at = $4 WHERE uuid = $1;`
+
+	ctx, span := startSpan(ctx, "webhook.update_status", updateSQL)
+	defer span.End()
+
+	_, err := Conn(ctx, r.db).ExecContext(ctx, updateSQL, w.UUID.String(), w.Enabled, w.ConsecutiveFailures, nullTime(w.DisabledAt))
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to update webhook status: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) Enqueue(ctx context.Context, d model.WebhookDelivery) error {
+	insertSQL := `INSERT INTO webhooks.delivery (webhook_uuid, event_uuid, event_type, payload, next_attempt_at)
+					values ($1, $2, $3, $4, $5) ON CONFLICT (webhook_uuid, event_uuid) DO NOTHING;`
+
+	ctx, span := startSpan(ctx, "webhook.enqueue", insertSQL)
+	defer span.End()
+
+	_, err := Conn(ctx, r.db).ExecContext(ctx, insertSQL,
+		d.WebhookUUID.String(),
+		d.EventUUID.String(),
+		d.EventType,
+		string(d.Payload),
+		d.NextAttemptAt.UTC(),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert webhook delivery: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	dueSQL := `SELECT d.id, d.webhook_uuid, d.event_uuid, d.event_type, d.payload, d.attempts, d.next_attempt_at
+					FROM webhooks.delivery d JOIN webhooks.subscription s ON s.uuid = d.webhook_uuid
+					WHERE d.delivered_at IS NULL AND d.next_attempt_at <= $1 AND s.enabled
+					ORDER BY d.id LIMIT $2;`
+
+	ctx, span := startSpan(ctx, "webhook.due_deliveries", dueSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, dueSQL, now.UTC(), limit)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query webhook deliveries: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.WebhookUUID,
+			&d.EventUUID,
+			&d.EventType,
+			&d.Payload,
+			&d.Attempts,
+			&d.NextAttemptAt,
+		); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan webhook delivery: %w", DBError(ctx, err)))
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate webhook deliveries: %w", DBError(ctx, err)))
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepo) RecordAttempt(ctx context.Context, d model.WebhookDelivery, a model.WebhookDeliveryAttempt) error {
+	updateSQL := `UPDATE webhooks.delivery SET attempts = $2, next_attempt_at = $3, delivered_at = $4 WHERE id = $1;`
+	insertSQL := `INSERT INTO webhooks.delivery_attempt (delivery_id, webhook_uuid, event_uuid, event_type, attempt, status_code,
+					error, succeeded, duration_ms, attempted_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+
+	ctx, span := startSpan(ctx, "webhook.record_attempt", updateSQL)
+	defer span.End()
+
+	var deliveredAt *time.Time
+	if a.Succeeded {
+		deliveredAt = &a.AttemptedAt
+	}
+
+	conn := Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, updateSQL, d.ID, d.Attempts, d.NextAttemptAt.UTC(), nullTime(deliveredAt)); err != nil {
+		return RecordError(span, fmt.Errorf("failed to update webhook delivery: %w", DBError(ctx, err)))
+	}
+
+	_, err := conn.ExecContext(ctx, insertSQL,
+		d.ID,
+		d.WebhookUUID.String(),
+		a.EventUUID.String(),
+		a.EventType,
+		a.Attempt,
+		a.StatusCode,
+		a.Error,
+		a.Succeeded,
+		a.DurationMS,
+		a.AttemptedAt.UTC(),
+	)
+	if err != nil {
+		return RecordError(span, fmt.Errorf("failed to insert webhook delivery attempt: %w", DBError(ctx, err)))
+	}
+
+	return nil
+}
+
+func (r *webhookRepo) ListAttempts(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDeliveryAttempt, error) {
+	listSQL := `SELECT event_uuid, event_type, attempt, status_code, error, succeeded, duration_ms, attempted_at
+					FROM webhooks.delivery_attempt where webhook_uuid = $1 ORDER BY id DESC LIMIT $2;`
+
+	ctx, span := startSpan(ctx, "webhook.list_attempts", listSQL)
+	defer span.End()
+
+	rows, err := Conn(ctx, r.db).QueryContext(ctx, listSQL, webhookUUID, limit)
+	if err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to query webhook delivery attempts: %w", DBError(ctx, err)))
+	}
+	defer rows.Close()
+
+	var attempts []model.WebhookDeliveryAttempt
+	for rows.Next() {
+		var a model.WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&a.EventUUID,
+			&a.EventType,
+			&a.Attempt,
+			&a.StatusCode,
+			&a.Error,
+			&a.Succeeded,
+			&a.DurationMS,
+			&a.AttemptedAt,
+		); err != nil {
+			return nil, RecordError(span, fmt.Errorf("failed to scan webhook delivery attempt: %w", DBError(ctx, err)))
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, RecordError(span, fmt.Errorf("failed to iterate webhook delivery attempts: %w", DBError(ctx, err)))
+	}
+
+	return attempts, nil
+}
+
+// scanWebhook scans a row of webhookColumns
+func scanWebhook(row rowScanner) (model.Webhook, error) {
+	var w model.Webhook
+	var eventTypes string
+	err := row.Scan(
+		&w.UUID,
+		&w.URL,
+		&eventTypes,
+		&w.Secret,
+		&w.Enabled,
+		&w.ConsecutiveFailures,
+		&w.CreatedAt,
+		&w.DisabledAt,
+	)
+	w.EventTypes = strings.Split(eventTypes, ",")
+
+	return w, err
+}
+
+// nullTime returns the time in UTC, or nil to store NULL
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.UTC()
+}
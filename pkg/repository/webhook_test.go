@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-jose/go-jose/v4/testutils/require"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type webhookSuite struct {
+	suite.Suite
+	repo WebhookConnector
+	db   sqlmock.Sqlmock
+}
+
+func TestWebhook(t *testing.T) {
+	suite.Run(t, new(webhookSuite))
+}
+
+func (s *webhookSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.repo = NewWebhookRepo(db)
+	s.db = mock
+}
+
+func (s *webhookSuite) TearDownTest() {
+	s.NoError(s.db.ExpectationsWereMet())
+}
+
+func mockWebhook() model.Webhook {
+	return model.Webhook{
+		UUID:       uuid.NewV5(uuid.Nil, "webhook"),
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{model.EventAccountCreated, model.EventTransactionCreated},
+		Secret:     "whsec_secret",
+		Enabled:    true,
+		CreatedAt:  time.Date(2025, time.October, 1, 6, 22, 46, 0, time.UTC),
+	}
+}
+
+func mockDelivery() model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:            7,
+		WebhookUUID:   uuid.NewV5(uuid.Nil, "webhook"),
+		EventUUID:     uuid.NewV5(uuid.Nil, "event"),
+		EventType:     model.EventAccountCreated,
+		Payload:       []byte(`{"type":"account.created"}`),
+		NextAttemptAt: time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC),
+	}
+}
+
+func (s *webhookSuite) TestCreateSuccess() {
+	webhook := mockWebhook()
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhooks.subscription (uuid, url, event_types, secret, enabled, consecutive_failures,
+					created_at, disabled_at) values ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (uuid) DO NOTHING;`)).
+		WithArgs(webhook.UUID.String(), webhook.URL, "account.created,transaction.created", webhook.Secret, true, 0,
+			webhook.CreatedAt, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// the time is stored in UTC
+	webhook.CreatedAt = webhook.CreatedAt.In(time.FixedZone("UTC-3", -3*60*60))
+	s.NoError(s.repo.Create(context.Background(), webhook))
+}
+
+func (s *webhookSuite) TestCreateDuplicate() {
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhooks.subscription`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s.ErrorIs(s.repo.Create(context.Background(), mockWebhook()), ErrDuplicate)
+}
+
+func (s *webhookSuite) TestCheckIdempotencyDuplicate() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) as count FROM webhooks.subscription where uuid = $1;`)).
+		WithArgs("some-uuid").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	s.ErrorIs(s.repo.CheckIdempotency(context.Background(), "some-uuid"), ErrDuplicate)
+}
+
+func (s *webhookSuite) TestGetSuccess() {
+	webhook := mockWebhook()
+	disabledAt := webhook.CreatedAt.Add(time.Hour)
+	webhook.Enabled, webhook.ConsecutiveFailures, webhook.DisabledAt = false, 10, &disabledAt
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT uuid, url, event_types, secret, enabled, consecutive_failures, created_at, disabled_at
+					FROM webhooks.subscription where uuid = $1;`)).
+		WithArgs(webhook.UUID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "url", "event_types", "secret", "enabled", "consecutive_failures",
+			"created_at", "disabled_at"}).
+			AddRow(webhook.UUID.String(), webhook.URL, "account.created,transaction.created", webhook.Secret, false, 10,
+				webhook.CreatedAt, disabledAt))
+
+	got, err := s.repo.Get(context.Background(), webhook.UUID.String())
+	s.NoError(err)
+	s.Equal(webhook, got)
+}
+
+func (s *webhookSuite) TestGetNotFound() {
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM webhooks.subscription where uuid = $1;`)).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}))
+
+	_, err := s.repo.Get(context.Background(), "some-uuid")
+	s.ErrorIs(err, ErrNoRows)
+}
+
+func (s *webhookSuite) TestUpdateStatusSuccess() {
+	webhook := mockWebhook()
+	disabledAt := time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC)
+	webhook.Enabled, webhook.ConsecutiveFailures, webhook.DisabledAt = false, 10, &disabledAt
+
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE webhooks.subscription SET enabled = $2, consecutive_failures = $3, disabled_at = $4
+					WHERE uuid = $1;`)).
+		WithArgs(webhook.UUID.String(), false, 10, disabledAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.UpdateStatus(context.Background(), webhook))
+}
+
+func (s *webhookSuite) TestEnqueueSuccess() {
+	delivery := mockDelivery()
+
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhooks.delivery (webhook_uuid, event_uuid, event_type, payload, next_attempt_at)
+					values ($1, $2, $3, $4, $5) ON CONFLICT (webhook_uuid, event_uuid) DO NOTHING;`)).
+		WithArgs(delivery.WebhookUUID.String(), delivery.EventUUID.String(), delivery.EventType, string(delivery.Payload),
+			delivery.NextAttemptAt).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	s.NoError(s.repo.Enqueue(context.Background(), delivery))
+}
+
+func (s *webhookSuite) TestDueDeliveriesSuccess() {
+	delivery := mockDelivery()
+	delivery.Attempts = 2
+	now := time.Date(2025, time.October, 1, 8, 0, 0, 0, time.UTC)
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT d.id, d.webhook_uuid, d.event_uuid, d.event_type, d.payload, d.attempts, d.next_attempt_at
+					FROM webhooks.delivery d JOIN webhooks.subscription s ON s.uuid = d.webhook_uuid
+					WHERE d.delivered_at IS NULL AND d.next_attempt_at <= $1 AND s.enabled
+					ORDER BY d.id LIMIT $2;`)).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_uuid", "event_uuid", "event_type", "payload", "attempts",
+			"next_attempt_at"}).
+			AddRow(7, delivery.WebhookUUID.String(), delivery.EventUUID.String(), delivery.EventType, delivery.Payload, 2,
+				delivery.NextAttemptAt))
+
+	got, err := s.repo.DueDeliveries(context.Background(), now, 100)
+	s.NoError(err)
+	s.Equal([]model.WebhookDelivery{delivery}, got)
+}
+
+func (s *webhookSuite) TestDueDeliveriesError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`FROM webhooks.delivery d`)).WillReturnError(mockError)
+
+	got, err := s.repo.DueDeliveries(context.Background(), time.Now(), 100)
+	s.ErrorIs(err, mockError)
+	s.Nil(got)
+}
+
+func (s *webhookSuite) expectRecordAttempt(d model.WebhookDelivery, a model.WebhookDeliveryAttempt, deliveredAt driver.Value) {
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE webhooks.delivery SET attempts = $2, next_attempt_at = $3, delivered_at = $4 WHERE id = $1;`)).
+		WithArgs(d.ID, d.Attempts, d.NextAttemptAt, deliveredAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.db.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhooks.delivery_attempt (delivery_id, webhook_uuid, event_uuid, event_type, attempt,
+					status_code, error, succeeded, duration_ms, attempted_at)
+					values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`)).
+		WithArgs(d.ID, d.WebhookUUID.String(), a.EventUUID.String(), a.EventType, a.Attempt, a.StatusCode, a.Error, a.Succeeded,
+			a.DurationMS, a.AttemptedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func (s *webhookSuite) TestRecordAttemptSucceeded() {
+	delivery := mockDelivery()
+	delivery.Attempts = 1
+	attempt := model.WebhookDeliveryAttempt{
+		EventUUID:   delivery.EventUUID,
+		EventType:   delivery.EventType,
+		Attempt:     1,
+		StatusCode:  200,
+		Succeeded:   true,
+		DurationMS:  42,
+		AttemptedAt: time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC),
+	}
+
+	// the delivery is marked delivered when the attempt was made
+	s.expectRecordAttempt(delivery, attempt, attempt.AttemptedAt)
+
+	s.NoError(s.repo.RecordAttempt(context.Background(), delivery, attempt))
+}
+
+func (s *webhookSuite) TestRecordAttemptFailed() {
+	delivery := mockDelivery()
+	delivery.Attempts = 1
+	attempt := model.WebhookDeliveryAttempt{
+		EventUUID:   delivery.EventUUID,
+		EventType:   delivery.EventType,
+		Attempt:     1,
+		Error:       "connection refused",
+		AttemptedAt: time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC),
+	}
+
+	s.expectRecordAttempt(delivery, attempt, nil)
+
+	s.NoError(s.repo.RecordAttempt(context.Background(), delivery, attempt))
+}
+
+func (s *webhookSuite) TestRecordAttemptError() {
+	mockError := errors.New("db error")
+
+	s.db.ExpectExec(regexp.QuoteMeta(`UPDATE webhooks.delivery`)).WillReturnError(mockError)
+
+	s.ErrorIs(s.repo.RecordAttempt(context.Background(), mockDelivery(), model.WebhookDeliveryAttempt{}), mockError)
+}
+
+func (s *webhookSuite) TestListAttemptsSuccess() {
+	attempt := model.WebhookDeliveryAttempt{
+		EventUUID:   uuid.NewV5(uuid.Nil, "event"),
+		EventType:   model.EventTransactionCreated,
+		Attempt:     3,
+		StatusCode:  503,
+		Error:       "webhook responded with status 503",
+		DurationMS:  12,
+		AttemptedAt: time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC),
+	}
+
+	s.db.ExpectQuery(regexp.QuoteMeta(`SELECT event_uuid, event_type, attempt, status_code, error, succeeded, duration_ms, attempted_at
+					FROM webhooks.delivery_attempt where webhook_uuid = $1 ORDER BY id DESC LIMIT $2;`)).
+		WithArgs("some-uuid", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"event_uuid", "event_type", "attempt", "status_code", "error", "succeeded",
+			"duration_ms", "attempted_at"}).
+			AddRow(attempt.EventUUID.String(), attempt.EventType, 3, 503, attempt.Error, false, 12, attempt.AttemptedAt))
+
+	got, err := s.repo.ListAttempts(context.Background(), "some-uuid", 50)
+	s.NoError(err)
+	s.Equal([]model.WebhookDeliveryAttempt{attempt}, got)
+}
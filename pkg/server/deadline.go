@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RouteTimeouts holds the request deadline of each route group, keyed by its path prefix
+// (e.g. "/api/v1/accounts"). Groups without an entry use Default, 0 disables the deadline
+type RouteTimeouts struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+func (rt RouteTimeouts) For(route string) time.Duration {
+	if d, ok := rt.Routes[route]; ok {
+		return d
+	}
+
+	return rt.Default
+}
+
+// Deadline bounds the request context by timeout, so that database calls made while serving
+// the request are cancelled once it expires
+func Deadline(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type deadlineTestSuite struct {
+	suite.Suite
+}
+
+func TestDeadline(t *testing.T) {
+	suite.Run(t, new(deadlineTestSuite))
+}
+
+// Request context carries the configured deadline
+func (s *deadlineTestSuite) TestDeadlineSet() {
+	var deadline time.Time
+	var ok bool
+	h := Deadline(time.Minute)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/", nil))
+
+	s.True(ok)
+	s.WithinDuration(time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+// Zero timeout leaves the request context untouched
+func (s *deadlineTestSuite) TestDeadlineDisabled() {
+	var ok bool
+	h := Deadline(0)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, ok = r.Context().Deadline()
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/", nil))
+
+	s.False(ok)
+}
+
+func (s *deadlineTestSuite) TestRouteTimeoutsFor() {
+	rt := RouteTimeouts{
+		Default: time.Second,
+		Routes:  map[string]time.Duration{"/api/v1/transactions": 3 * time.Second},
+	}
+
+	s.Equal(3*time.Second, rt.For("/api/v1/transactions"))
+	s.Equal(time.Second, rt.For("/api/v1/accounts"))
+}
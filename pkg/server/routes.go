@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	_ "go-pismo-challenge/docs"
 	"go-pismo-challenge/pkg/handler"
 
@@ -9,21 +10,60 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(a *handler.Account, t *handler.Transaction) *chi.Mux {
+// NewRouter returns the routes of the API, the streams served end once shutdown is done
+func NewRouter(timeouts RouteTimeouts, h Handlers, shutdown context.Context) *chi.Mux {
 	router := chi.NewRouter()
 
+	router.Use(middleware.RequestID)
+	router.Use(Tracing)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 
+	// probes
+	router.Get("/healthz", h.Health.Live)
+	router.Get("/readyz", h.Health.Ready)
+
 	// accounts
 	router.Route("/api/v1/accounts", func(r chi.Router) {
-		r.Post("/", a.Create)
-		r.Get("/{uuid}", a.Get)
+		// the event stream lasts as long as its client, the request deadline does not apply
+		r.With(Stream(shutdown)).Get("/{uuid}/events", handler.Handle(h.AccountEvent.Stream))
+
+		r.Group(func(r chi.Router) {
+			r.Use(Deadline(timeouts.For("/api/v1/accounts")))
+			r.Post("/", handler.Handle(h.Account.Create))
+			r.Get("/{uuid}", handler.Handle(h.Account.Get))
+			r.Get("/{uuid}/statements", handler.Handle(h.Statement.List))
+			r.Get("/{uuid}/statements/{id}", handler.Handle(h.Statement.Get))
+			r.Get("/{uuid}/accruals/preview", handler.Handle(h.Accrual.Preview))
+		})
 	})
 
 	// transactions
 	router.Route("/api/v1/transactions", func(r chi.Router) {
-		r.Post("/", t.Create)
+		r.Use(Deadline(timeouts.For("/api/v1/transactions")))
+		r.Post("/", handler.Handle(h.Transaction.Create))
+		r.Post("/batch", handler.Handle(h.Transaction.CreateBatch))
+	})
+
+	// transfers
+	router.Route("/api/v1/transfers", func(r chi.Router) {
+		r.Use(Deadline(timeouts.For("/api/v1/transfers")))
+		r.Post("/", handler.Handle(h.Transfer.Create))
+	})
+
+	// webhooks
+	router.Route("/api/v1/webhooks", func(r chi.Router) {
+		r.Use(Deadline(timeouts.For("/api/v1/webhooks")))
+		r.Post("/", handler.Handle(h.Webhook.Create))
+		r.Get("/{uuid}", handler.Handle(h.Webhook.Get))
+		r.Post("/{uuid}/enable", handler.Handle(h.Webhook.Enable))
+		r.Get("/{uuid}/deliveries", handler.Handle(h.Webhook.Deliveries))
+	})
+
+	// ledger
+	router.Route("/api/v1/ledger", func(r chi.Router) {
+		r.Use(Deadline(timeouts.For("/api/v1/ledger")))
+		r.Get("/trial-balance", handler.Handle(h.Ledger.TrialBalance))
 	})
 
 	// serve swagger UI
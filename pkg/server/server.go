@@ -1,15 +1,121 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"go-pismo-challenge/pkg/handler"
 	"net/http"
+	"os"
+	"time"
 )
 
-func NewServer(a *handler.Account, t *handler.Transaction) *http.Server {
-	r := NewRouter(a, t)
+var (
+	ErrIncompleteTLSConfig = errors.New("both TLS cert and key files must be set")
+	ErrInvalidClientCA     = errors.New("no certificates found in client CA file")
+)
+
+type Options struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// MaxRequestBodyBytes limits the size of request bodies, 0 means unlimited
+	MaxRequestBodyBytes int64
+	RequestTimeouts     RouteTimeouts
+
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+}
+
+// Handlers serve the routes of the API
+type Handlers struct {
+	Account      *handler.Account
+	AccountEvent *handler.AccountEvent
+	Transaction  *handler.Transaction
+	Transfer     *handler.Transfer
+	Webhook      *handler.Webhook
+	Statement    *handler.Statement
+	Accrual      *handler.Accrual
+	Ledger       *handler.Ledger
+	Health       *handler.Health
+}
+
+// NewServer returns the server of the API. Shutting it down ends the streams it serves, which
+// Shutdown would wait for otherwise
+func NewServer(opts Options, h Handlers) (*http.Server, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdown, endStreams := context.WithCancel(context.Background())
+	var r http.Handler = NewRouter(opts.RequestTimeouts, h, shutdown)
+	if opts.MaxRequestBodyBytes > 0 {
+		r = http.MaxBytesHandler(r, opts.MaxRequestBodyBytes)
+	}
+
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           r,
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+		TLSConfig:         tlsConfig,
+	}
+	srv.RegisterOnShutdown(endStreams)
+
+	return srv, nil
+}
 
-	return &http.Server{
-		Addr:    ":3000",
-		Handler: r,
+// ListenAndServe serves TLS if the server was configured with a certificate, plain HTTP otherwise
+func ListenAndServe(srv *http.Server) error {
+	if srv.TLSConfig != nil {
+		return srv.ListenAndServeTLS("", "")
 	}
+
+	return srv.ListenAndServe()
+}
+
+// returns nil when TLS is not configured
+func newTLSConfig(opts Options) (*tls.Config, error) {
+	if opts.TLSCertFile == "" && opts.TLSKeyFile == "" {
+		return nil, nil //nolint:nilnil // TLS disabled
+	}
+	if opts.TLSCertFile == "" || opts.TLSKeyFile == "" {
+		return nil, ErrIncompleteTLSConfig
+	}
+
+	cert, err := tls.LoadX509KeyPair(opts.TLSCertFile, opts.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if opts.TLSClientCAFile != "" {
+		caPEM, err := os.ReadFile(opts.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidClientCA, opts.TLSClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
 }
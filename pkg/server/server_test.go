@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"go-pismo-challenge/pkg/handler"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/service/mocks"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type serverTestSuite struct {
+	suite.Suite
+	health *handler.Health
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(serverTestSuite))
+}
+
+func (s *serverTestSuite) SetupTest() {
+	s.health = handler.NewHealthHandler(time.Second)
+}
+
+// Timeouts and limits are copied onto the http.Server
+func (s *serverTestSuite) TestNewServerOptions() {
+	srv, err := NewServer(Options{
+		Addr:              ":8080",
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+		MaxHeaderBytes:    512,
+	}, Handlers{Health: s.health})
+	s.Require().NoError(err)
+
+	s.Equal(":8080", srv.Addr)
+	s.Equal(time.Second, srv.ReadTimeout)
+	s.Equal(2*time.Second, srv.ReadHeaderTimeout)
+	s.Equal(3*time.Second, srv.WriteTimeout)
+	s.Equal(4*time.Second, srv.IdleTimeout)
+	s.Equal(512, srv.MaxHeaderBytes)
+	s.Nil(srv.TLSConfig)
+}
+
+// Request bodies above the limit are rejected while decoding
+func (s *serverTestSuite) TestMaxRequestBodyBytes() {
+	srv, err := NewServer(Options{MaxRequestBodyBytes: 8}, Handlers{Account: handler.NewAccountHandler(nil), Health: s.health})
+	s.Require().NoError(err)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodPost, "/api/v1/accounts/",
+		strings.NewReader(`{"document_number": "abc", "idempotency_key": "abc"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	srv.Handler.ServeHTTP(rec, req)
+
+	s.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	s.Regexp("payload_too_large", rec.Body.String())
+}
+
+// TLS requires both cert and key
+func (s *serverTestSuite) TestIncompleteTLSConfig() {
+	_, err := NewServer(Options{TLSCertFile: "cert.pem"}, Handlers{Health: s.health})
+	s.ErrorIs(err, ErrIncompleteTLSConfig)
+}
+
+// mTLS: clients without a certificate signed by the client CA are rejected
+func (s *serverTestSuite) TestMutualTLS() {
+	dir := s.T().TempDir()
+	caCert, caKey := s.writeCert(dir, "ca", nil, nil)
+	s.writeCert(dir, "server", caCert, caKey)
+	clientCert, _ := s.writeCert(dir, "client", caCert, caKey)
+
+	srv, err := NewServer(Options{
+		TLSCertFile:     filepath.Join(dir, "server.crt"),
+		TLSKeyFile:      filepath.Join(dir, "server.key"),
+		TLSClientCAFile: filepath.Join(dir, "ca.crt"),
+	}, Handlers{Health: s.health})
+	s.Require().NoError(err)
+	s.Equal(tls.RequireAndVerifyClientCert, srv.TLSConfig.ClientAuth)
+
+	ts := httptest.NewUnstartedServer(srv.Handler)
+	ts.TLS = srv.TLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	// without client certificate
+	noCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}}}
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, ts.URL+"/healthz", nil)
+	s.Require().NoError(err)
+	resp, err := noCert.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	s.Error(err)
+
+	// with client certificate
+	keyPair, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	s.Require().NoError(err)
+	s.Equal(clientCert.Raw, keyPair.Certificate[0])
+
+	withCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{keyPair},
+		MinVersion:   tls.VersionTLS12,
+	}}}
+	resp, err = withCert.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+// Streams outlive the write timeout and the request deadline, and end when the server shuts down
+func (s *serverTestSuite) TestStreamEndsOnShutdown() {
+	ctrl := gomock.NewController(s.T())
+	accountEvents := mocks.NewMockAccountEventService(ctrl)
+	accountEvents.EXPECT().Stream(gomock.Any(), "some-uuid", nil, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ *int64, send func(model.AccountEvent) error) error {
+			for id := range int64(2) {
+				time.Sleep(100 * time.Millisecond)
+				if err := send(model.AccountEvent{ID: id, Type: model.AccountEventBalance, Data: 0}); err != nil {
+					return err
+				}
+			}
+			<-ctx.Done()
+
+			return nil
+		})
+
+	srv, err := NewServer(Options{
+		WriteTimeout:    50 * time.Millisecond,
+		RequestTimeouts: RouteTimeouts{Default: 50 * time.Millisecond},
+	}, Handlers{AccountEvent: handler.NewAccountEventHandler(accountEvents, 0, 0), Health: s.health})
+	s.Require().NoError(err)
+
+	ts := httptest.NewUnstartedServer(srv.Handler)
+	ts.Config = srv
+	ts.Start()
+	defer ts.Close()
+
+	req, err := http.NewRequestWithContext(s.T().Context(), http.MethodGet, ts.URL+"/api/v1/accounts/some-uuid/events", nil)
+	s.Require().NoError(err)
+	resp, err := ts.Client().Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	body := bufio.NewReader(resp.Body)
+	for _, expected := range []string{"id: 0\n", "event: balance\n", "data: 0\n", "\n", "id: 1\n"} {
+		line, err := body.ReadString('\n')
+		s.Require().NoError(err)
+		s.Equal(expected, line)
+	}
+
+	ctx, cancel := context.WithTimeout(s.T().Context(), time.Second)
+	defer cancel()
+	s.Require().NoError(srv.Shutdown(ctx))
+
+	rest, err := io.ReadAll(body)
+	s.Require().NoError(err)
+	s.Equal("event: balance\ndata: 0\n\n", string(rest))
+}
+
+// writes <name>.crt and <name>.key into dir, self-signed when parent is nil
+func (s *serverTestSuite) writeCert(dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	s.Require().NoError(err)
+	cert, err := x509.ParseCertificate(der)
+	s.Require().NoError(err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	s.Require().NoError(err)
+
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return cert, key
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Stream serves long-lived responses such as event streams. The read and write timeouts of the
+// server are lifted, the handler bounds its own writes, and the request context is cancelled
+// once shutdown is done, as Shutdown waits for the requests in flight to complete
+func Stream(shutdown context.Context) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// not every response writer supports deadlines, those have none to lift
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(time.Time{})
+			_ = rc.SetWriteDeadline(time.Time{})
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			stop := context.AfterFunc(shutdown, cancel)
+			defer stop()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingTestSuite struct {
+	suite.Suite
+	recorder *tracetest.SpanRecorder
+	router   *chi.Mux
+}
+
+func TestTracing(t *testing.T) {
+	suite.Run(t, new(tracingTestSuite))
+}
+
+func (s *tracingTestSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	s.router = chi.NewRouter()
+	s.router.Use(Tracing)
+	s.router.Get("/accounts/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
+// Span is named after the route pattern and carries the response status
+func (s *tracingTestSuite) TestSpanNamedAfterRoute() {
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/abc", nil)
+	rec := httptest.NewRecorder()
+
+	s.router.ServeHTTP(rec, req)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("GET /accounts/{uuid}", spans[0].Name())
+	s.Equal(trace.SpanKindServer, spans[0].SpanKind())
+	s.Equal(spans[0].SpanContext().TraceID().String(), rec.Header().Get("X-Trace-Id"))
+}
+
+// Incoming traceparent header is continued instead of starting a new trace
+func (s *tracingTestSuite) TestTraceparentPropagated() {
+	req := httptest.NewRequestWithContext(s.T().Context(), http.MethodGet, "/accounts/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	s.router.ServeHTTP(rec, req)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	s.Equal("00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get("X-Trace-Id"))
+}
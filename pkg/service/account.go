@@ -0,0 +1,125 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/account_mock.go -source=account.go
+type AccountService interface {
+	// Create stores a new account and adds its account.created event to the outbox, the uuid is
+	// derived from the idempotency key of req
+	Create(ctx context.Context, req model.AccountRequest) (model.Account, error)
+	Get(ctx context.Context, accountUUID string) (model.Account, error)
+}
+
+type accountService struct {
+	accountRepo  repository.AccountConnector
+	ratePlanRepo repository.RatePlanConnector
+	outboxRepo   repository.OutboxConnector
+	uow          repository.UnitOfWork
+}
+
+func NewAccountService(
+	a repository.AccountConnector,
+	r repository.RatePlanConnector,
+	o repository.OutboxConnector,
+	uow repository.UnitOfWork,
+) AccountService {
+	return &accountService{
+		accountRepo:  a,
+		ratePlanRepo: r,
+		outboxRepo:   o,
+		uow:          uow,
+	}
+}
+
+func (s *accountService) Create(ctx context.Context, req model.AccountRequest) (model.Account, error) {
+	if vErr := req.Validate(); len(vErr) > 0 {
+		return model.Account{}, &ValidationError{Fields: vErr}
+	}
+
+	accountUUID := uuid.NewV5(uuid.Nil, req.IdempotencyKey)
+
+	var account model.Account
+	// the account and its event are stored in one serializable transaction, so that concurrent
+	// requests with the same idempotency key cannot both pass the check
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var err error
+		account, err = s.create(ctx, accountUUID, req)
+
+		return err
+	})
+	if err != nil {
+		return model.Account{}, err
+	}
+
+	return account, nil
+}
+
+// create validates the rate plan of req, stores the account and adds its event to the outbox
+func (s *accountService) create(ctx context.Context, accountUUID uuid.UUID, req model.AccountRequest) (model.Account, error) {
+	if err := s.accountRepo.CheckIdempotency(ctx, accountUUID.String()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Account{}, ErrDuplicateRequest
+		}
+
+		return model.Account{}, err
+	}
+
+	// validate rate plan
+	ratePlanID := cmp.Or(req.RatePlanID, model.DefaultRatePlanID)
+	if _, err := s.ratePlanRepo.Get(ctx, ratePlanID); err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Account{}, fmt.Errorf("%w: %d", ErrRatePlanNotFound, ratePlanID)
+		}
+
+		return model.Account{}, err
+	}
+
+	account := model.Account{
+		UUID:           accountUUID,
+		DocumentNumber: req.DocumentNumber,
+		ClosingDay:     cmp.Or(req.ClosingDay, model.DefaultClosingDay),
+		DueDay:         cmp.Or(req.DueDay, model.DefaultDueDay),
+		RatePlanID:     ratePlanID,
+		Status:         model.AccountStatusActive,
+		CreditLimit:    req.CreditLimit,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.accountRepo.Create(ctx, account); err != nil {
+		return model.Account{}, err
+	}
+
+	event, err := newEvent(model.EventAccountCreated, account.UUID, account.UUID, account, account.CreatedAt)
+	if err != nil {
+		return model.Account{}, err
+	}
+
+	if err := s.outboxRepo.Add(ctx, event); err != nil {
+		return model.Account{}, err
+	}
+
+	return account, nil
+}
+
+func (s *accountService) Get(ctx context.Context, accountUUID string) (model.Account, error) {
+	account, err := s.accountRepo.Get(ctx, accountUUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Account{}, fmt.Errorf("%w for uuid: '%s'", ErrAccountNotFound, accountUUID)
+		}
+
+		return model.Account{}, err
+	}
+
+	return account, nil
+}
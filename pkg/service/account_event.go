@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// endOfTime bounds the entries counted in the balance of a stream, so that it counts every entry
+// posted whatever the clock of the instance that posted it
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/account_event_mock.go -source=account_event.go
+type AccountEventService interface {
+	// Listen wakes the streams of the accounts transactions are committed for, until ctx is done
+	Listen(ctx context.Context) error
+	// Stream sends the activity of the account until ctx is done or send fails: every transaction
+	// of the account with an id greater than lastEventID, each batch followed by the balance of
+	// the account. Without lastEventID the stream starts after the last transaction, and a stream
+	// with no transaction to send starts with the balance
+	Stream(ctx context.Context, accountUUID string, lastEventID *int64, send func(model.AccountEvent) error) error
+}
+
+// AccountEventOptions configure the streams, BatchSize transactions are read at a time
+type AccountEventOptions struct {
+	BatchSize int
+}
+
+type accountEventService struct {
+	trxRepo     repository.TransactionConnector
+	accountRepo repository.AccountConnector
+	ledgerRepo  repository.LedgerConnector
+	listener    repository.TransactionListener
+	opts        AccountEventOptions
+
+	mu sync.Mutex
+	// streams holds the wake up channel of every stream by account uuid
+	streams map[string]map[chan struct{}]struct{}
+}
+
+func NewAccountEventService(
+	t repository.TransactionConnector,
+	a repository.AccountConnector,
+	l repository.LedgerConnector,
+	listener repository.TransactionListener,
+	opts AccountEventOptions,
+) AccountEventService {
+	return &accountEventService{
+		trxRepo:     t,
+		accountRepo: a,
+		ledgerRepo:  l,
+		listener:    listener,
+		opts:        opts,
+		streams:     map[string]map[chan struct{}]struct{}{},
+	}
+}
+
+func (s *accountEventService) Listen(ctx context.Context) error {
+	return s.listener.Listen(ctx, s.wake)
+}
+
+func (s *accountEventService) Stream(
+	ctx context.Context,
+	accountUUID string,
+	lastEventID *int64,
+	send func(model.AccountEvent) error,
+) error {
+	account, err := s.accountRepo.Get(ctx, accountUUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return fmt.Errorf("%w for uuid: '%s'", ErrAccountNotFound, accountUUID)
+		}
+
+		return err
+	}
+
+	// the stream subscribes before reading, so that a transaction committed in between wakes it
+	wake := s.subscribe(account.UUID.String())
+	defer s.unsubscribe(account.UUID.String(), wake)
+
+	var cursor int64
+	if lastEventID != nil {
+		cursor = *lastEventID
+	} else if cursor, err = s.trxRepo.LatestID(ctx, account.UUID.String()); err != nil {
+		return streamError(ctx, err)
+	}
+
+	next, err := s.catchUp(ctx, account.UUID, cursor, send)
+	if err != nil {
+		return streamError(ctx, err)
+	}
+	// a stream with nothing to catch up on starts with the balance
+	if next == cursor {
+		if err := s.sendBalance(ctx, account.UUID, cursor, send); err != nil {
+			return streamError(ctx, err)
+		}
+	}
+	cursor = next
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		}
+
+		if cursor, err = s.catchUp(ctx, account.UUID, cursor, send); err != nil {
+			return streamError(ctx, err)
+		}
+	}
+}
+
+// catchUp sends the transactions of the account after cursor, and returns the id of the last one
+// sent
+func (s *accountEventService) catchUp(
+	ctx context.Context,
+	accountUUID uuid.UUID,
+	cursor int64,
+	send func(model.AccountEvent) error,
+) (int64, error) {
+	for {
+		transactions, err := s.trxRepo.ListAfter(ctx, accountUUID.String(), cursor, s.opts.BatchSize)
+		if err != nil {
+			return cursor, err
+		}
+		if len(transactions) == 0 {
+			return cursor, nil
+		}
+
+		for _, trx := range transactions {
+			if err := send(model.AccountEvent{ID: trx.ID, Type: model.AccountEventTransaction, Data: trx.Transaction}); err != nil {
+				return cursor, err
+			}
+			cursor = trx.ID
+		}
+
+		// the balance is read after the batch and may count transactions committed since, which
+		// the next batch sends
+		if err := s.sendBalance(ctx, accountUUID, cursor, send); err != nil {
+			return cursor, err
+		}
+
+		if len(transactions) < s.opts.BatchSize {
+			return cursor, nil
+		}
+	}
+}
+
+func (s *accountEventService) sendBalance(ctx context.Context, accountUUID uuid.UUID, id int64, send func(model.AccountEvent) error) error {
+	balance, err := s.ledgerRepo.Balance(ctx, accountUUID.String(), model.LedgerAccountReceivable, endOfTime)
+	if err != nil {
+		return err
+	}
+
+	return send(model.AccountEvent{
+		ID:   id,
+		Type: model.AccountEventBalance,
+		Data: model.AccountBalance{AccountUUID: accountUUID, Balance: roundCents(balance)},
+	})
+}
+
+// wake wakes the streams of the account, or every stream when accountUUID is empty. A stream
+// still sending is woken once when done, however many transactions were committed meanwhile,
+// so that slow streams never hold back the listener
+func (s *accountEventService) wake(accountUUID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for account, streams := range s.streams {
+		if accountUUID != "" && account != accountUUID {
+			continue
+		}
+
+		for wake := range streams {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *accountEventService) subscribe(accountUUID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wake := make(chan struct{}, 1)
+	if s.streams[accountUUID] == nil {
+		s.streams[accountUUID] = map[chan struct{}]struct{}{}
+	}
+	s.streams[accountUUID][wake] = struct{}{}
+
+	return wake
+}
+
+func (s *accountEventService) unsubscribe(accountUUID string, wake chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.streams[accountUUID], wake)
+	if len(s.streams[accountUUID]) == 0 {
+		delete(s.streams, accountUUID)
+	}
+}
+
+// streamError returns nil for the errors of a stream ended by its client or by shutdown
+func streamError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"strconv"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type accountEventServiceSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	service      AccountEventService
+	mockTrx      *mocks.MockTransactionConnector
+	mockAccounts *mocks.MockAccountConnector
+	mockLedger   *mocks.MockLedgerConnector
+	mockListener *mocks.MockTransactionListener
+}
+
+func TestAccountEventService(t *testing.T) {
+	suite.Run(t, new(accountEventServiceSuite))
+}
+
+func (s *accountEventServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockTrx = mocks.NewMockTransactionConnector(s.ctrl)
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockLedger = mocks.NewMockLedgerConnector(s.ctrl)
+	s.mockListener = mocks.NewMockTransactionListener(s.ctrl)
+
+	s.service = NewAccountEventService(s.mockTrx, s.mockAccounts, s.mockLedger, s.mockListener, AccountEventOptions{BatchSize: 2})
+}
+
+func (s *accountEventServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func (s *accountEventServiceSuite) expectAccount() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).
+		Return(model.Account{UUID: uuid.FromStringOrNil(mockAccountUUID)}, nil)
+}
+
+func (s *accountEventServiceSuite) expectBalance(balance float64) *gomock.Call {
+	return s.mockLedger.EXPECT().Balance(gomock.Any(), mockAccountUUID, model.LedgerAccountReceivable, endOfTime).Return(balance, nil)
+}
+
+func storedTransaction(id int64) model.StoredTransaction {
+	return model.StoredTransaction{
+		Transaction: model.Transaction{
+			UUID:            uuid.NewV5(uuid.Nil, "transaction"+strconv.FormatInt(id, 10)),
+			AccountUUID:     uuid.FromStringOrNil(mockAccountUUID),
+			OperationTypeID: 1,
+			Amount:          -10,
+		},
+		ID: id,
+	}
+}
+
+func transactionEvent(id int64) model.AccountEvent {
+	return model.AccountEvent{ID: id, Type: model.AccountEventTransaction, Data: storedTransaction(id).Transaction}
+}
+
+func balanceEvent(id int64, balance float64) model.AccountEvent {
+	return model.AccountEvent{
+		ID:   id,
+		Type: model.AccountEventBalance,
+		Data: model.AccountBalance{AccountUUID: uuid.FromStringOrNil(mockAccountUUID), Balance: balance},
+	}
+}
+
+// collect returns a send func appending the events to the slice
+func collect(events *[]model.AccountEvent) func(model.AccountEvent) error {
+	return func(e model.AccountEvent) error {
+		*events = append(*events, e)
+
+		return nil
+	}
+}
+
+func (s *accountEventServiceSuite) TestStreamAccountNotFound() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(model.Account{}, repository.ErrNoRows)
+
+	err := s.service.Stream(context.Background(), mockAccountUUID, nil, collect(new([]model.AccountEvent)))
+	s.ErrorIs(err, ErrAccountNotFound)
+}
+
+// Without a last event id the stream starts with the balance, the transactions committed before
+// are not sent
+func (s *accountEventServiceSuite) TestStreamStartsWithBalance() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.expectAccount()
+	s.mockTrx.EXPECT().LatestID(gomock.Any(), mockAccountUUID).Return(int64(5), nil)
+	gomock.InOrder(
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(5), 2).Return(nil, nil),
+		s.expectBalance(60.5).Do(func(context.Context, string, int, any) { cancel() }),
+	)
+
+	var events []model.AccountEvent
+	s.Require().NoError(s.service.Stream(ctx, mockAccountUUID, nil, collect(&events)))
+	s.Equal([]model.AccountEvent{balanceEvent(5, 60.5)}, events)
+}
+
+// The stream resumes after the last event id, in batches each followed by the balance
+func (s *accountEventServiceSuite) TestStreamResumes() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.expectAccount()
+	gomock.InOrder(
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(3), 2).
+			Return([]model.StoredTransaction{storedTransaction(4), storedTransaction(5)}, nil),
+		s.expectBalance(20),
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(5), 2).
+			Return([]model.StoredTransaction{storedTransaction(6)}, nil),
+		s.expectBalance(30).Do(func(context.Context, string, int, any) { cancel() }),
+	)
+
+	lastEventID := int64(3)
+	var events []model.AccountEvent
+	s.Require().NoError(s.service.Stream(ctx, mockAccountUUID, &lastEventID, collect(&events)))
+	s.Equal([]model.AccountEvent{
+		transactionEvent(4), transactionEvent(5), balanceEvent(5, 20),
+		transactionEvent(6), balanceEvent(6, 30),
+	}, events)
+}
+
+// Notifications for the account or for every account wake the stream, the others do not
+func (s *accountEventServiceSuite) TestStreamWakesOnNotification() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var notify func(string)
+	s.mockListener.EXPECT().Listen(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(string)) error {
+			notify = fn
+
+			return nil
+		})
+	s.Require().NoError(s.service.Listen(ctx))
+
+	s.expectAccount()
+	s.mockTrx.EXPECT().LatestID(gomock.Any(), mockAccountUUID).Return(int64(0), nil)
+	gomock.InOrder(
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(0), 2).Return(nil, nil),
+		s.expectBalance(0),
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(0), 2).
+			Return([]model.StoredTransaction{storedTransaction(1)}, nil),
+		s.expectBalance(10),
+		s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(1), 2).
+			DoAndReturn(func(context.Context, string, int64, int) ([]model.StoredTransaction, error) {
+				cancel()
+
+				return nil, nil
+			}),
+	)
+
+	events := make(chan model.AccountEvent, 4)
+	done := make(chan error)
+	go func() {
+		done <- s.service.Stream(ctx, mockAccountUUID, nil, func(e model.AccountEvent) error {
+			events <- e
+
+			return nil
+		})
+	}()
+
+	s.Equal(balanceEvent(0, 0), <-events)
+	// the stream is subscribed before its first event, a wake up sent while it reads is kept
+	notify(uuid.NewV5(uuid.Nil, "other").String())
+	notify(mockAccountUUID)
+	s.Equal(transactionEvent(1), <-events)
+	s.Equal(balanceEvent(1, 10), <-events)
+
+	notify("")
+	s.NoError(<-done)
+}
+
+func (s *accountEventServiceSuite) TestStreamSendFailed() {
+	sendErr := errors.New("broken pipe")
+
+	s.expectAccount()
+	s.mockTrx.EXPECT().LatestID(gomock.Any(), mockAccountUUID).Return(int64(5), nil)
+	s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(5), 2).Return(nil, nil)
+	s.expectBalance(60.5)
+
+	err := s.service.Stream(context.Background(), mockAccountUUID, nil, func(model.AccountEvent) error {
+		return sendErr
+	})
+	s.ErrorIs(err, sendErr)
+}
+
+func (s *accountEventServiceSuite) TestStreamReadFailed() {
+	mockDBError := errors.New("some-db-error")
+
+	s.expectAccount()
+	s.mockTrx.EXPECT().ListAfter(gomock.Any(), mockAccountUUID, int64(3), 2).Return(nil, mockDBError)
+
+	lastEventID := int64(3)
+	err := s.service.Stream(context.Background(), mockAccountUUID, &lastEventID, collect(new([]model.AccountEvent)))
+	s.ErrorIs(err, mockDBError)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type accountServiceSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	service      AccountService
+	mockAccounts *mocks.MockAccountConnector
+	mockPlans    *mocks.MockRatePlanConnector
+	mockOutbox   *mocks.MockOutboxConnector
+	mockUoW      *mocks.MockUnitOfWork
+}
+
+func TestAccountService(t *testing.T) {
+	suite.Run(t, new(accountServiceSuite))
+}
+
+func (s *accountServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+
+	s.mockPlans = mocks.NewMockRatePlanConnector(s.ctrl)
+	s.mockOutbox = mocks.NewMockOutboxConnector(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	s.service = NewAccountService(s.mockAccounts, s.mockPlans, s.mockOutbox, s.mockUoW)
+}
+
+func (s *accountServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func validAccountRequest() model.AccountRequest {
+	return model.AccountRequest{
+		DocumentNumber: "abc",
+		IdempotencyKey: "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}
+}
+
+// Returns the uuid derived from the idempotency key of the test requests
+func getMockUUID() uuid.UUID {
+	return uuid.NewV5(uuid.Nil, "bc1f3956-e92e-4666-a5cd-4cbbd937b17f")
+}
+
+func (s *accountServiceSuite) TestCreateSuccess() {
+	accountUUID := getMockUUID()
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), accountUUID.String()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), model.DefaultRatePlanID).Return(model.RatePlan{ID: model.DefaultRatePlanID}, nil)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, a model.Account) error {
+			s.Equal(accountUUID, a.UUID)
+			s.Equal("abc", a.DocumentNumber)
+			s.Equal(model.DefaultClosingDay, a.ClosingDay)
+			s.Equal(model.DefaultDueDay, a.DueDay)
+			s.Equal(model.DefaultRatePlanID, a.RatePlanID)
+			s.Equal(model.AccountStatusActive, a.Status)
+			s.Zero(a.CreditLimit)
+			s.WithinDuration(time.Now(), a.CreatedAt, time.Second)
+
+			return nil
+		})
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e model.Event) error {
+			s.Equal(uuid.NewV5(accountUUID, model.EventAccountCreated), e.UUID)
+			s.Equal(model.EventAccountCreated, e.Type)
+			s.Equal(accountUUID, e.AccountUUID)
+			s.WithinDuration(time.Now(), e.CreatedAt, time.Second)
+
+			var a model.Account
+			s.Require().NoError(json.Unmarshal(e.Data, &a))
+			s.Equal(accountUUID, a.UUID)
+			s.Equal("abc", a.DocumentNumber)
+
+			return nil
+		})
+
+	account, err := s.service.Create(context.Background(), validAccountRequest())
+	s.Require().NoError(err)
+	s.Equal(accountUUID, account.UUID)
+}
+
+func (s *accountServiceSuite) TestCreateBillingCycle() {
+	req := validAccountRequest()
+	req.ClosingDay, req.DueDay = 25, 5
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), gomock.Any()).Return(model.RatePlan{}, nil)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+
+	account, err := s.service.Create(context.Background(), req)
+	s.Require().NoError(err)
+	s.Equal(25, account.ClosingDay)
+	s.Equal(5, account.DueDay)
+}
+
+func (s *accountServiceSuite) TestCreateRatePlan() {
+	req := validAccountRequest()
+	req.RatePlanID = 2
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), 2).Return(model.RatePlan{ID: 2}, nil)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+
+	account, err := s.service.Create(context.Background(), req)
+	s.Require().NoError(err)
+	s.Equal(2, account.RatePlanID)
+}
+
+func (s *accountServiceSuite) TestCreateRatePlanNotFound() {
+	req := validAccountRequest()
+	req.RatePlanID = 999
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), 999).Return(model.RatePlan{}, repository.ErrNoRows)
+
+	_, err := s.service.Create(context.Background(), req)
+	s.ErrorIs(err, ErrRatePlanNotFound)
+	s.ErrorContains(err, "999")
+}
+
+// `document_number` and `idempotency_key` are required
+func (s *accountServiceSuite) TestCreateValidationFailed() {
+	_, err := s.service.Create(context.Background(), model.AccountRequest{})
+
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Len(vErr.Fields, 2)
+	s.Equal("invalid request: document_number, idempotency_key", err.Error())
+}
+
+func (s *accountServiceSuite) TestCreateDuplicate() {
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validAccountRequest())
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+func (s *accountServiceSuite) TestCreateFailed() {
+	mockDBError := errors.New("some db error")
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), model.DefaultRatePlanID).Return(model.RatePlan{}, nil)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Create(context.Background(), validAccountRequest())
+	s.ErrorIs(err, mockDBError)
+}
+
+// the account is not stored without its event
+func (s *accountServiceSuite) TestCreateOutboxFailed() {
+	mockDBError := errors.New("some db error")
+
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), model.DefaultRatePlanID).Return(model.RatePlan{}, nil)
+	s.mockAccounts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Create(context.Background(), validAccountRequest())
+	s.ErrorIs(err, mockDBError)
+}
+
+// storage timeouts are passed on, so that callers can report them
+func (s *accountServiceSuite) TestCreateIdempotencyTimeout() {
+	s.mockAccounts.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(repository.ErrQueryTimeout)
+
+	_, err := s.service.Create(context.Background(), validAccountRequest())
+	s.ErrorIs(err, repository.ErrQueryTimeout)
+}
+
+func (s *accountServiceSuite) TestGetSuccess() {
+	expected := model.Account{
+		UUID:           getMockUUID(),
+		DocumentNumber: "abc",
+		CreatedAt:      time.Now(),
+	}
+	s.mockAccounts.EXPECT().Get(gomock.Any(), expected.UUID.String()).Return(expected, nil)
+
+	account, err := s.service.Get(context.Background(), expected.UUID.String())
+	s.NoError(err)
+	s.Equal(expected, account)
+}
+
+func (s *accountServiceSuite) TestGetNotFound() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), "missing").Return(model.Account{}, repository.ErrNoRows)
+
+	_, err := s.service.Get(context.Background(), "missing")
+	s.ErrorIs(err, ErrAccountNotFound)
+	s.Equal("account not found for uuid: 'missing'", err.Error())
+}
+
+func (s *accountServiceSuite) TestGetFailed() {
+	mockDBError := errors.New("some db error")
+	s.mockAccounts.EXPECT().Get(gomock.Any(), "uuid").Return(model.Account{}, mockDBError)
+
+	_, err := s.service.Get(context.Background(), "uuid")
+	s.ErrorIs(err, mockDBError)
+	s.NotErrorIs(err, ErrAccountNotFound)
+}
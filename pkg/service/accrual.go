@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/accrual_mock.go -source=accrual.go
+type AccrualService interface {
+	// Preview returns the interest and late fees accrued by now on the latest statement of an
+	// existing account, telling which were posted already. Nothing is posted
+	Preview(ctx context.Context, accountUUID string) (model.AccrualPreview, error)
+	// Accrue posts the accruals of every account that were not posted yet, returning the number
+	// posted. Accounts failing to accrue are skipped, their errors joined
+	Accrue(ctx context.Context) (int, error)
+}
+
+type accrualService struct {
+	statementRepo repository.StatementConnector
+	accountRepo   repository.AccountConnector
+	ratePlanRepo  repository.RatePlanConnector
+	trxRepo       repository.TransactionConnector
+	trxService    TransactionService
+	clock         clock.Clock
+}
+
+// NewAccrualService posts accruals through the transaction service, as any other transaction
+func NewAccrualService(
+	s repository.StatementConnector,
+	a repository.AccountConnector,
+	r repository.RatePlanConnector,
+	t repository.TransactionConnector,
+	trxService TransactionService,
+	c clock.Clock,
+) AccrualService {
+	return &accrualService{
+		statementRepo: s,
+		accountRepo:   a,
+		ratePlanRepo:  r,
+		trxRepo:       t,
+		trxService:    trxService,
+		clock:         c,
+	}
+}
+
+func (s *accrualService) Preview(ctx context.Context, accountUUID string) (model.AccrualPreview, error) {
+	account, err := s.accountRepo.Get(ctx, accountUUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.AccrualPreview{}, fmt.Errorf("%w for uuid: '%s'", ErrAccountNotFound, accountUUID)
+		}
+
+		return model.AccrualPreview{}, err
+	}
+
+	return s.preview(ctx, account, s.clock.Now())
+}
+
+func (s *accrualService) Accrue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+
+	accounts, err := s.accountRepo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		posted int
+		errs   []error
+	)
+	for _, account := range accounts {
+		n, err := s.accrueAccount(ctx, account, now)
+		posted += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to accrue interest of account %s: %w", account.UUID, err))
+		}
+	}
+
+	return posted, errors.Join(errs...)
+}
+
+// accrueAccount posts the accruals of the account not posted yet. They are created with an
+// idempotency key, so an accrual posted meanwhile by another instance is skipped
+func (s *accrualService) accrueAccount(ctx context.Context, account model.Account, now time.Time) (int, error) {
+	preview, err := s.preview(ctx, account, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var posted int
+	for _, accrual := range preview.Accruals {
+		if accrual.Posted {
+			continue
+		}
+
+		_, err := s.trxService.Create(ctx, model.TransactionRequest{
+			AccountUUID:     account.UUID.String(),
+			OperationTypeID: accrual.OperationTypeID,
+			Amount:          accrual.Amount,
+			IdempotencyKey:  accrual.IdempotencyKey,
+		})
+		if errors.Is(err, ErrDuplicateRequest) {
+			continue
+		}
+		if err != nil {
+			return posted, err
+		}
+
+		posted++
+	}
+
+	return posted, nil
+}
+
+// preview computes the accruals of the latest statement of the account as of now. Posted
+// accruals are found among the transactions since the statement closed, by the uuid the
+// transaction service derives from their idempotency key
+func (s *accrualService) preview(ctx context.Context, account model.Account, now time.Time) (model.AccrualPreview, error) {
+	plan, err := s.ratePlanRepo.Get(ctx, account.RatePlanID)
+	if err != nil {
+		return model.AccrualPreview{}, err
+	}
+
+	preview := model.AccrualPreview{
+		AccountUUID: account.UUID,
+		RatePlan:    plan,
+		AsOf:        now,
+		Accruals:    []model.Accrual{},
+	}
+
+	statement, err := s.statementRepo.Latest(ctx, account.UUID.String())
+	if errors.Is(err, repository.ErrNoRows) {
+		return preview, nil
+	}
+	if err != nil {
+		return model.AccrualPreview{}, err
+	}
+	preview.StatementUUID = &statement.UUID
+
+	transactions, err := s.trxRepo.ListByPeriod(ctx, account.UUID.String(), statement.PeriodEnd, now)
+	if err != nil {
+		return model.AccrualPreview{}, err
+	}
+
+	posted := make(map[uuid.UUID]bool, len(transactions))
+	for _, trx := range transactions {
+		posted[trx.UUID] = true
+	}
+
+	for _, accrual := range accruals(statement, plan, transactions, now) {
+		accrual.Posted = posted[uuid.NewV5(uuid.Nil, accrual.IdempotencyKey)]
+		preview.Accruals = append(preview.Accruals, accrual)
+	}
+
+	return preview, nil
+}
+
+// accruals returns the late fee and the daily interest accrued by now on the unpaid balance of
+// the statement, given the transactions since it closed. Payments made by the end of the due
+// date plus the grace period are on time: the late fee is charged when they fall short of the
+// minimum payment, and interest accrues for every day after the due date when they fall short
+// of the closing balance. Interest of a day accrues on the balance unpaid at its end, with the
+// interest of the previous days if the plan compounds daily, until the balance is paid
+func accruals(statement model.Statement, plan model.RatePlan, transactions []model.Transaction, now time.Time) []model.Accrual {
+	dueEnd := statement.DueDate.AddDate(0, 0, 1)
+	lateFrom := dueEnd.AddDate(0, 0, plan.GracePeriodDays)
+	if now.Before(lateFrom) {
+		return nil
+	}
+
+	// paidBy sums the payments made before t, debits are stored with a negative amount
+	paidBy := func(t time.Time) float64 {
+		var paid float64
+		for _, trx := range transactions {
+			if trx.Amount > 0 && trx.EventDate.Before(t) {
+				paid += trx.Amount
+			}
+		}
+
+		return roundCents(paid)
+	}
+
+	var result []model.Accrual
+	paidOnTime := paidBy(lateFrom)
+	if plan.LateFee > 0 && paidOnTime < statement.MinimumPayment {
+		result = append(result, model.Accrual{
+			Date:            lateFrom,
+			OperationTypeID: model.OperationTypeFee,
+			Amount:          plan.LateFee,
+			IdempotencyKey:  "late_fee:" + statement.UUID.String(),
+		})
+	}
+
+	if plan.APR > 0 && paidOnTime < statement.ClosingBalance {
+		var interest float64
+		for day := dueEnd; !day.AddDate(0, 0, 1).After(now); day = day.AddDate(0, 0, 1) {
+			balance := statement.ClosingBalance - paidBy(day.AddDate(0, 0, 1))
+			if plan.CompoundDaily {
+				balance += interest
+			}
+			balance = roundCents(balance)
+			if balance <= 0 {
+				break
+			}
+
+			amount := roundCents(balance * plan.APR / 100 / 365)
+			if amount <= 0 {
+				continue
+			}
+
+			interest += amount
+			result = append(result, model.Accrual{
+				Date:            day,
+				OperationTypeID: model.OperationTypeInterest,
+				Amount:          amount,
+				Balance:         balance,
+				IdempotencyKey:  fmt.Sprintf("interest:%s:%s", statement.AccountUUID, day.Format(time.DateOnly)),
+			})
+		}
+	}
+
+	// the late fee is charged on the first late day, before its interest
+	slices.SortStableFunc(result, func(x, y model.Accrual) int {
+		return x.Date.Compare(y.Date)
+	})
+
+	return result
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	serviceMocks "go-pismo-challenge/pkg/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type accrualServiceSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	clock          *clock.Fake
+	service        AccrualService
+	mockStatements *mocks.MockStatementConnector
+	mockAccounts   *mocks.MockAccountConnector
+	mockPlans      *mocks.MockRatePlanConnector
+	mockTrx        *mocks.MockTransactionConnector
+	mockTrxService *serviceMocks.MockTransactionService
+}
+
+func TestAccrualService(t *testing.T) {
+	suite.Run(t, new(accrualServiceSuite))
+}
+
+func (s *accrualServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.clock = clock.NewFake(date(2025, time.October, 20))
+	s.mockStatements = mocks.NewMockStatementConnector(s.ctrl)
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockPlans = mocks.NewMockRatePlanConnector(s.ctrl)
+	s.mockTrx = mocks.NewMockTransactionConnector(s.ctrl)
+	s.mockTrxService = serviceMocks.NewMockTransactionService(s.ctrl)
+
+	s.service = NewAccrualService(s.mockStatements, s.mockAccounts, s.mockPlans, s.mockTrx, s.mockTrxService, s.clock)
+}
+
+func (s *accrualServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func standardPlan() model.RatePlan {
+	return model.RatePlan{ID: 1, Description: "STANDARD", APR: 29.99, CompoundDaily: true, GracePeriodDays: 3, LateFee: 10}
+}
+
+// lateStatement is due on October 15th and only 1000 of it were paid
+func lateStatement() (model.Statement, []model.Transaction) {
+	accountUUID := uuid.FromStringOrNil(mockAccountUUID)
+	statement := model.Statement{
+		UUID:           uuid.NewV5(accountUUID, "2025-10-05T00:00:00Z"),
+		AccountUUID:    accountUUID,
+		PeriodStart:    date(2025, time.September, 5),
+		PeriodEnd:      date(2025, time.October, 5),
+		DueDate:        date(2025, time.October, 15),
+		Purchases:      10000,
+		ClosingBalance: 10000,
+		MinimumPayment: 1500,
+	}
+	payments := []model.Transaction{{UUID: uuid.NewV5(uuid.Nil, "payment"), AccountUUID: accountUUID, Amount: 1000,
+		OperationTypeID: 4, EventDate: date(2025, time.October, 10)}}
+
+	return statement, payments
+}
+
+func interest(day time.Time, balance, amount float64) model.Accrual {
+	return model.Accrual{
+		Date:            day,
+		OperationTypeID: model.OperationTypeInterest,
+		Amount:          amount,
+		Balance:         balance,
+		IdempotencyKey:  "interest:" + mockAccountUUID + ":" + day.Format(time.DateOnly),
+	}
+}
+
+func lateFee(statement model.Statement, day time.Time, amount float64) model.Accrual {
+	return model.Accrual{
+		Date:            day,
+		OperationTypeID: model.OperationTypeFee,
+		Amount:          amount,
+		IdempotencyKey:  "late_fee:" + statement.UUID.String(),
+	}
+}
+
+func (s *accrualServiceSuite) mockAccount(account model.Account, statement model.Statement, trx []model.Transaction) {
+	s.mockPlans.EXPECT().Get(gomock.Any(), account.RatePlanID).Return(standardPlan(), nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), account.UUID.String()).Return(statement, nil)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), account.UUID.String(), statement.PeriodEnd, s.clock.Now()).Return(trx, nil)
+}
+
+// accruals posted before are told apart by the uuid of their transaction
+func (s *accrualServiceSuite) TestPreview() {
+	account := model.Account{UUID: uuid.FromStringOrNil(mockAccountUUID), RatePlanID: 1}
+	statement, trx := lateStatement()
+	trx = append(trx, model.Transaction{UUID: uuid.NewV5(uuid.Nil, "interest:"+mockAccountUUID+":2025-10-16"), Amount: -7.39})
+
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(account, nil)
+	s.mockAccount(account, statement, trx)
+
+	got, err := s.service.Preview(context.Background(), mockAccountUUID)
+	s.Require().NoError(err)
+	s.Equal(account.UUID, got.AccountUUID)
+	s.Equal(&statement.UUID, got.StatementUUID)
+	s.Equal(standardPlan(), got.RatePlan)
+	s.Equal(s.clock.Now(), got.AsOf)
+	s.Require().Len(got.Accruals, 5)
+	s.True(got.Accruals[0].Posted)
+	for _, accrual := range got.Accruals[1:] {
+		s.False(accrual.Posted)
+	}
+}
+
+func (s *accrualServiceSuite) TestPreviewWithoutStatements() {
+	account := model.Account{UUID: uuid.FromStringOrNil(mockAccountUUID), RatePlanID: 1}
+
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(account, nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), 1).Return(standardPlan(), nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), mockAccountUUID).Return(model.Statement{}, repository.ErrNoRows)
+
+	got, err := s.service.Preview(context.Background(), mockAccountUUID)
+	s.Require().NoError(err)
+	s.Nil(got.StatementUUID)
+	s.NotNil(got.Accruals)
+	s.Empty(got.Accruals)
+}
+
+func (s *accrualServiceSuite) TestPreviewAccountNotFound() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(model.Account{}, repository.ErrNoRows)
+
+	_, err := s.service.Preview(context.Background(), mockAccountUUID)
+	s.ErrorIs(err, ErrAccountNotFound)
+}
+
+// every run posts what accrued since the previous one, accruals posted meanwhile are skipped
+func (s *accrualServiceSuite) TestAccrue() {
+	account := model.Account{UUID: uuid.FromStringOrNil(mockAccountUUID), RatePlanID: 1}
+	statement, trx := lateStatement()
+
+	var posted []model.TransactionRequest
+	s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req model.TransactionRequest) (model.Transaction, error) {
+			posted = append(posted, req)
+			trx = append(trx, model.Transaction{UUID: uuid.NewV5(uuid.Nil, req.IdempotencyKey), Amount: -req.Amount})
+			// posted by another instance meanwhile
+			if req.IdempotencyKey == "interest:"+mockAccountUUID+":2025-10-18" {
+				return model.Transaction{}, ErrDuplicateRequest
+			}
+
+			return model.Transaction{}, nil
+		}).Times(6)
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{account}, nil).Times(2)
+	s.mockPlans.EXPECT().Get(gomock.Any(), 1).Return(standardPlan(), nil).Times(2)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), mockAccountUUID).Return(statement, nil).Times(2)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), mockAccountUUID, statement.PeriodEnd, gomock.Any()).
+		DoAndReturn(func(context.Context, string, time.Time, time.Time) ([]model.Transaction, error) {
+			return trx, nil
+		}).Times(2)
+
+	n, err := s.service.Accrue(context.Background())
+	s.Require().NoError(err)
+	s.Equal(4, n)
+	s.Equal(model.TransactionRequest{
+		AccountUUID:     mockAccountUUID,
+		OperationTypeID: model.OperationTypeFee,
+		Amount:          10,
+		IdempotencyKey:  "late_fee:" + statement.UUID.String(),
+	}, posted[3])
+
+	s.clock.Advance(24 * time.Hour)
+
+	n, err = s.service.Accrue(context.Background())
+	s.Require().NoError(err)
+	s.Equal(1, n)
+	s.Equal(model.TransactionRequest{
+		AccountUUID:     mockAccountUUID,
+		OperationTypeID: model.OperationTypeInterest,
+		Amount:          7.42,
+		IdempotencyKey:  "interest:" + mockAccountUUID + ":2025-10-20",
+	}, posted[5])
+}
+
+// a failing account does not stop the others from accruing
+func (s *accrualServiceSuite) TestAccrueAccountFailed() {
+	failing := model.Account{UUID: getMockUUID(), RatePlanID: 1}
+	account := model.Account{UUID: uuid.FromStringOrNil(mockAccountUUID), RatePlanID: 1}
+	statement, trx := lateStatement()
+	mockError := errors.New("db error")
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{failing, account}, nil)
+	s.mockPlans.EXPECT().Get(gomock.Any(), 1).Return(model.RatePlan{}, mockError)
+	s.mockAccount(account, statement, trx)
+	s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, nil).Times(5)
+
+	n, err := s.service.Accrue(context.Background())
+	s.ErrorIs(err, mockError)
+	s.ErrorContains(err, failing.UUID.String())
+	s.Equal(5, n)
+}
+
+func (s *accrualServiceSuite) TestAccrueListFailed() {
+	mockError := errors.New("db error")
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return(nil, mockError)
+
+	_, err := s.service.Accrue(context.Background())
+	s.ErrorIs(err, mockError)
+}
+
+func TestAccruals(t *testing.T) {
+	statement, payments := lateStatement()
+	payment := func(day time.Time, amount float64) model.Transaction {
+		return model.Transaction{AccountUUID: statement.AccountUUID, OperationTypeID: 4, Amount: amount, EventDate: day}
+	}
+	preferred := model.RatePlan{ID: 2, Description: "PREFERRED", APR: 19.99, GracePeriodDays: 5}
+
+	for _, tc := range []struct {
+		name     string
+		plan     model.RatePlan
+		payments []model.Transaction
+		now      time.Time
+		want     []model.Accrual
+	}{
+		{
+			name:     "grace period",
+			plan:     standardPlan(),
+			payments: payments,
+			now:      date(2025, time.October, 19).Add(-time.Nanosecond),
+		},
+		{
+			name:     "late fee and compound interest",
+			plan:     standardPlan(),
+			payments: payments,
+			now:      date(2025, time.October, 20),
+			want: []model.Accrual{
+				interest(date(2025, time.October, 16), 9000, 7.39),
+				interest(date(2025, time.October, 17), 9007.39, 7.40),
+				interest(date(2025, time.October, 18), 9014.79, 7.41),
+				lateFee(statement, date(2025, time.October, 19), 10),
+				interest(date(2025, time.October, 19), 9022.20, 7.41),
+			},
+		},
+		{
+			name:     "minimum paid in the grace period",
+			plan:     standardPlan(),
+			payments: append(payments, payment(date(2025, time.October, 18).Add(12*time.Hour), 2000)),
+			now:      date(2025, time.October, 20),
+			want: []model.Accrual{
+				interest(date(2025, time.October, 16), 9000, 7.39),
+				interest(date(2025, time.October, 17), 9007.39, 7.40),
+				interest(date(2025, time.October, 18), 7014.79, 5.76),
+				interest(date(2025, time.October, 19), 7020.55, 5.77),
+			},
+		},
+		{
+			name:     "paid in full in the grace period",
+			plan:     standardPlan(),
+			payments: append(payments, payment(date(2025, time.October, 18).Add(12*time.Hour), 9000)),
+			now:      date(2025, time.November, 1),
+		},
+		{
+			name:     "paid in full when late",
+			plan:     standardPlan(),
+			payments: append(payments, payment(date(2025, time.October, 19).Add(12*time.Hour), 9500)),
+			now:      date(2025, time.November, 1),
+			want: []model.Accrual{
+				interest(date(2025, time.October, 16), 9000, 7.39),
+				interest(date(2025, time.October, 17), 9007.39, 7.40),
+				interest(date(2025, time.October, 18), 9014.79, 7.41),
+				lateFee(statement, date(2025, time.October, 19), 10),
+			},
+		},
+		{
+			name:     "simple interest without late fee",
+			plan:     preferred,
+			payments: payments,
+			now:      date(2025, time.October, 23).Add(time.Hour),
+			want: []model.Accrual{
+				interest(date(2025, time.October, 16), 9000, 4.93),
+				interest(date(2025, time.October, 17), 9000, 4.93),
+				interest(date(2025, time.October, 18), 9000, 4.93),
+				interest(date(2025, time.October, 19), 9000, 4.93),
+				interest(date(2025, time.October, 20), 9000, 4.93),
+				interest(date(2025, time.October, 21), 9000, 4.93),
+				interest(date(2025, time.October, 22), 9000, 4.93),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, accruals(statement, tc.plan, tc.payments, tc.now))
+		})
+	}
+}
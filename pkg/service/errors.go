@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"go-pismo-challenge/pkg/util"
+	"strings"
+)
+
+// Domain errors returned by the services. Failures of the underlying storage are returned
+// wrapped as they are, so that callers can still tell e.g. timeouts apart
+var (
+	// ErrDuplicateRequest is returned when the idempotency key of a request was used before
+	ErrDuplicateRequest = errors.New("duplicate request received")
+	// ErrAccountNotFound is returned when the requested or referenced account does not exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrOperationTypeNotFound is returned when a transaction references an unknown operation type
+	ErrOperationTypeNotFound = errors.New("invalid operation type")
+	// ErrStatementNotFound is returned when the requested statement does not exist for the account
+	ErrStatementNotFound = errors.New("statement not found")
+	// ErrRatePlanNotFound is returned when an account references an unknown rate plan
+	ErrRatePlanNotFound = errors.New("invalid rate plan")
+	// ErrUnbalancedJournal is returned when the debits of the journal entries of a transaction
+	// do not equal their credits, the transaction is not stored
+	ErrUnbalancedJournal = errors.New("unbalanced journal entries")
+	// ErrWebhookNotFound is returned when the requested webhook does not exist
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrAccountBlocked is returned when a transfer sends from or to a blocked account
+	ErrAccountBlocked = errors.New("account blocked")
+	// ErrCreditLimitExceeded is returned when a transfer would leave its from account owing more
+	// than its credit limit
+	ErrCreditLimitExceeded = errors.New("credit limit exceeded")
+)
+
+// ValidationError is returned when a request fails validation, Fields describes every invalid field
+type ValidationError struct {
+	Fields []util.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field)
+	}
+
+	return "invalid request: " + strings.Join(fields, ", ")
+}
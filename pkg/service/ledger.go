@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/ledger_mock.go -source=ledger.go
+type LedgerService interface {
+	// TrialBalance returns the totals of every ledger account and whether the debits of all
+	// entries equal their credits
+	TrialBalance(ctx context.Context) (model.TrialBalance, error)
+}
+
+type ledgerService struct {
+	ledgerRepo repository.LedgerConnector
+}
+
+func NewLedgerService(l repository.LedgerConnector) LedgerService {
+	return &ledgerService{
+		ledgerRepo: l,
+	}
+}
+
+func (s *ledgerService) TrialBalance(ctx context.Context) (model.TrialBalance, error) {
+	accounts, err := s.ledgerRepo.TrialBalance(ctx)
+	if err != nil {
+		return model.TrialBalance{}, err
+	}
+
+	trialBalance := model.TrialBalance{Accounts: []model.TrialBalanceAccount{}}
+	var debits, credits float64
+	for _, account := range accounts {
+		account.Debits, account.Credits = roundCents(account.Debits), roundCents(account.Credits)
+		account.Balance = roundCents(account.Debits - account.Credits)
+		if account.Type != model.LedgerAccountTypeAsset {
+			account.Balance = -account.Balance
+		}
+
+		debits += account.Debits
+		credits += account.Credits
+		trialBalance.Accounts = append(trialBalance.Accounts, account)
+	}
+
+	trialBalance.TotalDebits = roundCents(debits)
+	trialBalance.TotalCredits = roundCents(credits)
+	trialBalance.Balanced = trialBalance.TotalDebits == trialBalance.TotalCredits
+
+	return trialBalance, nil
+}
+
+// journal returns the entries posting a transaction: the receivable of the customer is debited
+// for debits and credited for credits, against cash, or revenue and fees for the interest and
+// late fees the issuer earns. Debits are stored with a negative amount
+func journal(trx model.Transaction) []model.JournalEntry {
+	counter := model.LedgerAccountCash
+	switch trx.OperationTypeID {
+	case model.OperationTypeFee:
+		counter = model.LedgerAccountFees
+	case model.OperationTypeInterest:
+		counter = model.LedgerAccountRevenue
+	}
+
+	receivable := model.JournalEntry{
+		TransactionUUID: trx.UUID,
+		AccountUUID:     trx.AccountUUID,
+		LedgerAccountID: model.LedgerAccountReceivable,
+		PostedAt:        trx.EventDate,
+	}
+	other := receivable
+	other.LedgerAccountID = counter
+
+	if trx.Amount < 0 {
+		receivable.Debit, other.Credit = -trx.Amount, -trx.Amount
+	} else {
+		receivable.Credit, other.Debit = trx.Amount, trx.Amount
+	}
+
+	return []model.JournalEntry{receivable, other}
+}
+
+// checkBalanced returns ErrUnbalancedJournal unless the debits of the entries equal their
+// credits, to the cent
+func checkBalanced(entries []model.JournalEntry) error {
+	var debits, credits float64
+	for _, entry := range entries {
+		debits += entry.Debit
+		credits += entry.Credit
+	}
+
+	if roundCents(debits) != roundCents(credits) {
+		return fmt.Errorf("%w: debits %.2f, credits %.2f", ErrUnbalancedJournal, debits, credits)
+	}
+
+	return nil
+}
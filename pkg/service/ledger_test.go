@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ledgerServiceSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	service    LedgerService
+	mockLedger *mocks.MockLedgerConnector
+}
+
+func TestLedgerService(t *testing.T) {
+	suite.Run(t, new(ledgerServiceSuite))
+}
+
+func (s *ledgerServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockLedger = mocks.NewMockLedgerConnector(s.ctrl)
+
+	s.service = NewLedgerService(s.mockLedger)
+}
+
+func (s *ledgerServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+// balances are on the side the account type increases with
+func (s *ledgerServiceSuite) TestTrialBalanceSuccess() {
+	s.mockLedger.EXPECT().TrialBalance(gomock.Any()).Return([]model.TrialBalanceAccount{
+		{LedgerAccountID: 1, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset, Debits: 160.50000000000003, Credits: 100},
+		{LedgerAccountID: 2, Code: "CASH", Type: model.LedgerAccountTypeAsset, Debits: 100, Credits: 150.5},
+		{LedgerAccountID: 3, Code: "REVENUE", Type: model.LedgerAccountTypeRevenue},
+		{LedgerAccountID: 4, Code: "FEES", Type: model.LedgerAccountTypeRevenue, Credits: 10},
+	}, nil)
+
+	got, err := s.service.TrialBalance(context.Background())
+	s.Require().NoError(err)
+	s.Equal(model.TrialBalance{
+		Accounts: []model.TrialBalanceAccount{
+			{LedgerAccountID: 1, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset, Debits: 160.5, Credits: 100, Balance: 60.5},
+			{LedgerAccountID: 2, Code: "CASH", Type: model.LedgerAccountTypeAsset, Debits: 100, Credits: 150.5, Balance: -50.5},
+			{LedgerAccountID: 3, Code: "REVENUE", Type: model.LedgerAccountTypeRevenue},
+			{LedgerAccountID: 4, Code: "FEES", Type: model.LedgerAccountTypeRevenue, Credits: 10, Balance: 10},
+		},
+		TotalDebits:  260.5,
+		TotalCredits: 260.5,
+		Balanced:     true,
+	}, got)
+}
+
+func (s *ledgerServiceSuite) TestTrialBalanceUnbalanced() {
+	s.mockLedger.EXPECT().TrialBalance(gomock.Any()).Return([]model.TrialBalanceAccount{
+		{LedgerAccountID: 1, Code: "CUSTOMER_RECEIVABLE", Type: model.LedgerAccountTypeAsset, Debits: 60.5},
+		{LedgerAccountID: 2, Code: "CASH", Type: model.LedgerAccountTypeAsset, Credits: 60.49},
+	}, nil)
+
+	got, err := s.service.TrialBalance(context.Background())
+	s.Require().NoError(err)
+	s.False(got.Balanced)
+}
+
+func (s *ledgerServiceSuite) TestTrialBalanceFailed() {
+	mockError := errors.New("db error")
+	s.mockLedger.EXPECT().TrialBalance(gomock.Any()).Return(nil, mockError)
+
+	_, err := s.service.TrialBalance(context.Background())
+	s.ErrorIs(err, mockError)
+}
+
+func TestJournal(t *testing.T) {
+	accountUUID := uuid.FromStringOrNil(mockAccountUUID)
+	eventDate := date(2025, time.October, 1)
+
+	for _, tc := range []struct {
+		name            string
+		operationTypeID int
+		amount          float64
+		debit, credit   int
+	}{
+		{"purchase", 1, -60.5, model.LedgerAccountReceivable, model.LedgerAccountCash},
+		{"withdrawal", 3, -20, model.LedgerAccountReceivable, model.LedgerAccountCash},
+		{"payment", 4, 100, model.LedgerAccountCash, model.LedgerAccountReceivable},
+		{"late fee", model.OperationTypeFee, -10, model.LedgerAccountReceivable, model.LedgerAccountFees},
+		{"interest", model.OperationTypeInterest, -0.58, model.LedgerAccountReceivable, model.LedgerAccountRevenue},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trx := model.Transaction{
+				UUID:            getMockUUID(),
+				AccountUUID:     accountUUID,
+				OperationTypeID: tc.operationTypeID,
+				Amount:          tc.amount,
+				EventDate:       eventDate,
+			}
+
+			entries := journal(trx)
+			assert.NoError(t, checkBalanced(entries))
+			assert.Len(t, entries, 2)
+			for _, entry := range entries {
+				assert.Equal(t, trx.UUID, entry.TransactionUUID)
+				assert.Equal(t, accountUUID, entry.AccountUUID)
+				assert.Equal(t, eventDate, entry.PostedAt)
+
+				switch entry.LedgerAccountID {
+				case tc.debit:
+					assert.InDelta(t, max(tc.amount, -tc.amount), entry.Debit, 0)
+					assert.Zero(t, entry.Credit)
+				case tc.credit:
+					assert.InDelta(t, max(tc.amount, -tc.amount), entry.Credit, 0)
+					assert.Zero(t, entry.Debit)
+				default:
+					t.Errorf("unexpected ledger account %d", entry.LedgerAccountID)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBalanced(t *testing.T) {
+	assert.NoError(t, checkBalanced(nil))
+	assert.NoError(t, checkBalanced([]model.JournalEntry{{Debit: 0.1}, {Debit: 0.2}, {Credit: 0.3}}))
+	assert.ErrorIs(t, checkBalanced([]model.JournalEntry{{Debit: 10}, {Credit: 9.99}}), ErrUnbalancedJournal)
+}
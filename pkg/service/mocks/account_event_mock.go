@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: account_event.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/account_event_mock.go -source=account_event.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountEventService is a mock of AccountEventService interface.
+type MockAccountEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountEventServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountEventServiceMockRecorder is the mock recorder for MockAccountEventService.
+type MockAccountEventServiceMockRecorder struct {
+	mock *MockAccountEventService
+}
+
+// NewMockAccountEventService creates a new mock instance.
+func NewMockAccountEventService(ctrl *gomock.Controller) *MockAccountEventService {
+	mock := &MockAccountEventService{ctrl: ctrl}
+	mock.recorder = &MockAccountEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountEventService) EXPECT() *MockAccountEventServiceMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockAccountEventService) Listen(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockAccountEventServiceMockRecorder) Listen(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockAccountEventService)(nil).Listen), ctx)
+}
+
+// Stream mocks base method.
+func (m *MockAccountEventService) Stream(ctx context.Context, accountUUID string, lastEventID *int64, send func(model.AccountEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, accountUUID, lastEventID, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockAccountEventServiceMockRecorder) Stream(ctx, accountUUID, lastEventID, send any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockAccountEventService)(nil).Stream), ctx, accountUUID, lastEventID, send)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: accrual.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/accrual_mock.go -source=accrual.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccrualService is a mock of AccrualService interface.
+type MockAccrualService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccrualServiceMockRecorder is the mock recorder for MockAccrualService.
+type MockAccrualServiceMockRecorder struct {
+	mock *MockAccrualService
+}
+
+// NewMockAccrualService creates a new mock instance.
+func NewMockAccrualService(ctrl *gomock.Controller) *MockAccrualService {
+	mock := &MockAccrualService{ctrl: ctrl}
+	mock.recorder = &MockAccrualServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualService) EXPECT() *MockAccrualServiceMockRecorder {
+	return m.recorder
+}
+
+// Accrue mocks base method.
+func (m *MockAccrualService) Accrue(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accrue", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accrue indicates an expected call of Accrue.
+func (mr *MockAccrualServiceMockRecorder) Accrue(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accrue", reflect.TypeOf((*MockAccrualService)(nil).Accrue), ctx)
+}
+
+// Preview mocks base method.
+func (m *MockAccrualService) Preview(ctx context.Context, accountUUID string) (model.AccrualPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", ctx, accountUUID)
+	ret0, _ := ret[0].(model.AccrualPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockAccrualServiceMockRecorder) Preview(ctx, accountUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockAccrualService)(nil).Preview), ctx, accountUUID)
+}
@@ -0,0 +1,104 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: statement.go
+//
+// Generated by this command:
+//
+//	mockgen -package mocks -destination=./mocks/statement_mock.go -source=statement.go
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	export "go-pismo-challenge/pkg/export"
+	model "go-pismo-challenge/pkg/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStatementService is a mock of StatementService interface.
+type MockStatementService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockStatementServiceMockRecorder is the mock recorder for MockStatementService.
+type MockStatementServiceMockRecorder struct {
+	mock *MockStatementService
+}
+
+// NewMockStatementService creates a new mock instance.
+func NewMockStatementService(ctrl *gomock.Controller) *MockStatementService {
+	mock := &MockStatementService{ctrl: ctrl}
+	mock.recorder = &MockStatementServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementService) EXPECT() *MockStatementServiceMockRecorder {
+	return m.recorder
+}
+
+// CloseCycles mocks base method.
+func (m *MockStatementService) CloseCycles(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseCycles", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseCycles indicates an expected call of CloseCycles.
+func (mr *MockStatementServiceMockRecorder) CloseCycles(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseCycles", reflect.TypeOf((*MockStatementService)(nil).CloseCycles), ctx, now)
+}
+
+// Export mocks base method.
+func (m *MockStatementService) Export(ctx context.Context, accountUUID, statementUUID string) (export.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, accountUUID, statementUUID)
+	ret0, _ := ret[0].(export.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockStatementServiceMockRecorder) Export(ctx, accountUUID, statementUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockStatementService)(nil).Export), ctx, accountUUID, statementUUID)
+}
+
+// Get mocks base method.
+func (m *MockStatementService) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, accountUUID, statementUUID)
+	ret0, _ := ret[0].(model.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatementServiceMockRecorder) Get(ctx, accountUUID, statementUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatementService)(nil).Get), ctx, accountUUID, statementUUID)
+}
+
+// List mocks base method.
+func (m *MockStatementService) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, accountUUID)
+	ret0, _ := ret[0].([]model.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStatementServiceMockRecorder) List(ctx, accountUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStatementService)(nil).List), ctx, accountUUID)
+}
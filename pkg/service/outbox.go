@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/publisher"
+	"go-pismo-challenge/pkg/repository"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/outbox_mock.go -source=outbox.go
+type OutboxService interface {
+	// Relay publishes the pending events of the outbox in order per account, until none is due,
+	// and returns how many were published. An event that fails to publish is retried with
+	// exponential backoff, the later events of its account wait for it. Only one relay publishes
+	// at a time, Relay returns right away while the relay of another instance does
+	Relay(ctx context.Context) (int, error)
+}
+
+// OutboxOptions configure the relay: BatchSize events are read from the outbox at a time, and a
+// failed event is retried after InitialBackoff, doubled on every attempt up to MaxBackoff
+type OutboxOptions struct {
+	BatchSize      int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type outboxService struct {
+	outboxRepo repository.OutboxConnector
+	publisher  publisher.Publisher
+	uow        repository.UnitOfWork
+	clock      clock.Clock
+	opts       OutboxOptions
+}
+
+func NewOutboxService(
+	o repository.OutboxConnector,
+	p publisher.Publisher,
+	uow repository.UnitOfWork,
+	c clock.Clock,
+	opts OutboxOptions,
+) OutboxService {
+	return &outboxService{
+		outboxRepo: o,
+		publisher:  p,
+		uow:        uow,
+		clock:      c,
+		opts:       opts,
+	}
+}
+
+func (s *outboxService) Relay(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		n, more, err := s.relayBatch(ctx)
+		published += n
+		if err != nil || !more {
+			return published, err
+		}
+	}
+}
+
+// relayBatch publishes a batch of pending events holding the relay lock, and marks them in the
+// same unit of work. It reports whether more events may be pending. The batch is published again
+// if the unit of work fails, delivery is at least once
+func (s *outboxService) relayBatch(ctx context.Context) (int, bool, error) {
+	var (
+		published int
+		more      bool
+	)
+	// the lock serialises the relays, the events need no stronger isolation
+	err := s.uow.Do(ctx, sql.LevelReadCommitted, func(ctx context.Context) error {
+		published, more = 0, false
+
+		locked, err := s.outboxRepo.LockRelay(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := s.outboxRepo.Pending(ctx, s.clock.Now(), s.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		published, err = s.publish(ctx, events)
+		// failed events are not due before their backoff, so the next batch moves on
+		more = len(events) > 0 && len(events) == s.opts.BatchSize
+
+		return err
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return published, more, nil
+}
+
+// publish publishes the events in order, skipping the events of an account after one of its
+// events failed
+func (s *outboxService) publish(ctx context.Context, events []model.OutboxEvent) (int, error) {
+	published := 0
+	failed := map[uuid.UUID]bool{}
+	for _, e := range events {
+		if failed[e.AccountUUID] {
+			continue
+		}
+
+		if err := s.publisher.Publish(ctx, e.Event); err != nil {
+			if ctx.Err() != nil {
+				return published, err
+			}
+
+			failed[e.AccountUUID] = true
+			nextAttemptAt := s.clock.Now().Add(backoff(e.Attempts, s.opts.InitialBackoff, s.opts.MaxBackoff))
+			log.Warn().Ctx(ctx).Err(err).Str("event_uuid", e.UUID.String()).Int("attempt", e.Attempts+1).
+				Time("next_attempt_at", nextAttemptAt).Msg("failed to publish event")
+
+			if err := s.outboxRepo.MarkFailed(ctx, e.ID, nextAttemptAt, err.Error()); err != nil {
+				return published, err
+			}
+
+			continue
+		}
+
+		if err := s.outboxRepo.MarkPublished(ctx, e.ID, s.clock.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// backoff returns the wait before retrying what failed attempts times before, initial doubled on
+// every attempt up to maxBackoff
+func backoff(attempts int, initial, maxBackoff time.Duration) time.Duration {
+	wait := initial
+	for range attempts {
+		if wait >= maxBackoff {
+			break
+		}
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
+
+// newEvent returns the event of the created entity, its uuid is derived from the entity so that
+// retries of the unit of work creating the entity add the same event
+func newEvent(eventType string, entityUUID, accountUUID uuid.UUID, entity any, createdAt time.Time) (model.Event, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return model.Event{}, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	return model.Event{
+		UUID:        uuid.NewV5(entityUUID, eventType),
+		Type:        eventType,
+		AccountUUID: accountUUID,
+		Data:        data,
+		CreatedAt:   createdAt,
+	}, nil
+}
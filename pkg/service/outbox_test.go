@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	publisherMocks "go-pismo-challenge/pkg/publisher/mocks"
+	"go-pismo-challenge/pkg/repository/memory"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type outboxServiceSuite struct {
+	suite.Suite
+	ctrl          *gomock.Controller
+	service       OutboxService
+	clock         *clock.Fake
+	mockOutbox    *mocks.MockOutboxConnector
+	mockPublisher *publisherMocks.MockPublisher
+	mockUoW       *mocks.MockUnitOfWork
+}
+
+func TestOutboxService(t *testing.T) {
+	suite.Run(t, new(outboxServiceSuite))
+}
+
+func (s *outboxServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.clock = clock.NewFake(time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC))
+	s.mockOutbox = mocks.NewMockOutboxConnector(s.ctrl)
+	s.mockPublisher = publisherMocks.NewMockPublisher(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelReadCommitted, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	s.mockOutbox.EXPECT().LockRelay(gomock.Any()).Return(true, nil).AnyTimes()
+
+	s.service = NewOutboxService(s.mockOutbox, s.mockPublisher, s.mockUoW, s.clock, OutboxOptions{
+		BatchSize:      3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	})
+}
+
+func (s *outboxServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+// outboxEvent returns a pending event of the account
+func outboxEvent(id int64, accountUUID uuid.UUID) model.OutboxEvent {
+	return model.OutboxEvent{
+		Event: model.Event{
+			UUID:        uuid.NewV5(accountUUID, strconv.FormatInt(id, 10)),
+			Type:        model.EventTransactionCreated,
+			AccountUUID: accountUUID,
+			Data:        []byte(`{}`),
+		},
+		ID: id,
+	}
+}
+
+func (s *outboxServiceSuite) TestRelaySuccess() {
+	account, other := uuid.NewV5(uuid.Nil, "account"), uuid.NewV5(uuid.Nil, "other")
+	events := []model.OutboxEvent{outboxEvent(1, account), outboxEvent(2, other)}
+	now := s.clock.Now()
+
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), now, 3).Return(events, nil)
+	gomock.InOrder(
+		s.mockPublisher.EXPECT().Publish(gomock.Any(), events[0].Event).Return(nil),
+		s.mockOutbox.EXPECT().MarkPublished(gomock.Any(), int64(1), now).Return(nil),
+		s.mockPublisher.EXPECT().Publish(gomock.Any(), events[1].Event).Return(nil),
+		s.mockOutbox.EXPECT().MarkPublished(gomock.Any(), int64(2), now).Return(nil),
+	)
+
+	published, err := s.service.Relay(context.Background())
+	s.Require().NoError(err)
+	s.Equal(2, published)
+}
+
+// full batches are followed by the next one
+func (s *outboxServiceSuite) TestRelayBatches() {
+	account := uuid.NewV5(uuid.Nil, "account")
+	first := []model.OutboxEvent{outboxEvent(1, account), outboxEvent(2, account), outboxEvent(3, account)}
+
+	gomock.InOrder(
+		s.mockOutbox.EXPECT().Pending(gomock.Any(), gomock.Any(), 3).Return(first, nil),
+		s.mockOutbox.EXPECT().Pending(gomock.Any(), gomock.Any(), 3).Return([]model.OutboxEvent{outboxEvent(4, account)}, nil),
+	)
+	s.mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+	s.mockOutbox.EXPECT().MarkPublished(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	published, err := s.service.Relay(context.Background())
+	s.Require().NoError(err)
+	s.Equal(4, published)
+}
+
+// a failed event is retried later and holds back the later events of its account only
+func (s *outboxServiceSuite) TestRelayPublishFailed() {
+	account, other := uuid.NewV5(uuid.Nil, "account"), uuid.NewV5(uuid.Nil, "other")
+	failing := outboxEvent(1, account)
+	failing.Attempts = 2
+	events := []model.OutboxEvent{failing, outboxEvent(2, other), outboxEvent(3, account)}
+	now := s.clock.Now()
+
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), now, 3).Return(events, nil)
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), now, 3).Return(nil, nil)
+	gomock.InOrder(
+		s.mockPublisher.EXPECT().Publish(gomock.Any(), events[0].Event).Return(errors.New("webhook responded with status 503")),
+		s.mockOutbox.EXPECT().MarkFailed(gomock.Any(), int64(1), now.Add(4*time.Second), "webhook responded with status 503").
+			Return(nil),
+		s.mockPublisher.EXPECT().Publish(gomock.Any(), events[1].Event).Return(nil),
+		s.mockOutbox.EXPECT().MarkPublished(gomock.Any(), int64(2), now).Return(nil),
+	)
+
+	published, err := s.service.Relay(context.Background())
+	s.Require().NoError(err)
+	s.Equal(1, published)
+}
+
+// events are not marked failed when the relay is stopped while publishing
+func (s *outboxServiceSuite) TestRelayCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), gomock.Any(), 3).
+		Return([]model.OutboxEvent{outboxEvent(1, uuid.NewV5(uuid.Nil, "account"))}, nil)
+	s.mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ model.Event) error {
+			cancel()
+
+			return ctx.Err()
+		})
+
+	published, err := s.service.Relay(ctx)
+	s.ErrorIs(err, context.Canceled)
+	s.Zero(published)
+}
+
+func (s *outboxServiceSuite) TestRelayPendingFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), gomock.Any(), 3).Return(nil, mockDBError)
+
+	_, err := s.service.Relay(context.Background())
+	s.ErrorIs(err, mockDBError)
+}
+
+// an event published but not marked is published again by the next relay
+func (s *outboxServiceSuite) TestRelayMarkPublishedFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockOutbox.EXPECT().Pending(gomock.Any(), gomock.Any(), 3).
+		Return([]model.OutboxEvent{outboxEvent(1, uuid.NewV5(uuid.Nil, "account"))}, nil)
+	s.mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().MarkPublished(gomock.Any(), int64(1), gomock.Any()).Return(mockDBError)
+
+	published, err := s.service.Relay(context.Background())
+	s.ErrorIs(err, mockDBError)
+	s.Zero(published)
+}
+
+// the relay of another instance holds the lock, the events are left to it
+func (s *outboxServiceSuite) TestRelayLocked() {
+	ctrl := gomock.NewController(s.T())
+	outbox := mocks.NewMockOutboxConnector(ctrl)
+	outbox.EXPECT().LockRelay(gomock.Any()).Return(false, nil)
+
+	published, err := NewOutboxService(outbox, s.mockPublisher, s.mockUoW, s.clock, OutboxOptions{BatchSize: 3}).
+		Relay(context.Background())
+	s.Require().NoError(err)
+	s.Zero(published)
+}
+
+func (s *outboxServiceSuite) TestRelayLockFailed() {
+	mockDBError := errors.New("some-db-error")
+	ctrl := gomock.NewController(s.T())
+	outbox := mocks.NewMockOutboxConnector(ctrl)
+	outbox.EXPECT().LockRelay(gomock.Any()).Return(false, mockDBError)
+
+	_, err := NewOutboxService(outbox, s.mockPublisher, s.mockUoW, s.clock, OutboxOptions{BatchSize: 3}).
+		Relay(context.Background())
+	s.ErrorIs(err, mockDBError)
+}
+
+// recordingPublisher records the events published, it is safe for concurrent use. Publishing
+// takes a while, like posting to a webhook, so that concurrent relays overlap
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []model.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e model.Event) error {
+	time.Sleep(100 * time.Microsecond)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, e)
+
+	return nil
+}
+
+// Relays running at the same time, like those of several instances, publish every event once and
+// the events of each account in order
+func TestRelayConcurrent(t *testing.T) {
+	ctx := context.Background()
+	outbox := memory.NewOutboxRepo()
+	accounts := []uuid.UUID{uuid.NewV5(uuid.Nil, "first"), uuid.NewV5(uuid.Nil, "second"), uuid.NewV5(uuid.Nil, "third")}
+
+	var added []model.Event
+	for i := range 60 {
+		e := outboxEvent(int64(i+1), accounts[i%len(accounts)]).Event
+		require.NoError(t, outbox.Add(ctx, e))
+		added = append(added, e)
+	}
+
+	pub := &recordingPublisher{}
+	uow := memory.NewUnitOfWork()
+	var wg sync.WaitGroup
+	for range 2 {
+		relay := NewOutboxService(outbox, pub, uow, clock.System(), OutboxOptions{
+			BatchSize:      7,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Minute,
+		})
+		wg.Go(func() {
+			_, err := relay.Relay(ctx)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	require.Len(t, pub.events, len(added))
+	for _, account := range accounts {
+		var want, got []uuid.UUID
+		for _, e := range added {
+			if e.AccountUUID == account {
+				want = append(want, e.UUID)
+			}
+		}
+		for _, e := range pub.events {
+			if e.AccountUUID == account {
+				got = append(got, e.UUID)
+			}
+		}
+		assert.Equal(t, want, got, account)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, backoff(0, time.Second, time.Minute))
+	assert.Equal(t, 2*time.Second, backoff(1, time.Second, time.Minute))
+	assert.Equal(t, 32*time.Second, backoff(5, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, backoff(6, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, backoff(1000, time.Second, time.Minute))
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/export"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/statement_mock.go -source=statement.go
+type StatementService interface {
+	// List returns the statements of an existing account, latest period first
+	List(ctx context.Context, accountUUID string) ([]model.Statement, error)
+	// Get returns a statement of the account with the transactions of its period
+	Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error)
+	// Export returns a statement of the account to render as a document, with the descriptions
+	// of the operation types of its transactions
+	Export(ctx context.Context, accountUUID, statementUUID string) (export.Statement, error)
+	// CloseCycles closes every billing cycle that ended by now into a statement, returning the
+	// number of statements created. Accounts failing to close are skipped, their errors joined
+	CloseCycles(ctx context.Context, now time.Time) (int, error)
+}
+
+// StatementOptions configures the minimum payment of statements, MinPaymentPercent of the
+// closing balance but at least MinPaymentFloor, and the currency of exported statements
+type StatementOptions struct {
+	MinPaymentPercent float64
+	MinPaymentFloor   float64
+	Currency          string
+}
+
+type statementService struct {
+	statementRepo     repository.StatementConnector
+	accountRepo       repository.AccountConnector
+	trxRepo           repository.TransactionConnector
+	operationTypeRepo repository.OperationTypeConnector
+	ledgerRepo        repository.LedgerConnector
+	uow               repository.UnitOfWork
+	opts              StatementOptions
+}
+
+func NewStatementService(
+	s repository.StatementConnector,
+	a repository.AccountConnector,
+	t repository.TransactionConnector,
+	o repository.OperationTypeConnector,
+	l repository.LedgerConnector,
+	uow repository.UnitOfWork,
+	opts StatementOptions,
+) StatementService {
+	return &statementService{
+		statementRepo:     s,
+		accountRepo:       a,
+		trxRepo:           t,
+		operationTypeRepo: o,
+		ledgerRepo:        l,
+		uow:               uow,
+		opts:              opts,
+	}
+}
+
+func (s *statementService) List(ctx context.Context, accountUUID string) ([]model.Statement, error) {
+	if _, err := s.accountRepo.Get(ctx, accountUUID); err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return nil, fmt.Errorf("%w for uuid: '%s'", ErrAccountNotFound, accountUUID)
+		}
+
+		return nil, err
+	}
+
+	statements, err := s.statementRepo.List(ctx, accountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// an account without statements lists none rather than null
+	if statements == nil {
+		statements = []model.Statement{}
+	}
+
+	return statements, nil
+}
+
+func (s *statementService) Get(ctx context.Context, accountUUID, statementUUID string) (model.Statement, error) {
+	statement, err := s.statementRepo.Get(ctx, accountUUID, statementUUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Statement{}, fmt.Errorf("%w for uuid: '%s'", ErrStatementNotFound, statementUUID)
+		}
+
+		return model.Statement{}, err
+	}
+
+	return statement, nil
+}
+
+func (s *statementService) Export(ctx context.Context, accountUUID, statementUUID string) (export.Statement, error) {
+	statement, err := s.Get(ctx, accountUUID, statementUUID)
+	if err != nil {
+		return export.Statement{}, err
+	}
+
+	operationTypes, err := s.operationTypeRepo.List(ctx)
+	if err != nil {
+		return export.Statement{}, err
+	}
+
+	descriptions := make(map[int]string, len(operationTypes))
+	for _, operationType := range operationTypes {
+		descriptions[operationType.OperationTypeID] = operationType.Description
+	}
+
+	return export.Statement{
+		Statement:      statement,
+		OperationTypes: descriptions,
+		Currency:       s.opts.Currency,
+	}, nil
+}
+
+func (s *statementService) CloseCycles(ctx context.Context, now time.Time) (int, error) {
+	accounts, err := s.accountRepo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		closed int
+		errs   []error
+	)
+	for _, account := range accounts {
+		n, err := s.closeAccountCycles(ctx, account, now)
+		closed += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to close billing cycle of account %s: %w", account.UUID, err))
+		}
+	}
+
+	return closed, errors.Join(errs...)
+}
+
+// closeAccountCycles closes the cycles of the account that ended by now, oldest first as each
+// statement opens with the closing balance of the previous one. The first cycle starts when
+// the account was created
+func (s *statementService) closeAccountCycles(ctx context.Context, account model.Account, now time.Time) (int, error) {
+	start, opening := account.CreatedAt, 0.0
+
+	latest, err := s.statementRepo.Latest(ctx, account.UUID.String())
+	switch {
+	case err == nil:
+		start, opening = latest.PeriodEnd, latest.ClosingBalance
+	case !errors.Is(err, repository.ErrNoRows):
+		return 0, err
+	}
+
+	var closed int
+	for end := nextClosingDate(start, account.ClosingDay); !end.After(now); end = nextClosingDate(end, account.ClosingDay) {
+		statement, err := s.closeCycle(ctx, account, start, end, opening, now)
+		if errors.Is(err, repository.ErrDuplicate) {
+			// another instance of the job is closing the cycles of this account
+			return closed, nil
+		}
+		if err != nil {
+			return closed, err
+		}
+
+		closed++
+		start, opening = end, statement.ClosingBalance
+	}
+
+	return closed, nil
+}
+
+// closeCycle snapshots the transactions of [start, end) into a statement, closing with the
+// balance of the receivable of the account in the ledger. Reading them and storing the
+// statement run in one serializable transaction, so that a transaction created meanwhile is
+// either in the snapshot or after it. The uuid is derived from the account and period, so the
+// statement of a cycle is created only once
+func (s *statementService) closeCycle(
+	ctx context.Context,
+	account model.Account,
+	start, end time.Time,
+	opening float64,
+	now time.Time,
+) (model.Statement, error) {
+	statement := model.Statement{
+		UUID:           uuid.NewV5(account.UUID, end.Format(time.RFC3339)),
+		AccountUUID:    account.UUID,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		DueDate:        dueDate(end, account.DueDay),
+		OpeningBalance: opening,
+		CreatedAt:      now,
+	}
+
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		transactions, err := s.trxRepo.ListByPeriod(ctx, account.UUID.String(), start, end)
+		if err != nil {
+			return err
+		}
+
+		var purchases, payments float64
+		for _, trx := range transactions {
+			// debits are stored with a negative amount
+			if trx.Amount < 0 {
+				purchases -= trx.Amount
+			} else {
+				payments += trx.Amount
+			}
+		}
+
+		// the ledger is the source of truth of the balance, the amount owed by the account
+		balance, err := s.ledgerRepo.Balance(ctx, account.UUID.String(), model.LedgerAccountReceivable, end)
+		if err != nil {
+			return err
+		}
+
+		statement.Transactions = transactions
+		statement.Purchases = roundCents(purchases)
+		statement.Payments = roundCents(payments)
+		statement.ClosingBalance = roundCents(balance)
+		statement.MinimumPayment = minimumPayment(statement.ClosingBalance, s.opts)
+
+		return s.statementRepo.Create(ctx, statement)
+	})
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	return statement, nil
+}
+
+// nextClosingDate returns the first closing date after t. Cycles close at midnight UTC of the
+// closing day, which is at most 28 and so exists in every month
+func nextClosingDate(t time.Time, closingDay int) time.Time {
+	t = t.UTC()
+
+	closing := time.Date(t.Year(), t.Month(), closingDay, 0, 0, 0, 0, time.UTC)
+	if !closing.After(t) {
+		closing = closing.AddDate(0, 1, 0)
+	}
+
+	return closing
+}
+
+// dueDate returns the first due day after the closing date of a cycle
+func dueDate(closing time.Time, dueDay int) time.Time {
+	due := time.Date(closing.Year(), closing.Month(), dueDay, 0, 0, 0, 0, time.UTC)
+	if !due.After(closing) {
+		due = due.AddDate(0, 1, 0)
+	}
+
+	return due
+}
+
+// minimumPayment returns the share of the closing balance due, nothing when the account owes
+// nothing and never more than the balance
+func minimumPayment(balance float64, opts StatementOptions) float64 {
+	if balance <= 0 {
+		return 0
+	}
+
+	return min(balance, max(opts.MinPaymentFloor, roundCents(balance*opts.MinPaymentPercent/100)))
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
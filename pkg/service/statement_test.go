@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-pismo-challenge/pkg/export"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type statementServiceSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	service        StatementService
+	mockStatements *mocks.MockStatementConnector
+	mockAccounts   *mocks.MockAccountConnector
+	mockTrx        *mocks.MockTransactionConnector
+	mockOpTypes    *mocks.MockOperationTypeConnector
+	mockLedger     *mocks.MockLedgerConnector
+	mockUoW        *mocks.MockUnitOfWork
+}
+
+func TestStatementService(t *testing.T) {
+	suite.Run(t, new(statementServiceSuite))
+}
+
+func (s *statementServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockStatements = mocks.NewMockStatementConnector(s.ctrl)
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockTrx = mocks.NewMockTransactionConnector(s.ctrl)
+	s.mockOpTypes = mocks.NewMockOperationTypeConnector(s.ctrl)
+	s.mockLedger = mocks.NewMockLedgerConnector(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	s.service = NewStatementService(s.mockStatements, s.mockAccounts, s.mockTrx, s.mockOpTypes, s.mockLedger, s.mockUoW, StatementOptions{
+		MinPaymentPercent: 15,
+		MinPaymentFloor:   20,
+		Currency:          "BRL",
+	})
+}
+
+func (s *statementServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func mockBillingAccount() model.Account {
+	return model.Account{
+		UUID:       uuid.FromStringOrNil(mockAccountUUID),
+		ClosingDay: 5,
+		DueDay:     15,
+		CreatedAt:  date(2025, time.August, 20).Add(10 * time.Hour),
+	}
+}
+
+func (s *statementServiceSuite) TestListSuccess() {
+	statements := []model.Statement{{UUID: getMockUUID(), AccountUUID: uuid.FromStringOrNil(mockAccountUUID)}}
+
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+	s.mockStatements.EXPECT().List(gomock.Any(), mockAccountUUID).Return(statements, nil)
+
+	got, err := s.service.List(context.Background(), mockAccountUUID)
+	s.Require().NoError(err)
+	s.Equal(statements, got)
+}
+
+func (s *statementServiceSuite) TestListEmpty() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+	s.mockStatements.EXPECT().List(gomock.Any(), mockAccountUUID).Return(nil, nil)
+
+	got, err := s.service.List(context.Background(), mockAccountUUID)
+	s.Require().NoError(err)
+	s.NotNil(got)
+	s.Empty(got)
+}
+
+func (s *statementServiceSuite) TestListAccountNotFound() {
+	s.mockAccounts.EXPECT().Get(gomock.Any(), mockAccountUUID).Return(model.Account{}, repository.ErrNoRows)
+
+	_, err := s.service.List(context.Background(), mockAccountUUID)
+	s.ErrorIs(err, ErrAccountNotFound)
+}
+
+func (s *statementServiceSuite) TestGetNotFound() {
+	statementUUID := getMockUUID().String()
+	s.mockStatements.EXPECT().Get(gomock.Any(), mockAccountUUID, statementUUID).Return(model.Statement{}, repository.ErrNoRows)
+
+	_, err := s.service.Get(context.Background(), mockAccountUUID, statementUUID)
+	s.ErrorIs(err, ErrStatementNotFound)
+}
+
+func (s *statementServiceSuite) TestExportSuccess() {
+	statement := model.Statement{UUID: getMockUUID(), AccountUUID: uuid.FromStringOrNil(mockAccountUUID)}
+	statementUUID := statement.UUID.String()
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), mockAccountUUID, statementUUID).Return(statement, nil)
+	s.mockOpTypes.EXPECT().List(gomock.Any()).Return([]model.OperationType{
+		{OperationTypeID: 1, Description: "CASH_PURCHASE"},
+		{OperationTypeID: 4, Description: "PAYMENT", IsCredit: true},
+	}, nil)
+
+	got, err := s.service.Export(context.Background(), mockAccountUUID, statementUUID)
+	s.Require().NoError(err)
+	s.Equal(export.Statement{
+		Statement:      statement,
+		OperationTypes: map[int]string{1: "CASH_PURCHASE", 4: "PAYMENT"},
+		Currency:       "BRL",
+	}, got)
+}
+
+func (s *statementServiceSuite) TestExportNotFound() {
+	statementUUID := getMockUUID().String()
+	s.mockStatements.EXPECT().Get(gomock.Any(), mockAccountUUID, statementUUID).Return(model.Statement{}, repository.ErrNoRows)
+
+	_, err := s.service.Export(context.Background(), mockAccountUUID, statementUUID)
+	s.ErrorIs(err, ErrStatementNotFound)
+}
+
+func (s *statementServiceSuite) TestExportOperationTypesFailed() {
+	statementUUID := getMockUUID().String()
+	mockError := errors.New("db error")
+
+	s.mockStatements.EXPECT().Get(gomock.Any(), mockAccountUUID, statementUUID).Return(model.Statement{}, nil)
+	s.mockOpTypes.EXPECT().List(gomock.Any()).Return(nil, mockError)
+
+	_, err := s.service.Export(context.Background(), mockAccountUUID, statementUUID)
+	s.ErrorIs(err, mockError)
+}
+
+// the first cycle starts when the account was created, every cycle opens with the closing
+// balance of the previous one and closes with the balance of the receivable in the ledger
+func (s *statementServiceSuite) TestCloseCyclesFirstStatements() {
+	account := mockBillingAccount()
+	accountUUID := account.UUID.String()
+	now := date(2025, time.October, 6)
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{account}, nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), accountUUID).Return(model.Statement{}, repository.ErrNoRows)
+
+	august := []model.Transaction{
+		{UUID: uuid.NewV5(uuid.Nil, "1"), Amount: -100},
+		{UUID: uuid.NewV5(uuid.Nil, "2"), Amount: 30},
+	}
+	september := []model.Transaction{{UUID: uuid.NewV5(uuid.Nil, "3"), Amount: -250.4}}
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), accountUUID, account.CreatedAt, date(2025, time.September, 5)).Return(august, nil)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), accountUUID, date(2025, time.September, 5), date(2025, time.October, 5)).
+		Return(september, nil)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), accountUUID, model.LedgerAccountReceivable, date(2025, time.September, 5)).Return(70.0, nil)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), accountUUID, model.LedgerAccountReceivable, date(2025, time.October, 5)).
+		Return(320.40000000000003, nil)
+
+	var created []model.Statement
+	s.mockStatements.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, st model.Statement) error {
+			created = append(created, st)
+
+			return nil
+		}).Times(2)
+
+	closed, err := s.service.CloseCycles(context.Background(), now)
+	s.Require().NoError(err)
+	s.Equal(2, closed)
+	s.Require().Len(created, 2)
+
+	s.Equal(model.Statement{
+		UUID:           uuid.NewV5(account.UUID, "2025-09-05T00:00:00Z"),
+		AccountUUID:    account.UUID,
+		PeriodStart:    account.CreatedAt,
+		PeriodEnd:      date(2025, time.September, 5),
+		DueDate:        date(2025, time.September, 15),
+		Purchases:      100,
+		Payments:       30,
+		ClosingBalance: 70,
+		MinimumPayment: 20,
+		CreatedAt:      now,
+		Transactions:   august,
+	}, created[0])
+
+	s.Equal(model.Statement{
+		UUID:           uuid.NewV5(account.UUID, "2025-10-05T00:00:00Z"),
+		AccountUUID:    account.UUID,
+		PeriodStart:    date(2025, time.September, 5),
+		PeriodEnd:      date(2025, time.October, 5),
+		DueDate:        date(2025, time.October, 15),
+		OpeningBalance: 70,
+		Purchases:      250.4,
+		ClosingBalance: 320.4,
+		MinimumPayment: 48.06,
+		CreatedAt:      now,
+		Transactions:   september,
+	}, created[1])
+}
+
+func (s *statementServiceSuite) TestCloseCyclesNothingDue() {
+	account := mockBillingAccount()
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{account}, nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), account.UUID.String()).
+		Return(model.Statement{PeriodEnd: date(2025, time.October, 5), ClosingBalance: 50}, nil)
+
+	closed, err := s.service.CloseCycles(context.Background(), date(2025, time.November, 5).Add(-time.Nanosecond))
+	s.Require().NoError(err)
+	s.Zero(closed)
+}
+
+// a cycle closed by another instance of the job is not an error
+func (s *statementServiceSuite) TestCloseCyclesClosedConcurrently() {
+	account := mockBillingAccount()
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{account}, nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), account.UUID.String()).
+		Return(model.Statement{PeriodEnd: date(2025, time.October, 5), ClosingBalance: 50}, nil)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), gomock.Any(), model.LedgerAccountReceivable, gomock.Any()).Return(50.0, nil)
+	s.mockStatements.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, st model.Statement) error {
+			s.InDelta(50, st.ClosingBalance, 0)
+			s.InDelta(20, st.MinimumPayment, 0)
+
+			return repository.ErrDuplicate
+		})
+
+	closed, err := s.service.CloseCycles(context.Background(), date(2025, time.December, 1))
+	s.Require().NoError(err)
+	s.Zero(closed)
+}
+
+// a failing account does not stop the others from being closed
+func (s *statementServiceSuite) TestCloseCyclesAccountFailed() {
+	failing, account := mockBillingAccount(), mockBillingAccount()
+	failing.UUID = getMockUUID()
+	mockError := errors.New("db error")
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{failing, account}, nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), failing.UUID.String()).Return(model.Statement{}, mockError)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), account.UUID.String()).Return(model.Statement{}, repository.ErrNoRows)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), account.UUID.String(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), account.UUID.String(), model.LedgerAccountReceivable, gomock.Any()).Return(0.0, nil)
+	s.mockStatements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	closed, err := s.service.CloseCycles(context.Background(), date(2025, time.September, 10))
+	s.ErrorIs(err, mockError)
+	s.ErrorContains(err, failing.UUID.String())
+	s.Equal(1, closed)
+}
+
+// the statement is not created without the balance of the ledger
+func (s *statementServiceSuite) TestCloseCyclesBalanceFailed() {
+	account := mockBillingAccount()
+	mockError := errors.New("db error")
+
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return([]model.Account{account}, nil)
+	s.mockStatements.EXPECT().Latest(gomock.Any(), account.UUID.String()).Return(model.Statement{}, repository.ErrNoRows)
+	s.mockTrx.EXPECT().ListByPeriod(gomock.Any(), account.UUID.String(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), account.UUID.String(), model.LedgerAccountReceivable, gomock.Any()).Return(0.0, mockError)
+
+	closed, err := s.service.CloseCycles(context.Background(), date(2025, time.September, 10))
+	s.ErrorIs(err, mockError)
+	s.Zero(closed)
+}
+
+func (s *statementServiceSuite) TestCloseCyclesListFailed() {
+	mockError := errors.New("db error")
+	s.mockAccounts.EXPECT().List(gomock.Any()).Return(nil, mockError)
+
+	_, err := s.service.CloseCycles(context.Background(), time.Now())
+	s.ErrorIs(err, mockError)
+}
+
+func TestNextClosingDate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		t    time.Time
+		day  int
+		want time.Time
+	}{
+		{"before closing day", date(2025, time.January, 3).Add(time.Hour), 5, date(2025, time.January, 5)},
+		{"on closing date", date(2025, time.January, 5), 5, date(2025, time.February, 5)},
+		{"after closing day", date(2025, time.January, 5).Add(time.Second), 5, date(2025, time.February, 5)},
+		{"end of year", date(2025, time.December, 28).Add(time.Hour), 28, date(2026, time.January, 28)},
+		{"short month", date(2025, time.January, 31), 28, date(2025, time.February, 28)},
+		{"local time", time.Date(2025, time.January, 4, 22, 0, 0, 0, time.FixedZone("UTC-3", -3*60*60)), 5,
+			date(2025, time.February, 5)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, nextClosingDate(tc.t, tc.day))
+		})
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	assert.Equal(t, date(2025, time.January, 15), dueDate(date(2025, time.January, 5), 15))
+	assert.Equal(t, date(2025, time.February, 5), dueDate(date(2025, time.January, 5), 5))
+	assert.Equal(t, date(2025, time.February, 1), dueDate(date(2025, time.January, 25), 1))
+}
+
+func TestMinimumPayment(t *testing.T) {
+	opts := StatementOptions{MinPaymentPercent: 15, MinPaymentFloor: 20}
+	for _, tc := range []struct {
+		balance, want float64
+	}{
+		{-10, 0},
+		{0, 0},
+		{12.5, 12.5},
+		{100, 20},
+		{1000, 150},
+		{333.33, 50},
+	} {
+		assert.InDelta(t, tc.want, minimumPayment(tc.balance, opts), 0, "balance %v", tc.balance)
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/util"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/transaction_mock.go -source=transaction.go
+type TransactionService interface {
+	// Create stores a new transaction for an existing account, posts it to the ledger and adds its
+	// transaction.created event to the outbox. The uuid is derived from the idempotency key of req
+	// and the sign of the amount from the operation type
+	Create(ctx context.Context, req model.TransactionRequest) (model.Transaction, error)
+	// CreateBatch creates the transactions of reqs like Create, all in one unit of work. Requests
+	// failing validation or referencing an account or operation type that does not exist are
+	// rejected, and requests with an idempotency key used before or earlier in the batch are
+	// duplicates. Requests that could not be decoded are rejected with their field error in
+	// decodeErrs, by index. Rejected requests leave the others to be created, unless mode is
+	// all-or-nothing
+	CreateBatch(
+		ctx context.Context,
+		reqs []model.TransactionRequest,
+		decodeErrs map[int]util.FieldError,
+		mode string,
+	) (model.TransactionBatchResult, error)
+}
+
+// fieldCodeNotFound is the code of the field errors of batch requests referencing an account or
+// operation type that does not exist
+const fieldCodeNotFound = "not_found"
+
+type transactionService struct {
+	trxRepo           repository.TransactionConnector
+	accountRepo       repository.AccountConnector
+	operationTypeRepo repository.OperationTypeConnector
+	ledgerRepo        repository.LedgerConnector
+	outboxRepo        repository.OutboxConnector
+	uow               repository.UnitOfWork
+}
+
+func NewTransactionService(
+	t repository.TransactionConnector,
+	a repository.AccountConnector,
+	o repository.OperationTypeConnector,
+	l repository.LedgerConnector,
+	ob repository.OutboxConnector,
+	uow repository.UnitOfWork,
+) TransactionService {
+	return &transactionService{
+		trxRepo:           t,
+		accountRepo:       a,
+		operationTypeRepo: o,
+		ledgerRepo:        l,
+		outboxRepo:        ob,
+		uow:               uow,
+	}
+}
+
+func (s *transactionService) Create(ctx context.Context, req model.TransactionRequest) (model.Transaction, error) {
+	if vErr := req.Validate(); len(vErr) > 0 {
+		return model.Transaction{}, &ValidationError{Fields: vErr}
+	}
+
+	trxUUID := uuid.NewV5(uuid.Nil, req.IdempotencyKey)
+
+	var trx model.Transaction
+	// the checks and the insert run in one serializable transaction, so that concurrent
+	// requests with the same idempotency key cannot both pass the check
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var err error
+		trx, err = s.create(ctx, trxUUID, req)
+
+		return err
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	return trx, nil
+}
+
+// create validates the account and operation type of req, stores the transaction, posts its
+// journal entries and adds its event to the outbox
+func (s *transactionService) create(ctx context.Context, trxUUID uuid.UUID, req model.TransactionRequest) (model.Transaction, error) {
+	if err := s.trxRepo.CheckIdempotency(ctx, trxUUID.String()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Transaction{}, ErrDuplicateRequest
+		}
+
+		return model.Transaction{}, err
+	}
+
+	// validate account, locking it so that the transactions of an account are stored one at a time
+	// and their ids follow the order they are committed in, which account event streams resume by
+	if _, err := s.accountRepo.GetForUpdate(ctx, req.AccountUUID); err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Transaction{}, fmt.Errorf("%w for account_uuid: '%s'", ErrAccountNotFound, req.AccountUUID)
+		}
+
+		return model.Transaction{}, err
+	}
+
+	// validate operation type
+	operationType, err := s.operationTypeRepo.Get(ctx, req.OperationTypeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Transaction{}, fmt.Errorf("%w: %d", ErrOperationTypeNotFound, req.OperationTypeID)
+		}
+
+		return model.Transaction{}, err
+	}
+
+	// ignoring the error as the field was validated
+	accountUUID, _ := uuid.FromString(req.AccountUUID)
+	trx := model.Transaction{
+		UUID:            trxUUID,
+		AccountUUID:     accountUUID,
+		OperationTypeID: req.OperationTypeID,
+		Amount:          resolveAmount(req.Amount, operationType.IsCredit),
+		EventDate:       time.Now(),
+	}
+
+	entries := journal(trx)
+	if err := checkBalanced(entries); err != nil {
+		return model.Transaction{}, err
+	}
+
+	if err := s.trxRepo.Create(ctx, trx); err != nil {
+		return model.Transaction{}, err
+	}
+
+	if err := s.ledgerRepo.Post(ctx, entries); err != nil {
+		return model.Transaction{}, err
+	}
+
+	event, err := newEvent(model.EventTransactionCreated, trx.UUID, trx.AccountUUID, trx, trx.EventDate)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	if err := s.outboxRepo.Add(ctx, event); err != nil {
+		return model.Transaction{}, err
+	}
+
+	return trx, nil
+}
+
+func (s *transactionService) CreateBatch(
+	ctx context.Context,
+	reqs []model.TransactionRequest,
+	decodeErrs map[int]util.FieldError,
+	mode string,
+) (model.TransactionBatchResult, error) {
+	validated := make([]model.TransactionBatchItem, len(reqs))
+	for i, req := range reqs {
+		validated[i] = model.TransactionBatchItem{Index: i}
+		if fErr, ok := decodeErrs[i]; ok {
+			validated[i].Status = model.BatchItemRejected
+			validated[i].Errors = []util.FieldError{fErr}
+
+			continue
+		}
+		if vErr := req.Validate(); len(vErr) > 0 {
+			validated[i].Status = model.BatchItemRejected
+			validated[i].Errors = vErr
+
+			continue
+		}
+		validated[i].UUID = uuid.NewV5(uuid.Nil, req.IdempotencyKey).String()
+	}
+
+	var items []model.TransactionBatchItem
+	// the checks and the inserts run in one serializable transaction like in Create, an attempt
+	// retried after a serialization failure starts over from the validated requests
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		items = slices.Clone(validated)
+
+		return s.createBatch(ctx, reqs, items, mode)
+	})
+	if err != nil {
+		return model.TransactionBatchResult{}, err
+	}
+
+	result := model.TransactionBatchResult{Mode: mode, Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case model.BatchItemCreated:
+			result.Created++
+		case model.BatchItemDuplicate:
+			result.Duplicates++
+		case model.BatchItemRejected:
+			result.Rejected++
+		case model.BatchItemSkipped:
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// createBatch sets the status of the items not rejected by validation, and stores the
+// transactions of those created, their journal entries and their events
+func (s *transactionService) createBatch(
+	ctx context.Context,
+	reqs []model.TransactionRequest,
+	items []model.TransactionBatchItem,
+	mode string,
+) error {
+	if err := s.checkDuplicates(ctx, items); err != nil {
+		return err
+	}
+
+	operationTypes, err := s.checkReferences(ctx, reqs, items)
+	if err != nil {
+		return err
+	}
+
+	rejected := slices.ContainsFunc(items, func(item model.TransactionBatchItem) bool {
+		return item.Status == model.BatchItemRejected
+	})
+
+	var (
+		transactions []model.Transaction
+		entries      []model.JournalEntry
+		events       []model.Event
+	)
+	now := time.Now()
+	// an idempotency key used earlier in the batch is a duplicate of that transaction
+	seen := map[string]bool{}
+	for i, item := range items {
+		if item.Status != "" {
+			continue
+		}
+		if seen[item.UUID] {
+			items[i].Status = model.BatchItemDuplicate
+
+			continue
+		}
+		seen[item.UUID] = true
+
+		if rejected && mode == model.BatchModeAllOrNothing {
+			items[i].Status = model.BatchItemSkipped
+
+			continue
+		}
+
+		trx := model.Transaction{
+			UUID:            uuid.FromStringOrNil(item.UUID),
+			AccountUUID:     uuid.FromStringOrNil(reqs[i].AccountUUID),
+			OperationTypeID: reqs[i].OperationTypeID,
+			Amount:          resolveAmount(reqs[i].Amount, operationTypes[reqs[i].OperationTypeID].IsCredit),
+			EventDate:       now,
+		}
+
+		trxEntries := journal(trx)
+		if err := checkBalanced(trxEntries); err != nil {
+			return err
+		}
+
+		event, err := newEvent(model.EventTransactionCreated, trx.UUID, trx.AccountUUID, trx, trx.EventDate)
+		if err != nil {
+			return err
+		}
+
+		transactions = append(transactions, trx)
+		entries = append(entries, trxEntries...)
+		events = append(events, event)
+		items[i].Status = model.BatchItemCreated
+	}
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	if err := s.trxRepo.CreateBatch(ctx, transactions); err != nil {
+		return err
+	}
+
+	if err := s.ledgerRepo.Post(ctx, entries); err != nil {
+		return err
+	}
+
+	return s.outboxRepo.AddBatch(ctx, events)
+}
+
+// checkDuplicates marks the items whose transaction was stored before as duplicates
+func (s *transactionService) checkDuplicates(ctx context.Context, items []model.TransactionBatchItem) error {
+	var trxUUIDs []string
+	for _, item := range items {
+		if item.Status == "" {
+			trxUUIDs = append(trxUUIDs, item.UUID)
+		}
+	}
+	if len(trxUUIDs) == 0 {
+		return nil
+	}
+
+	existing, err := s.trxRepo.ListExisting(ctx, trxUUIDs)
+	if err != nil {
+		return err
+	}
+
+	stored := map[string]bool{}
+	for _, trxUUID := range existing {
+		stored[trxUUID] = true
+	}
+	for i, item := range items {
+		if item.Status == "" && stored[item.UUID] {
+			items[i].Status = model.BatchItemDuplicate
+		}
+	}
+
+	return nil
+}
+
+// checkReferences rejects the items referencing an account or an operation type that does not
+// exist, and returns the operation types referenced. The accounts are locked like in Create, in
+// the order of their uuids so that batches and transfers of the same accounts cannot deadlock
+func (s *transactionService) checkReferences(
+	ctx context.Context,
+	reqs []model.TransactionRequest,
+	items []model.TransactionBatchItem,
+) (map[int]model.OperationType, error) {
+	// the uuids were validated, in canonical form they order and compare as the stored ones
+	accountUUIDs := make([]string, len(items))
+	var locked []string
+	operationTypes := map[int]model.OperationType{}
+	for i, item := range items {
+		if item.Status == "" {
+			accountUUIDs[i] = uuid.FromStringOrNil(reqs[i].AccountUUID).String()
+			locked = append(locked, accountUUIDs[i])
+			operationTypes[reqs[i].OperationTypeID] = model.OperationType{}
+		}
+	}
+	slices.Sort(locked)
+	locked = slices.Compact(locked)
+
+	missingAccounts := map[string]bool{}
+	for _, accountUUID := range locked {
+		if _, err := s.accountRepo.GetForUpdate(ctx, accountUUID); err != nil {
+			if !errors.Is(err, repository.ErrNoRows) {
+				return nil, err
+			}
+			missingAccounts[accountUUID] = true
+		}
+	}
+
+	for id := range operationTypes {
+		operationType, err := s.operationTypeRepo.Get(ctx, id)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNoRows) {
+				return nil, err
+			}
+			delete(operationTypes, id)
+
+			continue
+		}
+		operationTypes[id] = operationType
+	}
+
+	for i, item := range items {
+		if item.Status != "" {
+			continue
+		}
+
+		if missingAccounts[accountUUIDs[i]] {
+			items[i].Errors = append(items[i].Errors, util.FieldError{
+				Field:   "account_uuid",
+				Code:    fieldCodeNotFound,
+				Message: fmt.Sprintf("%s: '%s'", ErrAccountNotFound, reqs[i].AccountUUID),
+			})
+		}
+		if _, ok := operationTypes[reqs[i].OperationTypeID]; !ok {
+			items[i].Errors = append(items[i].Errors, util.FieldError{
+				Field:   "operation_type_id",
+				Code:    fieldCodeNotFound,
+				Message: fmt.Sprintf("%s: %d", ErrOperationTypeNotFound, reqs[i].OperationTypeID),
+			})
+		}
+		if len(items[i].Errors) > 0 {
+			items[i].Status = model.BatchItemRejected
+			items[i].UUID = ""
+		}
+	}
+
+	return operationTypes, nil
+}
+
+// returns negative amount for debit operation type, else positive
+func resolveAmount(amount float64, isCredit bool) float64 {
+	if isCredit {
+		return amount
+	}
+
+	return amount * (-1)
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"go-pismo-challenge/pkg/util"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+const mockAccountUUID = "e2a84838-88de-5fbc-8636-6ef49e26f00a"
+
+type transactionServiceSuite struct {
+	suite.Suite
+	ctrl               *gomock.Controller
+	service            TransactionService
+	mockAccounts       *mocks.MockAccountConnector
+	mockTrx            *mocks.MockTransactionConnector
+	mockOperationTypes *mocks.MockOperationTypeConnector
+	mockLedger         *mocks.MockLedgerConnector
+	mockOutbox         *mocks.MockOutboxConnector
+	mockUoW            *mocks.MockUnitOfWork
+}
+
+func TestTransactionService(t *testing.T) {
+	suite.Run(t, new(transactionServiceSuite))
+}
+
+func (s *transactionServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockTrx = mocks.NewMockTransactionConnector(s.ctrl)
+	s.mockOperationTypes = mocks.NewMockOperationTypeConnector(s.ctrl)
+	s.mockLedger = mocks.NewMockLedgerConnector(s.ctrl)
+	s.mockOutbox = mocks.NewMockOutboxConnector(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	s.service = NewTransactionService(s.mockTrx, s.mockAccounts, s.mockOperationTypes, s.mockLedger, s.mockOutbox, s.mockUoW)
+}
+
+func (s *transactionServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func validTransactionRequest(operationTypeID int) model.TransactionRequest {
+	return model.TransactionRequest{
+		AccountUUID:     mockAccountUUID,
+		OperationTypeID: operationTypeID,
+		Amount:          1.1,
+		IdempotencyKey:  "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}
+}
+
+func (s *transactionServiceSuite) expectValidAccount() {
+	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+}
+
+// credit operations keep the amount positive
+func (s *transactionServiceSuite) TestCreateCreditSuccess() {
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, t model.Transaction) error {
+			s.Equal(getMockUUID(), t.UUID)
+			s.Equal(mockAccountUUID, t.AccountUUID.String())
+			s.Equal(4, t.OperationTypeID)
+			s.InDelta(1.1, t.Amount, 0)
+
+			return nil
+		})
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entries []model.JournalEntry) error {
+			// a payment credits the receivable against cash
+			s.Require().Len(entries, 2)
+			s.Equal(model.LedgerAccountReceivable, entries[0].LedgerAccountID)
+			s.InDelta(1.1, entries[0].Credit, 0)
+			s.Equal(model.LedgerAccountCash, entries[1].LedgerAccountID)
+			s.InDelta(1.1, entries[1].Debit, 0)
+
+			return nil
+		})
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e model.Event) error {
+			s.Equal(uuid.NewV5(getMockUUID(), model.EventTransactionCreated), e.UUID)
+			s.Equal(model.EventTransactionCreated, e.Type)
+			s.Equal(mockAccountUUID, e.AccountUUID.String())
+
+			var t model.Transaction
+			s.Require().NoError(json.Unmarshal(e.Data, &t))
+			s.Equal(getMockUUID(), t.UUID)
+			s.InDelta(1.1, t.Amount, 0)
+			s.True(t.EventDate.Equal(e.CreatedAt))
+
+			return nil
+		})
+
+	trx, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.Require().NoError(err)
+	s.Equal(getMockUUID(), trx.UUID)
+}
+
+// debit operations store a negative amount
+func (s *transactionServiceSuite) TestCreateDebitSuccess() {
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 1).Return(model.OperationType{OperationTypeID: 1}, nil)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+
+	trx, err := s.service.Create(context.Background(), validTransactionRequest(1))
+	s.Require().NoError(err)
+	s.InDelta(-1.1, trx.Amount, 0)
+}
+
+func (s *transactionServiceSuite) TestCreateValidationFailed() {
+	_, err := s.service.Create(context.Background(), model.TransactionRequest{
+		AccountUUID:     "invalid-uuid",
+		OperationTypeID: -5,
+		Amount:          -9,
+	})
+
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Len(vErr.Fields, 4)
+}
+
+func (s *transactionServiceSuite) TestCreateDuplicate() {
+	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+func (s *transactionServiceSuite) TestCreateAccountNotFound() {
+	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, repository.ErrNoRows)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, ErrAccountNotFound)
+	s.Equal("account not found for account_uuid: '"+mockAccountUUID+"'", err.Error())
+}
+
+func (s *transactionServiceSuite) TestCreateAccountCheckFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockTrx.EXPECT().CheckIdempotency(gomock.Any(), getMockUUID().String()).Return(nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, mockDBError)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, mockDBError)
+}
+
+func (s *transactionServiceSuite) TestCreateOperationTypeNotFound() {
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 14).Return(model.OperationType{}, repository.ErrNoRows)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(14))
+	s.ErrorIs(err, ErrOperationTypeNotFound)
+	s.Equal("invalid operation type: 14", err.Error())
+}
+
+func (s *transactionServiceSuite) TestCreateOperationTypeFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{}, mockDBError)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, mockDBError)
+}
+
+func (s *transactionServiceSuite) TestCreateFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, mockDBError)
+}
+
+// the transaction is not stored without its journal entries
+func (s *transactionServiceSuite) TestCreateLedgerFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, mockDBError)
+}
+
+// the transaction is not stored without its event
+func (s *transactionServiceSuite) TestCreateOutboxFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.expectValidAccount()
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockOutbox.EXPECT().Add(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Create(context.Background(), validTransactionRequest(4))
+	s.ErrorIs(err, mockDBError)
+}
+
+// batchRequest returns a valid request of the batch tests, with its own idempotency key
+func batchRequest(key string, accountUUID string, operationTypeID int) model.TransactionRequest {
+	return model.TransactionRequest{AccountUUID: accountUUID, OperationTypeID: operationTypeID, Amount: 1.1, IdempotencyKey: key}
+}
+
+func batchUUID(key string) string {
+	return uuid.NewV5(uuid.Nil, key).String()
+}
+
+// A best-effort batch creates the transactions not rejected, and reports the others
+func (s *transactionServiceSuite) TestCreateBatchBestEffort() {
+	// sorts before mockAccountUUID
+	missingAccountUUID := "0d3c5c6e-1d4b-4f8e-9a1c-6a7f6f5f0e2b"
+	reqs := []model.TransactionRequest{
+		batchRequest("created", mockAccountUUID, 4),
+		{AccountUUID: "invalid-uuid", OperationTypeID: 4, Amount: 1.1, IdempotencyKey: "invalid"},
+		batchRequest("stored", mockAccountUUID, 4),
+		batchRequest("created", mockAccountUUID, 1),
+		batchRequest("missing-account", missingAccountUUID, 4),
+		batchRequest("missing-operation-type", mockAccountUUID, 99),
+	}
+
+	s.mockTrx.EXPECT().ListExisting(gomock.Any(), []string{
+		batchUUID("created"), batchUUID("stored"), batchUUID("created"), batchUUID("missing-account"), batchUUID("missing-operation-type"),
+	}).Return([]string{batchUUID("stored")}, nil)
+	// accounts are locked once each, in the order of their uuids
+	gomock.InOrder(
+		s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), missingAccountUUID).Return(model.Account{}, repository.ErrNoRows),
+		s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil),
+	)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 1).Return(model.OperationType{OperationTypeID: 1}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 99).Return(model.OperationType{}, repository.ErrNoRows)
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transactions []model.Transaction) error {
+			s.Require().Len(transactions, 1)
+			s.Equal(batchUUID("created"), transactions[0].UUID.String())
+			s.Equal(mockAccountUUID, transactions[0].AccountUUID.String())
+			s.InDelta(1.1, transactions[0].Amount, 0)
+
+			return nil
+		})
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Len(2)).Return(nil)
+	s.mockOutbox.EXPECT().AddBatch(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(ctx context.Context, events []model.Event) error {
+			s.Equal(model.EventTransactionCreated, events[0].Type)
+			s.Equal(uuid.NewV5(uuid.FromStringOrNil(batchUUID("created")), model.EventTransactionCreated), events[0].UUID)
+
+			return nil
+		})
+
+	result, err := s.service.CreateBatch(context.Background(), reqs, nil, model.BatchModeBestEffort)
+	s.Require().NoError(err)
+	s.Equal(model.BatchModeBestEffort, result.Mode)
+	s.Equal(1, result.Created)
+	s.Equal(2, result.Duplicates)
+	s.Equal(3, result.Rejected)
+	s.Zero(result.Skipped)
+
+	s.Require().Len(result.Items, len(reqs))
+	s.Equal(model.TransactionBatchItem{Index: 0, Status: model.BatchItemCreated, UUID: batchUUID("created")}, result.Items[0])
+	s.Equal(model.BatchItemRejected, result.Items[1].Status)
+	s.Empty(result.Items[1].UUID)
+	s.Equal("account_uuid", result.Items[1].Errors[0].Field)
+	s.Equal(model.TransactionBatchItem{Index: 2, Status: model.BatchItemDuplicate, UUID: batchUUID("stored")}, result.Items[2])
+	s.Equal(model.TransactionBatchItem{Index: 3, Status: model.BatchItemDuplicate, UUID: batchUUID("created")}, result.Items[3])
+	s.Equal(model.TransactionBatchItem{Index: 4, Status: model.BatchItemRejected, Errors: []util.FieldError{{
+		Field: "account_uuid", Code: "not_found", Message: "account not found: '" + missingAccountUUID + "'",
+	}}}, result.Items[4])
+	s.Equal(model.TransactionBatchItem{Index: 5, Status: model.BatchItemRejected, Errors: []util.FieldError{{
+		Field: "operation_type_id", Code: "not_found", Message: "invalid operation type: 99",
+	}}}, result.Items[5])
+}
+
+// An all-or-nothing batch with a rejected transaction stores none
+func (s *transactionServiceSuite) TestCreateBatchAllOrNothingRejected() {
+	reqs := []model.TransactionRequest{
+		batchRequest("valid", mockAccountUUID, 4),
+		batchRequest("stored", mockAccountUUID, 4),
+		batchRequest("missing-operation-type", mockAccountUUID, 99),
+	}
+
+	s.mockTrx.EXPECT().ListExisting(gomock.Any(), gomock.Len(3)).Return([]string{batchUUID("stored")}, nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 99).Return(model.OperationType{}, repository.ErrNoRows)
+
+	result, err := s.service.CreateBatch(context.Background(), reqs, nil, model.BatchModeAllOrNothing)
+	s.Require().NoError(err)
+	s.Equal(model.TransactionBatchResult{
+		Mode:       model.BatchModeAllOrNothing,
+		Duplicates: 1,
+		Rejected:   1,
+		Skipped:    1,
+		Items: []model.TransactionBatchItem{
+			{Index: 0, Status: model.BatchItemSkipped, UUID: batchUUID("valid")},
+			{Index: 1, Status: model.BatchItemDuplicate, UUID: batchUUID("stored")},
+			{Index: 2, Status: model.BatchItemRejected, Errors: []util.FieldError{{
+				Field: "operation_type_id", Code: "not_found", Message: "invalid operation type: 99",
+			}}},
+		},
+	}, result)
+}
+
+// An all-or-nothing batch rejected by validation does not reach the database
+func (s *transactionServiceSuite) TestCreateBatchAllOrNothingInvalid() {
+	result, err := s.service.CreateBatch(context.Background(), []model.TransactionRequest{{Amount: -1}}, nil, model.BatchModeAllOrNothing)
+	s.Require().NoError(err)
+	s.Equal(1, result.Rejected)
+	s.Len(result.Items[0].Errors, 4)
+}
+
+// A transaction that could not be decoded is rejected with its field error, the others of a
+// best-effort batch are created
+func (s *transactionServiceSuite) TestCreateBatchDecodeFailed() {
+	decodeErr := util.FieldError{Field: "amount", Code: util.FieldCodeInvalidType, Message: "expected number, got string"}
+
+	s.mockTrx.EXPECT().ListExisting(gomock.Any(), []string{batchUUID("valid")}).Return(nil, nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Len(1)).Return(nil)
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Len(2)).Return(nil)
+	s.mockOutbox.EXPECT().AddBatch(gomock.Any(), gomock.Len(1)).Return(nil)
+
+	result, err := s.service.CreateBatch(context.Background(), []model.TransactionRequest{
+		{},
+		batchRequest("valid", mockAccountUUID, 4),
+	}, map[int]util.FieldError{0: decodeErr}, model.BatchModeBestEffort)
+	s.Require().NoError(err)
+	s.Equal(1, result.Created)
+	s.Equal(1, result.Rejected)
+	s.Equal(model.TransactionBatchItem{Index: 0, Status: model.BatchItemRejected, Errors: []util.FieldError{decodeErr}},
+		result.Items[0])
+}
+
+func (s *transactionServiceSuite) TestCreateBatchAccountCheckFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockTrx.EXPECT().ListExisting(gomock.Any(), gomock.Any()).Return(nil, nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, mockDBError)
+
+	_, err := s.service.CreateBatch(context.Background(), []model.TransactionRequest{batchRequest("key", mockAccountUUID, 4)},
+		nil, model.BatchModeBestEffort)
+	s.ErrorIs(err, mockDBError)
+}
+
+// the transactions are not stored without their events
+func (s *transactionServiceSuite) TestCreateBatchOutboxFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockTrx.EXPECT().ListExisting(gomock.Any(), gomock.Any()).Return(nil, nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockAccountUUID).Return(model.Account{}, nil)
+	s.mockOperationTypes.EXPECT().Get(gomock.Any(), 4).Return(model.OperationType{OperationTypeID: 4, IsCredit: true}, nil)
+	s.mockTrx.EXPECT().CreateBatch(gomock.Any(), gomock.Len(2)).Return(nil)
+	s.mockLedger.EXPECT().Post(gomock.Any(), gomock.Len(4)).Return(nil)
+	s.mockOutbox.EXPECT().AddBatch(gomock.Any(), gomock.Len(2)).Return(mockDBError)
+
+	_, err := s.service.CreateBatch(context.Background(), []model.TransactionRequest{
+		batchRequest("first", mockAccountUUID, 4),
+		batchRequest("second", mockAccountUUID, 4),
+	}, nil, model.BatchModeBestEffort)
+	s.ErrorIs(err, mockDBError)
+}
+
+func TestResolveAmount(t *testing.T) {
+	if got := resolveAmount(10, true); got != 10 {
+		t.Errorf("credit: expected 10, got %v", got)
+	}
+	if got := resolveAmount(10, false); got != -10 {
+		t.Errorf("debit: expected -10, got %v", got)
+	}
+}
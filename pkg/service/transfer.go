@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/transfer_mock.go -source=transfer.go
+type TransferService interface {
+	// Create moves the amount of req between two existing accounts, creating the debit of the
+	// from account and the credit of the to account atomically. Neither account may be blocked,
+	// and the from account may not owe more than its credit limit afterwards. The uuid of the
+	// transfer is derived from the idempotency key of req, and the uuids of its transactions from it
+	Create(ctx context.Context, req model.TransferRequest) (model.Transfer, error)
+}
+
+type transferService struct {
+	transferRepo repository.TransferConnector
+	accountRepo  repository.AccountConnector
+	ledgerRepo   repository.LedgerConnector
+	trxService   TransactionService
+	uow          repository.UnitOfWork
+}
+
+// NewTransferService creates the transactions of transfers through the transaction service, in
+// the unit of work of the transfer
+func NewTransferService(
+	t repository.TransferConnector,
+	a repository.AccountConnector,
+	l repository.LedgerConnector,
+	trxService TransactionService,
+	uow repository.UnitOfWork,
+) TransferService {
+	return &transferService{
+		transferRepo: t,
+		accountRepo:  a,
+		ledgerRepo:   l,
+		trxService:   trxService,
+		uow:          uow,
+	}
+}
+
+func (s *transferService) Create(ctx context.Context, req model.TransferRequest) (model.Transfer, error) {
+	if vErr := req.Validate(); len(vErr) > 0 {
+		return model.Transfer{}, &ValidationError{Fields: vErr}
+	}
+
+	// the uuids were validated, in canonical form they order and compare as the stored ones
+	req.FromAccountUUID = uuid.FromStringOrNil(req.FromAccountUUID).String()
+	req.ToAccountUUID = uuid.FromStringOrNil(req.ToAccountUUID).String()
+
+	transferUUID := uuid.NewV5(uuid.Nil, "transfer:"+req.IdempotencyKey)
+
+	var transfer model.Transfer
+	// the transfer and both of its transactions are created in one serializable transaction,
+	// which the transaction service joins
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		var err error
+		transfer, err = s.create(ctx, transferUUID, req)
+
+		return err
+	})
+	if err != nil {
+		return model.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// create locks and checks both accounts, and creates the transactions and the transfer linking them
+func (s *transferService) create(ctx context.Context, transferUUID uuid.UUID, req model.TransferRequest) (model.Transfer, error) {
+	if err := s.transferRepo.CheckIdempotency(ctx, transferUUID.String()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Transfer{}, ErrDuplicateRequest
+		}
+
+		return model.Transfer{}, err
+	}
+
+	accounts, err := s.lockAccounts(ctx, req)
+	if err != nil {
+		return model.Transfer{}, err
+	}
+
+	if err := s.checkAccounts(ctx, req, accounts); err != nil {
+		return model.Transfer{}, err
+	}
+
+	debit, err := s.trxService.Create(ctx, model.TransactionRequest{
+		AccountUUID:     req.FromAccountUUID,
+		OperationTypeID: model.OperationTypeTransferOut,
+		Amount:          req.Amount,
+		IdempotencyKey:  transferUUID.String() + ":debit",
+	})
+	if err != nil {
+		return model.Transfer{}, err
+	}
+
+	credit, err := s.trxService.Create(ctx, model.TransactionRequest{
+		AccountUUID:     req.ToAccountUUID,
+		OperationTypeID: model.OperationTypeTransferIn,
+		Amount:          req.Amount,
+		IdempotencyKey:  transferUUID.String() + ":credit",
+	})
+	if err != nil {
+		return model.Transfer{}, err
+	}
+
+	transfer := model.Transfer{
+		UUID:                  transferUUID,
+		FromAccountUUID:       debit.AccountUUID,
+		ToAccountUUID:         credit.AccountUUID,
+		Amount:                req.Amount,
+		DebitTransactionUUID:  debit.UUID,
+		CreditTransactionUUID: credit.UUID,
+		CreatedAt:             time.Now(),
+	}
+
+	if err := s.transferRepo.Create(ctx, transfer); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Transfer{}, ErrDuplicateRequest
+		}
+
+		return model.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// lockAccounts locks both accounts of the transfer until the end of the unit of work, ordered by
+// uuid so that transfers between the same accounts in opposite directions cannot deadlock. The
+// locked accounts are returned by uuid
+func (s *transferService) lockAccounts(ctx context.Context, req model.TransferRequest) (map[string]model.Account, error) {
+	fields := map[string]string{
+		req.FromAccountUUID: "from_account_uuid",
+		req.ToAccountUUID:   "to_account_uuid",
+	}
+
+	accountUUIDs := []string{req.FromAccountUUID, req.ToAccountUUID}
+	slices.Sort(accountUUIDs)
+
+	accounts := make(map[string]model.Account, len(accountUUIDs))
+	for _, accountUUID := range accountUUIDs {
+		account, err := s.accountRepo.GetForUpdate(ctx, accountUUID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNoRows) {
+				return nil, fmt.Errorf("%w for %s: '%s'", ErrAccountNotFound, fields[accountUUID], accountUUID)
+			}
+
+			return nil, err
+		}
+		accounts[accountUUID] = account
+	}
+
+	return accounts, nil
+}
+
+// checkAccounts rejects transfers from or to a blocked account, and transfers after which the
+// from account would owe more than its credit limit. The accounts are locked, so the receivable
+// balance of the from account cannot change until the transfer is stored
+func (s *transferService) checkAccounts(ctx context.Context, req model.TransferRequest, accounts map[string]model.Account) error {
+	for _, accountUUID := range []string{req.FromAccountUUID, req.ToAccountUUID} {
+		if accounts[accountUUID].Status == model.AccountStatusBlocked {
+			return fmt.Errorf("%w: '%s'", ErrAccountBlocked, accountUUID)
+		}
+	}
+
+	from := accounts[req.FromAccountUUID]
+	if from.CreditLimit == 0 {
+		return nil
+	}
+
+	balance, err := s.ledgerRepo.Balance(ctx, req.FromAccountUUID, model.LedgerAccountReceivable, endOfTime)
+	if err != nil {
+		return err
+	}
+
+	if owed := roundCents(balance + req.Amount); owed > from.CreditLimit {
+		return fmt.Errorf("%w: '%s' would owe %.2f, its limit is %.2f",
+			ErrCreditLimitExceeded, req.FromAccountUUID, owed, from.CreditLimit)
+	}
+
+	return nil
+}
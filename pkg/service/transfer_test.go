@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/memory"
+	"go-pismo-challenge/pkg/repository/mocks"
+	serviceMocks "go-pismo-challenge/pkg/service/mocks"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+// the accounts of the transfers, the to account orders first
+const (
+	mockFromAccountUUID = "f1a7d6a4-0b52-4cf5-9a3e-8a1c51b0a6d2"
+	mockToAccountUUID   = "0c9b1e2f-7d3a-4a8e-b5c6-2f4e6a8b0d1c"
+)
+
+type transferServiceSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	service        TransferService
+	mockTransfers  *mocks.MockTransferConnector
+	mockAccounts   *mocks.MockAccountConnector
+	mockLedger     *mocks.MockLedgerConnector
+	mockTrxService *serviceMocks.MockTransactionService
+	mockUoW        *mocks.MockUnitOfWork
+}
+
+func TestTransferService(t *testing.T) {
+	suite.Run(t, new(transferServiceSuite))
+}
+
+func (s *transferServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockTransfers = mocks.NewMockTransferConnector(s.ctrl)
+	s.mockAccounts = mocks.NewMockAccountConnector(s.ctrl)
+	s.mockLedger = mocks.NewMockLedgerConnector(s.ctrl)
+	s.mockTrxService = serviceMocks.NewMockTransactionService(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	s.service = NewTransferService(s.mockTransfers, s.mockAccounts, s.mockLedger, s.mockTrxService, s.mockUoW)
+}
+
+func (s *transferServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func validTransferRequest() model.TransferRequest {
+	return model.TransferRequest{
+		FromAccountUUID: mockFromAccountUUID,
+		ToAccountUUID:   mockToAccountUUID,
+		Amount:          25.5,
+		IdempotencyKey:  "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}
+}
+
+func transferUUID() uuid.UUID {
+	return uuid.NewV5(uuid.Nil, "transfer:bc1f3956-e92e-4666-a5cd-4cbbd937b17f")
+}
+
+// expectLocks expects both accounts to be locked ordered by uuid, they are active and have no
+// credit limit
+func (s *transferServiceSuite) expectLocks() {
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusActive},
+		model.Account{Status: model.AccountStatusActive},
+	)
+}
+
+// expectLockedAccounts expects both accounts to be locked ordered by uuid, returning from and to
+func (s *transferServiceSuite) expectLockedAccounts(from, to model.Account) {
+	s.mockTransfers.EXPECT().CheckIdempotency(gomock.Any(), transferUUID().String()).Return(nil)
+	gomock.InOrder(
+		s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockToAccountUUID).Return(to, nil),
+		s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockFromAccountUUID).Return(from, nil),
+	)
+}
+
+// createTransaction returns the transaction the transaction service creates for req
+func createTransaction(_ context.Context, req model.TransactionRequest) (model.Transaction, error) {
+	return model.Transaction{
+		UUID:            uuid.NewV5(uuid.Nil, req.IdempotencyKey),
+		AccountUUID:     uuid.FromStringOrNil(req.AccountUUID),
+		OperationTypeID: req.OperationTypeID,
+		Amount:          req.Amount,
+	}, nil
+}
+
+func (s *transferServiceSuite) TestCreateSuccess() {
+	s.expectLocks()
+	debitKey, creditKey := transferUUID().String()+":debit", transferUUID().String()+":credit"
+	gomock.InOrder(
+		s.mockTrxService.EXPECT().Create(gomock.Any(), model.TransactionRequest{
+			AccountUUID:     mockFromAccountUUID,
+			OperationTypeID: model.OperationTypeTransferOut,
+			Amount:          25.5,
+			IdempotencyKey:  debitKey,
+		}).DoAndReturn(createTransaction),
+		s.mockTrxService.EXPECT().Create(gomock.Any(), model.TransactionRequest{
+			AccountUUID:     mockToAccountUUID,
+			OperationTypeID: model.OperationTypeTransferIn,
+			Amount:          25.5,
+			IdempotencyKey:  creditKey,
+		}).DoAndReturn(createTransaction),
+	)
+	s.mockTransfers.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	transfer, err := s.service.Create(context.Background(), validTransferRequest())
+	s.Require().NoError(err)
+	s.Equal(transferUUID(), transfer.UUID)
+	s.Equal(mockFromAccountUUID, transfer.FromAccountUUID.String())
+	s.Equal(mockToAccountUUID, transfer.ToAccountUUID.String())
+	s.InDelta(25.5, transfer.Amount, 0)
+	s.Equal(uuid.NewV5(uuid.Nil, debitKey), transfer.DebitTransactionUUID)
+	s.Equal(uuid.NewV5(uuid.Nil, creditKey), transfer.CreditTransactionUUID)
+}
+
+// uuids are locked and created in canonical form
+func (s *transferServiceSuite) TestCreateUpperCaseUUIDs() {
+	s.expectLocks()
+	s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(createTransaction).Times(2)
+	s.mockTransfers.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	req := validTransferRequest()
+	req.FromAccountUUID = strings.ToUpper(req.FromAccountUUID)
+	_, err := s.service.Create(context.Background(), req)
+	s.Require().NoError(err)
+}
+
+func (s *transferServiceSuite) TestCreateValidationFailed() {
+	req := validTransferRequest()
+	req.ToAccountUUID = strings.ToUpper(req.FromAccountUUID)
+	req.Amount = 0
+
+	_, err := s.service.Create(context.Background(), req)
+
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Require().Len(vErr.Fields, 2)
+	s.Equal("amount", vErr.Fields[0].Field)
+	s.Equal("to_account_uuid", vErr.Fields[1].Field)
+}
+
+func (s *transferServiceSuite) TestCreateDuplicate() {
+	s.mockTransfers.EXPECT().CheckIdempotency(gomock.Any(), transferUUID().String()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+func (s *transferServiceSuite) TestCreateAccountNotFound() {
+	s.mockTransfers.EXPECT().CheckIdempotency(gomock.Any(), transferUUID().String()).Return(nil)
+	s.mockAccounts.EXPECT().GetForUpdate(gomock.Any(), mockToAccountUUID).Return(model.Account{}, repository.ErrNoRows)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrAccountNotFound)
+	s.Equal("account not found for to_account_uuid: '"+mockToAccountUUID+"'", err.Error())
+}
+
+// the transfer fails as a whole when one of its transactions fails
+func (s *transferServiceSuite) TestCreateTransactionFailed() {
+	mockError := errors.New("some-db-error")
+	s.expectLocks()
+	gomock.InOrder(
+		s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(createTransaction),
+		s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Transaction{}, mockError),
+	)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, mockError)
+}
+
+// a transfer created concurrently with the same idempotency key
+func (s *transferServiceSuite) TestCreateTransferDuplicate() {
+	s.expectLocks()
+	s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(createTransaction).Times(2)
+	s.mockTransfers.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+func (s *transferServiceSuite) TestCreateFromAccountBlocked() {
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusBlocked},
+		model.Account{Status: model.AccountStatusActive},
+	)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrAccountBlocked)
+	s.Equal("account blocked: '"+mockFromAccountUUID+"'", err.Error())
+}
+
+func (s *transferServiceSuite) TestCreateToAccountBlocked() {
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusActive},
+		model.Account{Status: model.AccountStatusBlocked},
+	)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrAccountBlocked)
+	s.Equal("account blocked: '"+mockToAccountUUID+"'", err.Error())
+}
+
+// the from account owes 80 of its limit of 100, the transfer of 25.5 would take it to 105.5
+func (s *transferServiceSuite) TestCreateCreditLimitExceeded() {
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusActive, CreditLimit: 100},
+		model.Account{Status: model.AccountStatusActive},
+	)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), mockFromAccountUUID, model.LedgerAccountReceivable, gomock.Any()).
+		Return(80.0, nil)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, ErrCreditLimitExceeded)
+	s.Equal("credit limit exceeded: '"+mockFromAccountUUID+"' would owe 105.50, its limit is 100.00", err.Error())
+}
+
+// the transfer takes the from account exactly to its limit
+func (s *transferServiceSuite) TestCreateUpToCreditLimit() {
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusActive, CreditLimit: 100},
+		model.Account{Status: model.AccountStatusActive},
+	)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), mockFromAccountUUID, model.LedgerAccountReceivable, gomock.Any()).
+		Return(74.5, nil)
+	s.mockTrxService.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(createTransaction).Times(2)
+	s.mockTransfers.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.NoError(err)
+}
+
+func (s *transferServiceSuite) TestCreateBalanceFailed() {
+	mockError := errors.New("some-db-error")
+	s.expectLockedAccounts(
+		model.Account{Status: model.AccountStatusActive, CreditLimit: 100},
+		model.Account{Status: model.AccountStatusActive},
+	)
+	s.mockLedger.EXPECT().Balance(gomock.Any(), mockFromAccountUUID, model.LedgerAccountReceivable, gomock.Any()).
+		Return(0.0, mockError)
+
+	_, err := s.service.Create(context.Background(), validTransferRequest())
+	s.ErrorIs(err, mockError)
+}
+
+// slowLedger pauses after reading a balance, widening the window in which concurrent transfers
+// could act on the same balance
+type slowLedger struct {
+	repository.LedgerConnector
+}
+
+func (l slowLedger) Balance(ctx context.Context, accountUUID string, ledgerAccountID int, before time.Time) (float64, error) {
+	balance, err := l.LedgerConnector.Balance(ctx, accountUUID, ledgerAccountID, before)
+	time.Sleep(time.Millisecond)
+
+	return balance, err
+}
+
+// Concurrent transfers out of a limited account are serialised by the unit of work, none of them
+// may pass the limit check with a balance another one is about to change
+func TestTransferConcurrentCreditLimit(t *testing.T) {
+	ctx := context.Background()
+	accounts := memory.NewAccountRepo()
+	ledger := slowLedger{memory.NewLedgerRepo()}
+	uow := memory.NewUnitOfWork()
+	trxService := NewTransactionService(memory.NewTransactionRepo(), accounts, memory.NewOperationTypeRepo(), ledger,
+		memory.NewOutboxRepo(), uow)
+	service := NewTransferService(memory.NewTransferRepo(), accounts, ledger, trxService, uow)
+
+	from := model.Account{UUID: uuid.NewV5(uuid.Nil, "from"), Status: model.AccountStatusActive, CreditLimit: 100}
+	to := model.Account{UUID: uuid.NewV5(uuid.Nil, "to"), Status: model.AccountStatusActive}
+	require.NoError(t, accounts.Create(ctx, from))
+	require.NoError(t, accounts.Create(ctx, to))
+
+	const transfers = 50
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int64
+	)
+	// the transfers start together, so that they race for the account
+	start := make(chan struct{})
+	for i := range transfers {
+		wg.Go(func() {
+			<-start
+			_, err := service.Create(ctx, model.TransferRequest{
+				IdempotencyKey:  "transfer-" + strconv.Itoa(i),
+				FromAccountUUID: from.UUID.String(),
+				ToAccountUUID:   to.UUID.String(),
+				Amount:          7.5,
+			})
+			if err == nil {
+				created.Add(1)
+
+				return
+			}
+			assert.ErrorIs(t, err, ErrCreditLimitExceeded)
+		})
+	}
+	close(start)
+	wg.Wait()
+
+	// 13 transfers of 7.50 owe 97.50, a 14th would pass the limit
+	assert.Equal(t, int64(13), created.Load())
+	owed, err := ledger.Balance(ctx, from.UUID.String(), model.LedgerAccountReceivable, endOfTime)
+	require.NoError(t, err)
+	assert.InDelta(t, 97.5, owed, 0.001)
+}
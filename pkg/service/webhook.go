@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/publisher"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/util"
+	"go-pismo-challenge/pkg/validate"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// deliveryLogSize is the number of latest delivery attempts returned for a webhook
+const deliveryLogSize = 100
+
+//go:generate go run -mod=mod go.uber.org/mock/mockgen -package mocks -destination=./mocks/webhook_mock.go -source=webhook.go
+type WebhookService interface {
+	// Create registers the endpoint of req for events of its types, returning the webhook with the
+	// secret its deliveries are signed with. The uuid of the webhook is derived from the
+	// idempotency key of req
+	Create(ctx context.Context, req model.WebhookRequest) (model.Webhook, error)
+	// Get returns the webhook without its secret
+	Get(ctx context.Context, webhookUUID string) (model.Webhook, error)
+	// Enable re-enables the webhook, disabled after failing too many deliveries in a row, and
+	// returns it without its secret. The deliveries queued before it was disabled are attempted
+	// again, the events published while it was disabled are not delivered
+	Enable(ctx context.Context, webhookUUID string) (model.Webhook, error)
+	// Deliveries returns the latest attempts to deliver events to the webhook, newest first
+	Deliveries(ctx context.Context, webhookUUID string) ([]model.WebhookDeliveryAttempt, error)
+	// Publish queues the delivery of the event to the enabled webhooks subscribed to its type, so
+	// that the outbox relays events to the webhooks
+	Publish(ctx context.Context, e model.Event) error
+	// Deliver attempts the deliveries due until none is, and returns how many succeeded. A failed
+	// delivery is retried with exponential backoff, and a webhook failing MaxConsecutiveFailures
+	// deliveries in a row is disabled
+	Deliver(ctx context.Context) (int, error)
+}
+
+// WebhookOptions configure the deliveries: BatchSize deliveries are read at a time, a failed
+// delivery is retried after InitialBackoff, doubled on every attempt up to MaxBackoff, and a
+// webhook is disabled after MaxConsecutiveFailures failed deliveries in a row
+type WebhookOptions struct {
+	BatchSize              int
+	InitialBackoff         time.Duration
+	MaxBackoff             time.Duration
+	MaxConsecutiveFailures int
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookConnector
+	sender      publisher.WebhookSender
+	uow         repository.UnitOfWork
+	clock       clock.Clock
+	opts        WebhookOptions
+}
+
+func NewWebhookService(
+	w repository.WebhookConnector,
+	sender publisher.WebhookSender,
+	uow repository.UnitOfWork,
+	c clock.Clock,
+	opts WebhookOptions,
+) WebhookService {
+	return &webhookService{
+		webhookRepo: w,
+		sender:      sender,
+		uow:         uow,
+		clock:       c,
+		opts:        opts,
+	}
+}
+
+func (s *webhookService) Create(ctx context.Context, req model.WebhookRequest) (model.Webhook, error) {
+	if vErr := req.Validate(); len(vErr) > 0 {
+		return model.Webhook{}, &ValidationError{Fields: vErr}
+	}
+
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhookUUID := uuid.NewV5(uuid.Nil, "webhook:"+req.IdempotencyKey)
+
+	if err := s.webhookRepo.CheckIdempotency(ctx, webhookUUID.String()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Webhook{}, ErrDuplicateRequest
+		}
+
+		return model.Webhook{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook := model.Webhook{
+		UUID:       webhookUUID,
+		URL:        req.URL,
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(req.EventTypes))),
+		Secret:     secret,
+		Enabled:    true,
+		CreatedAt:  s.clock.Now(),
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Webhook{}, ErrDuplicateRequest
+		}
+
+		return model.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) Get(ctx context.Context, webhookUUID string) (model.Webhook, error) {
+	webhook, err := s.webhookRepo.Get(ctx, webhookUUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoRows) {
+			return model.Webhook{}, fmt.Errorf("%w for uuid: '%s'", ErrWebhookNotFound, webhookUUID)
+		}
+
+		return model.Webhook{}, err
+	}
+
+	// the secret is only returned when the webhook is created
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+func (s *webhookService) Enable(ctx context.Context, webhookUUID string) (model.Webhook, error) {
+	webhook, err := s.Get(ctx, webhookUUID)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	// the url may resolve to another address since the webhook was created
+	if err := s.checkURL(ctx, webhook.URL); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhook.Enabled = true
+	webhook.ConsecutiveFailures = 0
+	webhook.DisabledAt = nil
+
+	if err := s.webhookRepo.UpdateStatus(ctx, webhook); err != nil {
+		return model.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) Deliveries(ctx context.Context, webhookUUID string) ([]model.WebhookDeliveryAttempt, error) {
+	if _, err := s.Get(ctx, webhookUUID); err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.webhookRepo.ListAttempts(ctx, webhookUUID, deliveryLogSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// a webhook without deliveries lists none rather than null
+	if attempts == nil {
+		attempts = []model.WebhookDeliveryAttempt{}
+	}
+
+	return attempts, nil
+}
+
+// Publish posts the event as it is published by the other publishers of the outbox
+func (s *webhookService) Publish(ctx context.Context, e model.Event) error {
+	webhooks, err := s.webhookRepo.ListEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	// deliveries queued before the outbox retries the event are left untouched
+	for _, w := range webhooks {
+		if !w.Subscribes(e.Type) {
+			continue
+		}
+
+		err := s.webhookRepo.Enqueue(ctx, model.WebhookDelivery{
+			WebhookUUID:   w.UUID,
+			EventUUID:     e.UUID,
+			EventType:     e.Type,
+			Payload:       payload,
+			NextAttemptAt: s.clock.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *webhookService) Deliver(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		deliveries, err := s.webhookRepo.DueDeliveries(ctx, s.clock.Now(), s.opts.BatchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		n, err := s.deliver(ctx, deliveries)
+		delivered += n
+		if err != nil {
+			return delivered, err
+		}
+
+		// failed deliveries are not due before their backoff, so the next batch moves on
+		if len(deliveries) == 0 || len(deliveries) < s.opts.BatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// deliver attempts the deliveries in order, skipping the deliveries to a webhook once it is
+// disabled
+func (s *webhookService) deliver(ctx context.Context, deliveries []model.WebhookDelivery) (int, error) {
+	delivered := 0
+	webhooks := map[uuid.UUID]*model.Webhook{}
+	for _, d := range deliveries {
+		w, ok := webhooks[d.WebhookUUID]
+		if !ok {
+			webhook, err := s.webhookRepo.Get(ctx, d.WebhookUUID.String())
+			if err != nil {
+				return delivered, err
+			}
+			w = &webhook
+			webhooks[d.WebhookUUID] = w
+		}
+		if !w.Enabled {
+			continue
+		}
+
+		succeeded, err := s.attempt(ctx, w, d)
+		if err != nil {
+			return delivered, err
+		}
+		if succeeded {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// attempt sends the delivery to the webhook and records the attempt, counting the consecutive
+// failures of the webhook
+func (s *webhookService) attempt(ctx context.Context, w *model.Webhook, d model.WebhookDelivery) (bool, error) {
+	attemptedAt := s.clock.Now()
+	status, sendErr := s.sender.Send(ctx, w.URL, w.Secret, d)
+	if sendErr != nil && ctx.Err() != nil {
+		return false, sendErr
+	}
+
+	d.Attempts++
+	attempt := model.WebhookDeliveryAttempt{
+		EventUUID:   d.EventUUID,
+		EventType:   d.EventType,
+		Attempt:     d.Attempts,
+		StatusCode:  status,
+		Succeeded:   sendErr == nil,
+		DurationMS:  s.clock.Now().Sub(attemptedAt).Milliseconds(),
+		AttemptedAt: attemptedAt,
+	}
+
+	failures := w.ConsecutiveFailures
+	if sendErr == nil {
+		w.ConsecutiveFailures = 0
+	} else {
+		attempt.Error = sendErr.Error()
+		d.NextAttemptAt = attemptedAt.Add(backoff(d.Attempts-1, s.opts.InitialBackoff, s.opts.MaxBackoff))
+		w.ConsecutiveFailures++
+		log.Warn().Ctx(ctx).Err(sendErr).Str("webhook_uuid", w.UUID.String()).Str("event_uuid", d.EventUUID.String()).
+			Int("attempt", d.Attempts).Time("next_attempt_at", d.NextAttemptAt).Msg("failed to deliver webhook")
+
+		if w.ConsecutiveFailures >= s.opts.MaxConsecutiveFailures {
+			w.Enabled = false
+			w.DisabledAt = &attemptedAt
+			log.Warn().Ctx(ctx).Str("webhook_uuid", w.UUID.String()).Int("consecutive_failures", w.ConsecutiveFailures).
+				Msg("disabled webhook after consecutive failed deliveries")
+		}
+	}
+
+	err := s.uow.Do(ctx, sql.LevelSerializable, func(ctx context.Context) error {
+		if err := s.webhookRepo.RecordAttempt(ctx, d, attempt); err != nil {
+			return err
+		}
+
+		if w.ConsecutiveFailures == failures {
+			return nil
+		}
+
+		return s.webhookRepo.UpdateStatus(ctx, *w)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return attempt.Succeeded, nil
+}
+
+// checkURL checks the url with the sender that posts to it, deliveries are refused the same way
+func (s *webhookService) checkURL(ctx context.Context, rawURL string) error {
+	err := s.sender.CheckURL(ctx, rawURL)
+	if errors.Is(err, publisher.ErrForbiddenURL) {
+		return &ValidationError{Fields: []util.FieldError{{
+			Field:   "url",
+			Code:    validate.CodeNotAllowed,
+			Message: err.Error(),
+		}}}
+	}
+
+	return err
+}
+
+// newSecret returns a random secret to sign the deliveries of a webhook with
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pismo-challenge/pkg/clock"
+	"go-pismo-challenge/pkg/model"
+	"go-pismo-challenge/pkg/publisher"
+	publisherMocks "go-pismo-challenge/pkg/publisher/mocks"
+	"go-pismo-challenge/pkg/repository"
+	"go-pismo-challenge/pkg/repository/mocks"
+	"go-pismo-challenge/pkg/util"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type webhookServiceSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	service      WebhookService
+	clock        *clock.Fake
+	mockWebhooks *mocks.MockWebhookConnector
+	mockSender   *publisherMocks.MockWebhookSender
+	mockUoW      *mocks.MockUnitOfWork
+}
+
+func TestWebhookService(t *testing.T) {
+	suite.Run(t, new(webhookServiceSuite))
+}
+
+func (s *webhookServiceSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.clock = clock.NewFake(time.Date(2025, time.October, 1, 7, 0, 0, 0, time.UTC))
+	s.mockWebhooks = mocks.NewMockWebhookConnector(s.ctrl)
+	s.mockSender = publisherMocks.NewMockWebhookSender(s.ctrl)
+	s.mockUoW = mocks.NewMockUnitOfWork(s.ctrl)
+
+	// run the unit of work inline, it is tested in the repository package
+	s.mockUoW.EXPECT().Do(gomock.Any(), sql.LevelSerializable, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ sql.IsolationLevel, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	s.service = NewWebhookService(s.mockWebhooks, s.mockSender, s.mockUoW, s.clock, WebhookOptions{
+		BatchSize:              3,
+		InitialBackoff:         time.Second,
+		MaxBackoff:             time.Minute,
+		MaxConsecutiveFailures: 3,
+	})
+}
+
+func (s *webhookServiceSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func validWebhookRequest() model.WebhookRequest {
+	return model.WebhookRequest{
+		URL:            "https://example.com/webhooks",
+		EventTypes:     []string{model.EventTransactionCreated, model.EventAccountCreated, model.EventTransactionCreated},
+		IdempotencyKey: "bc1f3956-e92e-4666-a5cd-4cbbd937b17f",
+	}
+}
+
+func webhookUUID() uuid.UUID {
+	return uuid.NewV5(uuid.Nil, "webhook:bc1f3956-e92e-4666-a5cd-4cbbd937b17f")
+}
+
+func mockWebhook() model.Webhook {
+	return model.Webhook{
+		UUID:       webhookUUID(),
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{model.EventAccountCreated},
+		Secret:     "whsec_secret",
+		Enabled:    true,
+	}
+}
+
+// webhookDelivery returns a delivery due to the webhook
+func webhookDelivery(id int64, webhook uuid.UUID) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:          id,
+		WebhookUUID: webhook,
+		EventUUID:   uuid.NewV5(webhook, strconv.FormatInt(id, 10)),
+		EventType:   model.EventAccountCreated,
+		Payload:     []byte(`{}`),
+	}
+}
+
+func (s *webhookServiceSuite) TestCreateSuccess() {
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), "https://example.com/webhooks").Return(nil)
+	s.mockWebhooks.EXPECT().CheckIdempotency(gomock.Any(), webhookUUID().String()).Return(nil)
+	var stored model.Webhook
+	s.mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, w model.Webhook) error {
+			stored = w
+
+			return nil
+		})
+
+	webhook, err := s.service.Create(context.Background(), validWebhookRequest())
+	s.Require().NoError(err)
+	s.Equal(stored, webhook)
+	s.Equal(webhookUUID(), webhook.UUID)
+	s.Equal("https://example.com/webhooks", webhook.URL)
+	// the event types are stored once each
+	s.Equal([]string{model.EventAccountCreated, model.EventTransactionCreated}, webhook.EventTypes)
+	s.Regexp(`^whsec_[0-9a-f]{64}$`, webhook.Secret)
+	s.True(webhook.Enabled)
+	s.Equal(s.clock.Now(), webhook.CreatedAt)
+}
+
+func (s *webhookServiceSuite) TestCreateSecretsDiffer() {
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockWebhooks.EXPECT().CheckIdempotency(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	s.mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	first, err := s.service.Create(context.Background(), validWebhookRequest())
+	s.Require().NoError(err)
+	second, err := s.service.Create(context.Background(), validWebhookRequest())
+	s.Require().NoError(err)
+	s.NotEqual(first.Secret, second.Secret)
+}
+
+func (s *webhookServiceSuite) TestCreateValidationFailed() {
+	req := validWebhookRequest()
+	req.EventTypes = []string{"account.deleted"}
+
+	_, err := s.service.Create(context.Background(), req)
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Equal("event_types[0]", vErr.Fields[0].Field)
+}
+
+// urls deliveries would be refused to are not registered, e.g. those of internal addresses
+func (s *webhookServiceSuite) TestCreateForbiddenURL() {
+	req := validWebhookRequest()
+	req.URL = "https://169.254.169.254/latest/meta-data"
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), req.URL).
+		Return(fmt.Errorf("%w: address 169.254.169.254 is not public", publisher.ErrForbiddenURL))
+
+	_, err := s.service.Create(context.Background(), req)
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Equal([]util.FieldError{{
+		Field:   "url",
+		Code:    "not_allowed",
+		Message: "webhook url is not allowed: address 169.254.169.254 is not public",
+	}}, vErr.Fields)
+}
+
+func (s *webhookServiceSuite) TestCreateDuplicate() {
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockWebhooks.EXPECT().CheckIdempotency(gomock.Any(), webhookUUID().String()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validWebhookRequest())
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+// a concurrent request with the same idempotency key stores the webhook first
+func (s *webhookServiceSuite) TestCreateRace() {
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockWebhooks.EXPECT().CheckIdempotency(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrDuplicate)
+
+	_, err := s.service.Create(context.Background(), validWebhookRequest())
+	s.ErrorIs(err, ErrDuplicateRequest)
+}
+
+func (s *webhookServiceSuite) TestGetSuccess() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhookUUID().String()).Return(mockWebhook(), nil)
+
+	webhook, err := s.service.Get(context.Background(), webhookUUID().String())
+	s.Require().NoError(err)
+	s.Empty(webhook.Secret)
+	s.Equal(webhookUUID(), webhook.UUID)
+}
+
+func (s *webhookServiceSuite) TestGetNotFound() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), "some-uuid").Return(model.Webhook{}, repository.ErrNoRows)
+
+	_, err := s.service.Get(context.Background(), "some-uuid")
+	s.ErrorIs(err, ErrWebhookNotFound)
+}
+
+// a webhook disabled after failed deliveries is enabled with its failures reset
+func (s *webhookServiceSuite) TestEnableSuccess() {
+	disabled := mockWebhook()
+	disabledAt := s.clock.Now().Add(-time.Hour)
+	disabled.Enabled = false
+	disabled.ConsecutiveFailures = 3
+	disabled.DisabledAt = &disabledAt
+
+	enabled := mockWebhook()
+	enabled.Secret = ""
+
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhookUUID().String()).Return(disabled, nil)
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), disabled.URL).Return(nil)
+	s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), enabled).Return(nil)
+
+	webhook, err := s.service.Enable(context.Background(), webhookUUID().String())
+	s.Require().NoError(err)
+	s.Equal(enabled, webhook)
+}
+
+func (s *webhookServiceSuite) TestEnableNotFound() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), "some-uuid").Return(model.Webhook{}, repository.ErrNoRows)
+
+	_, err := s.service.Enable(context.Background(), "some-uuid")
+	s.ErrorIs(err, ErrWebhookNotFound)
+}
+
+// a url resolving to an internal address since the webhook was created stays disabled
+func (s *webhookServiceSuite) TestEnableForbiddenURL() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhookUUID().String()).Return(mockWebhook(), nil)
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), mockWebhook().URL).
+		Return(fmt.Errorf("%w: address 10.1.2.3 is not public", publisher.ErrForbiddenURL))
+
+	_, err := s.service.Enable(context.Background(), webhookUUID().String())
+	var vErr *ValidationError
+	s.Require().ErrorAs(err, &vErr)
+	s.Equal("url", vErr.Fields[0].Field)
+	s.Equal("not_allowed", vErr.Fields[0].Code)
+}
+
+func (s *webhookServiceSuite) TestEnableUpdateFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), gomock.Any()).Return(mockWebhook(), nil)
+	s.mockSender.EXPECT().CheckURL(gomock.Any(), gomock.Any()).Return(nil)
+	s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	_, err := s.service.Enable(context.Background(), webhookUUID().String())
+	s.ErrorIs(err, mockDBError)
+}
+
+func (s *webhookServiceSuite) TestDeliveriesSuccess() {
+	attempts := []model.WebhookDeliveryAttempt{{EventUUID: uuid.NewV5(uuid.Nil, "event"), Attempt: 1, Succeeded: true}}
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhookUUID().String()).Return(mockWebhook(), nil)
+	s.mockWebhooks.EXPECT().ListAttempts(gomock.Any(), webhookUUID().String(), 100).Return(attempts, nil)
+
+	got, err := s.service.Deliveries(context.Background(), webhookUUID().String())
+	s.Require().NoError(err)
+	s.Equal(attempts, got)
+}
+
+func (s *webhookServiceSuite) TestDeliveriesEmpty() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhookUUID().String()).Return(mockWebhook(), nil)
+	s.mockWebhooks.EXPECT().ListAttempts(gomock.Any(), webhookUUID().String(), 100).Return(nil, nil)
+
+	got, err := s.service.Deliveries(context.Background(), webhookUUID().String())
+	s.Require().NoError(err)
+	s.NotNil(got)
+	s.Empty(got)
+}
+
+func (s *webhookServiceSuite) TestDeliveriesNotFound() {
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), "some-uuid").Return(model.Webhook{}, repository.ErrNoRows)
+
+	_, err := s.service.Deliveries(context.Background(), "some-uuid")
+	s.ErrorIs(err, ErrWebhookNotFound)
+}
+
+// deliveries are queued to the webhooks subscribed to the type of the event only
+func (s *webhookServiceSuite) TestPublishSuccess() {
+	subscribed, other := mockWebhook(), mockWebhook()
+	other.UUID, other.EventTypes = uuid.NewV5(uuid.Nil, "other"), []string{model.EventTransactionCreated}
+	event := model.Event{
+		UUID:        uuid.NewV5(uuid.Nil, "event"),
+		Type:        model.EventAccountCreated,
+		AccountUUID: uuid.NewV5(uuid.Nil, "account"),
+		Data:        []byte(`{"document_number":"12345678900"}`),
+		CreatedAt:   s.clock.Now(),
+	}
+	payload, err := json.Marshal(event)
+	s.Require().NoError(err)
+
+	s.mockWebhooks.EXPECT().ListEnabled(gomock.Any()).Return([]model.Webhook{other, subscribed}, nil)
+	s.mockWebhooks.EXPECT().Enqueue(gomock.Any(), model.WebhookDelivery{
+		WebhookUUID:   subscribed.UUID,
+		EventUUID:     event.UUID,
+		EventType:     model.EventAccountCreated,
+		Payload:       payload,
+		NextAttemptAt: s.clock.Now(),
+	}).Return(nil)
+
+	s.NoError(s.service.Publish(context.Background(), event))
+}
+
+func (s *webhookServiceSuite) TestPublishEnqueueFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockWebhooks.EXPECT().ListEnabled(gomock.Any()).Return([]model.Webhook{mockWebhook()}, nil)
+	s.mockWebhooks.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	s.ErrorIs(s.service.Publish(context.Background(), model.Event{Type: model.EventAccountCreated}), mockDBError)
+}
+
+func (s *webhookServiceSuite) TestDeliverSuccess() {
+	webhook := mockWebhook()
+	webhook.ConsecutiveFailures = 2
+	deliveries := []model.WebhookDelivery{webhookDelivery(1, webhook.UUID), webhookDelivery(2, webhook.UUID)}
+	now := s.clock.Now()
+
+	s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), now, 3).Return(deliveries, nil)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil)
+
+	sent := deliveries[0]
+	sent.Attempts = 1
+	reset := webhook
+	reset.ConsecutiveFailures = 0
+	gomock.InOrder(
+		s.mockSender.EXPECT().Send(gomock.Any(), webhook.URL, webhook.Secret, deliveries[0]).Return(200, nil),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), sent, model.WebhookDeliveryAttempt{
+			EventUUID:   deliveries[0].EventUUID,
+			EventType:   model.EventAccountCreated,
+			Attempt:     1,
+			StatusCode:  200,
+			Succeeded:   true,
+			AttemptedAt: now,
+		}).Return(nil),
+		// a delivery resets the consecutive failures of the webhook
+		s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), reset).Return(nil),
+		s.mockSender.EXPECT().Send(gomock.Any(), webhook.URL, webhook.Secret, deliveries[1]).Return(204, nil),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	delivered, err := s.service.Deliver(context.Background())
+	s.Require().NoError(err)
+	s.Equal(2, delivered)
+}
+
+// full batches are followed by the next one
+func (s *webhookServiceSuite) TestDeliverBatches() {
+	webhook := mockWebhook()
+	first := []model.WebhookDelivery{
+		webhookDelivery(1, webhook.UUID), webhookDelivery(2, webhook.UUID), webhookDelivery(3, webhook.UUID),
+	}
+
+	gomock.InOrder(
+		s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), gomock.Any(), 3).Return(first, nil),
+		s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), gomock.Any(), 3).
+			Return([]model.WebhookDelivery{webhookDelivery(4, webhook.UUID)}, nil),
+	)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil).Times(2)
+	s.mockSender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(200, nil).Times(4)
+	s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	delivered, err := s.service.Deliver(context.Background())
+	s.Require().NoError(err)
+	s.Equal(4, delivered)
+}
+
+// a failed delivery is retried later and counted against its webhook
+func (s *webhookServiceSuite) TestDeliverFailed() {
+	webhook := mockWebhook()
+	failing := webhookDelivery(1, webhook.UUID)
+	failing.Attempts = 2
+	now := s.clock.Now()
+
+	s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), now, 3).Return([]model.WebhookDelivery{failing}, nil)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil)
+
+	retried := failing
+	retried.Attempts, retried.NextAttemptAt = 3, now.Add(4*time.Second)
+	failed := webhook
+	failed.ConsecutiveFailures = 1
+	gomock.InOrder(
+		s.mockSender.EXPECT().Send(gomock.Any(), webhook.URL, webhook.Secret, failing).
+			Return(503, errors.New("webhook responded with status 503")),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), retried, model.WebhookDeliveryAttempt{
+			EventUUID:   failing.EventUUID,
+			EventType:   model.EventAccountCreated,
+			Attempt:     3,
+			StatusCode:  503,
+			Error:       "webhook responded with status 503",
+			AttemptedAt: now,
+		}).Return(nil),
+		s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), failed).Return(nil),
+	)
+
+	delivered, err := s.service.Deliver(context.Background())
+	s.Require().NoError(err)
+	s.Zero(delivered)
+}
+
+// a webhook failing MaxConsecutiveFailures deliveries in a row is disabled, its later deliveries
+// are not attempted
+func (s *webhookServiceSuite) TestDeliverDisablesWebhook() {
+	webhook, other := mockWebhook(), mockWebhook()
+	webhook.ConsecutiveFailures = 1
+	other.UUID = uuid.NewV5(uuid.Nil, "other")
+	deliveries := []model.WebhookDelivery{
+		webhookDelivery(1, webhook.UUID), webhookDelivery(2, webhook.UUID), webhookDelivery(3, other.UUID),
+	}
+	now := s.clock.Now()
+
+	gomock.InOrder(
+		s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), now, 3).Return(deliveries, nil),
+		s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), now, 3).Return(nil, nil),
+	)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), other.UUID.String()).Return(other, nil)
+
+	disabled := webhook
+	disabled.ConsecutiveFailures, disabled.Enabled, disabled.DisabledAt = 3, false, &now
+	gomock.InOrder(
+		s.mockSender.EXPECT().Send(gomock.Any(), webhook.URL, webhook.Secret, deliveries[0]).Return(0, errors.New("connection refused")),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).Return(nil),
+		s.mockSender.EXPECT().Send(gomock.Any(), webhook.URL, webhook.Secret, deliveries[1]).Return(0, errors.New("connection refused")),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		s.mockWebhooks.EXPECT().UpdateStatus(gomock.Any(), disabled).Return(nil),
+		s.mockSender.EXPECT().Send(gomock.Any(), other.URL, other.Secret, deliveries[2]).Return(200, nil),
+		s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	delivered, err := s.service.Deliver(context.Background())
+	s.Require().NoError(err)
+	s.Equal(1, delivered)
+}
+
+// deliveries are not recorded when the deliverer is stopped while sending
+func (s *webhookServiceSuite) TestDeliverCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	webhook := mockWebhook()
+	s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), gomock.Any(), 3).
+		Return([]model.WebhookDelivery{webhookDelivery(1, webhook.UUID)}, nil)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil)
+	s.mockSender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _ string, _ model.WebhookDelivery) (int, error) {
+			cancel()
+
+			return 0, ctx.Err()
+		})
+
+	delivered, err := s.service.Deliver(ctx)
+	s.ErrorIs(err, context.Canceled)
+	s.Zero(delivered)
+}
+
+func (s *webhookServiceSuite) TestDeliverRecordFailed() {
+	mockDBError := errors.New("some-db-error")
+	webhook := mockWebhook()
+	s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), gomock.Any(), 3).
+		Return([]model.WebhookDelivery{webhookDelivery(1, webhook.UUID)}, nil)
+	s.mockWebhooks.EXPECT().Get(gomock.Any(), webhook.UUID.String()).Return(webhook, nil)
+	s.mockSender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(200, nil)
+	s.mockWebhooks.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockDBError)
+
+	delivered, err := s.service.Deliver(context.Background())
+	s.ErrorIs(err, mockDBError)
+	s.Zero(delivered)
+}
+
+func (s *webhookServiceSuite) TestDeliverDueFailed() {
+	mockDBError := errors.New("some-db-error")
+	s.mockWebhooks.EXPECT().DueDeliveries(gomock.Any(), gomock.Any(), 3).Return(nil, mockDBError)
+
+	_, err := s.service.Deliver(context.Background())
+	s.ErrorIs(err, mockDBError)
+}
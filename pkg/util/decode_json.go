@@ -0,0 +1,268 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NDJSONContentType is the media type of newline delimited JSON, one JSON value per line
+const NDJSONContentType = "application/x-ndjson"
+
+// Codes of the field errors reported while decoding
+const (
+	FieldCodeUnknown     = "unknown_field"
+	FieldCodeInvalidType = "invalid_type"
+)
+
+// DecodeError describes why a request body could not be decoded. Status is the response status
+// to report it with and Field, if set, points to the offending field of the body
+type DecodeError struct {
+	Status int
+	Field  *FieldError
+	Msg    string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON decodes the body of r into v. The body must be sent with Content-Type
+// "application/json", be at most maxBytes long and hold a single JSON value without fields
+// unknown to v. A maxBytes of 0 keeps any limit already set on the body, e.g. by
+// http.MaxBytesHandler. Any violation is returned as a *DecodeError
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any, maxBytes int64) error {
+	if _, err := checkContentType(r, "application/json"); err != nil {
+		return err
+	}
+
+	dec := newDecoder(w, r, maxBytes)
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	return checkEOF(dec, "request body must contain a single JSON value")
+}
+
+// DecodeJSONList decodes the body of r into a list of at most maxItems values, either a JSON
+// array sent with Content-Type "application/json" or one JSON value per line sent with
+// "application/x-ndjson". The body is limited and checked like by DecodeJSON. Each item is
+// decoded on its own, so an item holding a value of the wrong type or an unknown field does not
+// fail the others: it is left as its zero value and its field error is returned by its index.
+// Only a body that is not a well-formed list of JSON values is returned as a *DecodeError
+func DecodeJSONList[T any](
+	w http.ResponseWriter,
+	r *http.Request,
+	maxBytes int64,
+	maxItems int,
+) ([]T, map[int]FieldError, error) {
+	mediaType, err := checkContentType(r, "application/json", NDJSONContentType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec := newDecoder(w, r, maxBytes)
+
+	if mediaType == "application/json" {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, decodeError(err)
+		}
+		if tok != json.Delim('[') {
+			return nil, nil, &DecodeError{Status: http.StatusBadRequest, Msg: "request body must be a JSON array"}
+		}
+	}
+
+	var (
+		items  []T
+		failed = map[int]FieldError{}
+	)
+	for dec.More() {
+		if len(items) == maxItems {
+			return nil, nil, &DecodeError{
+				Status: http.StatusRequestEntityTooLarge,
+				Msg:    fmt.Sprintf("request body must not contain more than %d items", maxItems),
+			}
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, decodeError(err)
+		}
+
+		var item T
+		if err := decodeItem(raw, &item); err != nil {
+			dErr := decodeError(err)
+			if dErr.Field == nil {
+				dErr.Msg = fmt.Sprintf("item %d: %s", len(items), dErr.Msg)
+
+				return nil, nil, dErr
+			}
+			failed[len(items)] = *dErr.Field
+			item = *new(T)
+		}
+		items = append(items, item)
+	}
+
+	if mediaType == "application/json" {
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, decodeError(err)
+		}
+	}
+
+	if err := checkEOF(dec, "request body must contain a single JSON array or JSON values only"); err != nil {
+		return nil, nil, err
+	}
+
+	return items, failed, nil
+}
+
+// decodeItem decodes an item of a list into v, rejecting fields unknown to v like the decoder
+// of the body
+func decodeItem(raw json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
+
+func newDecoder(w http.ResponseWriter, r *http.Request, maxBytes int64) *json.Decoder {
+	body := r.Body
+	if maxBytes > 0 {
+		body = http.MaxBytesReader(w, body, maxBytes)
+	}
+
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+
+	return dec
+}
+
+// checkEOF returns a *DecodeError with msg if anything but white space is left in the body
+func checkEOF(dec *json.Decoder, msg string) error {
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+
+		return &DecodeError{
+			Status: http.StatusBadRequest,
+			Msg:    msg,
+			Err:    err,
+		}
+	}
+
+	return nil
+}
+
+// checkContentType returns the media type of the body of r, which must be one of mediaTypes and
+// encoded in UTF-8
+func checkContentType(r *http.Request, mediaTypes ...string) (string, error) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && slices.Contains(mediaTypes, mediaType) {
+		if charset, ok := params["charset"]; !ok || strings.EqualFold(charset, "utf-8") {
+			return mediaType, nil
+		}
+	}
+
+	return "", &DecodeError{
+		Status: http.StatusUnsupportedMediaType,
+		Msg:    fmt.Sprintf("Content-Type must be %s, got: '%s'", strings.Join(mediaTypes, " or "), contentType),
+		Err:    err,
+	}
+}
+
+func decodeError(err error) *DecodeError {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return &DecodeError{Status: http.StatusBadRequest, Msg: "request body cannot be empty", Err: err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{Status: http.StatusBadRequest, Msg: "request body contains malformed JSON", Err: err}
+	case errors.As(err, &syntaxErr):
+		return &DecodeError{
+			Status: http.StatusBadRequest,
+			Msg:    fmt.Sprintf("request body contains malformed JSON at offset %d", syntaxErr.Offset),
+			Err:    err,
+		}
+	case errors.As(err, &typeErr):
+		field := &FieldError{
+			Field:   typeErr.Field,
+			Code:    FieldCodeInvalidType,
+			Message: fmt.Sprintf("expected %s, got %s", jsonType(typeErr.Type), typeErr.Value),
+		}
+
+		msg := fmt.Sprintf("invalid value for field '%s'", typeErr.Field)
+		if typeErr.Field == "" {
+			msg = "request body must be a JSON " + jsonType(typeErr.Type)
+		}
+
+		return &DecodeError{
+			Status: http.StatusBadRequest,
+			Field:  field,
+			Msg:    msg,
+			Err:    err,
+		}
+	case errors.As(err, &maxBytesErr):
+		return &DecodeError{
+			Status: http.StatusRequestEntityTooLarge,
+			Msg:    fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit),
+			Err:    err,
+		}
+	}
+
+	// the decoder has no typed error for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+
+		return &DecodeError{
+			Status: http.StatusBadRequest,
+			Field:  &FieldError{Field: name, Code: FieldCodeUnknown, Message: "unknown field"},
+			Msg:    fmt.Sprintf("request body contains unknown field '%s'", name),
+			Err:    err,
+		}
+	}
+
+	return &DecodeError{Status: http.StatusBadRequest, Msg: "failed to decode request body", Err: err}
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.Kind().String()
+	}
+}
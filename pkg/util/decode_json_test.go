@@ -0,0 +1,464 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeAddress struct {
+	Zip int `json:"zip"`
+}
+
+type decodeRequest struct {
+	Name    string          `json:"name"`
+	Count   int             `json:"count"`
+	Tags    []string        `json:"tags"`
+	Address decodeAddress   `json:"address"`
+	Items   []decodeAddress `json:"items"`
+}
+
+func newDecodeRequest(contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	return r
+}
+
+func TestDecodeJSON(t *testing.T) {
+	r := newDecodeRequest("application/json; charset=UTF-8", ` {"name":"abc","count":2,"address":{"zip":123}} `+"\n")
+
+	var v decodeRequest
+	require.NoError(t, DecodeJSON(httptest.NewRecorder(), r, &v, 1024))
+	assert.Equal(t, decodeRequest{Name: "abc", Count: 2, Address: decodeAddress{Zip: 123}}, v)
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		status      int
+		msg         string
+		field       *FieldError
+	}{
+		{
+			name:        "missing content type",
+			contentType: "",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json, got: ''",
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json, got: 'text/plain'",
+		},
+		{
+			name:        "ndjson content type",
+			contentType: NDJSONContentType,
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json, got: 'application/x-ndjson'",
+		},
+		{
+			name:        "wrong charset",
+			contentType: "application/json; charset=latin1",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json, got: 'application/json; charset=latin1'",
+		},
+		{
+			name:        "malformed content type",
+			contentType: "application/json; charset",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json, got: 'application/json; charset'",
+		},
+		{
+			name:   "empty body",
+			body:   ``,
+			status: http.StatusBadRequest,
+			msg:    "request body cannot be empty",
+		},
+		{
+			name:   "truncated body",
+			body:   `{"name":"abc"`,
+			status: http.StatusBadRequest,
+			msg:    "request body contains malformed JSON",
+		},
+		{
+			name:   "syntax error",
+			body:   `{"name":abc}`,
+			status: http.StatusBadRequest,
+			msg:    "request body contains malformed JSON at offset 9",
+		},
+		{
+			name:   "trailing value",
+			body:   `{"name":"abc"} {"name":"def"}`,
+			status: http.StatusBadRequest,
+			msg:    "request body must contain a single JSON value",
+		},
+		{
+			name:   "trailing garbage",
+			body:   `{"name":"abc"} x`,
+			status: http.StatusBadRequest,
+			msg:    "request body must contain a single JSON value",
+		},
+		{
+			name:     "too large",
+			body:     `{"name":"abcdefghijklmnopqrstuvwxyz"}`,
+			maxBytes: 16,
+			status:   http.StatusRequestEntityTooLarge,
+			msg:      "request body must not be larger than 16 bytes",
+		},
+		{
+			name:     "too large after the value",
+			body:     `{"name":"abc"}` + strings.Repeat(" ", 32),
+			maxBytes: 16,
+			status:   http.StatusRequestEntityTooLarge,
+			msg:      "request body must not be larger than 16 bytes",
+		},
+		{
+			name:   "invalid type",
+			body:   `{"count":"2"}`,
+			status: http.StatusBadRequest,
+			msg:    "invalid value for field 'count'",
+			field:  &FieldError{Field: "count", Code: FieldCodeInvalidType, Message: "expected number, got string"},
+		},
+		{
+			name:   "invalid type of nested field",
+			body:   `{"address":{"zip":true}}`,
+			status: http.StatusBadRequest,
+			msg:    "invalid value for field 'address.zip'",
+			field:  &FieldError{Field: "address.zip", Code: FieldCodeInvalidType, Message: "expected number, got bool"},
+		},
+		{
+			name:   "invalid type of array item field",
+			body:   `{"items":[{"zip":1},{"zip":[]}]}`,
+			status: http.StatusBadRequest,
+			msg:    "invalid value for field 'items.1.zip'",
+			field:  &FieldError{Field: "items.1.zip", Code: FieldCodeInvalidType, Message: "expected number, got array"},
+		},
+		{
+			name:   "invalid type of array",
+			body:   `{"tags":{}}`,
+			status: http.StatusBadRequest,
+			msg:    "invalid value for field 'tags'",
+			field:  &FieldError{Field: "tags", Code: FieldCodeInvalidType, Message: "expected array, got object"},
+		},
+		{
+			name:   "invalid type of object",
+			body:   `{"address":1}`,
+			status: http.StatusBadRequest,
+			msg:    "invalid value for field 'address'",
+			field:  &FieldError{Field: "address", Code: FieldCodeInvalidType, Message: "expected object, got number"},
+		},
+		{
+			name:   "invalid type of body",
+			body:   `[{"name":"abc"}]`,
+			status: http.StatusBadRequest,
+			msg:    "request body must be a JSON object",
+			field:  &FieldError{Field: "", Code: FieldCodeInvalidType, Message: "expected object, got array"},
+		},
+		{
+			name:   "unknown field",
+			body:   `{"name":"abc","color":"red"}`,
+			status: http.StatusBadRequest,
+			msg:    "request body contains unknown field 'color'",
+			field:  &FieldError{Field: "color", Code: FieldCodeUnknown, Message: "unknown field"},
+		},
+		{
+			name:   "unknown nested field",
+			body:   `{"address":{"zip":1,"street":"x"}}`,
+			status: http.StatusBadRequest,
+			msg:    "request body contains unknown field 'street'",
+			field:  &FieldError{Field: "street", Code: FieldCodeUnknown, Message: "unknown field"},
+		},
+		{
+			name:   "unknown field with quotes",
+			body:   `{"a\"b":1}`,
+			status: http.StatusBadRequest,
+			msg:    `request body contains unknown field 'a"b'`,
+			field:  &FieldError{Field: `a"b`, Code: FieldCodeUnknown, Message: "unknown field"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType := tt.contentType
+			if contentType == "" && tt.status != http.StatusUnsupportedMediaType {
+				contentType = "application/json"
+			}
+
+			var v decodeRequest
+			err := DecodeJSON(httptest.NewRecorder(), newDecodeRequest(contentType, tt.body), &v, tt.maxBytes)
+
+			var dErr *DecodeError
+			require.ErrorAs(t, err, &dErr)
+			assert.Equal(t, tt.status, dErr.Status)
+			assert.Equal(t, tt.msg, dErr.Msg)
+			assert.Equal(t, tt.field, dErr.Field)
+		})
+	}
+}
+
+// a maxBytes of 0 keeps the limit set on the body
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := newDecodeRequest("application/json", `{"name":"abcdefghijklmnopqrstuvwxyz"}`)
+	r.Body = http.MaxBytesReader(w, r.Body, 8)
+
+	var v decodeRequest
+	err := DecodeJSON(w, r, &v, 0)
+
+	var dErr *DecodeError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, dErr.Status)
+	assert.Equal(t, "request body must not be larger than 8 bytes", dErr.Msg)
+}
+
+func TestDecodeJSONList(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxItems    int
+		want        []decodeAddress
+		failed      map[int]FieldError
+	}{
+		{
+			name:        "array",
+			contentType: "application/json",
+			body:        `[{"zip":1}, {"zip":2}]`,
+			maxItems:    10,
+			want:        []decodeAddress{{Zip: 1}, {Zip: 2}},
+		},
+		{
+			name:        "ndjson",
+			contentType: NDJSONContentType,
+			body:        "{\"zip\":1}\n{\"zip\":2}\n",
+			maxItems:    10,
+			want:        []decodeAddress{{Zip: 1}, {Zip: 2}},
+		},
+		{
+			name:        "ndjson without trailing newline",
+			contentType: NDJSONContentType + "; charset=utf-8",
+			body:        "{\"zip\":1}\n{\"zip\":2}",
+			maxItems:    10,
+			want:        []decodeAddress{{Zip: 1}, {Zip: 2}},
+		},
+		{
+			name:        "empty array",
+			contentType: "application/json",
+			body:        `[]`,
+			maxItems:    10,
+		},
+		{
+			name:        "empty ndjson",
+			contentType: NDJSONContentType,
+			body:        "\n",
+			maxItems:    10,
+		},
+		{
+			name:        "array of max items",
+			contentType: "application/json",
+			body:        `[{"zip":1},{"zip":2}]`,
+			maxItems:    2,
+			want:        []decodeAddress{{Zip: 1}, {Zip: 2}},
+		},
+		{
+			name:        "ndjson of max items",
+			contentType: NDJSONContentType,
+			body:        "{\"zip\":1}\n{\"zip\":2}\n",
+			maxItems:    2,
+			want:        []decodeAddress{{Zip: 1}, {Zip: 2}},
+		},
+		{
+			name:        "empty array with no items allowed",
+			contentType: "application/json",
+			body:        `[]`,
+			maxItems:    0,
+		},
+		{
+			name:        "items failing on their own",
+			contentType: "application/json",
+			body:        `[{"zip":"1"},{"zip":2},{"zip":3,"street":"x"}]`,
+			maxItems:    10,
+			want:        []decodeAddress{{}, {Zip: 2}, {}},
+			failed: map[int]FieldError{
+				0: {Field: "zip", Code: FieldCodeInvalidType, Message: "expected number, got string"},
+				2: {Field: "street", Code: FieldCodeUnknown, Message: "unknown field"},
+			},
+		},
+		{
+			name:        "array sent as ndjson is a single item of the wrong type",
+			contentType: NDJSONContentType,
+			body:        `[{"zip":1},{"zip":2}]`,
+			maxItems:    10,
+			want:        []decodeAddress{{}},
+			failed: map[int]FieldError{
+				0: {Field: "", Code: FieldCodeInvalidType, Message: "expected object, got array"},
+			},
+		},
+		{
+			name:        "ndjson items failing on their own",
+			contentType: NDJSONContentType,
+			body:        "{\"zip\":1}\n{\"zip\":false}\n",
+			maxItems:    10,
+			want:        []decodeAddress{{Zip: 1}, {}},
+			failed: map[int]FieldError{
+				1: {Field: "zip", Code: FieldCodeInvalidType, Message: "expected number, got bool"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, failed, err := DecodeJSONList[decodeAddress](
+				httptest.NewRecorder(), newDecodeRequest(tt.contentType, tt.body), 1024, tt.maxItems)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, items)
+			if tt.failed == nil {
+				assert.Empty(t, failed)
+			} else {
+				assert.Equal(t, tt.failed, failed)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONListErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		maxItems    int
+		status      int
+		msg         string
+	}{
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `[]`,
+			maxItems:    10,
+			status:      http.StatusUnsupportedMediaType,
+			msg:         "Content-Type must be application/json or application/x-ndjson, got: 'text/plain'",
+		},
+		{
+			name:        "object instead of array",
+			contentType: "application/json",
+			body:        `{"zip":1}`,
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body must be a JSON array",
+		},
+		{
+			name:        "ndjson sent as json",
+			contentType: "application/json",
+			body:        "{\"zip\":1}\n{\"zip\":2}\n",
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body must be a JSON array",
+		},
+		{
+			name:        "empty array body",
+			contentType: "application/json",
+			body:        ``,
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body cannot be empty",
+		},
+		{
+			name:        "unterminated array",
+			contentType: "application/json",
+			body:        `[{"zip":1}`,
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body contains malformed JSON at offset 10",
+		},
+		{
+			name:        "malformed ndjson line",
+			contentType: NDJSONContentType,
+			body:        "{\"zip\":1}\n{\"zip\":}\n",
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body contains malformed JSON at offset 18",
+		},
+		{
+			name:        "trailing data after array",
+			contentType: "application/json",
+			body:        `[{"zip":1}] {"zip":2}`,
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body must contain a single JSON array or JSON values only",
+		},
+		{
+			name:        "second array",
+			contentType: "application/json",
+			body:        `[] []`,
+			maxItems:    10,
+			status:      http.StatusBadRequest,
+			msg:         "request body must contain a single JSON array or JSON values only",
+		},
+		{
+			name:        "array over max items",
+			contentType: "application/json",
+			body:        `[{"zip":1},{"zip":2},{"zip":3}]`,
+			maxItems:    2,
+			status:      http.StatusRequestEntityTooLarge,
+			msg:         "request body must not contain more than 2 items",
+		},
+		{
+			name:        "ndjson over max items",
+			contentType: NDJSONContentType,
+			body:        "{\"zip\":1}\n{\"zip\":2}\n{\"zip\":3}\n",
+			maxItems:    2,
+			status:      http.StatusRequestEntityTooLarge,
+			msg:         "request body must not contain more than 2 items",
+		},
+		{
+			name:        "item with no items allowed",
+			contentType: "application/json",
+			body:        `[{"zip":1}]`,
+			maxItems:    0,
+			status:      http.StatusRequestEntityTooLarge,
+			msg:         "request body must not contain more than 0 items",
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `[{"zip":1},{"zip":2},{"zip":3}]`,
+			maxBytes:    16,
+			maxItems:    10,
+			status:      http.StatusRequestEntityTooLarge,
+			msg:         "request body must not be larger than 16 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxBytes := tt.maxBytes
+			if maxBytes == 0 {
+				maxBytes = 1024
+			}
+
+			items, failed, err := DecodeJSONList[decodeAddress](
+				httptest.NewRecorder(), newDecodeRequest(tt.contentType, tt.body), maxBytes, tt.maxItems)
+
+			var dErr *DecodeError
+			require.ErrorAs(t, err, &dErr)
+			assert.Equal(t, tt.status, dErr.Status)
+			assert.Equal(t, tt.msg, dErr.Msg)
+			assert.Nil(t, items)
+			assert.Nil(t, failed)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// mediaRange is a media range of an Accept header with its quality value
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// acceptedMediaRanges parses the Accept headers of r, skipping malformed ranges and those with
+// a quality value outside [0, 1]
+func acceptedMediaRanges(r *http.Request) []mediaRange {
+	var ranges []mediaRange
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				// NaN fails the range check too
+				if q, err = strconv.ParseFloat(v, 64); err != nil || !(q >= 0 && q <= 1) {
+					continue
+				}
+			}
+
+			ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+		}
+	}
+
+	return ranges
+}
+
+// NegotiateContentType returns the offered media type the Accept header of r prefers, the
+// first offer winning ties. Each offer takes the quality of the most specific range matching
+// it, so "text/csv;q=0" rejects CSV even when "*/*" is accepted. Without a valid Accept header
+// the first offer is returned, ok is false when none of the offers is acceptable
+func NegotiateContentType(r *http.Request, offers ...string) (string, bool) {
+	ranges := acceptedMediaRanges(r)
+	if len(ranges) == 0 {
+		if len(offers) == 0 {
+			return "", false
+		}
+
+		return offers[0], true
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, offer := range offers {
+		q, specificity := 0.0, 0
+		for _, mr := range ranges {
+			if s := matchMediaRange(mr.mediaType, offer); s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// matchMediaRange returns how specifically mediaRange matches mediaType, zero when it does
+// not match
+func matchMediaRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 3
+	case mediaRange == "*/*":
+		return 1
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 2
+	default:
+		return 0
+	}
+}
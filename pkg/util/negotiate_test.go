@@ -0,0 +1,82 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAcceptRequest returns a request with an Accept header for each of the values
+func newAcceptRequest(accept ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, a := range accept {
+		r.Header.Add("Accept", a)
+	}
+
+	return r
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "text/csv", "application/pdf"}
+
+	tests := []struct {
+		name   string
+		accept []string
+		offers []string
+		want   string
+		ok     bool
+	}{
+		{"no accept header", nil, offers, "application/json", true},
+		{"empty accept header", []string{""}, offers, "application/json", true},
+		{"no offers without accept header", nil, nil, "", false},
+		{"no offers", []string{"*/*"}, nil, "", false},
+		{"exact match", []string{"text/csv"}, offers, "text/csv", true},
+		{"case insensitive range", []string{"Text/CSV"}, offers, "text/csv", true},
+		{"first offer wins ties", []string{"application/pdf, text/csv"}, offers, "text/csv", true},
+		{"any", []string{"*/*"}, offers, "application/json", true},
+		{"type wildcard", []string{"text/*"}, offers, "text/csv", true},
+		{"type wildcard does not match other types", []string{"image/*"}, offers, "", false},
+		{"no match", []string{"image/png"}, offers, "", false},
+
+		// quality values
+		{"higher q wins", []string{"application/json;q=0.5, text/csv;q=0.8"}, offers, "text/csv", true},
+		{"q defaults to 1", []string{"application/json;q=0.9, application/pdf"}, offers, "application/pdf", true},
+		{"q with spaces", []string{"application/json ; q=0.2, text/csv ; q=0.3"}, offers, "text/csv", true},
+		{"q of three decimals", []string{"text/csv;q=0.001, application/pdf;q=0.002"}, offers, "application/pdf", true},
+		{"q with other params", []string{"text/csv;charset=utf-8;q=0.4, application/json;q=0.3"}, offers, "text/csv", true},
+		{"several headers", []string{"application/json;q=0.1", "application/pdf;q=0.7"}, offers, "application/pdf", true},
+
+		// q=0 excludes
+		{"q=0 excludes the type", []string{"application/json;q=0, text/csv;q=0.1"}, offers, "text/csv", true},
+		{"q=0 excludes every offer", []string{"application/json;q=0"}, []string{"application/json"}, "", false},
+		{"q=0.000 excludes", []string{"text/csv;q=0.000"}, []string{"text/csv"}, "", false},
+		{"q=0 overrides any", []string{"*/*, application/json;q=0"}, offers, "text/csv", true},
+		{"q=0 overrides type wildcard", []string{"text/*, text/csv;q=0"}, offers, "", false},
+		{"q=0 any excludes the others", []string{"*/*;q=0, text/csv"}, offers, "text/csv", true},
+
+		// the most specific range sets the quality of an offer, whatever its q
+		{"type wildcard before any", []string{"*/*;q=0.9, application/*;q=0.1, text/csv;q=0.05"}, offers, "application/json", true},
+		{"exact before type wildcard", []string{"text/*;q=0.1, text/csv;q=0.9, */*;q=0.5"}, offers, "text/csv", true},
+		{"lower type wildcard loses to any", []string{"application/*;q=0.2, */*;q=0.8"}, offers, "text/csv", true},
+		{"order does not matter", []string{"application/*;q=0.1, */*;q=0.9"}, offers, "text/csv", true},
+		{"type wildcard matches the full type only", []string{"text/*, application/js*"}, offers, "text/csv", true},
+
+		// malformed ranges are skipped
+		{"malformed range", []string{"text/csv;q=0.5, ;;, application/pdf;q=0.9"}, offers, "application/pdf", true},
+		{"missing subtype", []string{"text/, application/pdf;q=0.2"}, offers, "application/pdf", true},
+		{"malformed q", []string{"application/json;q=abc, text/csv;q=0.5"}, offers, "text/csv", true},
+		{"q above 1", []string{"application/json;q=2, text/csv;q=0.5"}, offers, "text/csv", true},
+		{"negative q", []string{"application/json;q=-1"}, offers, "application/json", true},
+		{"NaN q", []string{"application/json;q=NaN, text/csv;q=0.1"}, offers, "text/csv", true},
+		{"only malformed ranges", []string{"garbage;;, /"}, offers, "application/json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NegotiateContentType(newAcceptRequest(tt.accept...), tt.offers...)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
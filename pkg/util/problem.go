@@ -0,0 +1,71 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"go-pismo-challenge/pkg/telemetry"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI prefixes the error code to build the type URI of a problem document, it
+// resolves to the description of the code in docs/problems.md
+const ProblemTypeBaseURI = "https://github.com/raxitchauhan/go-pismo-challenge/blob/main/docs/problems.md#"
+
+// ProblemDetails is an RFC 9457 problem document. Code, Trace and Errors are extension members,
+// Errors holding one entry per invalid field of the request
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Trace    string       `json:"trace,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ProblemType returns the type URI of the problem documents with the given error code
+func ProblemType(code string) string {
+	return ProblemTypeBaseURI + code
+}
+
+// WriteProblemJSON writes p with Content-Type set to "application/problem+json". The type is
+// derived from the code and the trace ID of the span in ctx is attached, unless already set
+func WriteProblemJSON(ctx context.Context, w http.ResponseWriter, p ProblemDetails) error {
+	if p.Status == 0 {
+		return ErrEmptyHTTPStatus
+	}
+	if p.Title == "" {
+		return ErrEmptyErrorMessage
+	}
+
+	if p.Type == "" {
+		p.Type = ProblemType(p.Code)
+	}
+	if p.Trace == "" {
+		p.Trace = telemetry.TraceID(ctx)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+
+	return json.NewEncoder(w).Encode(p)
+}
+
+// AcceptsProblemJSON reports whether the Accept header of r lists "application/problem+json"
+// with a quality value at least as high as any other media type, so that clients asking for
+// plain JSON keep the default error format
+func AcceptsProblemJSON(r *http.Request) bool {
+	var problemQ, otherQ float64
+	for _, mr := range acceptedMediaRanges(r) {
+		if mr.mediaType == ProblemContentType {
+			problemQ = max(problemQ, mr.q)
+		} else {
+			otherQ = max(otherQ, mr.q)
+		}
+	}
+
+	return problemQ > 0 && problemQ >= otherQ
+}
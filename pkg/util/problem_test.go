@@ -0,0 +1,135 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAcceptsProblemJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		want   bool
+	}{
+		// plain JSON errors are the fallback
+		{"no accept header", nil, false},
+		{"any", []string{"*/*"}, false},
+		{"type wildcard", []string{"application/*"}, false},
+		{"json", []string{"application/json"}, false},
+		{"malformed only", []string{"application/problem+json;;"}, false},
+
+		{"problem json", []string{"application/problem+json"}, true},
+		{"case insensitive", []string{"Application/Problem+JSON"}, true},
+		{"problem json before any", []string{"application/problem+json, */*;q=0.8"}, true},
+		{"problem json and json tie", []string{"application/json, application/problem+json"}, true},
+		{"several headers", []string{"application/json;q=0.5", "application/problem+json"}, true},
+		{"with params", []string{"application/problem+json;charset=utf-8;q=0.9, application/json;q=0.8"}, true},
+		{"malformed range skipped", []string{"application/problem+json, text/;q=1"}, true},
+		{"malformed q skipped", []string{"application/problem+json;q=0.5, application/json;q=abc"}, true},
+		{"q above 1 skipped", []string{"application/problem+json;q=0.5, application/json;q=5"}, true},
+
+		{"json preferred", []string{"application/json, application/problem+json;q=0.9"}, false},
+		{"any preferred", []string{"*/*, application/problem+json;q=0.5"}, false},
+		{"type wildcard preferred", []string{"application/*;q=0.9, application/problem+json;q=0.8"}, false},
+		{"problem json q=0", []string{"application/problem+json;q=0"}, false},
+		{"problem json q=0 with any", []string{"application/problem+json;q=0, */*;q=0.1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AcceptsProblemJSON(newAcceptRequest(tt.accept...)))
+		})
+	}
+}
+
+func TestWriteProblemJSON(t *testing.T) {
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		in   ProblemDetails
+		want ProblemDetails
+	}{
+		{
+			name: "type and trace derived",
+			ctx:  ctx,
+			in: ProblemDetails{
+				Title:  "failed to create account",
+				Status: http.StatusBadRequest,
+				Code:   "validation_failed",
+				Errors: []FieldError{{Field: "document_number", Code: "required", Message: "field is required"}},
+			},
+			want: ProblemDetails{
+				Type:   ProblemTypeBaseURI + "validation_failed",
+				Title:  "failed to create account",
+				Status: http.StatusBadRequest,
+				Code:   "validation_failed",
+				Trace:  traceID.String(),
+				Errors: []FieldError{{Field: "document_number", Code: "required", Message: "field is required"}},
+			},
+		},
+		{
+			name: "type and trace kept",
+			ctx:  ctx,
+			in: ProblemDetails{
+				Type:   "about:blank",
+				Title:  "account not found",
+				Status: http.StatusNotFound,
+				Detail: "account not found for uuid: 'x'",
+				Code:   "not_found",
+				Trace:  "some-trace",
+			},
+			want: ProblemDetails{
+				Type:   "about:blank",
+				Title:  "account not found",
+				Status: http.StatusNotFound,
+				Detail: "account not found for uuid: 'x'",
+				Code:   "not_found",
+				Trace:  "some-trace",
+			},
+		},
+		{
+			name: "no span",
+			ctx:  context.Background(),
+			in:   ProblemDetails{Title: "internal error", Status: http.StatusInternalServerError, Code: "internal"},
+			want: ProblemDetails{
+				Type:   ProblemTypeBaseURI + "internal",
+				Title:  "internal error",
+				Status: http.StatusInternalServerError,
+				Code:   "internal",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			require.NoError(t, WriteProblemJSON(tt.ctx, w, tt.in))
+
+			assert.Equal(t, tt.want.Status, w.Code)
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+			var got ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteProblemJSONInvalid(t *testing.T) {
+	w := httptest.NewRecorder()
+	assert.ErrorIs(t, WriteProblemJSON(context.Background(), w, ProblemDetails{Title: "x"}), ErrEmptyHTTPStatus)
+	assert.ErrorIs(t, WriteProblemJSON(context.Background(), w, ProblemDetails{Status: http.StatusBadRequest}), ErrEmptyErrorMessage)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Type"))
+}
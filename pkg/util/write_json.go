@@ -1,9 +1,11 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go-pismo-challenge/pkg/telemetry"
 	"net/http"
 
 	"github.com/gofrs/uuid"
@@ -26,12 +28,14 @@ type (
 		Title   string      `json:"title"`
 		Details string      `json:"detail"`
 		Source  *FieldError `json:"source,omitempty"`
-		// Trace   string      `json:"trace,omitempty"`
+		Trace   string      `json:"trace,omitempty"`
 	}
 
-	// FieldError describes an error for a specific field, usually provided upon the request
+	// FieldError describes an error for a specific field, usually provided upon the request.
+	// Code is a stable, machine readable identifier of the error, Message is meant for humans
 	FieldError struct {
 		Field   string `json:"field"`
+		Code    string `json:"code"`
 		Message string `json:"message"`
 	}
 )
@@ -49,8 +53,10 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) (err error)
 }
 
 // WriteJSONError sets the fields and writes them into the given reader as JSON with
-// Content-Type set to "application/json; charset=utf-8"
+// Content-Type set to "application/json; charset=utf-8". The trace ID of the span in ctx,
+// if any, is attached to every error
 func WriteJSONError(
+	ctx context.Context,
 	w http.ResponseWriter,
 	status int,
 	errDesc ErrorDescription,
@@ -60,6 +66,10 @@ func WriteJSONError(
 		return ErrEmptyErrorMessage
 	}
 
+	if errDesc.Trace == "" {
+		errDesc.Trace = telemetry.TraceID(ctx)
+	}
+
 	var errResps []ErrorDescription
 	if len(sources) > 0 {
 		errResps = make([]ErrorDescription, 0, len(sources))
@@ -0,0 +1,186 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	errUnknownRule     = errors.New("unknown rule")
+	errUnsupportedKind = errors.New("rule does not apply to the field type")
+	errMissingParam    = errors.New("rule requires a parameter")
+)
+
+// oneofValues splits the parameter of oneof, values containing spaces are quoted with '
+var oneofValues = regexp.MustCompile(`'[^']*'|\S+`)
+
+func newCheck(name, param string, t reflect.Type) (check, error) {
+	if name != "uuid" && param == "" {
+		return nil, errMissingParam
+	}
+
+	switch name {
+	case "uuid":
+		return uuidCheck(t)
+	case "min":
+		return boundCheck(param, t, CodeTooSmall, "at least", func(v, bound float64) bool { return v >= bound })
+	case "max":
+		return boundCheck(param, t, CodeTooLarge, "at most", func(v, bound float64) bool { return v <= bound })
+	case "oneof":
+		return oneofCheck(param, t)
+	case "decimals":
+		return decimalsCheck(param, t)
+	case "regex":
+		return regexCheck(param, t)
+	default:
+		return nil, errUnknownRule
+	}
+}
+
+func uuidCheck(t reflect.Type) (check, error) {
+	if t.Kind() != reflect.String {
+		return nil, errUnsupportedKind
+	}
+
+	return func(v reflect.Value) (string, string, bool) {
+		if _, err := uuid.FromString(v.String()); err != nil {
+			return CodeInvalidUUID, fmt.Sprintf("must be a valid uuid: '%s'", v.String()), false
+		}
+
+		return "", "", true
+	}, nil
+}
+
+// boundCheck compares numbers by value, and strings, slices and maps by length
+func boundCheck(param string, t reflect.Type, code, relation string, ok func(v, bound float64) bool) (check, error) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) (string, string, bool) {
+			n := number(v)
+			if !ok(n, bound) {
+				return code, fmt.Sprintf("must be %s %s: %s", relation, param, formatNumber(v)), false
+			}
+
+			return "", "", true
+		}, nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		unit := "items"
+		if t.Kind() == reflect.String {
+			unit = "characters"
+		}
+
+		return func(v reflect.Value) (string, string, bool) {
+			n := v.Len()
+			if v.Kind() == reflect.String {
+				n = len([]rune(v.String()))
+			}
+			if !ok(float64(n), bound) {
+				return code, fmt.Sprintf("must have %s %s %s: %d", relation, param, unit, n), false
+			}
+
+			return "", "", true
+		}, nil
+	default:
+		return nil, errUnsupportedKind
+	}
+}
+
+func oneofCheck(param string, t reflect.Type) (check, error) {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return nil, errUnsupportedKind
+	}
+
+	allowed := oneofValues.FindAllString(param, -1)
+	for i := range allowed {
+		allowed[i] = strings.Trim(allowed[i], "'")
+	}
+
+	return func(v reflect.Value) (string, string, bool) {
+		s := fmt.Sprint(v.Interface())
+		if !slices.Contains(allowed, s) {
+			return CodeNotAllowed, fmt.Sprintf("must be one of [%s]: '%s'", strings.Join(allowed, ", "), s), false
+		}
+
+		return "", "", true
+	}, nil
+}
+
+func decimalsCheck(param string, t reflect.Type) (check, error) {
+	if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+		return nil, errUnsupportedKind
+	}
+
+	places, err := strconv.Atoi(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(v reflect.Value) (string, string, bool) {
+		// the shortest representation of the float is what the client sent
+		s := formatNumber(v)
+		if _, frac, found := strings.Cut(s, "."); found && len(frac) > places {
+			return CodeTooManyDecimals, fmt.Sprintf("must have at most %d decimal places: %s", places, s), false
+		}
+
+		return "", "", true
+	}, nil
+}
+
+func regexCheck(param string, t reflect.Type) (check, error) {
+	if t.Kind() != reflect.String {
+		return nil, errUnsupportedKind
+	}
+
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(v reflect.Value) (string, string, bool) {
+		if !re.MatchString(v.String()) {
+			return CodePatternMismatch, fmt.Sprintf("must match pattern '%s'", param), false
+		}
+
+		return "", "", true
+	}, nil
+}
+
+func number(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func formatNumber(v reflect.Value) string {
+	switch {
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10)
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+}
@@ -0,0 +1,46 @@
+package validate
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Schema holds the swagger constraints of a field that swag does not read from its validate tag
+type Schema struct {
+	// MultipleOf is the precision of a decimals rule, e.g. 0.01 for decimals=2
+	MultipleOf float64
+	// Pattern is the expression of a regex rule
+	Pattern string
+}
+
+// Schemas returns the Schema of the fields of the struct v that have a decimals or regex rule,
+// by json name. Tags are parsed as by Struct, malformed ones panic
+func Schemas(v any) map[string]Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schemas := map[string]Schema{}
+	for _, f := range fields(t) {
+		if f.schema != (Schema{}) {
+			schemas[f.name] = f.schema
+		}
+	}
+
+	return schemas
+}
+
+// with returns s completed with the rule, which is known to be valid
+func (s Schema) with(rule, param string) Schema {
+	switch rule {
+	case "decimals":
+		places, _ := strconv.Atoi(param)
+		s.MultipleOf = math.Pow10(-places)
+	case "regex":
+		s.Pattern = param
+	}
+
+	return s
+}
@@ -0,0 +1,184 @@
+// Package validate checks structs against the rules declared in their `validate` tags, e.g.
+//
+//	Amount float64 `json:"amount" validate:"required,min=0,decimals=2"`
+//
+// Rules are separated by commas and follow the syntax of go-playground/validator, so that swag
+// documents required, min, max and oneof in the swagger schema, decimals and regex are added to
+// it by cmd/swagger-validate, see Schemas. Commas and pipes inside a rule
+// parameter are written as 0x2C and 0x7C. Supported rules:
+//
+//	required   the field is not its zero value
+//	omitempty  skip the other rules when the field is its zero value
+//	uuid       strings holding a uuid
+//	min, max   bounds of numbers, or of the length of strings, slices and maps
+//	oneof      space separated list of allowed values, quoted with ' if they contain spaces
+//	decimals   maximum number of decimal places of floats
+//	regex      strings matching a regular expression
+//
+// Fields are reported by their json name, nested structs are validated with the path of their
+// fields joined by dots. Malformed tags are programming errors and panic.
+package validate
+
+import (
+	"fmt"
+	"go-pismo-challenge/pkg/util"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Codes identify the rule a field failed, they are stable and meant for clients to act on
+const (
+	CodeRequired        = "required"
+	CodeInvalidUUID     = "invalid_uuid"
+	CodeTooSmall        = "too_small"
+	CodeTooLarge        = "too_large"
+	CodeNotAllowed      = "not_allowed"
+	CodeTooManyDecimals = "too_many_decimals"
+	CodePatternMismatch = "pattern_mismatch"
+)
+
+// check validates a field value, returning the code and message of the failed rule
+type check func(v reflect.Value) (code, message string, ok bool)
+
+type field struct {
+	index     int
+	name      string
+	required  bool
+	omitEmpty bool
+	checks    []check
+	nested    bool
+	schema    Schema
+}
+
+var cache sync.Map //nolint:gochecknoglobals // reflect.Type -> []field, tags are parsed once per type
+
+// Struct validates v, a struct or a pointer to one, returning an error for every invalid field
+// in declaration order, or nil if all of them are valid
+func Struct(v any) []util.FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: expected a struct, got %s", rv.Type()))
+	}
+
+	return validateStruct(rv, "")
+}
+
+func validateStruct(rv reflect.Value, prefix string) []util.FieldError {
+	var errs []util.FieldError
+	for _, f := range fields(rv.Type()) {
+		fv := rv.Field(f.index)
+		name := prefix + f.name
+
+		if fv.IsZero() {
+			if f.required {
+				errs = append(errs, util.FieldError{Field: name, Code: CodeRequired, Message: "field is required"})
+
+				continue
+			}
+			if f.omitEmpty {
+				continue
+			}
+		}
+
+		for _, c := range f.checks {
+			if code, msg, ok := c(fv); !ok {
+				errs = append(errs, util.FieldError{Field: name, Code: code, Message: msg})
+
+				break
+			}
+		}
+
+		if f.nested {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				errs = append(errs, validateStruct(fv, name+".")...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// fields returns the validated fields of the struct type t
+func fields(t reflect.Type) []field {
+	if cached, ok := cache.Load(t); ok {
+		if fs, ok := cached.([]field); ok {
+			return fs
+		}
+	}
+
+	var fs []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+
+		f := field{index: i, name: name, nested: isStruct(sf.Type)}
+		if tag, ok := sf.Tag.Lookup("validate"); ok && tag != "" {
+			parseTag(&f, sf, tag)
+		}
+
+		if f.required || f.omitEmpty || len(f.checks) > 0 || f.nested {
+			fs = append(fs, f)
+		}
+	}
+
+	cache.Store(t, fs)
+
+	return fs
+}
+
+func parseTag(f *field, sf reflect.StructField, tag string) {
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		param = strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param)
+
+		switch name {
+		case "required":
+			f.required = true
+		case "omitempty":
+			f.omitEmpty = true
+		default:
+			c, err := newCheck(name, param, sf.Type)
+			if err != nil {
+				panic(fmt.Sprintf("validate: field %s: rule '%s': %s", sf.Name, rule, err))
+			}
+			f.checks = append(f.checks, c)
+			f.schema = f.schema.with(name, param)
+		}
+	}
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+
+	return name
+}
+
+// isStruct reports whether fields of type t are validated recursively
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t.PkgPath() != "time"
+}
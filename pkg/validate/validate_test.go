@@ -0,0 +1,178 @@
+package validate
+
+import (
+	"go-pismo-challenge/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type validateSuite struct {
+	suite.Suite
+}
+
+func TestValidate(t *testing.T) {
+	suite.Run(t, new(validateSuite))
+}
+
+type address struct {
+	Country string `json:"country" validate:"required,oneof=BR US 'United Kingdom'"`
+}
+
+type request struct {
+	Name     string   `json:"name" validate:"required,min=2,max=5"`
+	ID       string   `json:"id" validate:"omitempty,uuid"`
+	Count    int      `json:"count" validate:"min=1,max=10"`
+	Kind     uint     `json:"kind" validate:"omitempty,oneof=1 2"`
+	Amount   float64  `json:"amount" validate:"min=0,decimals=2"`
+	Code     string   `json:"code" validate:"omitempty,regex=^[A-Z]{2}0x2C[0-9]+$"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Address  address  `json:"address"`
+	Optional *address `json:"optional"`
+	Ignored  string   `json:"-" validate:"required"`
+}
+
+func validRequest() request {
+	return request{
+		Name:    "abc",
+		Count:   1,
+		Amount:  1.1,
+		Address: address{Country: "BR"},
+		Ignored: "x",
+	}
+}
+
+func (s *validateSuite) TestValid() {
+	r := validRequest()
+	r.ID = "550e8400-e29b-41d4-a716-446655440000"
+	r.Kind = 2
+	r.Code = "AB,12"
+	r.Address.Country = "United Kingdom"
+	r.Optional = &address{Country: "US"}
+
+	s.Nil(Struct(r))
+	s.Nil(Struct(&r))
+	s.Nil(Struct((*request)(nil)))
+}
+
+func (s *validateSuite) TestRules() {
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		want   util.FieldError
+	}{
+		{
+			"required",
+			func(r *request) { r.Name = "" },
+			util.FieldError{Field: "name", Code: CodeRequired, Message: "field is required"},
+		},
+		{
+			"min length",
+			func(r *request) { r.Name = "a" },
+			util.FieldError{Field: "name", Code: CodeTooSmall, Message: "must have at least 2 characters: 1"},
+		},
+		{
+			"max length counts runes",
+			func(r *request) { r.Name = "ãããããã" },
+			util.FieldError{Field: "name", Code: CodeTooLarge, Message: "must have at most 5 characters: 6"},
+		},
+		{
+			"uuid",
+			func(r *request) { r.ID = "abc" },
+			util.FieldError{Field: "id", Code: CodeInvalidUUID, Message: "must be a valid uuid: 'abc'"},
+		},
+		{
+			"min applies to zero values",
+			func(r *request) { r.Count = 0 },
+			util.FieldError{Field: "count", Code: CodeTooSmall, Message: "must be at least 1: 0"},
+		},
+		{
+			"max",
+			func(r *request) { r.Count = 11 },
+			util.FieldError{Field: "count", Code: CodeTooLarge, Message: "must be at most 10: 11"},
+		},
+		{
+			"oneof number",
+			func(r *request) { r.Kind = 3 },
+			util.FieldError{Field: "kind", Code: CodeNotAllowed, Message: "must be one of [1, 2]: '3'"},
+		},
+		{
+			"decimals",
+			func(r *request) { r.Amount = 0.001 },
+			util.FieldError{Field: "amount", Code: CodeTooManyDecimals, Message: "must have at most 2 decimal places: 0.001"},
+		},
+		{
+			"regex",
+			func(r *request) { r.Code = "ab,12" },
+			util.FieldError{Field: "code", Code: CodePatternMismatch, Message: "must match pattern '^[A-Z]{2},[0-9]+$'"},
+		},
+		{
+			"max items",
+			func(r *request) { r.Tags = []string{"a", "b", "c"} },
+			util.FieldError{Field: "tags", Code: CodeTooLarge, Message: "must have at most 2 items: 3"},
+		},
+		{
+			"nested",
+			func(r *request) { r.Address.Country = "AR" },
+			util.FieldError{Field: "address.country", Code: CodeNotAllowed, Message: "must be one of [BR, US, United Kingdom]: 'AR'"},
+		},
+		{
+			"nested pointer",
+			func(r *request) { r.Optional = &address{} },
+			util.FieldError{Field: "optional.country", Code: CodeRequired, Message: "field is required"},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			r := validRequest()
+			tt.modify(&r)
+
+			s.Equal([]util.FieldError{tt.want}, Struct(r))
+		})
+	}
+}
+
+// every invalid field is reported once, in declaration order
+func (s *validateSuite) TestAllFields() {
+	errs := Struct(request{Count: 20, Amount: -1})
+
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	s.Equal([]string{"name", "count", "amount", "address.country"}, fields)
+}
+
+func (s *validateSuite) TestMalformedTags() {
+	s.PanicsWithValue("validate: expected a struct, got string", func() { Struct("abc") })
+
+	s.Panics(func() {
+		Struct(struct {
+			A int `validate:"uuid"`
+		}{})
+	})
+	s.Panics(func() {
+		Struct(struct {
+			A int `validate:"unknown=1"`
+		}{})
+	})
+	s.Panics(func() {
+		Struct(struct {
+			A int `validate:"min"`
+		}{})
+	})
+	s.Panics(func() {
+		Struct(struct {
+			A string `validate:"regex=("`
+		}{})
+	})
+}
+
+func (s *validateSuite) TestSchemas() {
+	s.Equal(map[string]Schema{
+		"amount": {MultipleOf: 0.01},
+		"code":   {Pattern: "^[A-Z]{2},[0-9]+$"},
+	}, Schemas(&request{}))
+
+	s.Empty(Schemas(address{}))
+}